@@ -2,41 +2,82 @@ package main
 
 import "time"
 
-type LanguageEnumType string
+type TicketStatusEnumType string
 
 const (
-	English LanguageEnumType = "English"
-	Spanish LanguageEnumType = "Spanish"
-	French  LanguageEnumType = "French"
-	German  LanguageEnumType = "German"
-	Italian LanguageEnumType = "Italian"
-	Russian LanguageEnumType = "Русский"
+	Purchased TicketStatusEnumType = "Purchased"
+	Reserved  TicketStatusEnumType = "Reserved"
+	Available TicketStatusEnumType = "Available"
+	Refunded  TicketStatusEnumType = "Refunded"
 )
 
-func (l LanguageEnumType) IsValid() bool {
-	switch l {
-	case English, Spanish, French, German, Italian, Russian:
+func (t TicketStatusEnumType) IsValid() bool {
+	switch t {
+	case Purchased, Reserved, Available, Refunded:
 		return true
 	}
 	return false
 }
 
-type TicketStatusEnumType string
+type ShowStatusEnumType string
 
 const (
-	Purchased TicketStatusEnumType = "Purchased"
-	Reserved  TicketStatusEnumType = "Reserved"
-	Available TicketStatusEnumType = "Available"
+	ShowScheduled  ShowStatusEnumType = "scheduled"
+	ShowOnSale     ShowStatusEnumType = "on_sale"
+	ShowSoldOut    ShowStatusEnumType = "sold_out"
+	ShowInProgress ShowStatusEnumType = "in_progress"
+	ShowFinished   ShowStatusEnumType = "finished"
+	ShowCancelled  ShowStatusEnumType = "cancelled"
 )
 
-func (t TicketStatusEnumType) IsValid() bool {
-	switch t {
-	case Purchased, Reserved, Available:
+func (s ShowStatusEnumType) IsValid() bool {
+	switch s {
+	case ShowScheduled, ShowOnSale, ShowSoldOut, ShowInProgress, ShowFinished, ShowCancelled:
 		return true
 	}
 	return false
 }
 
+// showStatusTransitions повторяет функцию show_status_transition_allowed из БД
+var showStatusTransitions = map[ShowStatusEnumType][]ShowStatusEnumType{
+	ShowScheduled:  {ShowOnSale, ShowInProgress, ShowCancelled},
+	ShowOnSale:     {ShowScheduled, ShowSoldOut, ShowInProgress, ShowCancelled},
+	ShowSoldOut:    {ShowOnSale, ShowInProgress, ShowCancelled},
+	ShowInProgress: {ShowFinished},
+}
+
+func (s ShowStatusEnumType) CanTransitionTo(next ShowStatusEnumType) bool {
+	if s == next {
+		return true
+	}
+	for _, allowed := range showStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+type Language struct {
+	ID   string `json:"id" example:"5e0c7a3b-2f4d-4a6e-8b1c-9d0e1f2a3b4c"`
+	Name string `json:"name" example:"日本語"`
+}
+
+type LanguageData struct {
+	Name string `json:"name" example:"日本語"`
+}
+
+type ShowFormat struct {
+	ID          string  `json:"id" example:"a3f1c9e2-6b7d-4e8f-9a0b-1c2d3e4f5a6b"`
+	Name        string  `json:"name" example:"3D"`
+	Description *string `json:"description,omitempty" example:"Стереоскопический показ, нужны 3D-очки"`
+}
+
+type ShowFormatData struct {
+	Name        string  `json:"name" example:"3D"`
+	Description *string `json:"description,omitempty" example:"Стереоскопический показ, нужны 3D-очки"`
+}
+
 type Genre struct {
 	ID          string `json:"id" example:"ad2805ab-bf4c-4f93-ac68-2e0a854022f8"`
 	Name        string `json:"name" example:"Исторический"`
@@ -55,37 +96,172 @@ type MovieData struct {
 	AgeLimit    int      `json:"age_limit" example:"12"`
 	ReleaseDate string   `json:"release_date" example:"2001-12-19"`
 	GenreIDs    []string `json:"genre_ids" example:"[\"f297eeaf-e784-43bf-a068-eef84f75baa4\", \"c5c8e037-a073-4105-9941-21e1cb4e79dd\"]"`
+	// Создатели фильма в порядке титров; если не указаны, при обновлении фильма остаются прежними
+	Credits []MovieCreditData `json:"credits,omitempty"`
+}
+
+// GenreSearchResult — жанр, найденный полнотекстовым поиском; выделение как в MovieSearchResult
+type GenreSearchResult struct {
+	Genre
+	Rank                 float64 `json:"rank" example:"2.5"`
+	NameHighlight        string  `json:"name_highlight" example:"<mark>Драма</mark>"`
+	DescriptionHighlight string  `json:"description_highlight" example:"Фильмы, в которых основное внимание уделяется эмоциональному развитию"`
+	Fuzzy                bool    `json:"fuzzy" example:"false"`
 }
 
 type Movie struct {
-	ID               string   `json:"id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
-	Title            string   `json:"title" example:"Властелин колец"`
-	Duration         string   `json:"duration" example:"02:58:00"`
-	Rating           *float64 `json:"rating,omitempty" example:"8.8"`
-	Description      string   `json:"description" example:"Эпическая история о кольце власти."`
-	AgeLimit         int      `json:"age_limit" example:"12"`
-	BoxOfficeRevenue float64  `json:"box_office_revenue" example:"300000000"`
-	ReleaseDate      string   `json:"release_date" example:"2001-12-19"`
-	Genres           []Genre  `json:"genres"`
+	ID               string        `json:"id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
+	Title            string        `json:"title" example:"Властелин колец"`
+	Duration         string        `json:"duration" example:"02:58:00"`
+	Rating           *float64      `json:"rating,omitempty" example:"8.8"`
+	Description      string        `json:"description" example:"Эпическая история о кольце власти."`
+	AgeLimit         int           `json:"age_limit" example:"12"`
+	BoxOfficeRevenue float64       `json:"box_office_revenue" example:"300000000"`
+	ReleaseDate      string        `json:"release_date" example:"2001-12-19"`
+	Genres           []Genre       `json:"genres"`
+	Credits          []MovieCredit `json:"credits"`
+	Media            []MovieMedia  `json:"media"`
+}
+
+// MovieSearchResult — фильм, найденный полнотекстовым поиском. Найденные слова в title_highlight
+// и description_highlight выделены тегами <mark>, остальной текст экранирован для HTML
+type MovieSearchResult struct {
+	Movie
+	Rank                 float64 `json:"rank" example:"2.31"`
+	TitleHighlight       string  `json:"title_highlight" example:"<mark>Интерстеллар</mark>"`
+	DescriptionHighlight string  `json:"description_highlight" example:"используют недавно обнаруженный <mark>червоточину</mark>, чтобы обойти ограничения"`
+	// Фильм найден только по сходству названия с запросом (вероятная опечатка)
+	Fuzzy bool `json:"fuzzy" example:"false"`
+}
+
+type CreditRoleEnumType string
+
+const (
+	CreditActor    CreditRoleEnumType = "actor"
+	CreditDirector CreditRoleEnumType = "director"
+	CreditWriter   CreditRoleEnumType = "writer"
+)
+
+func (c CreditRoleEnumType) IsValid() bool {
+	switch c {
+	case CreditActor, CreditDirector, CreditWriter:
+		return true
+	}
+	return false
+}
+
+type Person struct {
+	ID        string  `json:"id" example:"0c9f8e7d-6b5a-4c3d-2e1f-0a9b8c7d6e5f"`
+	Name      string  `json:"name" example:"Кристофер Нолан"`
+	BirthDate *string `json:"birth_date,omitempty" example:"1970-07-30"`
+	Biography *string `json:"biography,omitempty" example:"Британо-американский режиссёр, сценарист и продюсер."`
+}
+
+type PersonData struct {
+	Name      string  `json:"name" example:"Кристофер Нолан"`
+	BirthDate *string `json:"birth_date,omitempty" example:"1970-07-30"`
+	Biography *string `json:"biography,omitempty" example:"Британо-американский режиссёр, сценарист и продюсер."`
+}
+
+type MovieCredit struct {
+	PersonID string             `json:"person_id" example:"0c9f8e7d-6b5a-4c3d-2e1f-0a9b8c7d6e5f"`
+	Name     string             `json:"name" example:"Кристиан Бейл"`
+	Role     CreditRoleEnumType `json:"role" example:"actor"`
+	// Персонаж; только для актёров
+	Character *string `json:"character,omitempty" example:"Брюс Уэйн / Бэтмен"`
+}
+
+type MovieCreditData struct {
+	PersonID  string             `json:"person_id" example:"0c9f8e7d-6b5a-4c3d-2e1f-0a9b8c7d6e5f"`
+	Role      CreditRoleEnumType `json:"role" example:"actor"`
+	Character *string            `json:"character,omitempty" example:"Брюс Уэйн / Бэтмен"`
+}
+
+type MediaKindEnumType string
+
+const (
+	MediaPoster  MediaKindEnumType = "poster"
+	MediaStill   MediaKindEnumType = "still"
+	MediaTrailer MediaKindEnumType = "trailer"
+)
+
+func (k MediaKindEnumType) IsValid() bool {
+	switch k {
+	case MediaPoster, MediaStill, MediaTrailer:
+		return true
+	}
+	return false
+}
+
+// MovieMedia — постер, кадр или трейлер фильма. У трейлера есть только url
+type MovieMedia struct {
+	ID           string            `json:"id" example:"3e2d1c0b-9a8f-4e7d-6c5b-4a3928170615"`
+	Kind         MediaKindEnumType `json:"kind" example:"poster"`
+	URL          string            `json:"url" example:"/media/movies/9b165097-1c9f-4ea3-bef0-e505baa4ff63/3e2d1c0b-9a8f-4e7d-6c5b-4a3928170615.jpg"`
+	ThumbnailURL *string           `json:"thumbnail_url,omitempty" example:"/media/movies/9b165097-1c9f-4ea3-bef0-e505baa4ff63/3e2d1c0b-9a8f-4e7d-6c5b-4a3928170615_thumb.jpg"`
+	ContentType  *string           `json:"content_type,omitempty" example:"image/jpeg"`
+	SizeBytes    *int64            `json:"size_bytes,omitempty" example:"482133"`
+	Width        *int              `json:"width,omitempty" example:"1000"`
+	Height       *int              `json:"height,omitempty" example:"1500"`
+}
+
+type MovieTrailerData struct {
+	URL string `json:"url" example:"https://www.youtube.com/watch?v=EXeTwQWrcwY"`
+}
+
+type Cinema struct {
+	ID       string  `json:"id" example:"5d3c2b1a-0f9e-4d8c-b7a6-958473625140"`
+	Name     string  `json:"name" example:"Кинотеатр Центральный"`
+	Address  string  `json:"address" example:"Москва, ул. Тверская, 1"`
+	TimeZone string  `json:"time_zone" example:"Europe/Moscow"`
+	Phone    *string `json:"phone,omitempty" example:"+7 495 123-45-67"`
+	Email    *string `json:"email,omitempty" example:"central@cinema.ru"`
+}
+
+type CinemaData struct {
+	Name    string `json:"name" example:"Кинотеатр Центральный"`
+	Address string `json:"address" example:"Москва, ул. Тверская, 1"`
+	// Часовой пояс IANA всех залов кинотеатра; по умолчанию UTC
+	TimeZone *string `json:"time_zone,omitempty" example:"Europe/Moscow"`
+	Phone    *string `json:"phone,omitempty" example:"+7 495 123-45-67"`
+	Email    *string `json:"email,omitempty" example:"central@cinema.ru"`
+}
+
+type CinemaAdmin struct {
+	UserID string `json:"user_id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
 }
 
 type Hall struct {
-	ID           string  `json:"id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
-	Name         string  `json:"name" example:"Зал 1"`
-	ScreenTypeID string  `json:"screen_type_id" example:"de01f085-dffa-4347-88da-168560207511"`
-	Description  *string `json:"description,omitempty" example:"Комфортабельный зал с современным оборудованием"`
+	ID              string  `json:"id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
+	CinemaID        string  `json:"cinema_id" example:"5d3c2b1a-0f9e-4d8c-b7a6-958473625140"`
+	Name            string  `json:"name" example:"Зал 1"`
+	ScreenTypeID    string  `json:"screen_type_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	Description     *string `json:"description,omitempty" example:"Комфортабельный зал с современным оборудованием"`
+	CleaningMinutes *int    `json:"cleaning_minutes,omitempty" example:"15"`
+	// За сколько минут до начала места для маломобильных зрителей поступают в общую продажу (по умолчанию 120)
+	AccessibleReleaseMinutes *int    `json:"accessible_release_minutes,omitempty" example:"120"`
+	OpensAt                  *string `json:"opens_at,omitempty" example:"09:00:00"`
+	ClosesAt                 *string `json:"closes_at,omitempty" example:"24:00:00"`
+	TimeZone                 string  `json:"time_zone" example:"Europe/Moscow"`
 }
 
 type HallData struct {
-	Name         string  `json:"name" example:"Зал 1"`
-	ScreenTypeID string  `json:"screen_type_id" example:"de01f085-dffa-4347-88da-168560207511"`
-	Description  *string `json:"description,omitempty" example:"Комфортабельный зал с современным оборудованием"`
+	CinemaID        string  `json:"cinema_id" example:"5d3c2b1a-0f9e-4d8c-b7a6-958473625140"`
+	Name            string  `json:"name" example:"Зал 1"`
+	ScreenTypeID    string  `json:"screen_type_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	Description     *string `json:"description,omitempty" example:"Комфортабельный зал с современным оборудованием"`
+	CleaningMinutes *int    `json:"cleaning_minutes,omitempty" example:"15"`
+	// За сколько минут до начала места для маломобильных зрителей поступают в общую продажу (по умолчанию 120)
+	AccessibleReleaseMinutes *int    `json:"accessible_release_minutes,omitempty" example:"120"`
+	OpensAt                  *string `json:"opens_at,omitempty" example:"09:00"`
+	ClosesAt                 *string `json:"closes_at,omitempty" example:"24:00"`
 }
 
 type ScreenType struct {
-	ID          string `json:"id" example:"de01f085-dffa-4347-88da-168560207511"`
-	Name        string `json:"name" example:"IMAX"`
-	Description string `json:"description" example:"Экран с технологией IMAX для максимального погружения"`
+	ID                     string `json:"id" example:"de01f085-dffa-4347-88da-168560207511"`
+	Name                   string `json:"name" example:"IMAX"`
+	Description            string `json:"description" example:"Экран с технологией IMAX для максимального погружения"`
+	DefaultCleaningMinutes int    `json:"default_cleaning_minutes" example:"20"`
 }
 
 type ScreenTypeData struct {
@@ -94,32 +270,207 @@ type ScreenTypeData struct {
 }
 
 type ScreenTypeAdmin struct {
-	Name          string  `json:"name" example:"IMAX"`
-	Description   string  `json:"description" example:"Экран с технологией IMAX для максимального погружения"`
-	PriceModifier float64 `json:"price_modifier" example:"1"`
+	Name                   string  `json:"name" example:"IMAX"`
+	Description            string  `json:"description" example:"Экран с технологией IMAX для максимального погружения"`
+	PriceModifier          float64 `json:"price_modifier" example:"1"`
+	DefaultCleaningMinutes *int    `json:"default_cleaning_minutes,omitempty" example:"20"`
 }
 
 type MovieShow struct {
-	ID        string           `json:"id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
-	MovieID   string           `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
-	HallID    string           `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
-	StartTime time.Time        `json:"start_time" example:"2023-10-01T14:30:00Z"`
-	Language  LanguageEnumType `json:"language" example:"Русский"`
+	ID      string `json:"id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
+	MovieID string `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
+	HallID  string `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	// Время начала в часовом поясе зала
+	StartTime time.Time `json:"start_time" example:"2023-10-01T14:30:00+03:00"`
+	TimeZone  string    `json:"time_zone" example:"Europe/Moscow"`
+	Language  string    `json:"language" example:"Русский"`
+	// Не заполняется, если сеанс без субтитров
+	SubtitleLanguage *string            `json:"subtitle_language,omitempty" example:"English"`
+	Formats          []string           `json:"formats" example:"3D,Оригинал"`
+	Status           ShowStatusEnumType `json:"status" example:"on_sale"`
+	// Заполняются только у отменённых сеансов
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" example:"2023-09-30T10:00:00Z"`
+	CancelReason *string    `json:"cancel_reason,omitempty" example:"Техническая неисправность проектора"`
+}
+
+type MovieShowStatusUpdate struct {
+	Status ShowStatusEnumType `json:"status" example:"on_sale"`
+}
+
+type MovieShowCancel struct {
+	Reason string `json:"reason" example:"Техническая неисправность проектора"`
+}
+
+type MovieShowCancelResult struct {
+	RefundedTickets      int `json:"refunded_tickets" example:"12"`
+	ReleasedReservations int `json:"released_reservations" example:"3"`
+	NotifiedUsers        int `json:"notified_users" example:"9"`
+}
+
+type Notification struct {
+	ID          string    `json:"id" example:"3f2b8c1e-7d4a-4b6e-9c0f-1a2b3c4d5e6f"`
+	UserID      string    `json:"user_id" example:"5c7e2a1b-9d3f-4e8a-b6c0-2d4f6a8b0c1e"`
+	MovieShowID *string   `json:"movie_show_id,omitempty" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
+	Message     string    `json:"message" example:"Сеанс фильма «Титаник» 21.05.2025 20:30 отменён. Купленные билеты возвращены."`
+	CreatedAt   time.Time `json:"created_at" example:"2023-09-30T10:00:00Z"`
+}
+
+type CalendarToken struct {
+	Token string `json:"token" example:"7d9f1c2a-4b3e-4f6a-8c5d-0e1f2a3b4c5d"`
+	// Ссылка для подписки в календаре; доступна без авторизации
+	URL string `json:"url" example:"/calendar/users/7d9f1c2a-4b3e-4f6a-8c5d-0e1f2a3b4c5d"`
 }
 
 type MovieShowAdmin struct {
-	MovieID   string           `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
-	HallID    string           `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
-	StartTime time.Time        `json:"start_time" example:"2023-10-01T14:30:00Z"`
-	Language  LanguageEnumType `json:"language" example:"Русский"`
-	BasePrice float64          `json:"base_price" example:"300"`
+	MovieID   string    `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
+	HallID    string    `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	StartTime time.Time `json:"start_time" example:"2023-10-01T14:30:00Z"`
+	Language  string    `json:"language" example:"Русский"`
+	BasePrice float64   `json:"base_price" example:"300"`
+	// Язык субтитров из справочника языков, если они есть
+	SubtitleLanguage *string  `json:"subtitle_language,omitempty" example:"English"`
+	FormatIDs        []string `json:"format_ids,omitempty" example:"a3f1c9e2-6b7d-4e8f-9a0b-1c2d3e4f5a6b"`
+	// scheduled — продажа откроется позже, по умолчанию on_sale
+	Status ShowStatusEnumType `json:"status,omitempty" example:"on_sale"`
 }
 
 type MovieShowData struct {
-	MovieID   string           `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
-	HallID    string           `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
-	StartTime time.Time        `json:"start_time" example:"2023-10-01T14:30:00Z"`
-	Language  LanguageEnumType `json:"language" example:"Русский"`
+	MovieID   string    `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
+	HallID    string    `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	StartTime time.Time `json:"start_time" example:"2023-10-01T14:30:00Z"`
+	Language  string    `json:"language" example:"Русский"`
+	// Язык субтитров из справочника языков, если они есть
+	SubtitleLanguage *string `json:"subtitle_language,omitempty" example:"English"`
+	// Если не передан, форматы сеанса не меняются; пустой список их очищает
+	FormatIDs []string `json:"format_ids" example:"a3f1c9e2-6b7d-4e8f-9a0b-1c2d3e4f5a6b"`
+}
+
+type MovieShowBulk struct {
+	Shows []MovieShowAdmin `json:"shows"`
+}
+
+type ScheduleMovie struct {
+	MovieID    string  `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
+	Screenings int     `json:"screenings" example:"10"`
+	Language   string  `json:"language" example:"Русский"`
+	BasePrice  float64 `json:"base_price" example:"300"`
+}
+
+type ScheduleHall struct {
+	HallID          string `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	AvailableFrom   string `json:"available_from,omitempty" example:"10:00"`
+	AvailableTo     string `json:"available_to,omitempty" example:"23:30"`
+	CleaningMinutes int    `json:"cleaning_minutes,omitempty" example:"15"`
+}
+
+type PrimeTimeSlot struct {
+	From string `json:"from" example:"18:00"`
+	To   string `json:"to" example:"22:00"`
+}
+
+type ScheduleRequest struct {
+	WeekStart   string          `json:"week_start" example:"2025-06-02"`
+	Days        int             `json:"days,omitempty" example:"7"`
+	StepMinutes int             `json:"step_minutes,omitempty" example:"15"`
+	Movies      []ScheduleMovie `json:"movies"`
+	// Если указан кинотеатр, залы должны принадлежать ему; без списка залов планируются все залы кинотеатра
+	CinemaID  *string         `json:"cinema_id,omitempty" example:"5d3c2b1a-0f9e-4d8c-b7a6-958473625140"`
+	Halls     []ScheduleHall  `json:"halls"`
+	PrimeTime []PrimeTimeSlot `json:"prime_time"`
+}
+
+type ProposedShow struct {
+	Show              MovieShowAdmin `json:"show"`
+	ExpectedOccupancy float64        `json:"expected_occupancy" example:"0.64"`
+	ExpectedTickets   float64        `json:"expected_tickets" example:"45"`
+	PrimeTime         bool           `json:"prime_time" example:"true"`
+}
+
+type UnscheduledMovie struct {
+	MovieID string `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
+	Missing int    `json:"missing" example:"2"`
+}
+
+type ScheduleProposal struct {
+	Shows                []ProposedShow     `json:"shows"`
+	Unscheduled          []UnscheduledMovie `json:"unscheduled"`
+	TotalExpectedTickets float64            `json:"total_expected_tickets" example:"1250"`
+}
+
+type FreeSlot struct {
+	StartTime time.Time `json:"start_time" example:"2025-06-02T14:30:00Z"`
+	EndTime   time.Time `json:"end_time" example:"2025-06-02T16:45:00Z"`
+}
+
+type MovieShowConflict struct {
+	ID        string    `json:"id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
+	MovieID   string    `json:"movie_id" example:"1a2b3c4d-5e6f-7g8h-9i0j-k1l2m3n4o5p6"`
+	StartTime time.Time `json:"start_time" example:"2025-06-02T14:00:00Z"`
+	EndTime   time.Time `json:"end_time" example:"2025-06-02T16:15:00Z"`
+	// Время, когда зал освободится после уборки
+	AvailableAt time.Time `json:"available_at" example:"2025-06-02T16:30:00Z"`
+}
+
+type MovieShowValidation struct {
+	Valid               bool                `json:"valid" example:"false"`
+	OutsideOpeningHours bool                `json:"outside_opening_hours" example:"false"`
+	CleaningMinutes     int                 `json:"cleaning_minutes" example:"15"`
+	Conflicts           []MovieShowConflict `json:"conflicts"`
+	// Окна обслуживания зала, с которыми пересекается сеанс вместе с уборкой
+	MaintenanceWindows []HallMaintenanceWindow `json:"maintenance_windows"`
+}
+
+type HallMaintenanceWindow struct {
+	ID        string    `json:"id" example:"5d0a6a5c-7f7e-4a44-9c1b-2f3e4d5c6b7a"`
+	HallID    string    `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	StartTime time.Time `json:"start_time" example:"2025-06-02T08:00:00Z"`
+	EndTime   time.Time `json:"end_time" example:"2025-06-03T20:00:00Z"`
+	Reason    string    `json:"reason" example:"Ремонт проектора"`
+}
+
+type HallMaintenanceWindowData struct {
+	StartTime time.Time `json:"start_time" example:"2025-06-02T08:00:00Z"`
+	EndTime   time.Time `json:"end_time" example:"2025-06-03T20:00:00Z"`
+	Reason    string    `json:"reason" example:"Ремонт проектора"`
+}
+
+type HallMaintenanceWindowCreated struct {
+	ID string `json:"id" example:"5d0a6a5c-7f7e-4a44-9c1b-2f3e4d5c6b7a"`
+	// Запланированные сеансы, которые пересекаются с окном и должны быть перенесены
+	AffectedShows []MovieShowConflict `json:"affected_shows"`
+}
+
+type MovieShowReschedule struct {
+	StartTime *time.Time `json:"start_time,omitempty" example:"2023-10-02T18:00:00Z"`
+	HallID    *string    `json:"hall_id,omitempty" example:"de01f085-dffa-4347-88da-168560207511"`
+	// Начальная цена для новых билетов; по умолчанию вычисляется по текущим билетам сеанса
+	BasePrice *float64 `json:"base_price,omitempty" example:"300"`
+}
+
+type SeatRemap struct {
+	TicketID   string `json:"ticket_id" example:"a1b2c3d4-e5f6-7g8h-9i0j-k1l2m3n4o5p6"`
+	FromSeatID string `json:"from_seat_id" example:"c1bf35fb-4e5f-46cb-914b-bc8d76aaca23"`
+	ToSeatID   string `json:"to_seat_id" example:"5d0c9a8b-7e6f-4a3b-2c1d-0e9f8a7b6c5d"`
+}
+
+type UnmappableSeat struct {
+	TicketID   string `json:"ticket_id" example:"a1b2c3d4-e5f6-7g8h-9i0j-k1l2m3n4o5p6"`
+	SeatID     string `json:"seat_id" example:"c1bf35fb-4e5f-46cb-914b-bc8d76aaca23"`
+	SeatTypeID string `json:"seat_type_id" example:"7a6b5c4d-3e2f-1a0b-9c8d-7e6f5a4b3c2d"`
+	RowNumber  int    `json:"row_number" example:"5"`
+	SeatNumber int    `json:"seat_number" example:"12"`
+}
+
+type MovieShowRescheduleResult struct {
+	RemappedTickets    int         `json:"remapped_tickets" example:"12"`
+	RegeneratedTickets int         `json:"regenerated_tickets" example:"88"`
+	NotifiedUsers      int         `json:"notified_users" example:"9"`
+	Remaps             []SeatRemap `json:"remaps"`
+}
+
+type MovieShowRescheduleConflict struct {
+	Message         string           `json:"message" example:"Не для всех проданных мест есть места того же типа в новом зале"`
+	UnmappableSeats []UnmappableSeat `json:"unmappable_seats"`
 }
 
 type Ticket struct {
@@ -144,12 +495,74 @@ type TicketStatusData struct {
 	Reserve bool   `json:"reserve" example:"true"`
 }
 
+type SeatServiceStateEnumType string
+
+const (
+	SeatActive      SeatServiceStateEnumType = "active"
+	SeatBlocked     SeatServiceStateEnumType = "blocked"
+	SeatMaintenance SeatServiceStateEnumType = "maintenance"
+)
+
+func (s SeatServiceStateEnumType) IsValid() bool {
+	switch s {
+	case SeatActive, SeatBlocked, SeatMaintenance:
+		return true
+	}
+	return false
+}
+
 type Seat struct {
 	ID         string `json:"id" example:"a1b2c3d4-e5f6-7g8h-9i0j-k1l2m3n4o5p6"`
 	HallID     string `json:"hall_id" example:"de01f085-dffa-4347-88da-168560207511"`
 	SeatTypeID string `json:"seat_type_id" example:"premium"`
 	RowNumber  int    `json:"row_number" example:"5"`
 	SeatNumber int    `json:"seat_number" example:"12"`
+	// Заполняется, только если место выведено из обслуживания
+	Service         *SeatService `json:"service,omitempty"`
+	WheelchairSpace bool         `json:"wheelchair_space" example:"false"`
+	// Место сопровождающего, только у мест для коляски
+	CompanionSeatID *string `json:"companion_seat_id,omitempty" example:"c1bf35fb-4e5f-46cb-914b-bc8d76aaca23"`
+	// Зона зала, в которую входит место
+	ZoneID *string `json:"zone_id,omitempty" example:"5b8c2a41-7d3e-4f0a-9c6b-2e1f8a7d4c30"`
+}
+
+type SeatAccessibility struct {
+	WheelchairSpace bool    `json:"wheelchair_space" example:"true"`
+	CompanionSeatID *string `json:"companion_seat_id,omitempty" example:"c1bf35fb-4e5f-46cb-914b-bc8d76aaca23"`
+}
+
+type SeatService struct {
+	State  SeatServiceStateEnumType `json:"state" example:"maintenance"`
+	Reason string                   `json:"reason" example:"Сломан подлокотник"`
+	From   time.Time                `json:"from" example:"2025-03-01T10:00:00Z"`
+	// Пусто: до отмены блокировки
+	Until *time.Time `json:"until,omitempty" example:"2025-03-08T10:00:00Z"`
+}
+
+type SeatServiceData struct {
+	State  SeatServiceStateEnumType `json:"state" example:"maintenance"`
+	Reason *string                  `json:"reason,omitempty" example:"Сломан подлокотник"`
+	// По умолчанию — текущий момент
+	From  *time.Time `json:"from,omitempty" example:"2025-03-01T10:00:00Z"`
+	Until *time.Time `json:"until,omitempty" example:"2025-03-08T10:00:00Z"`
+}
+
+type SeatServiceConflict struct {
+	Message string              `json:"message" example:"На место в этот период проданы или забронированы билеты"`
+	Tickets []SeatServiceTicket `json:"tickets"`
+}
+
+type SeatServiceTicket struct {
+	TicketID    string               `json:"ticket_id" example:"a1b2c3d4-e5f6-7g8h-9i0j-k1l2m3n4o5p6"`
+	MovieShowID string               `json:"movie_show_id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
+	StartTime   time.Time            `json:"start_time" example:"2025-03-02T19:00:00+03:00"`
+	Status      TicketStatusEnumType `json:"ticket_status" example:"Purchased"`
+}
+
+type BlockedSeat struct {
+	Seat
+	// Число предстоящих сеансов, на которые место не продаётся
+	AffectedShows int `json:"affected_shows" example:"4"`
 }
 
 type SeatData struct {
@@ -159,6 +572,103 @@ type SeatData struct {
 	SeatNumber int    `json:"seat_number" example:"12"`
 }
 
+// Компактная схема мест зала: одинаковые соседние ряды описываются одной группой
+// Именованная группа мест зала со своей наценкой
+type HallZone struct {
+	ID     string `json:"id" example:"5b8c2a41-7d3e-4f0a-9c6b-2e1f8a7d4c30"`
+	HallID string `json:"hall_id" example:"9b165097-1c9f-4ea3-bef0-e505baa4ff63"`
+	Name   string `json:"name" example:"VIP"`
+	// Умножается на наценки типа экрана и типа места
+	PriceModifier float64  `json:"price_modifier" example:"1.5"`
+	SeatIDs       []string `json:"seat_ids" example:"c1bf35fb-4e5f-46cb-914b-bc8d76aaca23"`
+}
+
+type HallZoneData struct {
+	Name          string  `json:"name" example:"VIP"`
+	PriceModifier float64 `json:"price_modifier" example:"1.5"`
+	// Места зала; место из другой зоны переходит в эту
+	SeatIDs []string `json:"seat_ids" example:"c1bf35fb-4e5f-46cb-914b-bc8d76aaca23"`
+}
+
+type HallLayout struct {
+	Rows []HallLayoutRows `json:"rows"`
+}
+
+type HallLayoutRows struct {
+	FromRow int `json:"from_row" example:"1"`
+	ToRow   int `json:"to_row" example:"5"`
+	// Номер последнего места в ряду, включая проходы
+	Seats int `json:"seats" example:"12"`
+	// Номера мест, занятых проходом: таких мест в зале нет, нумерация не сдвигается
+	Gaps []int `json:"gaps,omitempty" example:"6,7"`
+	// Тип мест ряда, не попавших в ranges
+	SeatTypeID string            `json:"seat_type_id" example:"a1b2c3d4-e5f6-7a8b-9c0d-e1f2a3b4c5d6"`
+	Ranges     []HallLayoutRange `json:"ranges,omitempty"`
+}
+
+type HallLayoutRange struct {
+	FromSeat   int    `json:"from_seat" example:"1"`
+	ToSeat     int    `json:"to_seat" example:"2"`
+	SeatTypeID string `json:"seat_type_id" example:"de01f085-dffa-4347-88da-168560207511"`
+}
+
+type HallLayoutResult struct {
+	Seats   int `json:"seats" example:"60"`
+	Added   int `json:"added" example:"10"`
+	Changed int `json:"changed" example:"4"`
+	Removed int `json:"removed" example:"2"`
+}
+
+type HallLayoutConflict struct {
+	Message      string `json:"message" example:"На места, которые удаляет новая схема, есть билеты"`
+	BlockedSeats []Seat `json:"blocked_seats"`
+}
+
+type BestSeat struct {
+	TicketID   string  `json:"ticket_id" example:"a1b2c3d4-e5f6-7a8b-9c0d-e1f2a3b4c5d6"`
+	SeatID     string  `json:"seat_id" example:"c1bf35fb-4e5f-46cb-914b-bc8d76aaca23"`
+	SeatTypeID string  `json:"seat_type_id" example:"de01f085-dffa-4347-88da-168560207511"`
+	RowNumber  int     `json:"row_number" example:"6"`
+	SeatNumber int     `json:"seat_number" example:"9"`
+	Price      float64 `json:"price" example:"450"`
+}
+
+// Вариант из соседних мест одного ряда
+type BestSeatsOption struct {
+	RowNumber  int        `json:"row_number" example:"6"`
+	Seats      []BestSeat `json:"seats"`
+	TotalPrice float64    `json:"total_price" example:"1800"`
+	// Расстояние от центра блока до центра зала в рядах и местах: чем меньше, тем лучше
+	Distance float64 `json:"distance" example:"0.5"`
+}
+
+type BestSeatsRequest struct {
+	Count      int     `json:"count" example:"4"`
+	SeatTypeID *string `json:"seat_type_id,omitempty" example:"de01f085-dffa-4347-88da-168560207511"`
+}
+
+type BestSeatsHold struct {
+	BestSeatsOption
+	HeldUntil time.Time `json:"held_until" example:"2025-03-02T18:40:00Z"`
+}
+
+// Режим дистанцирования сеанса
+type MovieShowDistancing struct {
+	// Сколько мест ряда блокируется с каждой стороны от чужих забронированных и купленных мест
+	SideSeats int `json:"side_seats" example:"2"`
+	// Блокировать места с тем же номером в соседних рядах
+	BlockAdjacentRows bool `json:"block_adjacent_rows" example:"true"`
+	// Предельная доля забронированных и купленных мест зала, пусто — без ограничения
+	MaxOccupancyPercent *int `json:"max_occupancy_percent,omitempty" example:"50"`
+}
+
+type MovieShowDistancingStatus struct {
+	MovieShowDistancing
+	// Предельное число забронированных и купленных мест
+	MaxSeats    *int `json:"max_seats,omitempty" example:"60"`
+	BookedSeats int  `json:"booked_seats" example:"12"`
+}
+
 type SeatType struct {
 	ID          string `json:"id" example:"de01f085-dffa-4347-88da-168560207511"`
 	Name        string `json:"name" example:"Премиум"`
@@ -183,6 +693,8 @@ type User struct {
 	PasswordHash string `json:"password_hash" example:"93652657623450"`
 	BirthDate    string `json:"birth_date" example:"1990-01-01"`
 	IsAdmin      bool   `json:"is_admin,omitempty" example:"true"`
+	// Может бронировать придержанные места для маломобильных зрителей
+	NeedsAccessibility bool `json:"needs_accessibility,omitempty" example:"false"`
 }
 
 type UserData struct {
@@ -201,6 +713,10 @@ type UserAdmin struct {
 	IsAdmin bool `json:"is_admin" example:"true"`
 }
 
+type UserAccessibility struct {
+	NeedsAccessibility bool `json:"needs_accessibility" example:"true"`
+}
+
 type UserRegister struct {
 	Name         string `json:"name" example:"Иван Иванов"`
 	Email        string `json:"email" example:"ivan@example.com"`
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Сколько держится автоматическая бронь, поставленная подбором мест
+const seatHoldDuration = 10 * time.Minute
+
+const (
+	maxBestSeatsCount = 10
+	maxBestSeatsLimit = 20
+)
+
+// Центр зала в координатах рядов и мест
+type hallCenter struct {
+	row, seat float64
+}
+
+func validateBestSeatsCount(count int) error {
+	if count < 1 || count > maxBestSeatsCount {
+		return errors.New("количество мест должно быть от 1 до 10")
+	}
+	return nil
+}
+
+// findBestSeats ищет блоки из count мест с подряд идущими номерами в одном ряду и упорядочивает их
+// по удалённости центра блока от центра зала. seats должны быть отсортированы по ряду и номеру места.
+func findBestSeats(seats []BestSeat, center hallCenter, count, limit int) []BestSeatsOption {
+	var options []BestSeatsOption
+	for start := 0; start < len(seats); {
+		// Серия подряд идущих мест одного ряда
+		end := start + 1
+		for end < len(seats) && seats[end].RowNumber == seats[start].RowNumber &&
+			seats[end].SeatNumber == seats[end-1].SeatNumber+1 {
+			end++
+		}
+
+		for first := start; first+count <= end; first++ {
+			block := seats[first : first+count]
+			option := BestSeatsOption{RowNumber: block[0].RowNumber, Seats: slices.Clone(block)}
+			for _, s := range block {
+				option.TotalPrice += s.Price
+			}
+
+			middle := float64(block[0].SeatNumber+block[count-1].SeatNumber) / 2
+			distance := math.Hypot(float64(option.RowNumber)-center.row, middle-center.seat)
+			option.Distance = math.Round(distance*100) / 100
+			options = append(options, option)
+		}
+		start = end
+	}
+
+	slices.SortStableFunc(options, func(a, b BestSeatsOption) int {
+		if c := cmp.Compare(a.Distance, b.Distance); c != 0 {
+			return c
+		}
+		// При равенстве предпочитаем ряд ближе к центру, затем более дальний от экрана
+		if c := cmp.Compare(math.Abs(float64(a.RowNumber)-center.row), math.Abs(float64(b.RowNumber)-center.row)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.RowNumber, a.RowNumber); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Seats[0].SeatNumber, b.Seats[0].SeatNumber)
+	})
+
+	if len(options) > limit {
+		options = options[:limit]
+	}
+	return options
+}
+
+// userNeedsAccessibility сообщает, может ли пользователь userID занимать придержанные места для маломобильных зрителей.
+func userNeedsAccessibility(ctx context.Context, tx pgx.Tx, userID string) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+	var needs bool
+	err := tx.QueryRow(ctx, "SELECT accessibility_needs FROM users WHERE id = $1", userID).Scan(&needs)
+	if isNoRows(err) {
+		return false, nil
+	}
+	return needs, err
+}
+
+// loadBestSeatsCandidates возвращает статус сеанса, центр его зала и свободные места нужного типа.
+// Придержанные для маломобильных зрителей места попадают в выборку, только если includeHeld.
+func loadBestSeatsCandidates(ctx context.Context, tx pgx.Tx, showID string, seatTypeID *string, includeHeld bool) (ShowStatusEnumType, hallCenter, []BestSeat, error) {
+	var status ShowStatusEnumType
+	var center hallCenter
+	err := tx.QueryRow(ctx, `
+		SELECT ms.status,
+			COALESCE((MIN(s.row_number) + MAX(s.row_number)) / 2.0, 0),
+			COALESCE((MIN(s.seat_number) + MAX(s.seat_number)) / 2.0, 0)
+		FROM movie_shows ms
+		LEFT JOIN seats s ON s.hall_id = ms.hall_id
+		WHERE ms.id = $1
+		GROUP BY ms.id`, showID).Scan(&status, &center.row, &center.seat)
+	if err != nil {
+		return "", center, nil, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT t.id, s.id, COALESCE(s.seat_type_id::text, ''), s.row_number, s.seat_number, t.price
+		FROM movie_show_tickets t
+		JOIN seats s ON s.id = t.seat_id
+		WHERE t.movie_show_id = $1 AND t.ticket_status = 'Available'
+		AND ($2::uuid IS NULL OR s.seat_type_id = $2)
+		AND ($3 OR NOT accessible_seat_held(s.id, t.movie_show_id, NOW()))
+		ORDER BY s.row_number, s.seat_number`, showID, seatTypeID, includeHeld)
+	if err != nil {
+		return "", center, nil, err
+	}
+	defer rows.Close()
+
+	var seats []BestSeat
+	for rows.Next() {
+		var s BestSeat
+		if err := rows.Scan(&s.TicketID, &s.SeatID, &s.SeatTypeID, &s.RowNumber, &s.SeatNumber, &s.Price); err != nil {
+			return "", center, nil, err
+		}
+		seats = append(seats, s)
+	}
+	return status, center, seats, rows.Err()
+}
+
+// @Summary Подобрать лучшие соседние места (guest | user | admin)
+// @Description Ищет на сеансе в статусе on_sale блоки из count свободных мест с подряд идущими номерами в одном ряду
+// @Description и возвращает их в порядке удалённости от центра зала. Места, придержанные для маломобильных зрителей,
+// @Description предлагаются только пользователям с needs_accessibility.
+// @Tags Билеты
+// @Produce json
+// @Param id path string true "ID киносеанса"
+// @Param count query int true "Количество мест (от 1 до 10)"
+// @Param seat_type query string false "ID типа места"
+// @Param limit query int false "Количество вариантов (по умолчанию 5, не больше 20)"
+// @Success 200 {array} BestSeatsOption "Варианты рассадки, лучшие первыми"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 404 {object} ErrorResponse "Киносеанс не найден или подходящих мест нет"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/best-seats [get]
+func GetBestSeats(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		query := r.URL.Query()
+		count, err := strconv.Atoi(query.Get("count"))
+		if err == nil {
+			err = validateBestSeatsCount(count)
+		}
+		if err != nil {
+			http.Error(w, "Количество мест должно быть целым числом от 1 до 10", http.StatusBadRequest)
+			return
+		}
+
+		var seatTypeID *string
+		if v := query.Get("seat_type"); v != "" {
+			if _, err := uuid.Parse(v); err != nil {
+				http.Error(w, "Неверный формат ID типа места", http.StatusBadRequest)
+				return
+			}
+			seatTypeID = &v
+		}
+
+		limit := 5
+		if v := query.Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 1 || limit > maxBestSeatsLimit {
+				http.Error(w, "Количество вариантов должно быть целым числом от 1 до 20", http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		needsAccessibility, err := userNeedsAccessibility(ctx, tx, r.Header.Get("UserID"))
+		if IsError(w, err) {
+			return
+		}
+
+		status, center, seats, err := loadBestSeatsCandidates(ctx, tx, id.String(), seatTypeID, needsAccessibility)
+		if IsError(w, err) {
+			return
+		}
+
+		var options []BestSeatsOption
+		if status == ShowOnSale {
+			options = findBestSeats(seats, center, count, limit)
+		}
+		if len(options) == 0 {
+			http.Error(w, "Подходящих мест не найдено", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(options)
+	}
+}
+
+// @Summary Забронировать лучшие соседние места (user | admin)
+// @Description Подбирает лучший блок из count соседних мест, как GET /movie-shows/{id}/best-seats, и бронирует его
+// @Description на текущего пользователя. Бронь снимается автоматически, если билеты не выкуплены за 10 минут.
+// @Tags Билеты
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID киносеанса"
+// @Param request body BestSeatsRequest true "Количество и тип мест"
+// @Success 201 {object} BestSeatsHold "Забронированные места"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Киносеанс не найден или подходящих мест нет"
+// @Failure 409 {object} ErrorResponse "Продажа билетов закрыта или места заняты параллельным запросом"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/best-seats [post]
+func HoldBestSeats(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := r.Header.Get("UserID")
+		role := r.Header.Get("Role")
+		if userID == "" || (role != os.Getenv("CLAIM_ROLE_USER") && role != os.Getenv("CLAIM_ROLE_ADMIN")) {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var req BestSeatsRequest
+		if !DecodeJSONBody(w, r, &req) {
+			return
+		}
+		if err := validateBestSeatsCount(req.Count); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.SeatTypeID != nil {
+			if _, err := uuid.Parse(*req.SeatTypeID); err != nil {
+				http.Error(w, "Неверный формат ID типа места", http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		needsAccessibility, err := userNeedsAccessibility(ctx, tx, userID)
+		if IsError(w, err) {
+			return
+		}
+
+		status, center, seats, err := loadBestSeatsCandidates(ctx, tx, id.String(), req.SeatTypeID, needsAccessibility)
+		if IsError(w, err) {
+			return
+		}
+		if status != ShowOnSale {
+			http.Error(w, "Продажа билетов на киносеанс закрыта (статус "+string(status)+")", http.StatusConflict)
+			return
+		}
+
+		options := findBestSeats(seats, center, req.Count, 1)
+		if len(options) == 0 {
+			http.Error(w, "Подходящих мест не найдено", http.StatusNotFound)
+			return
+		}
+
+		hold := BestSeatsHold{BestSeatsOption: options[0], HeldUntil: time.Now().Add(seatHoldDuration).UTC()}
+		ticketIDs := make([]string, len(hold.Seats))
+		for i, s := range hold.Seats {
+			ticketIDs[i] = s.TicketID
+		}
+
+		if _, err := tx.Exec(ctx, "SELECT materialize_ticket(id) FROM unnest($1::uuid[]) AS id", ticketIDs); IsError(w, err) {
+			return
+		}
+
+		// Место могли занять между выборкой и бронированием: бронируем только ещё свободные билеты
+		res, err := tx.Exec(ctx, `
+			UPDATE tickets SET ticket_status = $1, user_id = $2, held_until = $3
+			WHERE id = ANY($4::uuid[]) AND ticket_status = $5`,
+			Reserved, userID, hold.HeldUntil, ticketIDs, Available)
+		if IsError(w, err) {
+			return
+		}
+		if res.RowsAffected() != int64(len(ticketIDs)) {
+			http.Error(w, "Места уже заняты, повторите подбор", http.StatusConflict)
+			return
+		}
+
+		if IsError(w, tx.Commit(ctx)) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(hold)
+	}
+}
+
+// ReleaseExpiredSeatHolds снимает автоматические брони, срок которых истёк к моменту now.
+func ReleaseExpiredSeatHolds(ctx context.Context, db *pgxpool.Pool, now time.Time) (int, error) {
+	var released int
+	err := db.QueryRow(ctx, "SELECT release_expired_seat_holds($1)", now).Scan(&released)
+	return released, err
+}
+
+// RunSeatHoldReleaser периодически снимает просроченные брони, пока не отменён ctx.
+func RunSeatHoldReleaser(ctx context.Context, db *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ReleaseExpiredSeatHolds(ctx, db, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("failed to release expired seat holds: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
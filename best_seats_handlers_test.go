@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFindBestSeats(t *testing.T) {
+	seat := func(row, number int) BestSeat {
+		return BestSeat{TicketID: uuid.New().String(), RowNumber: row, SeatNumber: number, Price: 100}
+	}
+	// Ряды 1-5 по 10 мест, центр зала - ряд 3, место 5.5
+	center := hallCenter{row: 3, seat: 5.5}
+	seats := []BestSeat{
+		seat(1, 1), seat(1, 2), seat(1, 3),
+		seat(3, 1), seat(3, 2), seat(3, 4), seat(3, 5), seat(3, 6), seat(3, 7),
+		seat(4, 5), seat(4, 6),
+		seat(5, 9), seat(5, 10),
+	}
+
+	tests := []struct {
+		name      string
+		count     int
+		limit     int
+		wantFirst [][2]int
+		wantLen   int
+	}{
+		{"Center of middle row", 2, 3, [][2]int{{3, 5}, {3, 4}, {3, 6}}, 3},
+		// Проход между местами 2 и 4 разрывает блок
+		{"Gap breaks block", 4, 5, [][2]int{{3, 4}}, 1},
+		{"Single seat", 1, 1, [][2]int{{3, 5}}, 1},
+		{"Not enough adjacent seats", 5, 5, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := findBestSeats(seats, center, tt.count, tt.limit)
+			if len(options) != tt.wantLen {
+				t.Fatalf("Expected %d options, got %d: %+v", tt.wantLen, len(options), options)
+			}
+			for i, want := range tt.wantFirst {
+				got := options[i]
+				if got.RowNumber != want[0] || got.Seats[0].SeatNumber != want[1] || len(got.Seats) != tt.count {
+					t.Errorf("Option %d: expected row %d from seat %d, got %+v", i, want[0], want[1], got)
+				}
+				if got.TotalPrice != float64(100*tt.count) {
+					t.Errorf("Option %d: expected total price %d, got %v", i, 100*tt.count, got.TotalPrice)
+				}
+			}
+			for i := 1; i < len(options); i++ {
+				if options[i].Distance < options[i-1].Distance {
+					t.Errorf("Options are not ordered by distance: %+v", options)
+				}
+			}
+		})
+	}
+}
+
+// seedBestSeatsShow открывает продажу на MovieShowsData[0] и добавляет в ряд 3 зала места 7-9 рядом с SeatsData[3]
+func seedBestSeatsShow(t *testing.T) {
+	ctx := context.Background()
+	if _, err := TestAdminDB.Exec(ctx,
+		"INSERT INTO movie_show_prices (movie_show_id, base_price) VALUES ($1, 300)", MovieShowsData[0].ID); err != nil {
+		t.Fatalf("Failed to set show price: %v", err)
+	}
+	if _, err := TestAdminDB.Exec(ctx, `
+		INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+		SELECT $1, $2, 3, n FROM generate_series(7, 9) AS n`,
+		HallsData[0].ID, SeatsData[3].SeatTypeID); err != nil {
+		t.Fatalf("Failed to add seats: %v", err)
+	}
+}
+
+func TestGetBestSeats(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+	seedBestSeatsShow(t)
+
+	base := ts.URL + "/movie-shows/" + MovieShowsData[0].ID + "/best-seats"
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		wantFirstSeat  int
+		wantLen        int
+	}{
+		// Центр зала - ряд 2, место 5.5: блок 7-8 ближе всего
+		{"Pair", base + "?count=2", http.StatusOK, 7, 3},
+		{"Limit", base + "?count=2&limit=1", http.StatusOK, 7, 1},
+		{"Seat type", base + "?count=4&seat_type=" + SeatsData[3].SeatTypeID, http.StatusOK, 7, 1},
+		{"Other seat type", base + "?count=1&seat_type=" + SeatsData[0].SeatTypeID, http.StatusNotFound, 0, 0},
+		{"Too many seats", base + "?count=5", http.StatusNotFound, 0, 0},
+		{"Missing count", base, http.StatusBadRequest, 0, 0},
+		{"Count out of range", base + "?count=11", http.StatusBadRequest, 0, 0},
+		{"Invalid limit", base + "?count=1&limit=0", http.StatusBadRequest, 0, 0},
+		{"Invalid seat type", base + "?count=1&seat_type=invalid-uuid", http.StatusBadRequest, 0, 0},
+		{"Unknown show", ts.URL + "/movie-shows/" + uuid.New().String() + "/best-seats?count=1", http.StatusNotFound, 0, 0},
+		{"Invalid show ID", ts.URL + "/movie-shows/invalid-uuid/best-seats?count=1", http.StatusBadRequest, 0, 0},
+		{"Unknown resource", ts.URL + "/movie-shows/" + MovieShowsData[0].ID + "/worst-seats", http.StatusNotFound, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createRequest(t, "GET", tt.url, "", nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var options []BestSeatsOption
+			parseResponseBody(t, resp, &options)
+			if len(options) != tt.wantLen {
+				t.Fatalf("Expected %d options, got %+v", tt.wantLen, options)
+			}
+			if options[0].RowNumber != 3 || options[0].Seats[0].SeatNumber != tt.wantFirstSeat {
+				t.Errorf("Expected best block in row 3 from seat %d, got %+v", tt.wantFirstSeat, options[0])
+			}
+		})
+	}
+}
+
+func TestHoldBestSeats(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+	seedBestSeatsShow(t)
+
+	url := ts.URL + "/movie-shows/" + MovieShowsData[0].ID + "/best-seats"
+	user := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+
+	req := createRequest(t, "POST", url, "", BestSeatsRequest{Count: 2})
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "POST", url, user, BestSeatsRequest{Count: 0})
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+
+	req = createRequest(t, "POST", url, user, BestSeatsRequest{Count: 2})
+	resp := executeRequest(t, req, http.StatusCreated)
+	var hold BestSeatsHold
+	parseResponseBody(t, resp, &hold)
+	resp.Body.Close()
+
+	if len(hold.Seats) != 2 || hold.Seats[0].SeatNumber != 7 || hold.Seats[1].SeatNumber != 8 {
+		t.Fatalf("Expected seats 7-8 to be held, got %+v", hold)
+	}
+	if until := time.Until(hold.HeldUntil); until <= 0 || until > seatHoldDuration {
+		t.Errorf("Unexpected hold expiry %v", hold.HeldUntil)
+	}
+
+	// Следующий подбор обходит удерживаемые места
+	req = createRequest(t, "POST", url, user, BestSeatsRequest{Count: 2})
+	resp = executeRequest(t, req, http.StatusCreated)
+	var next BestSeatsHold
+	parseResponseBody(t, resp, &next)
+	resp.Body.Close()
+	if next.Seats[0].SeatNumber != 9 {
+		t.Errorf("Expected seats 9-10 to be held, got %+v", next)
+	}
+
+	// Рядом свободно только одно место SeatsData[1]
+	req = createRequest(t, "POST", url, user, BestSeatsRequest{Count: 2})
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "POST", url, user, BestSeatsRequest{Count: 1})
+	executeRequest(t, req, http.StatusCreated).Body.Close()
+
+	// Свободных мест не осталось: сеанс распродан
+	req = createRequest(t, "POST", url, user, BestSeatsRequest{Count: 1})
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	released, err := ReleaseExpiredSeatHolds(context.Background(), TestAdminDB, time.Now())
+	if err != nil || released != 0 {
+		t.Fatalf("Expected no holds to expire yet, got %d (%v)", released, err)
+	}
+	released, err = ReleaseExpiredSeatHolds(context.Background(), TestAdminDB, time.Now().Add(seatHoldDuration+time.Minute))
+	if err != nil || released != 5 {
+		t.Fatalf("Expected 5 holds to expire, got %d (%v)", released, err)
+	}
+
+	req = createRequest(t, "GET", url+"?count=4", "", nil)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+}
+
+func TestReserveHeldSeat(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+	seedBestSeatsShow(t)
+
+	user := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+	req := createRequest(t, "POST", ts.URL+"/movie-shows/"+MovieShowsData[0].ID+"/best-seats", user, BestSeatsRequest{Count: 1})
+	resp := executeRequest(t, req, http.StatusCreated)
+	var hold BestSeatsHold
+	parseResponseBody(t, resp, &hold)
+	resp.Body.Close()
+	ticketID := hold.Seats[0].TicketID
+
+	// Чужое удержание забронировать нельзя
+	req = createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+ticketID, generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")),
+		TicketStatusData{UserID: UsersData[0].ID, Reserve: true})
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	req = createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+ticketID, user,
+		TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: true})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	released, err := ReleaseExpiredSeatHolds(context.Background(), TestAdminDB, time.Now().Add(seatHoldDuration+time.Minute))
+	if err != nil || released != 0 {
+		t.Fatalf("Expected reserved seat not to expire, got %d (%v)", released, err)
+	}
+
+	var status TicketStatusEnumType
+	if err := TestAdminDB.QueryRow(context.Background(), "SELECT ticket_status FROM tickets WHERE id = $1", ticketID).Scan(&status); err != nil {
+		t.Fatalf("Failed to get ticket: %v", err)
+	}
+	if status != Reserved {
+		t.Errorf("Expected ticket to stay reserved, got %s", status)
+	}
+}
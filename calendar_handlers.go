@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	icsTimeLayout = "20060102T150405Z"
+	// Длина строки iCalendar без CRLF (RFC 5545, раздел 3.1)
+	icsLineOctets = 75
+	// Прошедшие сеансы остаются в календаре, чтобы клиенты не удаляли их из истории
+	calendarHistory = 30 * 24 * time.Hour
+)
+
+// calendarEvent — один сеанс в iCalendar-ленте
+type calendarEvent struct {
+	ShowID     string
+	Title      string
+	Location   string
+	Start      time.Time
+	End        time.Time
+	Language   string
+	Subtitles  *string
+	Status     ShowStatusEnumType
+	Revision   int
+	UpdatedAt  time.Time
+	Seats      *string
+	CancelNote *string
+}
+
+// escapeICSText экранирует значение типа TEXT (RFC 5545, раздел 3.3.11)
+func escapeICSText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// foldICSLine разбивает строку на части не длиннее 75 октетов, не разрывая символы UTF-8.
+// Строки продолжения начинаются с пробела, который тоже входит в лимит.
+func foldICSLine(line string) string {
+	if len(line) <= icsLineOctets {
+		return line + "\r\n"
+	}
+
+	var b strings.Builder
+	limit := icsLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icsLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+func writeICSCalendar(w http.ResponseWriter, name string, events []calendarEvent) {
+	var b strings.Builder
+	line := func(s string) { b.WriteString(foldICSLine(s)) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//cw//Cinema schedule//RU")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeICSText(name))
+	for _, e := range events {
+		status := "CONFIRMED"
+		if e.Status == "cancelled" {
+			status = "CANCELLED"
+		}
+
+		description := "Язык: " + e.Language
+		if e.Subtitles != nil {
+			description += ", субтитры: " + *e.Subtitles
+		}
+		if e.Seats != nil {
+			description += "\nМеста: " + *e.Seats
+		}
+		if e.CancelNote != nil {
+			description += "\nСеанс отменён: " + *e.CancelNote
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:movie-show-" + e.ShowID + "@cinema")
+		line("DTSTAMP:" + e.UpdatedAt.UTC().Format(icsTimeLayout))
+		line("LAST-MODIFIED:" + e.UpdatedAt.UTC().Format(icsTimeLayout))
+		line("DTSTART:" + e.Start.UTC().Format(icsTimeLayout))
+		line("DTEND:" + e.End.UTC().Format(icsTimeLayout))
+		line("SUMMARY:" + escapeICSText(e.Title))
+		line("LOCATION:" + escapeICSText(e.Location))
+		line("DESCRIPTION:" + escapeICSText(description))
+		line(fmt.Sprintf("SEQUENCE:%d", e.Revision))
+		line("STATUS:" + status)
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write([]byte(b.String()))
+}
+
+func scanCalendarEvents(rows pgx.Rows) ([]calendarEvent, error) {
+	defer rows.Close()
+
+	var events []calendarEvent
+	for rows.Next() {
+		var e calendarEvent
+		if err := rows.Scan(&e.ShowID, &e.Title, &e.Location, &e.Start, &e.End, &e.Language,
+			&e.Subtitles, &e.Status, &e.Revision, &e.UpdatedAt, &e.CancelNote, &e.Seats); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+const calendarEventColumns = `
+	ms.id, m.title, h.name || ', ' || c.name || ', ' || c.address, ms.start_time, ms.start_time + m.duration::interval,
+	ms.language, ms.subtitle_language, ms.status, ms.revision, ms.updated_at, ms.cancel_reason`
+
+// @Summary Расписание фильма в формате iCalendar (guest | user | admin)
+// @Description Возвращает сеансы фильма за последние 30 дней и в будущем в формате RFC 5545. Отменённые сеансы остаются в ленте со статусом CANCELLED.
+// @Tags Календарь
+// @Produce text/calendar
+// @Param movie_id path string true "ID фильма"
+// @Success 200 {string} string "Календарь"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Фильм не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movies/{movie_id}/shows.ics [get]
+func GetMovieCalendar(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		movieID, ok := ParseUUIDFromPath(w, r.PathValue("movie_id"))
+		if !ok {
+			return
+		}
+
+		var title string
+		err := db.QueryRow(r.Context(), "SELECT title FROM movies WHERE id = $1", movieID).Scan(&title)
+		if IsError(w, err) {
+			return
+		}
+
+		rows, err := db.Query(r.Context(), `
+			SELECT `+calendarEventColumns+`, NULL::text
+			FROM movie_shows ms
+			JOIN movies m ON m.id = ms.movie_id
+			JOIN halls h ON h.id = ms.hall_id
+			JOIN cinemas c ON c.id = h.cinema_id
+			WHERE ms.movie_id = $1 AND ms.start_time >= $2
+			ORDER BY ms.start_time`,
+			movieID, time.Now().Add(-calendarHistory))
+		if IsError(w, err) {
+			return
+		}
+		events, err := scanCalendarEvents(rows)
+		if HandleDatabaseError(w, err, "сеансом") {
+			return
+		}
+
+		writeICSCalendar(w, "Сеансы: "+title, events)
+	}
+}
+
+// @Summary Расписание зала в формате iCalendar (guest | user | admin)
+// @Description Возвращает сеансы зала за последние 30 дней и в будущем в формате RFC 5545. Отменённые сеансы остаются в ленте со статусом CANCELLED.
+// @Tags Календарь
+// @Produce text/calendar
+// @Param id path string true "ID зала"
+// @Success 200 {string} string "Календарь"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Зал не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /halls/{id}/shows.ics [get]
+func GetHallCalendar(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var name string
+		err := db.QueryRow(r.Context(), "SELECT name FROM halls WHERE id = $1", hallID).Scan(&name)
+		if IsError(w, err) {
+			return
+		}
+
+		rows, err := db.Query(r.Context(), `
+			SELECT `+calendarEventColumns+`, NULL::text
+			FROM movie_shows ms
+			JOIN movies m ON m.id = ms.movie_id
+			JOIN halls h ON h.id = ms.hall_id
+			JOIN cinemas c ON c.id = h.cinema_id
+			WHERE ms.hall_id = $1 AND ms.start_time >= $2
+			ORDER BY ms.start_time`,
+			hallID, time.Now().Add(-calendarHistory))
+		if IsError(w, err) {
+			return
+		}
+		events, err := scanCalendarEvents(rows)
+		if HandleDatabaseError(w, err, "сеансом") {
+			return
+		}
+
+		writeICSCalendar(w, "Зал "+name, events)
+	}
+}
+
+// @Summary Личный календарь по секретной ссылке (guest | user | admin)
+// @Description Возвращает сеансы, на которые у владельца токена есть билеты, вместе с местами. Ссылка не требует авторизации, чтобы её мог опрашивать календарь; при переносе сеанса событие обновляется, при отмене получает статус CANCELLED.
+// @Description В календаре только действующие брони и покупки: после возврата билета пользователем событие просто исчезает из ленты, без статуса CANCELLED.
+// @Tags Календарь
+// @Produce text/calendar
+// @Param token path string true "Токен календаря"
+// @Success 200 {string} string "Календарь"
+// @Failure 400 {object} ErrorResponse "Неверный формат токена"
+// @Failure 404 {object} ErrorResponse "Календарь не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /calendar/users/{token} [get]
+func GetUserCalendar(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := ParseUUIDFromPath(w, r.PathValue("token"))
+		if !ok {
+			return
+		}
+
+		var valid bool
+		err := db.QueryRow(r.Context(), "SELECT calendar_token_valid($1)", token).Scan(&valid)
+		if IsError(w, err) {
+			return
+		}
+		if !valid {
+			http.Error(w, "Календарь не найден", http.StatusNotFound)
+			return
+		}
+
+		rows, err := db.Query(r.Context(), `
+			SELECT `+calendarEventColumns+`, ucs.seats
+			FROM user_calendar_shows($1, $2) ucs
+			JOIN movie_shows ms ON ms.id = ucs.movie_show_id
+			JOIN movies m ON m.id = ms.movie_id
+			JOIN halls h ON h.id = ms.hall_id
+			JOIN cinemas c ON c.id = h.cinema_id
+			ORDER BY ms.start_time`,
+			token, time.Now().Add(-calendarHistory))
+		if IsError(w, err) {
+			return
+		}
+		events, err := scanCalendarEvents(rows)
+		if HandleDatabaseError(w, err, "сеансом") {
+			return
+		}
+
+		writeICSCalendar(w, "Мои сеансы", events)
+	}
+}
+
+// @Summary Выпустить ссылку на личный календарь (user* | admin)
+// @Description Создаёт токен личного календаря или заменяет существующий; старая ссылка перестаёт работать.
+// @Tags Календарь
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path string true "ID пользователя"
+// @Success 201 {object} CalendarToken "Токен и ссылка на календарь"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 409 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /users/{user_id}/calendar-token [post]
+func RotateCalendarToken(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := ParseUUIDFromPath(w, r.PathValue("user_id"))
+		if !ok {
+			return
+		}
+
+		role := r.Header.Get("Role")
+		user_id := r.Header.Get("UserID")
+		if (role != os.Getenv("CLAIM_ROLE_ADMIN")) && (userID.String() != user_id) {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		var ct CalendarToken
+		err := db.QueryRow(context.Background(), `
+			INSERT INTO calendar_tokens (user_id) VALUES ($1)
+			ON CONFLICT (user_id) DO UPDATE
+			SET token = uuid_generate_v4(), created_at = CURRENT_TIMESTAMP
+			RETURNING token`, userID).Scan(&ct.Token)
+		if IsError(w, err) {
+			return
+		}
+		ct.URL = "/calendar/users/" + ct.Token
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(ct)
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestEscapeICSText(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Интерстеллар", "Интерстеллар"},
+		{"Зал 1, VIP; 3D", `Зал 1\, VIP\; 3D`},
+		{`C:\кино`, `C:\\кино`},
+		{"строка\nвторая\r\nтретья", `строка\nвторая\nтретья`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeICSText(tt.input); got != tt.expected {
+			t.Errorf("escapeICSText(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"Short", "SUMMARY:Интерстеллар"},
+		{"Exactly 75 octets", "DESCRIPTION:" + strings.Repeat("a", 63)},
+		{"Long ASCII", "DESCRIPTION:" + strings.Repeat("abc", 60)},
+		{"Long Cyrillic", "DESCRIPTION:" + strings.Repeat("Места: ряд 5\\, место 7\\; ", 10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := foldICSLine(tt.line)
+			if !strings.HasSuffix(folded, "\r\n") {
+				t.Fatalf("Folded line must end with CRLF: %q", folded)
+			}
+
+			parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			var unfolded strings.Builder
+			for i, part := range parts {
+				if len(part) > icsLineOctets {
+					t.Errorf("Part %d is %d octets long", i, len(part))
+				}
+				if !utf8.ValidString(part) {
+					t.Errorf("Part %d splits a UTF-8 sequence: %q", i, part)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(part, " ") {
+						t.Errorf("Continuation part %d must start with a space", i)
+					}
+					part = part[1:]
+				}
+				unfolded.WriteString(part)
+			}
+
+			if unfolded.String() != tt.line {
+				t.Errorf("Unfolded line differs:\n%q\n%q", unfolded.String(), tt.line)
+			}
+			if len(tt.line) <= icsLineOctets && len(parts) != 1 {
+				t.Errorf("Short line must not be folded, got %d parts", len(parts))
+			}
+		})
+	}
+}
+
+func readCalendar(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Expected text/calendar, got %q", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read calendar: %v", err)
+	}
+	// Развёртываем перенесённые строки, чтобы искать свойства целиком
+	return strings.ReplaceAll(string(body), "\r\n ", "")
+}
+
+func TestGetMovieAndHallCalendar(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedUIDs   []string
+	}{
+		{"Movie feed", "/movies/" + MoviesData[2].ID + "/shows.ics", http.StatusOK,
+			[]string{MovieShowsData[2].ID, MovieShowsData[3].ID}},
+		{"Hall feed", "/halls/" + HallsData[1].ID + "/shows.ics", http.StatusOK,
+			[]string{MovieShowsData[1].ID, MovieShowsData[3].ID}},
+		{"Invalid movie ID", "/movies/invalid-uuid/shows.ics", http.StatusBadRequest, nil},
+		{"Non-existent movie", "/movies/" + uuid.New().String() + "/shows.ics", http.StatusNotFound, nil},
+		{"Non-existent hall", "/halls/" + uuid.New().String() + "/shows.ics", http.StatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "GET", ts.URL+tt.url, "", nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			calendar := readCalendar(t, resp)
+			if !strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") {
+				t.Errorf("Unexpected calendar header: %q", calendar[:min(len(calendar), 40)])
+			}
+			if got := strings.Count(calendar, "BEGIN:VEVENT"); got != len(tt.expectedUIDs) {
+				t.Errorf("Expected %d events, got %d", len(tt.expectedUIDs), got)
+			}
+			for _, id := range tt.expectedUIDs {
+				if !strings.Contains(calendar, "UID:movie-show-"+id+"@cinema\r\n") {
+					t.Errorf("Expected event for show %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarEventUpdates(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	feed := ts.URL + "/halls/" + HallsData[0].ID + "/shows.ics"
+	fetch := func() string {
+		req := createRequest(t, "GET", feed, "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+		return readCalendar(t, resp)
+	}
+
+	if calendar := fetch(); !strings.Contains(calendar, "SEQUENCE:0\r\n") || !strings.Contains(calendar, "STATUS:CONFIRMED\r\n") {
+		t.Fatalf("Expected a confirmed event with SEQUENCE:0:\n%s", calendar)
+	}
+
+	_, err := TestAdminDB.Exec(context.Background(),
+		"UPDATE movie_shows SET start_time = start_time + INTERVAL '1 hour' WHERE id = $1", MovieShowsData[0].ID)
+	if err != nil {
+		t.Fatalf("Failed to move movie show: %v", err)
+	}
+	start := MovieShowsData[0].StartTime.Add(time.Hour).UTC().Format(icsTimeLayout)
+	if calendar := fetch(); !strings.Contains(calendar, "SEQUENCE:1\r\n") || !strings.Contains(calendar, "DTSTART:"+start+"\r\n") {
+		t.Errorf("Expected rescheduled event with SEQUENCE:1 starting at %s:\n%s", start, calendar)
+	}
+
+	req := createRequest(t, "POST", ts.URL+"/movie-shows/"+MovieShowsData[0].ID+"/cancel",
+		generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), MovieShowCancel{Reason: "Техническая неисправность проектора"})
+	resp := executeRequest(t, req, http.StatusOK)
+	resp.Body.Close()
+
+	calendar := fetch()
+	if !strings.Contains(calendar, "SEQUENCE:2\r\n") || !strings.Contains(calendar, "STATUS:CANCELLED\r\n") {
+		t.Errorf("Expected cancelled event with SEQUENCE:2:\n%s", calendar)
+	}
+}
+
+func TestUserCalendar(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	rotate := func(role, userID string, expectedStatus int) CalendarToken {
+		req := createRequest(t, "POST", ts.URL+"/users/"+userID+"/calendar-token", generateToken(t, role), nil)
+		resp := executeRequest(t, req, expectedStatus)
+		defer resp.Body.Close()
+
+		var ct CalendarToken
+		if expectedStatus == http.StatusCreated {
+			parseResponseBody(t, resp, &ct)
+		}
+		return ct
+	}
+
+	rotate("", UsersData[0].ID, http.StatusForbidden)
+	rotate(os.Getenv("CLAIM_ROLE_USER"), UsersData[0].ID, http.StatusForbidden)
+	rotate(os.Getenv("CLAIM_ROLE_USER"), "invalid-uuid", http.StatusBadRequest)
+	rotate(os.Getenv("CLAIM_ROLE_USER"), UsersData[len(UsersData)-1].ID, http.StatusCreated)
+
+	ct := rotate(os.Getenv("CLAIM_ROLE_ADMIN"), UsersData[0].ID, http.StatusCreated)
+	if ct.URL != "/calendar/users/"+ct.Token {
+		t.Errorf("Unexpected calendar URL %q", ct.URL)
+	}
+
+	req := createRequest(t, "GET", ts.URL+ct.URL, "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	calendar := readCalendar(t, resp)
+	resp.Body.Close()
+
+	for _, id := range []string{MovieShowsData[0].ID, MovieShowsData[1].ID} {
+		if !strings.Contains(calendar, "UID:movie-show-"+id+"@cinema\r\n") {
+			t.Errorf("Expected event for show %s", id)
+		}
+	}
+	if strings.Contains(calendar, MovieShowsData[2].ID) {
+		t.Errorf("Show without user's tickets must not be in the feed")
+	}
+	if !strings.Contains(calendar, "ряд ") {
+		t.Errorf("Expected seats in event description:\n%s", calendar)
+	}
+
+	rotated := rotate(os.Getenv("CLAIM_ROLE_ADMIN"), UsersData[0].ID, http.StatusCreated)
+	if rotated.Token == ct.Token {
+		t.Fatal("Expected a new token after rotation")
+	}
+
+	req = createRequest(t, "GET", ts.URL+ct.URL, "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/calendar/users/"+uuid.New().String(), "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "GET", ts.URL+rotated.URL, "", nil)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+}
+
+func TestUserCalendarCancelledReservation(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	req := createRequest(t, "POST", ts.URL+"/users/"+UsersData[0].ID+"/calendar-token",
+		generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), nil)
+	resp := executeRequest(t, req, http.StatusCreated)
+	var ct CalendarToken
+	parseResponseBody(t, resp, &ct)
+	resp.Body.Close()
+
+	// У пользователя бронь на MovieShowsData[1]: отмена снимает её, но событие остаётся отменённым
+	req = createRequest(t, "POST", ts.URL+"/movie-shows/"+MovieShowsData[1].ID+"/cancel",
+		generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), MovieShowCancel{Reason: "Отключение электричества"})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	req = createRequest(t, "GET", ts.URL+ct.URL, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	calendar := readCalendar(t, resp)
+	resp.Body.Close()
+
+	if !strings.Contains(calendar, "UID:movie-show-"+MovieShowsData[1].ID+"@cinema\r\n") ||
+		!strings.Contains(calendar, "STATUS:CANCELLED\r\n") {
+		t.Errorf("Expected cancelled event for released reservation:\n%s", calendar)
+	}
+}
@@ -0,0 +1,515 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Запросы, находящие кинотеатр объекта по параметру $2, для requireCinemaAccess
+const (
+	cinemaByID          = "SELECT $2::uuid"
+	cinemaOfHall        = "SELECT cinema_id FROM halls WHERE id = $2"
+	cinemaOfSeat        = "SELECT h.cinema_id FROM seats s JOIN halls h ON h.id = s.hall_id WHERE s.id = $2"
+	cinemaOfShow        = "SELECT h.cinema_id FROM movie_shows ms JOIN halls h ON h.id = ms.hall_id WHERE ms.id = $2"
+	cinemaOfTicket      = "SELECT h.cinema_id FROM movie_show_tickets t JOIN movie_shows ms ON ms.id = t.movie_show_id JOIN halls h ON h.id = ms.hall_id WHERE t.id = $2"
+	cinemaOfZone        = "SELECT h.cinema_id FROM hall_zones z JOIN halls h ON h.id = z.hall_id WHERE z.id = $2"
+	cinemaOfMaintenance = "SELECT h.cinema_id FROM hall_maintenance_windows mw JOIN halls h ON h.id = mw.hall_id WHERE mw.id = $2"
+)
+
+// requireCinemaAccess отвечает 403, если администратор закреплён за кинотеатрами,
+// среди которых нет кинотеатра объекта. Права остальных ролей проверяет база данных,
+// а ненайденный объект пропускается, чтобы обработчик вернул обычную ошибку.
+func requireCinemaAccess(w http.ResponseWriter, r *http.Request, db *pgxpool.Pool, cinemaQuery string, id any) bool {
+	if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+		return true
+	}
+
+	var allowed *bool
+	err := db.QueryRow(r.Context(),
+		"SELECT admin_can_manage_cinema($1, ("+cinemaQuery+"))", r.Header.Get("UserID"), id).Scan(&allowed)
+	if HandleDatabaseError(w, err, "кинотеатрами") {
+		return false
+	}
+
+	if allowed != nil && !*allowed {
+		http.Error(w, "Доступ к кинотеатру запрещён", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// requireNetworkAdmin пропускает только администраторов, не закреплённых за отдельными кинотеатрами
+func requireNetworkAdmin(w http.ResponseWriter, r *http.Request, db *pgxpool.Pool) bool {
+	if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+		http.Error(w, "Доступ запрещён", http.StatusForbidden)
+		return false
+	}
+
+	var scoped bool
+	err := db.QueryRow(r.Context(),
+		"SELECT EXISTS (SELECT 1 FROM cinema_admins WHERE user_id = $1)", r.Header.Get("UserID")).Scan(&scoped)
+	if HandleDatabaseError(w, err, "кинотеатрами") {
+		return false
+	}
+
+	if scoped {
+		http.Error(w, "Действие доступно только администратору всей сети", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// parseCinemaFilter разбирает необязательный параметр cinema_id; nil означает все кинотеатры
+func parseCinemaFilter(w http.ResponseWriter, r *http.Request) (*string, bool) {
+	cinemaID := r.URL.Query().Get("cinema_id")
+	if cinemaID == "" {
+		return nil, true
+	}
+	if _, err := uuid.Parse(cinemaID); err != nil {
+		http.Error(w, "Неверный формат ID кинотеатра", http.StatusBadRequest)
+		return nil, false
+	}
+	return &cinemaID, true
+}
+
+func validateAllCinemaData(w http.ResponseWriter, c *CinemaData) bool {
+	c.Name = PrepareString(c.Name)
+	c.Address = PrepareString(c.Address)
+	c.Phone = PrepareStringPointer(c.Phone)
+	c.Email = PrepareStringPointer(c.Email)
+
+	if err := validateCinemaName(c.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if err := validateCinemaAddress(c.Address); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if c.TimeZone != nil {
+		if _, err := LoadTimeZone(*c.TimeZone); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	if err := validateCinemaPhone(c.Phone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if c.Email != nil {
+		if err := validateUserEmail(*c.Email); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	return true
+}
+
+func validateCinemaName(name string) error {
+	if name == "" {
+		return errors.New("название кинотеатра не может быть пустым или состоять только из пробелов")
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return errors.New("название кинотеатра не может превышать 100 символов")
+	}
+	return nil
+}
+
+func validateCinemaAddress(address string) error {
+	if address == "" {
+		return errors.New("адрес кинотеатра не может быть пустым или состоять только из пробелов")
+	}
+	if utf8.RuneCountInString(address) > 300 {
+		return errors.New("адрес кинотеатра не может превышать 300 символов")
+	}
+	return nil
+}
+
+func validateCinemaPhone(phone *string) error {
+	if phone != nil && !regexp.MustCompile(`^\+?[0-9\s()-]{5,20}$`).MatchString(*phone) {
+		return errors.New("неверный формат телефона кинотеатра")
+	}
+	return nil
+}
+
+// @Summary Получить все кинотеатры (guest | user | admin)
+// @Description Возвращает список кинотеатров сети.
+// @Tags Кинотеатры
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Cinema] "Страница кинотеатров"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas [get]
+func GetCinemas(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
+			return
+		}
+
+		page, err := fetchPage(context.Background(), db, q, "id, name, address, time_zone, phone, email", "cinemas",
+			func(row pgx.Row) (Cinema, error) {
+				var c Cinema
+				err := row.Scan(&c.ID, &c.Name, &c.Address, &c.TimeZone, &c.Phone, &c.Email)
+				return c, err
+			})
+		if HandleDatabaseError(w, err, "кинотеатрами") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Получить кинотеатр по ID (guest | user | admin)
+// @Description Возвращает кинотеатр по ID.
+// @Tags Кинотеатры
+// @Produce json
+// @Param id path string true "ID кинотеатра"
+// @Success 200 {object} Cinema "Данные кинотеатра"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Кинотеатр не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas/{id} [get]
+func GetCinemaByID(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var c Cinema
+		c.ID = id.String()
+		err := db.QueryRow(context.Background(),
+			"SELECT name, address, time_zone, phone, email FROM cinemas WHERE id = $1", id).
+			Scan(&c.Name, &c.Address, &c.TimeZone, &c.Phone, &c.Email)
+
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c)
+	}
+}
+
+// @Summary Создать кинотеатр (admin)
+// @Description Создаёт кинотеатр. Доступно только администратору всей сети.
+// @Description Часовой пояс кинотеатра (time_zone, по умолчанию UTC) действует для всех его залов.
+// @Tags Кинотеатры
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param cinema body CinemaData true "Данные кинотеатра"
+// @Success 201 {object} CreateResponse "ID созданного кинотеатра"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 409 {object} ErrorResponse "Кинотеатр с таким названием уже существует"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas [post]
+func CreateCinema(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireNetworkAdmin(w, r, db) {
+			return
+		}
+
+		var c CinemaData
+		if !DecodeJSONBody(w, r, &c) || !validateAllCinemaData(w, &c) {
+			return
+		}
+
+		id := uuid.New()
+		_, err := db.Exec(context.Background(),
+			`INSERT INTO cinemas (id, name, address, time_zone, phone, email)
+			VALUES ($1, $2, $3, COALESCE($4, 'UTC'), $5, $6)`,
+			id, c.Name, c.Address, c.TimeZone, c.Phone, c.Email)
+
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(id.String())
+	}
+}
+
+// @Summary Обновить кинотеатр (admin)
+// @Description Обновляет кинотеатр. Если time_zone не передан, часовой пояс не меняется;
+// @Description новый пояс сразу действует для всех залов кинотеатра.
+// @Tags Кинотеатры
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID кинотеатра"
+// @Param cinema body CinemaData true "Обновлённые данные кинотеатра"
+// @Success 200 "Данные о кинотеатре успешно обновлены"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Кинотеатр не найден"
+// @Failure 409 {object} ErrorResponse "Кинотеатр с таким названием уже существует"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas/{id} [put]
+func UpdateCinema(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var c CinemaData
+		if !DecodeJSONBody(w, r, &c) || !validateAllCinemaData(w, &c) {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaByID, id) {
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			`UPDATE cinemas SET name=$1, address=$2, time_zone=COALESCE($3, time_zone), phone=$4, email=$5
+			WHERE id=$6`,
+			c.Name, c.Address, c.TimeZone, c.Phone, c.Email, id)
+
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// @Summary Удалить кинотеатр (admin)
+// @Description Удаляет кинотеатр по ID. Кинотеатр с залами удалить нельзя. Доступно только администратору всей сети.
+// @Tags Кинотеатры
+// @Param id path string true "ID кинотеатра"
+// @Security BearerAuth
+// @Success 204 "Кинотеатр успешно удалён"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Кинотеатр не найден"
+// @Failure 409 {object} ErrorResponse "В кинотеатре есть залы"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas/{id} [delete]
+func DeleteCinema(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		if !requireNetworkAdmin(w, r, db) {
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			"DELETE FROM cinemas WHERE id = $1", id)
+
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+var cinemaAdminListSpec = listSpec{
+	ID:          "user_id",
+	Sorts:       map[string]listSortField{"user_id": {Expr: "user_id", Kind: listUUID}},
+	DefaultSort: "user_id",
+}
+
+// @Summary Администраторы кинотеатра (admin)
+// @Description Возвращает страницу администраторов, закреплённых за кинотеатром. Доступно только администратору всей сети.
+// @Tags Кинотеатры
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID кинотеатра"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: user_id или -user_id (по убыванию)"
+// @Success 200 {object} Page[CinemaAdmin] "Страница администраторов кинотеатра"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID или неверные параметры списка"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas/{id}/admins [get]
+func GetCinemaAdmins(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		if !requireNetworkAdmin(w, r, db) {
+			return
+		}
+
+		q, ok := parseListQuery(w, r, cinemaAdminListSpec)
+		if !ok {
+			return
+		}
+		q.where("cinema_id = $%[1]d", id)
+
+		page, err := fetchPage(r.Context(), db, q, "user_id", "cinema_admins",
+			func(row pgx.Row) (CinemaAdmin, error) {
+				var a CinemaAdmin
+				err := row.Scan(&a.UserID)
+				return a, err
+			})
+		if HandleDatabaseError(w, err, "администраторами кинотеатра") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Закрепить администратора за кинотеатром (admin)
+// @Description Ограничивает администратора управлением залами, местами и сеансами указанных кинотеатров.
+// @Description Администратор без закреплённых кинотеатров управляет всей сетью. Доступно только администратору всей сети.
+// @Tags Кинотеатры
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "ID кинотеатра"
+// @Param admin body CinemaAdmin true "ID администратора"
+// @Success 201 "Администратор закреплён за кинотеатром"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные или кинотеатр не существует"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 409 {object} ErrorResponse "Пользователь не администратор или уже закреплён за кинотеатром"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas/{id}/admins [post]
+func AddCinemaAdmin(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var a CinemaAdmin
+		if !DecodeJSONBody(w, r, &a) {
+			return
+		}
+		if _, err := uuid.Parse(a.UserID); err != nil {
+			http.Error(w, "Неверный формат ID пользователя", http.StatusBadRequest)
+			return
+		}
+
+		if !requireNetworkAdmin(w, r, db) {
+			return
+		}
+
+		var isAdmin bool
+		err := db.QueryRow(r.Context(), "SELECT is_admin FROM users WHERE id = $1", a.UserID).Scan(&isAdmin)
+		if IsError(w, err) {
+			return
+		}
+		if !isAdmin {
+			http.Error(w, "Закрепить за кинотеатром можно только администратора", http.StatusConflict)
+			return
+		}
+
+		_, err = db.Exec(r.Context(),
+			"INSERT INTO cinema_admins (user_id, cinema_id) VALUES ($1, $2)", a.UserID, id)
+		if IsError(w, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// @Summary Открепить администратора от кинотеатра (admin)
+// @Description Снимает ограничение администратора кинотеатром. Последний кинотеатр открепить нельзя,
+// @Description иначе администратор получит доступ ко всей сети: сначала снимите права администратора.
+// @Description Доступно только администратору всей сети.
+// @Tags Кинотеатры
+// @Security BearerAuth
+// @Param id path string true "ID кинотеатра"
+// @Param user_id path string true "ID администратора"
+// @Success 204 "Администратор откреплён от кинотеатра"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Администратор не закреплён за кинотеатром"
+// @Failure 409 {object} ErrorResponse "Это последний кинотеатр администратора"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /cinemas/{id}/admins/{user_id} [delete]
+func RemoveCinemaAdmin(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+		userID, ok := ParseUUIDFromPath(w, r.PathValue("user_id"))
+		if !ok {
+			return
+		}
+
+		if !requireNetworkAdmin(w, r, db) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		res, err := tx.Exec(ctx,
+			"DELETE FROM cinema_admins WHERE user_id = $1 AND cinema_id = $2", userID, id)
+		if IsError(w, err) {
+			return
+		}
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		var remaining bool
+		err = tx.QueryRow(ctx,
+			"SELECT EXISTS (SELECT 1 FROM cinema_admins WHERE user_id = $1)", userID).Scan(&remaining)
+		if IsError(w, err) {
+			return
+		}
+		if !remaining {
+			http.Error(w, "Нельзя открепить администратора от последнего кинотеатра: он получит доступ ко всей сети", http.StatusConflict)
+			return
+		}
+
+		if err := tx.Commit(ctx); IsError(w, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// scopedAdminToken выдаёт токен администратора UsersData[1], закреплённого за CinemasData[1]
+func scopedAdminToken(t *testing.T) string {
+	t.Helper()
+	if _, err := TestAdminDB.Exec(context.Background(),
+		"INSERT INTO cinema_admins (user_id, cinema_id) VALUES ($1, $2)", UsersData[1].ID, CinemasData[1].ID); err != nil {
+		t.Fatalf("Failed to scope admin: %v", err)
+	}
+
+	token, err := GenerateToken(os.Getenv("CLAIM_ROLE_ADMIN"), UsersData[1].ID)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	return token
+}
+
+func TestValidateCinemaPhone(t *testing.T) {
+	valid := []string{"+7 495 123-45-67", "84951234567", "+1 (555) 010-99"}
+	for _, phone := range valid {
+		if err := validateCinemaPhone(&phone); err != nil {
+			t.Errorf("Expected %q to be valid: %v", phone, err)
+		}
+	}
+
+	invalid := []string{"", "123", "call me", "+7 495 123-45-67 доб. 1"}
+	for _, phone := range invalid {
+		if err := validateCinemaPhone(&phone); err == nil {
+			t.Errorf("Expected %q to be invalid", phone)
+		}
+	}
+
+	if err := validateCinemaPhone(nil); err != nil {
+		t.Errorf("Phone is optional: %v", err)
+	}
+}
+
+func TestGetCinemas(t *testing.T) {
+	ts := setupTestServer()
+	defer ts.Close()
+
+	req := createRequest(t, "GET", ts.URL+"/cinemas", "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var empty Page[Cinema]
+	parseResponseBody(t, resp, &empty)
+	resp.Body.Close()
+	if empty.Items == nil || len(empty.Items) != 0 || empty.NextCursor != nil {
+		t.Errorf("Expected empty page, got %+v", empty)
+	}
+
+	SeedAll(TestAdminDB)
+	for _, role := range []string{"", os.Getenv("CLAIM_ROLE_USER"), os.Getenv("CLAIM_ROLE_ADMIN")} {
+		req := createRequest(t, "GET", ts.URL+"/cinemas", generateToken(t, role), nil)
+		resp := executeRequest(t, req, http.StatusOK)
+
+		var page Page[Cinema]
+		parseResponseBody(t, resp, &page)
+		resp.Body.Close()
+
+		if len(page.Items) != len(CinemasData) {
+			t.Errorf("Expected %d cinemas, got %d", len(CinemasData), len(page.Items))
+		}
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/cinemas/"+CinemasData[1].ID, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var c Cinema
+	parseResponseBody(t, resp, &c)
+	resp.Body.Close()
+	if c.ID != CinemasData[1].ID || c.Name != CinemasData[1].Name || c.TimeZone != CinemasData[1].TimeZone || c.Phone != nil {
+		t.Errorf("Expected %+v, got %+v", CinemasData[1], c)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/cinemas/"+uuid.New().String(), "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/cinemas/invalid-uuid", "", nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+}
+
+func TestCreateCinema(t *testing.T) {
+	valid := CinemaData{Name: "Кинотеатр Звезда", Address: "Казань, ул. Баумана, 5", TimeZone: ptr("Europe/Moscow")}
+
+	tests := []struct {
+		name           string
+		token          func(t *testing.T) string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Guest", func(t *testing.T) string { return "" }, valid, http.StatusForbidden},
+		{"User", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_USER")) }, valid, http.StatusForbidden},
+		{"Scoped admin", scopedAdminToken, valid, http.StatusForbidden},
+		{"Admin", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")) }, valid, http.StatusCreated},
+		{"Default time zone", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")) },
+			CinemaData{Name: "Кинотеатр Звезда", Address: "Казань"}, http.StatusCreated},
+		{"Unknown time zone", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")) },
+			CinemaData{Name: "Кинотеатр Звезда", Address: "Казань", TimeZone: ptr("Mars/Olympus")}, http.StatusBadRequest},
+		{"Empty address", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")) },
+			CinemaData{Name: "Кинотеатр Звезда", Address: "  "}, http.StatusBadRequest},
+		{"Invalid email", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")) },
+			CinemaData{Name: "Кинотеатр Звезда", Address: "Казань", Email: ptr("zvezda")}, http.StatusBadRequest},
+		{"Duplicate name", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")) },
+			CinemaData{Name: CinemasData[0].Name, Address: "Казань"}, http.StatusConflict},
+		{"Invalid JSON", func(t *testing.T) string { return generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")) },
+			"{invalid json}", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/cinemas", tt.token(t), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var id string
+			parseResponseBody(t, resp, &id)
+			var timeZone string
+			if err := TestAdminDB.QueryRow(context.Background(),
+				"SELECT time_zone FROM cinemas WHERE id = $1", id).Scan(&timeZone); err != nil {
+				t.Fatalf("Failed to get cinema: %v", err)
+			}
+
+			expected := "UTC"
+			if body := tt.body.(CinemaData); body.TimeZone != nil {
+				expected = *body.TimeZone
+			}
+			if timeZone != expected {
+				t.Errorf("Expected time zone %s, got %s", expected, timeZone)
+			}
+		})
+	}
+}
+
+func TestUpdateCinemaTimeZone(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	body := CinemaData{Name: CinemasData[0].Name, Address: CinemasData[0].Address, TimeZone: ptr("Asia/Yekaterinburg")}
+	req := createRequest(t, "PUT", ts.URL+"/cinemas/"+CinemasData[0].ID, generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), body)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	// Новый пояс сразу действует для всех залов кинотеатра
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[0].ID, "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var h Hall
+	parseResponseBody(t, resp, &h)
+	resp.Body.Close()
+	if h.TimeZone != "Asia/Yekaterinburg" {
+		t.Errorf("Expected hall time zone Asia/Yekaterinburg, got %s", h.TimeZone)
+	}
+
+	// Без time_zone пояс не меняется
+	body.TimeZone = nil
+	req = createRequest(t, "PUT", ts.URL+"/cinemas/"+CinemasData[0].ID, generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), body)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	var timeZone string
+	if err := TestAdminDB.QueryRow(context.Background(),
+		"SELECT time_zone FROM cinemas WHERE id = $1", CinemasData[0].ID).Scan(&timeZone); err != nil {
+		t.Fatalf("Failed to get cinema: %v", err)
+	}
+	if timeZone != "Asia/Yekaterinburg" {
+		t.Errorf("Expected time zone to stay Asia/Yekaterinburg, got %s", timeZone)
+	}
+
+	req = createRequest(t, "PUT", ts.URL+"/cinemas/"+uuid.New().String(), generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), body)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/cinemas/"+CinemasData[0].ID, generateToken(t, os.Getenv("CLAIM_ROLE_USER")), body)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+}
+
+func TestDeleteCinema(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		expectedStatus int
+	}{
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), CinemasData[0].ID, http.StatusForbidden},
+		{"Cinema with halls", os.Getenv("CLAIM_ROLE_ADMIN"), CinemasData[0].ID, http.StatusConflict},
+		{"Non-existent", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), http.StatusNotFound},
+		{"Invalid ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "DELETE", ts.URL+"/cinemas/"+tt.id, generateToken(t, tt.role), nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+
+	t.Run("Empty cinema", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		defer ts.Close()
+
+		if _, err := TestAdminDB.Exec(context.Background(), "DELETE FROM halls WHERE cinema_id = $1", CinemasData[1].ID); err != nil {
+			t.Fatalf("Failed to delete halls: %v", err)
+		}
+
+		req := createRequest(t, "DELETE", ts.URL+"/cinemas/"+CinemasData[1].ID, generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), nil)
+		executeRequest(t, req, http.StatusNoContent).Body.Close()
+	})
+}
+
+func TestCinemaFilters(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"Halls of main cinema", "/halls?cinema_id=" + CinemasData[0].ID, http.StatusOK, 4},
+		{"Halls of second cinema", "/halls?cinema_id=" + CinemasData[1].ID, http.StatusOK, 1},
+		{"Halls of unknown cinema", "/halls?cinema_id=" + uuid.New().String(), http.StatusOK, 0},
+		{"Halls with invalid cinema", "/halls?cinema_id=invalid-uuid", http.StatusBadRequest, 0},
+		{"Hall search in cinema", "/halls/search?query=кинозал&cinema_id=" + CinemasData[1].ID, http.StatusOK, 1},
+		{"Halls by screen type in cinema", "/halls/by-screen-type?screen_type_id=" + ScreenTypesData[3].ID +
+			"&cinema_id=" + CinemasData[0].ID, http.StatusOK, 1},
+		{"Shows of main cinema", "/movie-shows?cinema_id=" + CinemasData[0].ID, http.StatusOK, len(MovieShowsData)},
+		{"Shows of second cinema", "/movie-shows?cinema_id=" + CinemasData[1].ID, http.StatusOK, 0},
+		{"Upcoming shows with invalid cinema", "/movie-shows/upcoming?cinema_id=invalid-uuid", http.StatusBadRequest, 0},
+		{"Movie shows in cinema", "/movies/" + MoviesData[2].ID + "/shows?hours=72&cinema_id=" + CinemasData[0].ID, http.StatusOK, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createRequest(t, "GET", ts.URL+tt.url, "", nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var page Page[map[string]any]
+			parseResponseBody(t, resp, &page)
+			if len(page.Items) != tt.expectedCount {
+				t.Errorf("Expected %d items, got %d", tt.expectedCount, len(page.Items))
+			}
+		})
+	}
+}
+
+func TestScopedCinemaAdmin(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	token := scopedAdminToken(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Update own cinema", "PUT", "/cinemas/" + CinemasData[1].ID,
+			CinemaData{Name: CinemasData[1].Name, Address: "Владивосток, Светланская ул., 1"}, http.StatusOK},
+		{"Update other cinema", "PUT", "/cinemas/" + CinemasData[0].ID,
+			CinemaData{Name: CinemasData[0].Name, Address: CinemasData[0].Address}, http.StatusForbidden},
+		{"Create hall in own cinema", "POST", "/halls",
+			HallData{CinemaID: CinemasData[1].ID, Name: "Новый зал", ScreenTypeID: ScreenTypesData[0].ID}, http.StatusCreated},
+		{"Create hall in other cinema", "POST", "/halls",
+			HallData{CinemaID: CinemasData[0].ID, Name: "Новый зал", ScreenTypeID: ScreenTypesData[0].ID}, http.StatusForbidden},
+		{"Move own hall to other cinema", "PUT", "/halls/" + HallsData[4].ID,
+			HallData{CinemaID: CinemasData[0].ID, Name: HallsData[4].Name, ScreenTypeID: HallsData[4].ScreenTypeID}, http.StatusForbidden},
+		{"Delete hall of other cinema", "DELETE", "/halls/" + HallsData[0].ID, nil, http.StatusForbidden},
+		{"Create seat in other cinema", "POST", "/seats",
+			SeatData{HallID: HallsData[0].ID, SeatTypeID: SeatTypesData[0].ID, RowNumber: 20, SeatNumber: 1}, http.StatusForbidden},
+		{"Create seat in own cinema", "POST", "/seats",
+			SeatData{HallID: HallsData[4].ID, SeatTypeID: SeatTypesData[0].ID, RowNumber: 1, SeatNumber: 1}, http.StatusCreated},
+		{"Cancel show in other cinema", "POST", "/movie-shows/" + MovieShowsData[0].ID + "/cancel",
+			MovieShowCancel{Reason: "Технические работы"}, http.StatusForbidden},
+		{"Delete show in other cinema", "DELETE", "/movie-shows/" + MovieShowsData[2].ID, nil, http.StatusForbidden},
+		{"Create ticket in other cinema", "POST", "/tickets",
+			TicketData{MovieShowID: MovieShowsData[0].ID, SeatID: SeatsData[3].ID, Status: Available, Price: 500}, http.StatusForbidden},
+		{"Update ticket in other cinema", "PUT", "/tickets/" + TicketsData[0].ID,
+			TicketData{MovieShowID: MovieShowsData[0].ID, SeatID: SeatsData[0].ID, Status: Available, Price: 500}, http.StatusForbidden},
+		{"Delete ticket in other cinema", "DELETE", "/tickets/" + TicketsData[1].ID, nil, http.StatusForbidden},
+		{"Propose schedule for other cinema", "POST", "/schedule/proposals",
+			ScheduleRequest{WeekStart: "2030-06-03", CinemaID: &CinemasData[0].ID, Movies: []ScheduleMovie{{MovieID: MoviesData[0].ID, Screenings: 1}}}, http.StatusForbidden},
+		{"Propose schedule in hall of other cinema", "POST", "/schedule/proposals",
+			ScheduleRequest{WeekStart: "2030-06-03", Halls: []ScheduleHall{{HallID: HallsData[0].ID}}, Movies: []ScheduleMovie{{MovieID: MoviesData[0].ID, Screenings: 1}}}, http.StatusForbidden},
+		{"Non-existent hall", "DELETE", "/halls/" + uuid.New().String(), nil, http.StatusNotFound},
+		{"Create cinema", "POST", "/cinemas", CinemaData{Name: "Кинотеатр Звезда", Address: "Казань"}, http.StatusForbidden},
+		{"Grant admin rights", "PUT", "/user/admin-status/" + UsersData[0].ID, UserAdmin{IsAdmin: true}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createRequest(t, tt.method, ts.URL+tt.url, token, tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+}
+
+func TestCinemaAdmins(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	adminsURL := ts.URL + "/cinemas/" + CinemasData[0].ID + "/admins"
+
+	req := createRequest(t, "GET", adminsURL, admin, nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var empty Page[CinemaAdmin]
+	parseResponseBody(t, resp, &empty)
+	resp.Body.Close()
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Errorf("Expected no cinema admins, got %+v", empty)
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"User", generateToken(t, os.Getenv("CLAIM_ROLE_USER")), CinemaAdmin{UserID: UsersData[1].ID}, http.StatusForbidden},
+		{"Not an admin", admin, CinemaAdmin{UserID: UsersData[0].ID}, http.StatusConflict},
+		{"Unknown user", admin, CinemaAdmin{UserID: uuid.New().String()}, http.StatusNotFound},
+		{"Invalid user ID", admin, CinemaAdmin{UserID: "invalid-uuid"}, http.StatusBadRequest},
+		{"Admin", admin, CinemaAdmin{UserID: UsersData[1].ID}, http.StatusCreated},
+		{"Duplicate", admin, CinemaAdmin{UserID: UsersData[1].ID}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		req := createRequest(t, "POST", adminsURL, tt.token, tt.body)
+		executeRequest(t, req, tt.expectedStatus).Body.Close()
+	}
+
+	req = createRequest(t, "GET", adminsURL, admin, nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var admins Page[CinemaAdmin]
+	parseResponseBody(t, resp, &admins)
+	resp.Body.Close()
+	if len(admins.Items) != 1 || admins.Items[0].UserID != UsersData[1].ID {
+		t.Errorf("Expected UsersData[1] as the only cinema admin, got %+v", admins.Items)
+	}
+
+	// Последнюю привязку снять нельзя: администратор получил бы доступ ко всей сети
+	req = createRequest(t, "DELETE", adminsURL+"/"+UsersData[1].ID, admin, nil)
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	req = createRequest(t, "POST", ts.URL+"/cinemas/"+CinemasData[1].ID+"/admins", admin, CinemaAdmin{UserID: UsersData[1].ID})
+	executeRequest(t, req, http.StatusCreated).Body.Close()
+	req = createRequest(t, "DELETE", adminsURL+"/"+UsersData[1].ID, admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+	req = createRequest(t, "DELETE", adminsURL+"/"+UsersData[1].ID, admin, nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+}
@@ -43,37 +43,77 @@ var SeatTypesData = []SeatType{
 	{ID: uuid.New().String(), Name: "Кресло с массажем", Description: "Кресло, которое предлагает функции массажа для расслабления зрителей."},
 }
 
-func ptr(s string) *string {
-	return &s
+// LanguagesData и ShowFormatsData повторяют справочники, которые создаёт схема
+var LanguagesData = []Language{
+	{ID: uuid.New().String(), Name: "English"},
+	{ID: uuid.New().String(), Name: "Spanish"},
+	{ID: uuid.New().String(), Name: "French"},
+	{ID: uuid.New().String(), Name: "German"},
+	{ID: uuid.New().String(), Name: "Italian"},
+	{ID: uuid.New().String(), Name: "Русский"},
+}
+
+var ShowFormatsData = []ShowFormat{
+	{ID: uuid.New().String(), Name: "2D", Description: ptr("Обычный показ")},
+	{ID: uuid.New().String(), Name: "3D", Description: ptr("Стереоскопический показ, нужны 3D-очки")},
+	{ID: uuid.New().String(), Name: "Дубляж", Description: ptr("Звуковая дорожка переведена")},
+	{ID: uuid.New().String(), Name: "Оригинал", Description: ptr("Оригинальная звуковая дорожка")},
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// Залы 0-3 находятся в CinemasData[0], зал 4 — в CinemasData[1]
+var CinemasData = []Cinema{
+	{
+		ID:       uuid.New().String(),
+		Name:     "Кинотеатр Центральный",
+		Address:  "Москва, ул. Тверская, 1",
+		TimeZone: "UTC",
+		Phone:    ptr("+7 495 123-45-67"),
+		Email:    ptr("central@cinema.ru"),
+	},
+	{
+		ID:       uuid.New().String(),
+		Name:     "Кинотеатр Океан",
+		Address:  "Владивосток, Набережная ул., 3",
+		TimeZone: "Asia/Vladivostok",
+	},
 }
 
 var HallsData = []Hall{
 	{
 		ID:           uuid.New().String(),
+		CinemaID:     CinemasData[0].ID,
 		Name:         "Основной кинозал",
 		ScreenTypeID: ScreenTypesData[0].ID,
 		Description:  ptr("Главный кинозал кинотеатра с современным оборудованием"),
 	},
 	{
 		ID:           uuid.New().String(),
+		CinemaID:     CinemasData[0].ID,
 		Name:         "Малый кинозал",
 		ScreenTypeID: ScreenTypesData[3].ID,
 		Description:  ptr("Небольшой уютный кинозал для камерных просмотров"),
 	},
 	{
 		ID:           uuid.New().String(),
+		CinemaID:     CinemasData[0].ID,
 		Name:         "VIP кинозал",
 		ScreenTypeID: ScreenTypesData[2].ID,
 		Description:  ptr("Премиальный кинозал с креслами-реклайнерами и сервисом"),
 	},
 	{
 		ID:           uuid.New().String(),
+		CinemaID:     CinemasData[0].ID,
 		Name:         "IMAX кинозал",
 		ScreenTypeID: ScreenTypesData[4].ID,
 		Description:  ptr("Зал с технологией IMAX Laser для максимального погружения"),
 	},
 	{
 		ID:           uuid.New().String(),
+		CinemaID:     CinemasData[1].ID,
 		Name:         "4DX кинозал",
 		ScreenTypeID: ScreenTypesData[3].ID,
 		Description:  ptr("Зал с движущимися креслами и спецэффектами"),
@@ -202,6 +242,9 @@ var MovieShowsData = []MovieShow{
 		HallID:    HallsData[1].ID,
 		StartTime: time.Now().Add(26 * time.Hour),
 		Language:  "English",
+		// Оригинальная дорожка с русскими субтитрами
+		SubtitleLanguage: ptr("Русский"),
+		Formats:          []string{"2D", "Оригинал"},
 	},
 	{
 		ID:        uuid.New().String(),
@@ -209,6 +252,7 @@ var MovieShowsData = []MovieShow{
 		HallID:    HallsData[2].ID,
 		StartTime: time.Now().Add(48 * time.Hour),
 		Language:  "Русский",
+		Formats:   []string{"3D", "Дубляж"},
 	},
 	{
 		ID:        uuid.New().String(),
@@ -326,3 +370,25 @@ var MoviesGenresData = [][2]string{
 	{MoviesData[4].ID, GenresData[0].ID}, // Зеленая книга - Драма
 	{MoviesData[4].ID, GenresData[1].ID}, // Зеленая книга - Комедия
 }
+
+var PeopleData = []Person{
+	{ID: uuid.New().String(), Name: "Кристофер Нолан", BirthDate: ptr("1970-07-30")},
+	{ID: uuid.New().String(), Name: "Леонардо ДиКаприо", BirthDate: ptr("1974-11-11")},
+	{ID: uuid.New().String(), Name: "Кристиан Бейл", BirthDate: ptr("1974-01-30")},
+	{ID: uuid.New().String(), Name: "Питер Фаррелли", Biography: ptr("Американский режиссёр, сценарист и продюсер.")},
+	{ID: uuid.New().String(), Name: "Вигго Мортенсен"},
+}
+
+// MovieCreditsData — создатели фильмов в порядке титров
+var MovieCreditsData = []struct {
+	MovieID string
+	Credit  MovieCreditData
+}{
+	{MoviesData[0].ID, MovieCreditData{PersonID: PeopleData[0].ID, Role: CreditDirector}},
+	{MoviesData[1].ID, MovieCreditData{PersonID: PeopleData[0].ID, Role: CreditDirector}},
+	{MoviesData[1].ID, MovieCreditData{PersonID: PeopleData[0].ID, Role: CreditWriter}},
+	{MoviesData[1].ID, MovieCreditData{PersonID: PeopleData[1].ID, Role: CreditActor, Character: ptr("Кобб")}},
+	{MoviesData[3].ID, MovieCreditData{PersonID: PeopleData[0].ID, Role: CreditDirector}},
+	{MoviesData[3].ID, MovieCreditData{PersonID: PeopleData[2].ID, Role: CreditActor, Character: ptr("Брюс Уэйн / Бэтмен")}},
+	{MoviesData[4].ID, MovieCreditData{PersonID: PeopleData[3].ID, Role: CreditDirector}},
+}
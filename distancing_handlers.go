@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const maxDistancingSideSeats = 10
+
+func validateMovieShowDistancing(d MovieShowDistancing) error {
+	if d.SideSeats < 0 || d.SideSeats > maxDistancingSideSeats {
+		return fmt.Errorf("число блокируемых мест с каждой стороны должно быть от 0 до %d", maxDistancingSideSeats)
+	}
+	if d.MaxOccupancyPercent != nil && (*d.MaxOccupancyPercent < 1 || *d.MaxOccupancyPercent > 100) {
+		return errors.New("предельная заполняемость должна быть от 1 до 100 процентов")
+	}
+	return nil
+}
+
+// loadMovieShowDistancing возвращает режим дистанцирования сеанса вместе с текущей заполняемостью
+func loadMovieShowDistancing(ctx context.Context, db *pgxpool.Pool, showID any) (MovieShowDistancingStatus, error) {
+	var d MovieShowDistancingStatus
+	err := db.QueryRow(ctx, `
+		SELECT side_seats, block_adjacent_rows, max_occupancy_percent, movie_show_max_occupancy($1),
+			(SELECT COUNT(*) FROM tickets WHERE movie_show_id = $1 AND ticket_status IN ('Reserved', 'Purchased'))
+		FROM movie_show_distancing
+		WHERE movie_show_id = $1`, showID).
+		Scan(&d.SideSeats, &d.BlockAdjacentRows, &d.MaxOccupancyPercent, &d.MaxSeats, &d.BookedSeats)
+	return d, err
+}
+
+// @Summary Получить режим дистанцирования сеанса (guest | user | admin)
+// @Description Возвращает настройки дистанции между зрителями и число уже забронированных и купленных мест.
+// @Tags Киносеансы
+// @Produce json
+// @Param id path string true "ID киносеанса"
+// @Success 200 {object} MovieShowDistancingStatus "Режим дистанцирования"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Режим дистанцирования для сеанса не включён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/distancing [get]
+func GetMovieShowDistancing(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		d, err := loadMovieShowDistancing(r.Context(), db, id)
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d)
+	}
+}
+
+// @Summary Включить режим дистанцирования сеанса (admin)
+// @Description Включает или меняет режим дистанцирования. Вокруг забронированных и купленных мест блокируются
+// @Description side_seats мест ряда с каждой стороны, при block_adjacent_rows — и места с тем же номером в соседних рядах;
+// @Description места одного пользователя друг друга не блокируют, поэтому группе лучше бронировать места одним запросом.
+// @Description При max_occupancy_percent бронирование сверх этой доли мест зала отклоняется.
+// @Description Уже сделанные брони не пересматриваются. Менять режим можно до начала сеанса.
+// @Tags Киносеансы
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID киносеанса"
+// @Param distancing body MovieShowDistancing true "Режим дистанцирования"
+// @Success 200 {object} MovieShowDistancingStatus "Режим дистанцирования"
+// @Failure 400 {object} ErrorResponse "Неверные параметры режима"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Киносеанс не найден"
+// @Failure 409 {object} ErrorResponse "Сеанс уже начался, завершён или отменён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/distancing [put]
+func SetMovieShowDistancing(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var d MovieShowDistancing
+		if !DecodeJSONBody(w, r, &d) {
+			return
+		}
+		if err := validateMovieShowDistancing(d); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, id) {
+			return
+		}
+
+		var status ShowStatusEnumType
+		err := db.QueryRow(r.Context(), "SELECT status FROM movie_shows WHERE id = $1", id).Scan(&status)
+		if IsError(w, err) {
+			return
+		}
+		if status != ShowScheduled && status != ShowOnSale && status != ShowSoldOut {
+			http.Error(w, fmt.Sprintf("Режим дистанцирования нельзя изменить у сеанса в статусе %s", status), http.StatusConflict)
+			return
+		}
+
+		_, err = db.Exec(r.Context(), `
+			INSERT INTO movie_show_distancing (movie_show_id, side_seats, block_adjacent_rows, max_occupancy_percent)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (movie_show_id) DO UPDATE SET
+				side_seats = EXCLUDED.side_seats,
+				block_adjacent_rows = EXCLUDED.block_adjacent_rows,
+				max_occupancy_percent = EXCLUDED.max_occupancy_percent`,
+			id, d.SideSeats, d.BlockAdjacentRows, d.MaxOccupancyPercent)
+		if IsError(w, err) {
+			return
+		}
+
+		result, err := loadMovieShowDistancing(r.Context(), db, id)
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// @Summary Отключить режим дистанцирования сеанса (admin)
+// @Description Снимает блокировку мест вокруг броней и ограничение заполняемости.
+// @Tags Киносеансы
+// @Security BearerAuth
+// @Param id path string true "ID киносеанса"
+// @Success 204 "Режим дистанцирования отключён"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Режим дистанцирования для сеанса не включён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/distancing [delete]
+func DeleteMovieShowDistancing(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, id) {
+			return
+		}
+
+		res, err := db.Exec(r.Context(), "DELETE FROM movie_show_distancing WHERE movie_show_id = $1", id)
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
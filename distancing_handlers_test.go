@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateMovieShowDistancing(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       MovieShowDistancing
+		wantErr bool
+	}{
+		{"Side seats only", MovieShowDistancing{SideSeats: 2}, false},
+		{"Rows and occupancy", MovieShowDistancing{SideSeats: 0, BlockAdjacentRows: true, MaxOccupancyPercent: ptr(100)}, false},
+		{"Negative side seats", MovieShowDistancing{SideSeats: -1}, true},
+		{"Too many side seats", MovieShowDistancing{SideSeats: 11}, true},
+		{"Zero occupancy", MovieShowDistancing{SideSeats: 1, MaxOccupancyPercent: ptr(0)}, true},
+		{"Occupancy over 100", MovieShowDistancing{SideSeats: 1, MaxOccupancyPercent: ptr(101)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMovieShowDistancing(tt.d); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetMovieShowDistancing(t *testing.T) {
+	admin := os.Getenv("CLAIM_ROLE_ADMIN")
+
+	tests := []struct {
+		name           string
+		role           string
+		showID         string
+		body           MovieShowDistancing
+		expectedStatus int
+	}{
+		{"Valid", admin, MovieShowsData[0].ID, MovieShowDistancing{SideSeats: 2, BlockAdjacentRows: true, MaxOccupancyPercent: ptr(50)}, http.StatusOK},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), MovieShowsData[0].ID, MovieShowDistancing{SideSeats: 2}, http.StatusForbidden},
+		{"Invalid side seats", admin, MovieShowsData[0].ID, MovieShowDistancing{SideSeats: 11}, http.StatusBadRequest},
+		{"Invalid occupancy", admin, MovieShowsData[0].ID, MovieShowDistancing{SideSeats: 1, MaxOccupancyPercent: ptr(0)}, http.StatusBadRequest},
+		{"Non-existent show", admin, uuid.New().String(), MovieShowDistancing{SideSeats: 1}, http.StatusNotFound},
+		{"Invalid show ID", admin, "invalid-uuid", MovieShowDistancing{SideSeats: 1}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "PUT", ts.URL+"/movie-shows/"+tt.showID+"/distancing", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var d MovieShowDistancingStatus
+			parseResponseBody(t, resp, &d)
+			// В зале 3 места, одно из них куплено
+			if d.SideSeats != 2 || !d.BlockAdjacentRows || d.MaxSeats == nil || *d.MaxSeats != 1 || d.BookedSeats != 1 {
+				t.Errorf("Unexpected distancing %+v", d)
+			}
+		})
+	}
+}
+
+func TestMovieShowDistancingBooking(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+	seedBestSeatsShow(t)
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	user := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+	showURL := ts.URL + "/movie-shows/" + MovieShowsData[0].ID
+
+	availableSeats := func() map[string]bool {
+		t.Helper()
+		req := createRequest(t, "GET", ts.URL+"/tickets/available-movie-show/"+MovieShowsData[0].ID, "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+		seats := make(map[string]bool)
+		var tickets Page[Ticket]
+		parseResponseBody(t, resp, &tickets)
+		for _, ticket := range tickets.Items {
+			seats[ticket.SeatID] = true
+		}
+		return seats
+	}
+
+	req := createRequest(t, "GET", showURL+"/distancing", "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+
+	req = createRequest(t, "PUT", showURL+"/distancing", admin, MovieShowDistancing{SideSeats: 1, BlockAdjacentRows: true})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	// Группа получает места 7-8 третьего ряда, место 9 попадает в зону дистанции
+	req = createRequest(t, "POST", showURL+"/best-seats", user, BestSeatsRequest{Count: 2})
+	resp := executeRequest(t, req, http.StatusCreated)
+	var hold BestSeatsHold
+	parseResponseBody(t, resp, &hold)
+	resp.Body.Close()
+	if hold.RowNumber != 3 || hold.Seats[0].SeatNumber != 7 {
+		t.Fatalf("Expected seats 7-8 of row 3, got %+v", hold)
+	}
+
+	if seats := availableSeats(); len(seats) != 2 || !seats[SeatsData[1].ID] || !seats[SeatsData[3].ID] {
+		t.Errorf("Expected only seats outside distancing zone, got %v", seats)
+	}
+
+	var blockedTicketID string
+	err := TestAdminDB.QueryRow(context.Background(), `
+		SELECT virtual_ticket_id($1, id) FROM seats WHERE hall_id = $2 AND row_number = 3 AND seat_number = 9`,
+		MovieShowsData[0].ID, HallsData[0].ID).Scan(&blockedTicketID)
+	if err != nil {
+		t.Fatalf("Failed to get ticket ID: %v", err)
+	}
+	req = createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+blockedTicketID, user,
+		TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: true})
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+
+	// 50% от 6 мест уже заняты: продажа закрывается
+	req = createRequest(t, "PUT", showURL+"/distancing", admin, MovieShowDistancing{SideSeats: 1, BlockAdjacentRows: true, MaxOccupancyPercent: ptr(50)})
+	resp = executeRequest(t, req, http.StatusOK)
+	var d MovieShowDistancingStatus
+	parseResponseBody(t, resp, &d)
+	resp.Body.Close()
+	if d.MaxSeats == nil || *d.MaxSeats != 3 || d.BookedSeats != 3 {
+		t.Errorf("Expected 3 of 3 seats booked, got %+v", d)
+	}
+	if seats := availableSeats(); len(seats) != 0 {
+		t.Errorf("Expected no available seats at capacity, got %d", len(seats))
+	}
+	req = createRequest(t, "POST", showURL+"/best-seats", user, BestSeatsRequest{Count: 1})
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	req = createRequest(t, "DELETE", showURL+"/distancing", user, nil)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "DELETE", showURL+"/distancing", admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+	req = createRequest(t, "DELETE", showURL+"/distancing", admin, nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+
+	if seats := availableSeats(); len(seats) != 3 {
+		t.Errorf("Expected 3 available seats without distancing, got %v", seats)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -48,6 +50,13 @@ func isNotNullViolation(err error) bool {
 	return false
 }
 
+func isExclusionViolation(err error) bool {
+	if pgErr, ok := err.(*pgconn.PgError); ok {
+		return pgErr.Code == "23P01" // Код ошибки для нарушения ограничения-исключения
+	}
+	return false
+}
+
 func isSyntaxError(err error) bool {
 	if pgErr, ok := err.(*pgconn.PgError); ok {
 		return pgErr.Code == "42601" // Код ошибки для синтаксической ошибки
@@ -79,6 +88,104 @@ func isTransactionIsolationViolation(err error) bool {
 	return false
 }
 
+// conflictTriggerMessages — начала сообщений триггеров, которые отклоняют изменение из-за конфликта
+var conflictTriggerMessages = []string{
+	"Невозможно запланировать показ",              // check_movie_show_conflict (часы работы и обслуживание зала)
+	"Недопустимый переход статуса киносеанса",     // check_movie_show_status_transition
+	"Недопустимое место сопровождающего",          // check_companion_seat
+	"Недопустимая зона места",                     // check_seat_zone
+	"Место недоступно из-за соблюдения дистанции", // check_distancing_booking
+	"Достигнута предельная заполняемость сеанса",  // check_distancing_booking
+}
+
+// triggerConflictMessage возвращает текст ошибки триггера расписания или статуса сеанса,
+// в котором указан конфликтующий сеанс, часы работы зала или недопустимый переход.
+func triggerConflictMessage(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+
+	for _, prefix := range conflictTriggerMessages {
+		if strings.Contains(pgErr.Message, prefix) {
+			return pgErr.Message, true
+		}
+	}
+
+	return "", false
+}
+
+// unknownReferenceMessages — внешние ключи на справочники, нарушение которых при записи
+// в ссылающуюся таблицу означает неизвестное значение в запросе, а не конфликт
+var unknownReferenceMessages = map[string]struct{ table, message string }{
+	"movie_show_language_fkey":          {"movie_shows", "Неизвестный язык киносеанса"},
+	"movie_show_subtitle_language_fkey": {"movie_shows", "Неизвестный язык субтитров"},
+	"movie_show_format_fkey":            {"movie_show_formats", "Неизвестный формат показа"},
+	"seats_companion_seat_id_fkey":      {"seats", "Место сопровождающего не найдено"},
+	"movie_credits_person_id_fkey":      {"movie_credits", "Человек из списка создателей фильма не найден"},
+}
+
+// unknownReferenceMessage возвращает текст ошибки для ссылки на несуществующую запись справочника.
+// При удалении записи справочника, на которую ссылаются, PostgreSQL указывает таблицу справочника,
+// и такая ошибка остаётся конфликтом.
+func unknownReferenceMessage(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+
+	ref, ok := unknownReferenceMessages[pgErr.ConstraintName]
+	if !ok || pgErr.TableName != ref.table {
+		return "", false
+	}
+
+	return ref.message, true
+}
+
+// showOverlapKeyPattern выделяет из DETAIL ошибки no_overlapping_movie_shows значения
+// (occupied, id); последнее совпадение относится к уже существующему сеансу.
+var showOverlapKeyPattern = regexp.MustCompile(`\["?([^",]+)"?,"?[^",]+"?\), ([0-9a-fA-F-]{36})\)`)
+
+// showOverlapTimeLayouts перечисляет форматы, в которых PostgreSQL выводит границы
+// диапазона occupied в DETAIL: со смещением часового пояса сессии или без него.
+var showOverlapTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999-07",
+	"2006-01-02 15:04:05.999999-07:00",
+	"2006-01-02 15:04:05.999999",
+}
+
+// exclusionConflictMessage возвращает текст ошибки пересечения сеансов в зале
+// с указанием конфликтующего сеанса и времени его начала в часовом поясе зала timeZone.
+func exclusionConflictMessage(err error, timeZone string) (string, bool) {
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) || pgErr.ConstraintName != "no_overlapping_movie_shows" {
+		return "", false
+	}
+
+	keys := showOverlapKeyPattern.FindAllStringSubmatch(pgErr.Detail, -1)
+	if len(keys) == 0 {
+		return "Невозможно запланировать показ, поскольку в это время кинозал будет занят другим показом или будет проводиться уборка", true
+	}
+
+	existing := keys[len(keys)-1]
+	start := existing[1]
+	for _, layout := range showOverlapTimeLayouts {
+		if t, err := time.Parse(layout, start); err == nil {
+			start = InTimeZone(t, timeZone).Format("2006-01-02 15:04")
+			break
+		}
+	}
+	if len(start) > len("2006-01-02 15:04") {
+		start = start[:len("2006-01-02 15:04")]
+	}
+
+	return fmt.Sprintf("Невозможно запланировать показ, поскольку в это время кинозал будет занят показом %s (начало в %s) или будет проводиться уборка",
+		existing[2], start), true
+}
+
 func ParseUUIDFromPath(w http.ResponseWriter, pathValue string) (uuid.UUID, bool) {
 	id, err := uuid.Parse(pathValue)
 	if err != nil || id.String() == "" {
@@ -165,6 +272,14 @@ func IsError(w http.ResponseWriter, err error) bool {
 			http.Error(w, "Конфликт при работе с БД", http.StatusConflict)
 			return true
 		}
+		if isExclusionViolation(err) {
+			msg, ok := exclusionConflictMessage(err, "")
+			if !ok {
+				msg = "Конфликт при работе с БД"
+			}
+			http.Error(w, msg, http.StatusConflict)
+			return true
+		}
 		if isPermissionDenied(err) {
 			http.Error(w, "Доступ запрещён", http.StatusForbidden)
 			return true
@@ -174,6 +289,10 @@ func IsError(w http.ResponseWriter, err error) bool {
 			return true
 		}
 		if isForeignKeyViolation(err) {
+			if msg, ok := unknownReferenceMessage(err); ok {
+				http.Error(w, msg, http.StatusBadRequest)
+				return true
+			}
 			http.Error(w, "Ошибка внешнего ключа", http.StatusConflict)
 			return true
 		}
@@ -189,8 +308,8 @@ func IsError(w http.ResponseWriter, err error) bool {
 			http.Error(w, "Передан null в обязательный непустой параметр", http.StatusInternalServerError)
 			return true
 		}
-		if strings.Contains(err.Error(), "Невозможно запланировать показ") {
-			http.Error(w, err.Error(), http.StatusConflict)
+		if msg, ok := triggerConflictMessage(err); ok {
+			http.Error(w, msg, http.StatusConflict)
 			return true
 		}
 		if strings.Contains(err.Error(), "permission denied") {
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"regexp"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -59,33 +61,31 @@ func validateGenreDescription(description string) error {
 // @Description Возвращает список всех жанров, хранящихся в базе данных.
 // @Tags Жанры фильмов
 // @Produce json
-// @Success 200 {array} Genre "Список жанров"
-// @Failure 404 {object} ErrorResponse "Жанры не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Genre] "Страница жанров"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /genres [get]
 func GetGenres(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(context.Background(), "SELECT id, name, description FROM genres")
-		if HandleDatabaseError(w, err, "жанрами") {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var genres []Genre
-		for rows.Next() {
-			var g Genre
-			if err := rows.Scan(&g.ID, &g.Name, &g.Description); HandleDatabaseError(w, err, "жанром") {
-				return
-			}
-			genres = append(genres, g)
-		}
 
-		if len(genres) == 0 {
-			http.Error(w, "Жанры не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q, "id, name, description", "genres",
+			func(row pgx.Row) (Genre, error) {
+				var g Genre
+				err := row.Scan(&g.ID, &g.Name, &g.Description)
+				return g, err
+			})
+		if HandleDatabaseError(w, err, "жанрами") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(genres)
+		writePage(w, page)
 	}
 }
 
@@ -237,14 +237,24 @@ func DeleteGenre(db *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
-// @Summary Поиск жанров по имени (guest | user | admin)
-// @Description Возвращает список жанров, имена которых содержат указанную строку (регистронезависимый поиск).
+// genreSearchListSpec — результаты поиска жанров упорядочены по релевантности, как у movieSearchListSpec
+var genreSearchListSpec = listSpec{
+	ID:          "s.genre_id",
+	Sorts:       map[string]listSortField{"rank": {Expr: "s.rank::numeric", Kind: listNumeric}},
+	DefaultSort: "-rank",
+}
+
+// @Summary Поиск жанров (guest | user | admin)
+// @Description Полнотекстовый поиск по названию и описанию жанра с учётом морфологии. Порядок и отметка fuzzy
+// @Description такие же, как в поиске фильмов: совпадения по словам, затем по подстроке названия, затем похожие названия.
 // @Tags Жанры фильмов
 // @Produce json
 // @Param query query string true "Строка для поиска"
-// @Success 200 {array} Genre "Список найденных жанров"
-// @Failure 400 {object} ErrorResponse "Строка поиска пуста"
-// @Failure 404 {object} ErrorResponse "Жанры не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: -rank (по умолчанию) или rank"
+// @Success 200 {object} Page[GenreSearchResult] "Страница найденных жанров в порядке релевантности"
+// @Failure 400 {object} ErrorResponse "Строка поиска пуста или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /genres/search [get]
 func SearchGenres(db *pgxpool.Pool) http.HandlerFunc {
@@ -256,29 +266,26 @@ func SearchGenres(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(),
-			"SELECT id, name, description FROM genres WHERE name ILIKE $1",
-			"%"+query+"%")
-		if IsError(w, err) {
+		q, ok := parseListQuery(w, r, genreSearchListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var genres []Genre
-		for rows.Next() {
-			var g Genre
-			if err := rows.Scan(&g.ID, &g.Name, &g.Description); HandleDatabaseError(w, err, "жанром") {
-				return
-			}
-
-			genres = append(genres, g)
-		}
-
-		if len(genres) == 0 {
-			http.Error(w, "Жанры по запросу не найдены", http.StatusNotFound)
+		n := q.param(query, isShortSearchQuery(query))
+		from := fmt.Sprintf("search_genres($%d, $%d) s JOIN genres g ON g.id = s.genre_id", n, n+1)
+
+		page, err := fetchPage(context.Background(), db, q,
+			"g.id, g.name, g.description, s.rank, s.name_highlight, s.description_highlight, s.fuzzy", from,
+			func(row pgx.Row) (GenreSearchResult, error) {
+				var g GenreSearchResult
+				err := row.Scan(&g.ID, &g.Name, &g.Description, &g.Rank, &g.NameHighlight, &g.DescriptionHighlight, &g.Fuzzy)
+				g.NameHighlight = sanitizeHighlight(g.NameHighlight)
+				g.DescriptionHighlight = sanitizeHighlight(g.DescriptionHighlight)
+				return g, err
+			})
+		if HandleDatabaseError(w, err, "жанрами") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(genres)
+		writePage(w, page)
 	}
 }
@@ -19,9 +19,9 @@ func TestGetGenres(t *testing.T) {
 		role           string
 		expectedStatus int
 	}{
-		{"Empty as Guest", false, "", http.StatusNotFound},
-		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Guest", false, "", http.StatusOK},
+		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusOK},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -52,10 +52,14 @@ func TestGetGenres(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var genres []Genre
-				parseResponseBody(t, resp, &genres)
+				var page Page[Genre]
+				parseResponseBody(t, resp, &page)
+				genres := page.Items
 
-				if len(genres) == 0 {
+				if !tt.seedData && (genres == nil || len(genres) != 0) {
+					t.Errorf("Expected empty genres list, got %v", genres)
+				}
+				if tt.seedData && len(genres) == 0 {
 					t.Error("Expected non-empty genres list")
 				}
 			}
@@ -244,9 +248,9 @@ func TestSearchGenres(t *testing.T) {
 		{"Short query as Guest", "д", "", http.StatusOK, 3},
 		{"Short query as User", "д", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 3},
 		{"Short query as Admin", "д", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 3},
-		{"No matches as Guest", "вестерн", "", http.StatusNotFound, 0},
-		{"No matches as User", "вестерн", os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound, 0},
-		{"No matches as Admin", "вестерн", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound, 0},
+		{"No matches as Guest", "вестерн", "", http.StatusOK, 0},
+		{"No matches as User", "вестерн", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 0},
+		{"No matches as Admin", "вестерн", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 0},
 		{"Exact match as Guest", "Драма", "", http.StatusOK, 1},
 		{"Exact match as User", "Драма", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
 		{"Exact match as Admin", "Драма", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
@@ -262,9 +266,15 @@ func TestSearchGenres(t *testing.T) {
 		{"Partial with spaces as Guest", "  научная    ", "", http.StatusOK, 1},
 		{"Partial with spaces as User", "  научная    ", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
 		{"Partial with spaces as Admin", "  научная    ", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
-		{"Special chars as Guest", "фэнтези/", "", http.StatusNotFound, 0},
-		{"Special chars as User", "фэнтези/", os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound, 0},
-		{"Special chars as Admin", "фэнтези/", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound, 0},
+		{"Special chars as Guest", "фэнтези/", "", http.StatusOK, 1},
+		{"Special chars as User", "фэнтези/", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
+		{"Special chars as Admin", "фэнтези/", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
+		{"Description match as Guest", "страх", "", http.StatusOK, 1},
+		{"Description match as User", "страх", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
+		{"Description match as Admin", "страх", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
+		{"Typo as Guest", "трилер", "", http.StatusOK, 1},
+		{"Typo as User", "трилер", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
+		{"Typo as Admin", "трилер", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
 		{"DBError as Guest", "Драма", "", http.StatusInternalServerError, 0},
 		{"DBError as User", "Драма", os.Getenv("CLAIM_ROLE_USER"), http.StatusInternalServerError, 0},
 		{"DBError as Admin", "Драма", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusInternalServerError, 0},
@@ -288,11 +298,11 @@ func TestSearchGenres(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var genres []Genre
-				parseResponseBody(t, resp, &genres)
+				var page Page[GenreSearchResult]
+				parseResponseBody(t, resp, &page)
 
-				if len(genres) != tt.expectedCount {
-					t.Errorf("Expected %d genres, got %d", tt.expectedCount, len(genres))
+				if len(page.Items) != tt.expectedCount {
+					t.Errorf("Expected %d genres, got %d", tt.expectedCount, len(page.Items))
 				}
 			}
 		})
@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"os"
 	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -15,6 +19,11 @@ func validateAllHallData(w http.ResponseWriter, h HallData) bool {
 	h.Name = PrepareString(h.Name)
 	h.Description = PrepareStringPointer(h.Description)
 
+	if _, err := uuid.Parse(h.CinemaID); err != nil {
+		http.Error(w, "Неверный формат ID кинотеатра", http.StatusBadRequest)
+		return false
+	}
+
 	if err := validateHallName(h.Name); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return false
@@ -30,6 +39,21 @@ func validateAllHallData(w http.ResponseWriter, h HallData) bool {
 		return false
 	}
 
+	if err := validateCleaningMinutes(h.CleaningMinutes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if err := validateOpeningHours(h.OpensAt, h.ClosesAt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if err := validateAccessibleReleaseMinutes(h.AccessibleReleaseMinutes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
 	return true
 }
 
@@ -68,39 +92,94 @@ func validateHallDescription(description *string) error {
 	return nil
 }
 
+func validateCleaningMinutes(minutes *int) error {
+	if minutes != nil && (*minutes < 0 || *minutes > 240) {
+		return errors.New("время уборки зала должно быть от 0 до 240 минут")
+	}
+	return nil
+}
+
+func validateAccessibleReleaseMinutes(minutes *int) error {
+	if minutes != nil && (*minutes < 0 || *minutes > 24*60) {
+		return errors.New("время открытия общей продажи мест для маломобильных зрителей должно быть от 0 до 1440 минут до начала")
+	}
+	return nil
+}
+
+func validateOpeningHours(opensAt, closesAt *string) error {
+	if opensAt == nil && closesAt == nil {
+		return nil
+	}
+	if opensAt == nil || closesAt == nil {
+		return errors.New("время открытия и закрытия зала должны быть указаны вместе")
+	}
+
+	opens, err := ParseClock(*opensAt)
+	if err != nil {
+		return errors.New("неверный формат времени открытия зала, используйте HH:MM")
+	}
+	closes, err := ParseClock(*closesAt)
+	if err != nil {
+		return errors.New("неверный формат времени закрытия зала, используйте HH:MM")
+	}
+	if opens >= closes {
+		return errors.New("время открытия зала должно быть раньше времени закрытия")
+	}
+	return nil
+}
+
+var hallListSpec = listSpec{
+	ID:          "id",
+	Sorts:       map[string]listSortField{"name": {Expr: "name", Kind: listText}},
+	DefaultSort: "name",
+	Filters: []listFilter{
+		{Param: "screen_type_id", Kind: listUUID, Cond: "screen_type_id = $%[1]d::uuid"},
+	},
+}
+
+func fetchHallPage(ctx context.Context, db *pgxpool.Pool, q *listQuery) (Page[Hall], error) {
+	return fetchPage(ctx, db, q,
+		"id, cinema_id, name, screen_type_id, description, cleaning_minutes, accessible_release_minutes, opens_at, closes_at, time_zone", "halls",
+		func(row pgx.Row) (Hall, error) {
+			var h Hall
+			err := row.Scan(&h.ID, &h.CinemaID, &h.Name, &h.ScreenTypeID, &h.Description, &h.CleaningMinutes, &h.AccessibleReleaseMinutes, &h.OpensAt, &h.ClosesAt, &h.TimeZone)
+			return h, err
+		})
+}
+
 // @Summary Получить все кинозалы (guest | user | admin)
 // @Description Возвращает список всех кинозалов, содержащихся в базе данных.
 // @Tags Кинозалы
 // @Produce json
-// @Success 200 {array} Hall "Список кинозалов"
-// @Failure 404 {object} ErrorResponse "Данные не найдены"
+// @Param cinema_id query string false "ID кинотеатра"
+// @Param screen_type_id query string false "ID типа экрана"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Hall] "Страница кинозалов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID кинотеатра или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /halls [get]
 func GetHalls(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(context.Background(),
-			"SELECT id, name, screen_type_id, description FROM halls")
-		if HandleDatabaseError(w, err, "залами") {
+		cinemaID, ok := parseCinemaFilter(w, r)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var halls []Hall
-		for rows.Next() {
-			var h Hall
-			if err := rows.Scan(&h.ID, &h.Name, &h.ScreenTypeID, &h.Description); HandleDatabaseError(w, err, "залом") {
-				return
-			}
-			halls = append(halls, h)
+		q, ok := parseListQuery(w, r, hallListSpec)
+		if !ok {
+			return
+		}
+		if cinemaID != nil {
+			q.where("cinema_id = $%[1]d", *cinemaID)
 		}
 
-		if len(halls) == 0 {
-			http.Error(w, "Залы не найдены", http.StatusNotFound)
+		page, err := fetchHallPage(context.Background(), db, q)
+		if HandleDatabaseError(w, err, "залами") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(halls)
+		writePage(w, page)
 	}
 }
 
@@ -124,8 +203,9 @@ func GetHallByID(db *pgxpool.Pool) http.HandlerFunc {
 		var h Hall
 		h.ID = id.String()
 		err := db.QueryRow(context.Background(),
-			"SELECT name, screen_type_id, description FROM halls WHERE id = $1", id).
-			Scan(&h.Name, &h.ScreenTypeID, &h.Description)
+			`SELECT cinema_id, name, screen_type_id, description, cleaning_minutes, accessible_release_minutes, opens_at, closes_at, time_zone
+			FROM halls WHERE id = $1`, id).
+			Scan(&h.CinemaID, &h.Name, &h.ScreenTypeID, &h.Description, &h.CleaningMinutes, &h.AccessibleReleaseMinutes, &h.OpensAt, &h.ClosesAt, &h.TimeZone)
 
 		if IsError(w, err) {
 			return
@@ -137,7 +217,9 @@ func GetHallByID(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Создать кинозал (admin)
-// @Description Создаёт новый кинозал.
+// @Description Создаёт новый кинозал. Время уборки между сеансами (cleaning_minutes) по умолчанию берётся из типа экрана, часы работы (opens_at, closes_at) необязательны.
+// @Description Места для маломобильных зрителей поступают в общую продажу за accessible_release_minutes минут до начала сеанса (по умолчанию за 120).
+// @Description Зал принадлежит кинотеатру: название уникально в пределах кинотеатра, часы работы и даты сеансов задаются в его часовом поясе.
 // @Tags Кинозалы
 // @Accept json
 // @Produce json
@@ -160,10 +242,15 @@ func CreateHall(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaByID, h.CinemaID) {
+			return
+		}
+
 		id := uuid.New()
 		_, err := db.Exec(context.Background(),
-			"INSERT INTO halls (id, name, screen_type_id, description) VALUES ($1, $2, $3, $4)",
-			id, h.Name, h.ScreenTypeID, h.Description)
+			`INSERT INTO halls (id, cinema_id, name, screen_type_id, description, cleaning_minutes, opens_at, closes_at, accessible_release_minutes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			id, h.CinemaID, h.Name, h.ScreenTypeID, h.Description, h.CleaningMinutes, h.OpensAt, h.ClosesAt, h.AccessibleReleaseMinutes)
 
 		if IsError(w, err) {
 			return
@@ -176,7 +263,7 @@ func CreateHall(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Обновить кинозал (admin)
-// @Description Обновляет существующий кинозал.
+// @Description Обновляет существующий кинозал. При переносе зала в другой кинотеатр он получает часовой пояс нового кинотеатра.
 // @Tags Кинозалы
 // @Accept json
 // @Produce json
@@ -206,9 +293,14 @@ func UpdateHall(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfHall, id) || !requireCinemaAccess(w, r, db, cinemaByID, h.CinemaID) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(),
-			"UPDATE halls SET name=$1, screen_type_id=$2, description=$3 WHERE id=$4",
-			h.Name, h.ScreenTypeID, h.Description, id)
+			`UPDATE halls SET cinema_id=$1, name=$2, screen_type_id=$3, description=$4,
+			cleaning_minutes=$5, opens_at=$6, closes_at=$7, accessible_release_minutes=$8 WHERE id=$9`,
+			h.CinemaID, h.Name, h.ScreenTypeID, h.Description, h.CleaningMinutes, h.OpensAt, h.ClosesAt, h.AccessibleReleaseMinutes, id)
 
 		if IsError(w, err) {
 			return
@@ -242,6 +334,10 @@ func DeleteHall(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfHall, id) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(),
 			"DELETE FROM halls WHERE id = $1", id)
 
@@ -258,59 +354,58 @@ func DeleteHall(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Получить залы по типу экрана (guest | user | admin)
-// @Description Возвращает список залов с указанным типом экрана.
+// @Description Возвращает страницу залов с указанным типом экрана.
 // @Tags Кинозалы
 // @Produce json
 // @Param screen_type_id query string true "ID типа экрана"
-// @Success 200 {array} Hall "Список найденных кинозалов"
-// @Failure 400 {object} ErrorResponse "Неверный формат ID типа экрана"
-// @Failure 404 {object} ErrorResponse "Залы не найдены"
+// @Param cinema_id query string false "ID кинотеатра"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Hall] "Страница найденных кинозалов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID типа экрана, кинотеатра или параметров списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /halls/by-screen-type [get]
 func GetHallsByScreenType(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		screenTypeID := r.URL.Query().Get("screen_type_id")
-		if _, err := uuid.Parse(screenTypeID); err != nil {
+		// Сам фильтр по типу экрана добавляет hallListSpec, здесь параметр только обязателен
+		if _, err := uuid.Parse(r.URL.Query().Get("screen_type_id")); err != nil {
 			http.Error(w, "Неверный формат ID типа экрана", http.StatusBadRequest)
 			return
 		}
 
-		rows, err := db.Query(context.Background(),
-			`SELECT h.id, h.name, h.screen_type_id, h.description 
-             FROM halls h
-             WHERE h.screen_type_id = $1`, screenTypeID)
-		if IsError(w, err) {
+		cinemaID, ok := parseCinemaFilter(w, r)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var halls []Hall
-		for rows.Next() {
-			var h Hall
-			if err := rows.Scan(&h.ID, &h.Name, &h.ScreenTypeID, &h.Description); HandleDatabaseError(w, err, "залом") {
-				return
-			}
-			halls = append(halls, h)
+		q, ok := parseListQuery(w, r, hallListSpec)
+		if !ok {
+			return
+		}
+		if cinemaID != nil {
+			q.where("cinema_id = $%[1]d", *cinemaID)
 		}
 
-		if len(halls) == 0 {
-			http.Error(w, "Кинозалы с указанным типом экрана не найдены", http.StatusNotFound)
+		page, err := fetchHallPage(context.Background(), db, q)
+		if HandleDatabaseError(w, err, "залами") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(halls)
+		writePage(w, page)
 	}
 }
 
 // @Summary Поиск залов по названию (guest | user | admin)
-// @Description Возвращает список залов, названия которых содержат указанную строку.
+// @Description Возвращает страницу залов, названия которых содержат указанную строку.
 // @Tags Кинозалы
 // @Produce json
 // @Param query query string true "Строка для поиска"
-// @Success 200 {array} Hall "Список найденных кинозалов"
-// @Failure 400 {object} ErrorResponse "Строка поиска пуста"
-// @Failure 404 {object} ErrorResponse "Залы не найдены"
+// @Param cinema_id query string false "ID кинотеатра"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Hall] "Страница найденных кинозалов"
+// @Failure 400 {object} ErrorResponse "Строка поиска пуста, неверный формат ID кинотеатра или параметров списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /halls/search [get]
 func SearchHallsByName(db *pgxpool.Pool) http.HandlerFunc {
@@ -322,30 +417,108 @@ func SearchHallsByName(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(),
-			`SELECT id, name, screen_type_id, description 
-              FROM halls 
-              WHERE name ILIKE '%' || $1 || '%'`, query)
-		if IsError(w, err) {
+		cinemaID, ok := parseCinemaFilter(w, r)
+		if !ok {
+			return
+		}
+		q, ok := parseListQuery(w, r, hallListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
+		q.where("name ILIKE '%%' || $%[1]d || '%%'", query)
+		if cinemaID != nil {
+			q.where("cinema_id = $%[1]d", *cinemaID)
+		}
 
-		var halls []Hall
-		for rows.Next() {
-			var h Hall
-			if err := rows.Scan(&h.ID, &h.Name, &h.ScreenTypeID, &h.Description); HandleDatabaseError(w, err, "залом") {
+		page, err := fetchHallPage(context.Background(), db, q)
+		if HandleDatabaseError(w, err, "залами") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Свободное время в зале (admin)
+// @Description Возвращает все возможные начала сеанса фильма в зале на указанную дату
+// @Description с учётом длительности фильма, времени уборки, часов работы зала, окон обслуживания и уже запланированных сеансов.
+// @Description Дата и время слотов указываются в часовом поясе зала.
+// @Tags Кинозалы
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID зала"
+// @Param date query string true "Дата в формате YYYY-MM-DD"
+// @Param movie_id query string true "ID фильма"
+// @Param step query int false "Шаг перебора в минутах (по умолчанию 5)"
+// @Success 200 {array} FreeSlot "Возможные начала сеанса"
+// @Failure 400 {object} ErrorResponse "Неверные параметры запроса"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Зал или фильм не найден, либо свободного времени нет"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /halls/{id}/free-slots [get]
+func GetHallFreeSlots(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		day, err := time.Parse(time.DateOnly, r.URL.Query().Get("date"))
+		if err != nil {
+			http.Error(w, "Неверный формат даты, используйте YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+
+		movieID := r.URL.Query().Get("movie_id")
+		if _, err := uuid.Parse(movieID); err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return
+		}
+
+		step := 5
+		if s := r.URL.Query().Get("step"); s != "" {
+			step, err = strconv.Atoi(s)
+			if err != nil || step <= 0 || step > 60 {
+				http.Error(w, "Шаг должен быть целым числом от 1 до 60 минут", http.StatusBadRequest)
 				return
 			}
-			halls = append(halls, h)
 		}
 
-		if len(halls) == 0 {
-			http.Error(w, "Кинозалы по запросу не найдены", http.StatusNotFound)
+		ctx := r.Context()
+		settings, err := loadHallSettings(ctx, db, id.String())
+		if IsError(w, err) {
+			return
+		}
+
+		duration, err := loadMovieDuration(ctx, db, movieID)
+		if IsError(w, err) {
+			return
+		}
+
+		// Дата понимается по местному времени зала
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, settings.location())
+		shows, err := loadHallShows(ctx, db, id.String(), day.Add(-24*time.Hour), day.Add(48*time.Hour), settings.location())
+		if HandleDatabaseError(w, err, "киносеансами") {
+			return
+		}
+
+		settings.Maintenance, err = loadHallMaintenance(ctx, db, id.String(), day, day.Add(48*time.Hour), settings.location())
+		if HandleDatabaseError(w, err, "окнами обслуживания") {
+			return
+		}
+
+		slots := computeFreeSlots(day, duration, settings, shows, time.Duration(step)*time.Minute)
+		if len(slots) == 0 {
+			http.Error(w, "Свободное время для сеанса не найдено", http.StatusNotFound)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(halls)
+		json.NewEncoder(w).Encode(slots)
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -21,9 +22,9 @@ func TestGetHalls(t *testing.T) {
 		role           string
 		expectedStatus int
 	}{
-		{"Empty as Guest", false, "", http.StatusNotFound},
-		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Guest", false, "", http.StatusOK},
+		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusOK},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -44,10 +45,14 @@ func TestGetHalls(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var halls []Hall
-				parseResponseBody(t, resp, &halls)
+				var page Page[Hall]
+				parseResponseBody(t, resp, &page)
+				halls := page.Items
 
-				if len(halls) == 0 {
+				if !tt.seedData && (halls == nil || len(halls) != 0) {
+					t.Errorf("Expected empty halls list, got %v", halls)
+				}
+				if tt.seedData && len(halls) == 0 {
 					t.Error("Expected non-empty halls list")
 				}
 
@@ -182,18 +187,21 @@ func TestGetHallByID(t *testing.T) {
 
 func TestCreateHall(t *testing.T) {
 	validHall := HallData{
+		CinemaID:     CinemasData[0].ID,
 		Name:         "Test Hall",
 		ScreenTypeID: ScreenTypesData[3].ID,
 		Description:  ptr("Test Description"),
 	}
 
 	invalidHall := HallData{
+		CinemaID:     CinemasData[0].ID,
 		Name:         "",
 		ScreenTypeID: "",
 		Description:  nil,
 	}
 
 	invalidForainKeyHall := HallData{
+		CinemaID:     CinemasData[0].ID,
 		Name:         "Test Hall",
 		ScreenTypeID: uuid.New().String(),
 		Description:  ptr("Test Description"),
@@ -278,6 +286,31 @@ func TestCreateHall(t *testing.T) {
 			nil,
 			http.StatusBadRequest,
 		},
+		{
+			"Same name in another cinema Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{CinemaID: CinemasData[1].ID, Name: HallsData[0].Name, ScreenTypeID: ScreenTypesData[3].ID},
+			func(t *testing.T) {
+				SeedAll(TestAdminDB)
+			},
+			http.StatusCreated,
+		},
+		{
+			"Unknown cinema Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{CinemaID: uuid.New().String(), Name: "Test Hall", ScreenTypeID: ScreenTypesData[3].ID},
+			func(t *testing.T) {
+				SeedAll(TestAdminDB)
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"Invalid cinema ID Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{CinemaID: "invalid-uuid", Name: "Test Hall", ScreenTypeID: ScreenTypesData[3].ID},
+			nil,
+			http.StatusBadRequest,
+		},
 		{
 			"Conflict Admin",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
@@ -297,6 +330,7 @@ func TestCreateHall(t *testing.T) {
 			"Capacity 1 as Admin",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         "Min Capacity",
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  nil,
@@ -308,6 +342,7 @@ func TestCreateHall(t *testing.T) {
 			"Max name length as Admin",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         strings.Repeat("a", 100),
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  ptr("Test"),
@@ -319,6 +354,7 @@ func TestCreateHall(t *testing.T) {
 			"Name too long as Admin",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         strings.Repeat("a", 101),
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  nil,
@@ -330,6 +366,7 @@ func TestCreateHall(t *testing.T) {
 			"Max description length as Admin",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         "Test Hall",
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  ptr(strings.Repeat("a", 1000)),
@@ -337,6 +374,94 @@ func TestCreateHall(t *testing.T) {
 			func(t *testing.T) { SeedAll(TestAdminDB) },
 			http.StatusCreated,
 		},
+		{
+			"Opening hours and cleaning time as Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{
+				CinemaID:        CinemasData[0].ID,
+				Name:            "Test Hall",
+				ScreenTypeID:    ScreenTypesData[0].ID,
+				CleaningMinutes: ptr(25),
+				OpensAt:         ptr("09:00"),
+				ClosesAt:        ptr("24:00"),
+			},
+			func(t *testing.T) { SeedAll(TestAdminDB) },
+			http.StatusCreated,
+		},
+		{
+			"Negative cleaning time as Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{
+				CinemaID:        CinemasData[0].ID,
+				Name:            "Test Hall",
+				ScreenTypeID:    ScreenTypesData[0].ID,
+				CleaningMinutes: ptr(-5),
+			},
+			nil,
+			http.StatusBadRequest,
+		},
+		{
+			"Accessible seats release time as Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{
+				CinemaID:                 CinemasData[0].ID,
+				Name:                     "Test Hall",
+				ScreenTypeID:             ScreenTypesData[0].ID,
+				AccessibleReleaseMinutes: ptr(60),
+			},
+			func(t *testing.T) { SeedAll(TestAdminDB) },
+			http.StatusCreated,
+		},
+		{
+			"Too long accessible seats hold as Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{
+				CinemaID:                 CinemasData[0].ID,
+				Name:                     "Test Hall",
+				ScreenTypeID:             ScreenTypesData[0].ID,
+				AccessibleReleaseMinutes: ptr(24*60 + 1),
+			},
+			nil,
+			http.StatusBadRequest,
+		},
+		{
+			"Only opening time as Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{
+				CinemaID:     CinemasData[0].ID,
+				Name:         "Test Hall",
+				ScreenTypeID: ScreenTypesData[0].ID,
+				OpensAt:      ptr("09:00"),
+			},
+			nil,
+			http.StatusBadRequest,
+		},
+		{
+			"Opening after closing as Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{
+				CinemaID:     CinemasData[0].ID,
+				Name:         "Test Hall",
+				ScreenTypeID: ScreenTypesData[0].ID,
+				OpensAt:      ptr("23:00"),
+				ClosesAt:     ptr("10:00"),
+			},
+			nil,
+			http.StatusBadRequest,
+		},
+		{
+			"Invalid opening time format as Admin",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			HallData{
+				CinemaID:     CinemasData[0].ID,
+				Name:         "Test Hall",
+				ScreenTypeID: ScreenTypesData[0].ID,
+				OpensAt:      ptr("9 утра"),
+				ClosesAt:     ptr("22:00"),
+			},
+			nil,
+			http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -371,12 +496,14 @@ func TestCreateHall(t *testing.T) {
 
 func TestUpdateHall(t *testing.T) {
 	validUpdateData := HallData{
+		CinemaID:     CinemasData[0].ID,
 		Name:         "Updated Hall",
 		ScreenTypeID: ScreenTypesData[6].ID,
 		Description:  ptr("Updated Description"),
 	}
 
 	invalidUpdateData := HallData{
+		CinemaID:     CinemasData[0].ID,
 		Name:         "",
 		ScreenTypeID: "",
 		Description:  nil,
@@ -414,6 +541,7 @@ func TestUpdateHall(t *testing.T) {
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			"",
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         "Min Capacity",
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  nil,
@@ -450,6 +578,7 @@ func TestUpdateHall(t *testing.T) {
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			"",
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         strings.Repeat("a", 100),
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  ptr("Test"),
@@ -466,6 +595,7 @@ func TestUpdateHall(t *testing.T) {
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			"",
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         strings.Repeat("a", 101),
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  nil,
@@ -482,6 +612,7 @@ func TestUpdateHall(t *testing.T) {
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			"",
 			HallData{
+				CinemaID:     CinemasData[0].ID,
 				Name:         "Test Hall",
 				ScreenTypeID: ScreenTypesData[0].ID,
 				Description:  ptr(strings.Repeat("a", 1000)),
@@ -847,7 +978,7 @@ func TestGetHallsByScreenType(t *testing.T) {
 				_ = SeedAll(TestAdminDB)
 				return ts, uuid.New().String()
 			},
-			http.StatusNotFound,
+			http.StatusOK,
 			0,
 		},
 		{
@@ -878,13 +1009,13 @@ func TestGetHallsByScreenType(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var halls []Hall
-				if err := json.NewDecoder(resp.Body).Decode(&halls); err != nil {
+				var page Page[Hall]
+				if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 					t.Fatalf("Could not decode response: %v", err)
 				}
 
-				if len(halls) != tt.expectedCount {
-					t.Errorf("Expected %d halls, got %d", tt.expectedCount, len(halls))
+				if len(page.Items) != tt.expectedCount {
+					t.Errorf("Expected %d halls, got %d", tt.expectedCount, len(page.Items))
 				}
 			}
 		})
@@ -969,7 +1100,7 @@ func TestSearchHallsByName(t *testing.T) {
 			os.Getenv("CLAIM_ROLE_USER"),
 			"VIP Lounge",
 			setupWithData,
-			http.StatusNotFound,
+			http.StatusOK,
 			0,
 		},
 		{
@@ -1014,13 +1145,66 @@ func TestSearchHallsByName(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var halls []Hall
-				if err := json.NewDecoder(resp.Body).Decode(&halls); err != nil {
+				var page Page[Hall]
+				if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 					t.Fatalf("Could not decode response: %v", err)
 				}
 
-				if len(halls) != tt.expectedCount {
-					t.Errorf("Expected %d halls, got %d", tt.expectedCount, len(halls))
+				if len(page.Items) != tt.expectedCount {
+					t.Errorf("Expected %d halls, got %d", tt.expectedCount, len(page.Items))
+				}
+			}
+		})
+	}
+}
+
+func TestGetHallFreeSlots(t *testing.T) {
+	tomorrow := MovieShowsData[0].StartTime.UTC().Format(time.DateOnly)
+
+	tests := []struct {
+		name           string
+		role           string
+		hallID         string
+		query          string
+		expectedStatus int
+	}{
+		{"Forbidden Guest", "", HallsData[0].ID, "?date=" + tomorrow + "&movie_id=" + MoviesData[1].ID, http.StatusForbidden},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), HallsData[0].ID, "?date=" + tomorrow + "&movie_id=" + MoviesData[1].ID, http.StatusForbidden},
+		{"Invalid hall ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", "?date=" + tomorrow + "&movie_id=" + MoviesData[1].ID, http.StatusBadRequest},
+		{"Invalid date", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[0].ID, "?date=tomorrow&movie_id=" + MoviesData[1].ID, http.StatusBadRequest},
+		{"Invalid movie ID", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[0].ID, "?date=" + tomorrow + "&movie_id=abc", http.StatusBadRequest},
+		{"Invalid step", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[0].ID, "?date=" + tomorrow + "&movie_id=" + MoviesData[1].ID + "&step=0", http.StatusBadRequest},
+		{"Unknown hall", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), "?date=" + tomorrow + "&movie_id=" + MoviesData[1].ID, http.StatusNotFound},
+		{"Unknown movie", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[0].ID, "?date=" + tomorrow + "&movie_id=" + uuid.New().String(), http.StatusNotFound},
+		{"Success Admin", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[0].ID, "?date=" + tomorrow + "&movie_id=" + MoviesData[1].ID + "&step=10", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "GET", ts.URL+"/halls/"+tt.hallID+"/free-slots"+tt.query, generateToken(t, tt.role), nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var slots []FreeSlot
+			parseResponseBody(t, resp, &slots)
+			if len(slots) == 0 {
+				t.Fatal("Expected non-empty list of free slots")
+			}
+
+			existing := MovieShowsData[0]
+			movieDuration, _ := time.Parse("15:04:05", MoviesData[0].Duration)
+			busyUntil := existing.StartTime.Add(movieDuration.Sub(time.Time{}) + 10*time.Minute)
+			for _, s := range slots {
+				if s.StartTime.Before(busyUntil) && existing.StartTime.Before(s.EndTime.Add(10*time.Minute)) {
+					t.Errorf("Slot %v overlaps with existing show at %v", s.StartTime, existing.StartTime)
 				}
 			}
 		})
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type seatPosition struct {
+	row, seat int
+}
+
+func validateHallLayout(l HallLayout) error {
+	if len(l.Rows) == 0 {
+		return errors.New("схема зала должна содержать хотя бы один ряд")
+	}
+
+	usedRows := make(map[int]bool)
+	for _, g := range l.Rows {
+		if err := validateRowNumber(g.FromRow); err != nil {
+			return err
+		}
+		if err := validateRowNumber(g.ToRow); err != nil {
+			return err
+		}
+		if g.FromRow > g.ToRow {
+			return fmt.Errorf("ряды %d-%d: первый ряд группы больше последнего", g.FromRow, g.ToRow)
+		}
+		if err := validateSeatNumber(g.Seats); err != nil {
+			return fmt.Errorf("ряды %d-%d: %w", g.FromRow, g.ToRow, err)
+		}
+		if err := validateSeatTypeID(g.SeatTypeID); err != nil {
+			return fmt.Errorf("ряды %d-%d: %w", g.FromRow, g.ToRow, err)
+		}
+
+		for row := g.FromRow; row <= g.ToRow; row++ {
+			if usedRows[row] {
+				return fmt.Errorf("ряд %d описан в схеме несколько раз", row)
+			}
+			usedRows[row] = true
+		}
+
+		gaps := make(map[int]bool)
+		for _, n := range g.Gaps {
+			if n <= 0 || n > g.Seats {
+				return fmt.Errorf("ряды %d-%d: проход на месте %d за пределами ряда", g.FromRow, g.ToRow, n)
+			}
+			if gaps[n] {
+				return fmt.Errorf("ряды %d-%d: проход на месте %d указан несколько раз", g.FromRow, g.ToRow, n)
+			}
+			gaps[n] = true
+		}
+		if len(gaps) == g.Seats {
+			return fmt.Errorf("ряды %d-%d: в ряду нет ни одного места", g.FromRow, g.ToRow)
+		}
+
+		typed := make(map[int]bool)
+		for _, rng := range g.Ranges {
+			if rng.FromSeat <= 0 || rng.FromSeat > rng.ToSeat || rng.ToSeat > g.Seats {
+				return fmt.Errorf("ряды %d-%d: неверный диапазон мест %d-%d", g.FromRow, g.ToRow, rng.FromSeat, rng.ToSeat)
+			}
+			if err := validateSeatTypeID(rng.SeatTypeID); err != nil {
+				return fmt.Errorf("ряды %d-%d: %w", g.FromRow, g.ToRow, err)
+			}
+			for n := rng.FromSeat; n <= rng.ToSeat; n++ {
+				if typed[n] {
+					return fmt.Errorf("ряды %d-%d: диапазоны мест пересекаются на месте %d", g.FromRow, g.ToRow, n)
+				}
+				typed[n] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// Разворачивает схему в список мест, упорядоченный по ряду и номеру места.
+// Схема должна быть проверена validateHallLayout.
+func expandHallLayout(l HallLayout) []Seat {
+	var seats []Seat
+	for _, g := range l.Rows {
+		for row := g.FromRow; row <= g.ToRow; row++ {
+			for n := 1; n <= g.Seats; n++ {
+				if slices.Contains(g.Gaps, n) {
+					continue
+				}
+
+				seatTypeID := g.SeatTypeID
+				for _, rng := range g.Ranges {
+					if n >= rng.FromSeat && n <= rng.ToSeat {
+						seatTypeID = rng.SeatTypeID
+						break
+					}
+				}
+				seats = append(seats, Seat{SeatTypeID: seatTypeID, RowNumber: row, SeatNumber: n})
+			}
+		}
+	}
+
+	slices.SortFunc(seats, func(a, b Seat) int {
+		if a.RowNumber != b.RowNumber {
+			return a.RowNumber - b.RowNumber
+		}
+		return a.SeatNumber - b.SeatNumber
+	})
+	return seats
+}
+
+// Строит компактную схему по местам зала, упорядоченным по ряду и номеру места.
+// Типом ряда по умолчанию становится самый частый тип, остальные описываются диапазонами.
+func compressHallLayout(seats []Seat) HallLayout {
+	layout := HallLayout{Rows: []HallLayoutRows{}}
+
+	for start := 0; start < len(seats); {
+		end := start
+		for end < len(seats) && seats[end].RowNumber == seats[start].RowNumber {
+			end++
+		}
+		row := compressLayoutRow(seats[start:end])
+		start = end
+
+		if n := len(layout.Rows); n > 0 {
+			last := &layout.Rows[n-1]
+			if last.ToRow+1 == row.FromRow && sameLayoutRow(*last, row) {
+				last.ToRow = row.ToRow
+				continue
+			}
+		}
+		layout.Rows = append(layout.Rows, row)
+	}
+
+	return layout
+}
+
+func compressLayoutRow(seats []Seat) HallLayoutRows {
+	row := HallLayoutRows{
+		FromRow: seats[0].RowNumber,
+		ToRow:   seats[0].RowNumber,
+		Seats:   seats[len(seats)-1].SeatNumber,
+	}
+
+	counts := make(map[string]int)
+	for _, s := range seats {
+		counts[s.SeatTypeID]++
+		if counts[s.SeatTypeID] > counts[row.SeatTypeID] {
+			row.SeatTypeID = s.SeatTypeID
+		}
+	}
+
+	next, prevType := 1, ""
+	for _, s := range seats {
+		for ; next < s.SeatNumber; next++ {
+			row.Gaps = append(row.Gaps, next)
+		}
+		next = s.SeatNumber + 1
+
+		// Проходы не разрывают диапазон: мест на них всё равно нет
+		switch {
+		case s.SeatTypeID == row.SeatTypeID:
+		case s.SeatTypeID == prevType:
+			row.Ranges[len(row.Ranges)-1].ToSeat = s.SeatNumber
+		default:
+			row.Ranges = append(row.Ranges, HallLayoutRange{FromSeat: s.SeatNumber, ToSeat: s.SeatNumber, SeatTypeID: s.SeatTypeID})
+		}
+		prevType = s.SeatTypeID
+	}
+
+	return row
+}
+
+func sameLayoutRow(a, b HallLayoutRows) bool {
+	return a.Seats == b.Seats && a.SeatTypeID == b.SeatTypeID &&
+		slices.Equal(a.Gaps, b.Gaps) && slices.Equal(a.Ranges, b.Ranges)
+}
+
+// Сравнивает текущие места зала с новой схемой. Места на тех же позициях сохраняют ID,
+// чтобы проданные на них билеты остались действительными.
+func diffHallLayout(existing, target []Seat) (added, changed, removed []Seat) {
+	current := make(map[seatPosition]Seat, len(existing))
+	for _, s := range existing {
+		current[seatPosition{s.RowNumber, s.SeatNumber}] = s
+	}
+
+	for _, s := range target {
+		pos := seatPosition{s.RowNumber, s.SeatNumber}
+		old, ok := current[pos]
+		if !ok {
+			added = append(added, s)
+			continue
+		}
+		delete(current, pos)
+
+		if old.SeatTypeID != s.SeatTypeID {
+			old.SeatTypeID = s.SeatTypeID
+			changed = append(changed, old)
+		}
+	}
+
+	for _, s := range existing {
+		if _, ok := current[seatPosition{s.RowNumber, s.SeatNumber}]; ok {
+			removed = append(removed, s)
+		}
+	}
+
+	return added, changed, removed
+}
+
+// @Summary Получить схему зала (guest | user | admin)
+// @Description Возвращает места зала в компактном виде: соседние одинаковые ряды объединены в группы,
+// @Description проходы перечислены в gaps, места нестандартного типа — в ranges.
+// @Description Ответ можно без изменений передать в POST /halls/{id}/layout.
+// @Tags Места
+// @Produce json
+// @Param id path string true "ID зала"
+// @Success 200 {object} HallLayout "Схема зала"
+// @Failure 400 {string} string "Неверный формат ID зала"
+// @Failure 404 {string} string "Места не найдены"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /halls/{id}/layout [get]
+func GetHallLayout(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		rows, err := db.Query(context.Background(), `
+			SELECT COALESCE(seat_type_id::text, ''), row_number, seat_number
+			FROM seats
+			WHERE hall_id = $1
+			ORDER BY row_number, seat_number`, hallID)
+		if IsError(w, err) {
+			return
+		}
+		defer rows.Close()
+
+		var seats []Seat
+		for rows.Next() {
+			var s Seat
+			if err := rows.Scan(&s.SeatTypeID, &s.RowNumber, &s.SeatNumber); IsError(w, err) {
+				return
+			}
+			seats = append(seats, s)
+		}
+		if IsError(w, rows.Err()) {
+			return
+		}
+
+		if len(seats) == 0 {
+			http.Error(w, "Места не найдены", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(compressHallLayout(seats))
+	}
+}
+
+// @Summary Заменить схему зала (admin)
+// @Description Заменяет все места зала местами из схемы в одной транзакции.
+// @Description Места на прежних позициях сохраняют ID и получают тип из схемы, лишние места удаляются, недостающие создаются.
+// @Description Если на удаляемые места есть проданные, забронированные или возвращённые билеты,
+// @Description схема не меняется и возвращается 409 со списком таких мест.
+// @Tags Места
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID зала"
+// @Param layout body HallLayout true "Схема зала"
+// @Success 200 {object} HallLayoutResult "Итоги замены схемы"
+// @Failure 400 {string} string "Неверная схема зала или неизвестный тип места"
+// @Failure 403 {string} string "Доступ запрещён"
+// @Failure 404 {string} string "Кинозал не найден"
+// @Failure 409 {object} HallLayoutConflict "На удаляемые места есть билеты"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /halls/{id}/layout [post]
+func ReplaceHallLayout(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var layout HallLayout
+		if !DecodeJSONBody(w, r, &layout) {
+			return
+		}
+		if err := validateHallLayout(layout); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfHall, hallID) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		// Блокировка зала не даёт двум заменам схемы выполняться одновременно
+		var lockedID string
+		err = tx.QueryRow(ctx, "SELECT id FROM halls WHERE id = $1 FOR UPDATE", hallID).Scan(&lockedID)
+		if IsError(w, err) {
+			return
+		}
+
+		existing, err := loadHallSeats(ctx, tx, hallID.String())
+		if IsError(w, err) {
+			return
+		}
+		target := expandHallLayout(layout)
+		added, changed, removed := diffHallLayout(existing, target)
+
+		if len(removed) > 0 {
+			removedIDs := make([]string, len(removed))
+			for i, s := range removed {
+				removedIDs[i] = s.ID
+			}
+
+			// Сохранённые свободные билеты вычисляются заново и не мешают удалению мест
+			_, err = tx.Exec(ctx, "DELETE FROM tickets WHERE seat_id = ANY($1::uuid[]) AND ticket_status = $2", removedIDs, Available)
+			if IsError(w, err) {
+				return
+			}
+
+			blocked, err := loadSeatsWithTickets(ctx, tx, removedIDs)
+			if IsError(w, err) {
+				return
+			}
+			if len(blocked) > 0 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(HallLayoutConflict{
+					Message:      "На места, которые удаляет новая схема, есть билеты",
+					BlockedSeats: blocked,
+				})
+				return
+			}
+
+			_, err = tx.Exec(ctx, "DELETE FROM seats WHERE id = ANY($1::uuid[])", removedIDs)
+			if IsError(w, err) {
+				return
+			}
+		}
+
+		if len(changed) > 0 {
+			ids := make([]string, len(changed))
+			seatTypeIDs := make([]string, len(changed))
+			for i, s := range changed {
+				ids[i], seatTypeIDs[i] = s.ID, s.SeatTypeID
+			}
+
+			_, err = tx.Exec(ctx, `
+				UPDATE seats s
+				SET seat_type_id = c.seat_type_id
+				FROM unnest($1::uuid[], $2::uuid[]) AS c(id, seat_type_id)
+				WHERE s.id = c.id`, ids, seatTypeIDs)
+			if IsError(w, err) {
+				return
+			}
+		}
+
+		if len(added) > 0 {
+			seatTypeIDs := make([]string, len(added))
+			rowNumbers := make([]int32, len(added))
+			seatNumbers := make([]int32, len(added))
+			for i, s := range added {
+				seatTypeIDs[i], rowNumbers[i], seatNumbers[i] = s.SeatTypeID, int32(s.RowNumber), int32(s.SeatNumber)
+			}
+
+			_, err = tx.Exec(ctx, `
+				INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+				SELECT $1, a.seat_type_id, a.row_number, a.seat_number
+				FROM unnest($2::uuid[], $3::int[], $4::int[]) AS a(seat_type_id, row_number, seat_number)`,
+				hallID, seatTypeIDs, rowNumbers, seatNumbers)
+			if IsError(w, err) {
+				return
+			}
+		}
+
+		if IsError(w, tx.Commit(ctx)) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(HallLayoutResult{
+			Seats:   len(target),
+			Added:   len(added),
+			Changed: len(changed),
+			Removed: len(removed),
+		})
+	}
+}
+
+func loadSeatsWithTickets(ctx context.Context, tx pgx.Tx, seatIDs []string) ([]Seat, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT s.id, s.hall_id, COALESCE(s.seat_type_id::text, ''), s.row_number, s.seat_number
+		FROM seats s
+		WHERE s.id = ANY($1::uuid[])
+		AND EXISTS (SELECT 1 FROM tickets t WHERE t.seat_id = s.id)
+		ORDER BY s.row_number, s.seat_number`, seatIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []Seat
+	for rows.Next() {
+		var s Seat
+		if err := rows.Scan(&s.ID, &s.HallID, &s.SeatTypeID, &s.RowNumber, &s.SeatNumber); err != nil {
+			return nil, err
+		}
+		seats = append(seats, s)
+	}
+
+	return seats, rows.Err()
+}
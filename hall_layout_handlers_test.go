@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const (
+	layoutStandard = "11111111-1111-4111-8111-111111111111"
+	layoutVIP      = "22222222-2222-4222-8222-222222222222"
+	layoutSofa     = "33333333-3333-4333-8333-333333333333"
+)
+
+func TestValidateHallLayout(t *testing.T) {
+	valid := HallLayoutRows{FromRow: 1, ToRow: 5, Seats: 10, SeatTypeID: layoutStandard}
+
+	tests := []struct {
+		name    string
+		layout  HallLayout
+		wantErr bool
+	}{
+		{"Valid", HallLayout{Rows: []HallLayoutRows{valid}}, false},
+		{"Valid with gaps and ranges", HallLayout{Rows: []HallLayoutRows{{
+			FromRow: 1, ToRow: 1, Seats: 12, Gaps: []int{6, 7}, SeatTypeID: layoutStandard,
+			Ranges: []HallLayoutRange{{FromSeat: 1, ToSeat: 2, SeatTypeID: layoutVIP}, {FromSeat: 11, ToSeat: 12, SeatTypeID: layoutVIP}},
+		}}}, false},
+		{"No rows", HallLayout{}, true},
+		{"Reversed rows", HallLayout{Rows: []HallLayoutRows{{FromRow: 5, ToRow: 1, Seats: 10, SeatTypeID: layoutStandard}}}, true},
+		{"Row out of range", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 101, Seats: 10, SeatTypeID: layoutStandard}}}, true},
+		{"Zero seats", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 0, SeatTypeID: layoutStandard}}}, true},
+		{"Invalid seat type", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 10, SeatTypeID: "vip"}}}, true},
+		{"Overlapping groups", HallLayout{Rows: []HallLayoutRows{valid, {FromRow: 5, ToRow: 6, Seats: 10, SeatTypeID: layoutStandard}}}, true},
+		{"Gap outside row", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 10, Gaps: []int{11}, SeatTypeID: layoutStandard}}}, true},
+		{"Duplicate gap", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 10, Gaps: []int{3, 3}, SeatTypeID: layoutStandard}}}, true},
+		{"Only gaps", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 2, Gaps: []int{1, 2}, SeatTypeID: layoutStandard}}}, true},
+		{"Range outside row", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 10, SeatTypeID: layoutStandard,
+			Ranges: []HallLayoutRange{{FromSeat: 9, ToSeat: 11, SeatTypeID: layoutVIP}}}}}, true},
+		{"Overlapping ranges", HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 10, SeatTypeID: layoutStandard,
+			Ranges: []HallLayoutRange{{FromSeat: 1, ToSeat: 3, SeatTypeID: layoutVIP}, {FromSeat: 3, ToSeat: 4, SeatTypeID: layoutSofa}}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHallLayout(tt.layout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateHallLayout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHallLayoutRoundTrip(t *testing.T) {
+	layout := HallLayout{Rows: []HallLayoutRows{
+		{FromRow: 1, ToRow: 4, Seats: 12, Gaps: []int{6, 7}, SeatTypeID: layoutStandard},
+		{FromRow: 5, ToRow: 5, Seats: 12, Gaps: []int{6, 7}, SeatTypeID: layoutStandard,
+			Ranges: []HallLayoutRange{{FromSeat: 4, ToSeat: 9, SeatTypeID: layoutVIP}}},
+		{FromRow: 7, ToRow: 8, Seats: 6, SeatTypeID: layoutSofa},
+	}}
+	if err := validateHallLayout(layout); err != nil {
+		t.Fatalf("Layout must be valid: %v", err)
+	}
+
+	seats := expandHallLayout(layout)
+	if len(seats) != 5*10+2*6 {
+		t.Fatalf("Expected %d seats, got %d", 5*10+2*6, len(seats))
+	}
+	for _, s := range seats {
+		if s.SeatNumber == 6 || s.SeatNumber == 7 {
+			if s.RowNumber <= 5 {
+				t.Errorf("Seat %d in row %d must be an aisle", s.SeatNumber, s.RowNumber)
+			}
+		}
+	}
+
+	// Диапазон 4-9 проходит через проход и не разрывается им
+	if got := compressHallLayout(seats); !reflect.DeepEqual(got, layout) {
+		t.Errorf("Expected %+v, got %+v", layout, got)
+	}
+}
+
+func TestCompressHallLayoutPicksMostCommonType(t *testing.T) {
+	seats := []Seat{
+		{SeatTypeID: layoutVIP, RowNumber: 1, SeatNumber: 1},
+		{SeatTypeID: layoutStandard, RowNumber: 1, SeatNumber: 2},
+		{SeatTypeID: layoutStandard, RowNumber: 1, SeatNumber: 3},
+		{SeatTypeID: layoutVIP, RowNumber: 1, SeatNumber: 4},
+	}
+
+	expected := HallLayout{Rows: []HallLayoutRows{{
+		FromRow: 1, ToRow: 1, Seats: 4, SeatTypeID: layoutStandard,
+		Ranges: []HallLayoutRange{{FromSeat: 1, ToSeat: 1, SeatTypeID: layoutVIP}, {FromSeat: 4, ToSeat: 4, SeatTypeID: layoutVIP}},
+	}}}
+	if got := compressHallLayout(seats); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+	if got := expandHallLayout(expected); !reflect.DeepEqual(got, seats) {
+		t.Errorf("Expected %+v, got %+v", seats, got)
+	}
+}
+
+func TestDiffHallLayout(t *testing.T) {
+	existing := []Seat{
+		{ID: "kept", SeatTypeID: layoutStandard, RowNumber: 1, SeatNumber: 1},
+		{ID: "changed", SeatTypeID: layoutStandard, RowNumber: 1, SeatNumber: 2},
+		{ID: "removed", SeatTypeID: layoutStandard, RowNumber: 2, SeatNumber: 1},
+	}
+	target := []Seat{
+		{SeatTypeID: layoutStandard, RowNumber: 1, SeatNumber: 1},
+		{SeatTypeID: layoutVIP, RowNumber: 1, SeatNumber: 2},
+		{SeatTypeID: layoutVIP, RowNumber: 1, SeatNumber: 3},
+	}
+
+	added, changed, removed := diffHallLayout(existing, target)
+	if len(added) != 1 || added[0].SeatNumber != 3 {
+		t.Errorf("Expected seat 1-3 to be added, got %+v", added)
+	}
+	if len(changed) != 1 || changed[0].ID != "changed" || changed[0].SeatTypeID != layoutVIP {
+		t.Errorf("Expected seat 1-2 to change type, got %+v", changed)
+	}
+	if len(removed) != 1 || removed[0].ID != "removed" {
+		t.Errorf("Expected seat 2-1 to be removed, got %+v", removed)
+	}
+}
+
+func TestReplaceHallLayout(t *testing.T) {
+	grid := HallLayout{Rows: []HallLayoutRows{
+		{FromRow: 1, ToRow: 3, Seats: 10, SeatTypeID: SeatTypesData[0].ID},
+	}}
+
+	tests := []struct {
+		name           string
+		role           string
+		hallID         string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Guest", "", HallsData[4].ID, grid, http.StatusForbidden},
+		{"User", os.Getenv("CLAIM_ROLE_USER"), HallsData[4].ID, grid, http.StatusForbidden},
+		{"Empty hall", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[4].ID, grid, http.StatusOK},
+		{"Keeps seats with tickets", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[0].ID, grid, http.StatusOK},
+		{"Removes seats with tickets", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[0].ID,
+			HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 5, SeatTypeID: SeatTypesData[0].ID}}}, http.StatusConflict},
+		{"Unknown seat type", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[4].ID,
+			HallLayout{Rows: []HallLayoutRows{{FromRow: 1, ToRow: 1, Seats: 5, SeatTypeID: uuid.New().String()}}}, http.StatusBadRequest},
+		{"Invalid layout", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[4].ID, HallLayout{}, http.StatusBadRequest},
+		{"Invalid JSON", os.Getenv("CLAIM_ROLE_ADMIN"), HallsData[4].ID, "{invalid json}", http.StatusBadRequest},
+		{"Non-existent hall", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), grid, http.StatusNotFound},
+		{"Invalid hall ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", grid, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/halls/"+tt.hallID+"/layout", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			switch tt.expectedStatus {
+			case http.StatusOK:
+				var result HallLayoutResult
+				parseResponseBody(t, resp, &result)
+				if result.Seats != 30 {
+					t.Errorf("Expected 30 seats, got %d", result.Seats)
+				}
+
+				req = createRequest(t, "GET", ts.URL+"/halls/"+tt.hallID+"/layout", "", nil)
+				layoutResp := executeRequest(t, req, http.StatusOK)
+				defer layoutResp.Body.Close()
+
+				var layout HallLayout
+				parseResponseBody(t, layoutResp, &layout)
+				if !reflect.DeepEqual(layout, grid) {
+					t.Errorf("Expected %+v, got %+v", grid, layout)
+				}
+			case http.StatusConflict:
+				var conflict HallLayoutConflict
+				parseResponseBody(t, resp, &conflict)
+				if len(conflict.BlockedSeats) != 1 || conflict.BlockedSeats[0].ID != SeatsData[1].ID {
+					t.Errorf("Expected seat %s to block the layout, got %+v", SeatsData[1].ID, conflict.BlockedSeats)
+				}
+
+				var count int
+				if err := TestAdminDB.QueryRow(context.Background(),
+					"SELECT COUNT(*) FROM seats WHERE hall_id = $1", tt.hallID).Scan(&count); err != nil {
+					t.Fatalf("Failed to count seats: %v", err)
+				}
+				if count != 3 {
+					t.Errorf("Expected seats to stay untouched, got %d seats", count)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceHallLayoutKeepsSeatIDs(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	layout := HallLayout{Rows: []HallLayoutRows{
+		{FromRow: 1, ToRow: 3, Seats: 10, SeatTypeID: SeatTypesData[1].ID},
+	}}
+	req := createRequest(t, "POST", ts.URL+"/halls/"+HallsData[0].ID+"/layout", generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), layout)
+	resp := executeRequest(t, req, http.StatusOK)
+	var result HallLayoutResult
+	parseResponseBody(t, resp, &result)
+	resp.Body.Close()
+
+	expected := HallLayoutResult{Seats: 30, Added: 27, Changed: 2, Removed: 0}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	// Проданный билет по-прежнему ссылается на место из новой схемы
+	req = createRequest(t, "GET", ts.URL+"/seats/"+SeatsData[0].ID, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var seat Seat
+	parseResponseBody(t, resp, &seat)
+	resp.Body.Close()
+	if seat.SeatTypeID != SeatTypesData[1].ID {
+		t.Errorf("Expected seat type %s, got %s", SeatTypesData[1].ID, seat.SeatTypeID)
+	}
+}
+
+func TestGetHallLayout(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	req := createRequest(t, "GET", ts.URL+"/halls/"+HallsData[4].ID+"/layout", "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/halls/invalid-uuid/layout", "", nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[0].ID+"/layout", "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	defer resp.Body.Close()
+
+	var layout HallLayout
+	parseResponseBody(t, resp, &layout)
+	if len(layout.Rows) != 3 {
+		t.Fatalf("Expected 3 row groups, got %+v", layout)
+	}
+	if layout.Rows[2].Seats != 10 || len(layout.Rows[2].Gaps) != 9 {
+		t.Errorf("Expected row 3 to have seat 10 only, got %+v", layout.Rows[2])
+	}
+}
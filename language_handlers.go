@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func validateLanguageName(name string) error {
+	if PrepareString(name) == "" {
+		return errors.New("название языка не может быть пустым или состоять только из пробелов")
+	}
+	if utf8.RuneCountInString(name) > 50 {
+		return errors.New("название языка не может превышать 50 символов")
+	}
+	return nil
+}
+
+// @Summary Получить все языки (guest | user | admin)
+// @Description Возвращает справочник языков звуковой дорожки и субтитров.
+// @Tags Языки
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Language] "Страница языков"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /languages [get]
+func GetLanguages(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
+			return
+		}
+
+		page, err := fetchPage(context.Background(), db, q, "id, name", "languages",
+			func(row pgx.Row) (Language, error) {
+				var l Language
+				err := row.Scan(&l.ID, &l.Name)
+				return l, err
+			})
+		if HandleDatabaseError(w, err, "языками") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Получить язык по ID (guest | user | admin)
+// @Description Возвращает язык по ID.
+// @Tags Языки
+// @Produce json
+// @Param id path string true "ID языка"
+// @Success 200 {object} Language "Язык"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Язык не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /languages/{id} [get]
+func GetLanguageByID(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var l Language
+		l.ID = id.String()
+		err := db.QueryRow(context.Background(),
+			"SELECT name FROM languages WHERE id = $1", id).
+			Scan(&l.Name)
+
+		if IsError(w, err) {
+			return
+		}
+
+		json.NewEncoder(w).Encode(l)
+	}
+}
+
+// @Summary Создать язык (admin)
+// @Description Добавляет язык в справочник; после этого его можно указывать у сеансов.
+// @Tags Языки
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param language body LanguageData true "Данные языка"
+// @Success 201 {object} CreateResponse "ID созданного языка"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 409 {object} ErrorResponse "Язык уже существует"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /languages [post]
+func CreateLanguage(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var l LanguageData
+		if !DecodeJSONBody(w, r, &l) {
+			return
+		}
+
+		l.Name = PrepareString(l.Name)
+		if err := validateLanguageName(l.Name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id := uuid.New()
+		_, err := db.Exec(context.Background(),
+			"INSERT INTO languages (id, name) VALUES ($1, $2)",
+			id, l.Name)
+
+		if IsError(w, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(id.String())
+	}
+}
+
+// @Summary Обновить язык (admin)
+// @Description Переименовывает язык; сеансы с этим языком получают новое название.
+// @Tags Языки
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID языка"
+// @Param language body LanguageData true "Новые данные языка"
+// @Success 200 "Данные о языке успешно обновлены"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Язык не найден"
+// @Failure 409 {object} ErrorResponse "Язык с таким названием уже существует"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /languages/{id} [put]
+func UpdateLanguage(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var l LanguageData
+		if !DecodeJSONBody(w, r, &l) {
+			return
+		}
+
+		l.Name = PrepareString(l.Name)
+		if err := validateLanguageName(l.Name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			"UPDATE languages SET name=$1 WHERE id=$2",
+			l.Name, id)
+
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// @Summary Удалить язык (admin)
+// @Description Удаляет язык по ID. Язык, который указан у сеансов, удалить нельзя.
+// @Tags Языки
+// @Param id path string true "ID языка"
+// @Security BearerAuth
+// @Success 204 "Язык успешно удалён"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Язык не найден"
+// @Failure 409 {object} ErrorResponse "Язык используется в сеансах"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /languages/{id} [delete]
+func DeleteLanguage(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			"DELETE FROM languages WHERE id = $1", id)
+
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetLanguages(t *testing.T) {
+	ts := setupTestServer()
+	defer ts.Close()
+
+	for _, role := range []string{"", os.Getenv("CLAIM_ROLE_USER"), os.Getenv("CLAIM_ROLE_ADMIN")} {
+		req := createRequest(t, "GET", ts.URL+"/languages", generateToken(t, role), nil)
+		resp := executeRequest(t, req, http.StatusOK)
+
+		var page Page[Language]
+		parseResponseBody(t, resp, &page)
+		resp.Body.Close()
+
+		if len(page.Items) != len(LanguagesData) {
+			t.Errorf("Expected %d languages, got %d", len(LanguagesData), len(page.Items))
+		}
+	}
+
+	req := createRequest(t, "GET", ts.URL+"/languages/"+LanguagesData[0].ID, "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var l Language
+	parseResponseBody(t, resp, &l)
+	resp.Body.Close()
+	if l.Name != LanguagesData[0].Name {
+		t.Errorf("Expected %q, got %q", LanguagesData[0].Name, l.Name)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/languages/"+uuid.New().String(), "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+}
+
+func TestCreateLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Valid as Guest", "", LanguageData{Name: "日本語"}, http.StatusForbidden},
+		{"Valid as User", os.Getenv("CLAIM_ROLE_USER"), LanguageData{Name: "日本語"}, http.StatusForbidden},
+		{"Valid as Admin", os.Getenv("CLAIM_ROLE_ADMIN"), LanguageData{Name: "日本語"}, http.StatusCreated},
+		{"Invalid JSON", os.Getenv("CLAIM_ROLE_ADMIN"), "{invalid json}", http.StatusBadRequest},
+		{"Whitespace name", os.Getenv("CLAIM_ROLE_ADMIN"), LanguageData{Name: "   "}, http.StatusBadRequest},
+		{"50 chars name", os.Getenv("CLAIM_ROLE_ADMIN"), LanguageData{Name: strings.Repeat("я", 50)}, http.StatusCreated},
+		{"51 chars name", os.Getenv("CLAIM_ROLE_ADMIN"), LanguageData{Name: strings.Repeat("я", 51)}, http.StatusBadRequest},
+		{"Duplicate name", os.Getenv("CLAIM_ROLE_ADMIN"), LanguageData{Name: "English"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedUsers(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/languages", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+}
+
+func TestUpdateLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		body           LanguageData
+		expectedStatus int
+	}{
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), LanguagesData[1].ID, LanguageData{Name: "Español"}, http.StatusForbidden},
+		{"Rename", os.Getenv("CLAIM_ROLE_ADMIN"), LanguagesData[1].ID, LanguageData{Name: "Español"}, http.StatusOK},
+		{"Rename used language", os.Getenv("CLAIM_ROLE_ADMIN"), LanguagesData[0].ID, LanguageData{Name: "Английский"}, http.StatusOK},
+		{"Duplicate name", os.Getenv("CLAIM_ROLE_ADMIN"), LanguagesData[1].ID, LanguageData{Name: "English"}, http.StatusConflict},
+		{"Non-existent", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), LanguageData{Name: "Español"}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "PUT", ts.URL+"/languages/"+tt.id, generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.name != "Rename used language" {
+				return
+			}
+
+			// Сеансы ссылаются на название языка, поэтому получают новое
+			var language string
+			err := TestAdminDB.QueryRow(context.Background(),
+				"SELECT language FROM movie_shows WHERE id = $1", MovieShowsData[1].ID).Scan(&language)
+			if err != nil {
+				t.Fatalf("Failed to get movie show language: %v", err)
+			}
+			if language != tt.body.Name {
+				t.Errorf("Expected show language %q, got %q", tt.body.Name, language)
+			}
+		})
+	}
+}
+
+func TestDeleteLanguage(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		expectedStatus int
+	}{
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), LanguagesData[1].ID, http.StatusForbidden},
+		{"Unused language", os.Getenv("CLAIM_ROLE_ADMIN"), LanguagesData[1].ID, http.StatusNoContent},
+		{"Used by show", os.Getenv("CLAIM_ROLE_ADMIN"), LanguagesData[0].ID, http.StatusConflict},
+		{"Non-existent", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), http.StatusNotFound},
+		{"Invalid ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "DELETE", ts.URL+"/languages/"+tt.id, generateToken(t, tt.role), nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Списки выдаются страницами. Страница выбирается по ключу (значение поля сортировки, id):
+// курсор хранит ключ последней строки, поэтому вставки и удаления между запросами
+// не приводят к пропускам и повторам строк, а глубина листания не влияет на скорость.
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// Page — страница списка. Чтобы получить следующую страницу, next_cursor передаётся
+// в параметре cursor; на последней странице он равен null
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// listValueKind — тип значения параметра фильтра или поля сортировки
+type listValueKind int
+
+const (
+	listText listValueKind = iota
+	listInt
+	listNumeric
+	listBool
+	listDate
+	listTime
+	listUUID
+)
+
+// listValueTypes — типы SQL, к которым приводятся значения в запросе
+var listValueTypes = map[listValueKind]string{
+	listText:    "text",
+	listInt:     "bigint",
+	listNumeric: "numeric",
+	listBool:    "boolean",
+	listDate:    "date",
+	listTime:    "timestamptz",
+	listUUID:    "uuid",
+}
+
+// parseListValue проверяет значение и приводит его к виду, в котором оно передаётся в запрос.
+// Даты ожидаются в формате 2006-01-02, моменты времени — в RFC 3339.
+func parseListValue(kind listValueKind, raw string) (any, error) {
+	switch kind {
+	case listInt:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		return v, err
+	case listNumeric:
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	case listBool:
+		v, err := strconv.ParseBool(raw)
+		return v, err
+	case listDate:
+		v, err := time.Parse("2006-01-02", raw)
+		return v, err
+	case listTime:
+		v, err := time.Parse(time.RFC3339, raw)
+		return v, err
+	case listUUID:
+		v, err := uuid.Parse(raw)
+		return v.String(), err
+	default:
+		return raw, nil
+	}
+}
+
+// listSortField — поле, по которому разрешено сортировать список. Expr не должен принимать
+// значение NULL, иначе сравнение с курсором теряет строки.
+type listSortField struct {
+	Expr string
+	Kind listValueKind
+}
+
+// listFilter — фильтр по параметру запроса Param. В Cond значение обозначается $%[1]d
+type listFilter struct {
+	Param string
+	Kind  listValueKind
+	Cond  string
+}
+
+// listSpec описывает список: столбец id, допустимые сортировки и фильтры
+type listSpec struct {
+	ID          string
+	Sorts       map[string]listSortField
+	DefaultSort string // с префиксом - сортировка по убыванию
+	Filters     []listFilter
+}
+
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeListCursor(c listCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeListCursor(s string) (listCursor, error) {
+	var c listCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// listQuery — разобранные параметры limit, cursor, sort и условия фильтров
+type listQuery struct {
+	spec       listSpec
+	sort       string
+	desc       bool
+	limit      int
+	after      *listCursor
+	afterValue any
+	conds      string
+	args       []any
+}
+
+// parseListQuery разбирает параметры списка и фильтры spec.Filters.
+// При ошибке отвечает 400 и возвращает false.
+func parseListQuery(w http.ResponseWriter, r *http.Request, spec listSpec) (*listQuery, bool) {
+	query := r.URL.Query()
+	q := &listQuery{spec: spec, limit: defaultListLimit}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			http.Error(w, fmt.Sprintf("Параметр limit должен быть числом от 1 до %d", maxListLimit), http.StatusBadRequest)
+			return nil, false
+		}
+		q.limit = limit
+	}
+
+	sortParam := query.Get("sort")
+	if sortParam == "" {
+		sortParam = spec.DefaultSort
+	}
+	q.sort, q.desc = strings.TrimPrefix(sortParam, "-"), strings.HasPrefix(sortParam, "-")
+	field, ok := spec.Sorts[q.sort]
+	if !ok {
+		names := make([]string, 0, len(spec.Sorts))
+		for name := range spec.Sorts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		http.Error(w, fmt.Sprintf("Сортировка по полю %s не поддерживается, допустимые поля: %s",
+			q.sort, strings.Join(names, ", ")), http.StatusBadRequest)
+		return nil, false
+	}
+
+	if raw := query.Get("cursor"); raw != "" {
+		c, err := decodeListCursor(raw)
+		if err == nil && c.Sort != sortParam {
+			err = fmt.Errorf("курсор выдан для сортировки %s", c.Sort)
+		}
+		if err == nil {
+			q.afterValue, err = parseListValue(field.Kind, c.Value)
+		}
+		if err != nil {
+			http.Error(w, "Неверный курсор страницы", http.StatusBadRequest)
+			return nil, false
+		}
+		q.after = &c
+	}
+
+	for _, f := range spec.Filters {
+		raw := strings.TrimSpace(query.Get(f.Param))
+		if raw == "" {
+			continue
+		}
+		v, err := parseListValue(f.Kind, raw)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Неверное значение параметра %s", f.Param), http.StatusBadRequest)
+			return nil, false
+		}
+		q.where(f.Cond, v)
+	}
+
+	return q, true
+}
+
+// param добавляет параметры запроса, на которые ссылаются не условия, а, например, источник строк
+// (функция поиска в FROM), и возвращает номер первого из них
+func (q *listQuery) param(args ...any) int {
+	first := len(q.args) + 1
+	q.args = append(q.args, args...)
+	return first
+}
+
+// and дописывает к условию фрагмент вида "AND ..."; fragment получает номер первого своего параметра
+func (q *listQuery) and(fragment func(first int) string, args ...any) {
+	q.conds += fragment(q.param(args...))
+}
+
+// where добавляет условие с одним параметром, который обозначается в cond как $%[1]d
+func (q *listQuery) where(cond string, arg any) {
+	q.and(func(n int) string { return "\n\t\tAND " + fmt.Sprintf(cond, n) }, arg)
+}
+
+// prefixedRow подставляет в начало Scan приёмники prefix для служебных столбцов, которые запрос
+// выбирает перед столбцами сущности, — так функции чтения сущности не зависят от запроса
+type prefixedRow struct {
+	row    pgx.Row
+	prefix []any
+}
+
+func (p prefixedRow) Scan(dest ...any) error {
+	return p.row.Scan(append(append([]any{}, p.prefix...), dest...)...)
+}
+
+// fetchPage выбирает страницу строк columns из from с учётом фильтров, сортировки и курсора q.
+// scan читает столбцы columns одной строки.
+func fetchPage[T any](ctx context.Context, db *pgxpool.Pool, q *listQuery, columns, from string, scan func(pgx.Row) (T, error)) (Page[T], error) {
+	field := q.spec.Sorts[q.sort]
+	dir, cmp := "ASC", ">"
+	if q.desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	conds, args := q.conds, q.args
+	if q.after != nil {
+		n := len(args) + 1
+		conds += fmt.Sprintf("\n\t\tAND (%s, %s) %s ($%d::%s, $%d::uuid)", field.Expr, q.spec.ID, cmp, n, listValueTypes[field.Kind], n+1)
+		args = append(append([]any{}, args...), q.afterValue, q.after.ID)
+	}
+
+	rows, err := db.Query(ctx, fmt.Sprintf(`
+		SELECT to_json(%[1]s) #>> '{}', %[2]s::text, %[3]s
+		FROM %[4]s
+		WHERE TRUE%[5]s
+		ORDER BY %[1]s %[6]s, %[2]s %[6]s
+		LIMIT %[7]d`, field.Expr, q.spec.ID, columns, from, conds, dir, q.limit+1), args...)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	defer rows.Close()
+
+	page := Page[T]{Items: make([]T, 0)}
+	last := listCursor{Sort: q.sort}
+	if q.desc {
+		last.Sort = "-" + q.sort
+	}
+	for rows.Next() {
+		// Лишняя строка лишь показывает, что за страницей есть продолжение
+		if len(page.Items) == q.limit {
+			cursor := encodeListCursor(last)
+			page.NextCursor = &cursor
+			break
+		}
+		item, err := scan(prefixedRow{row: rows, prefix: []any{&last.Value, &last.ID}})
+		if err != nil {
+			return Page[T]{}, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	return page, rows.Err()
+}
+
+func writePage[T any](w http.ResponseWriter, page Page[T]) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// nameListSpec — список справочника, который сортируется только по названию
+func nameListSpec(id, name string) listSpec {
+	return listSpec{
+		ID:          id,
+		Sorts:       map[string]listSortField{"name": {Expr: name, Kind: listText}},
+		DefaultSort: "name",
+	}
+}
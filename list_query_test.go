@@ -0,0 +1,184 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListCursor(t *testing.T) {
+	c := listCursor{Sort: "-title", Value: "Начало, часть 2", ID: "9b165097-1c9f-4ea3-bef0-e505baa4ff63"}
+	encoded := encodeListCursor(c)
+	if strings.ContainsAny(encoded, "+/=") {
+		t.Errorf("Expected URL-safe cursor, got %s", encoded)
+	}
+	if got, err := decodeListCursor(encoded); err != nil || got != c {
+		t.Errorf("Expected %+v, got %+v (%v)", c, got, err)
+	}
+
+	for _, bad := range []string{"not base64!", "bm90IGpzb24", encodeListCursor(listCursor{Sort: "title", ID: "42"})} {
+		if _, err := decodeListCursor(bad); err == nil {
+			t.Errorf("Expected error for cursor %q", bad)
+		}
+	}
+}
+
+func TestParseListValue(t *testing.T) {
+	tests := []struct {
+		kind    listValueKind
+		raw     string
+		want    any
+		wantErr bool
+	}{
+		{listInt, "18", int64(18), false},
+		{listInt, "18+", nil, true},
+		{listNumeric, "1500.50", "1500.50", false},
+		{listNumeric, "много", nil, true},
+		{listBool, "true", true, false},
+		{listBool, "да", nil, true},
+		{listDate, "2010-07-08", time.Date(2010, 7, 8, 0, 0, 0, 0, time.UTC), false},
+		{listDate, "08.07.2010", nil, true},
+		{listTime, "2024-05-01T18:30:00+03:00", time.Date(2024, 5, 1, 15, 30, 0, 0, time.UTC), false},
+		{listTime, "2024-05-01", nil, true},
+		{listUUID, "9B165097-1C9F-4EA3-BEF0-E505BAA4FF63", "9b165097-1c9f-4ea3-bef0-e505baa4ff63", false},
+		{listUUID, "not-a-uuid", nil, true},
+		{listText, "Драма", "Драма", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseListValue(tt.kind, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want, ok := tt.want.(time.Time); ok {
+				if !got.(time.Time).Equal(want) {
+					t.Errorf("Expected %v, got %v", want, got)
+				}
+			} else if got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseListQuery(t *testing.T) {
+	titleCursor := url.QueryEscape(encodeListCursor(listCursor{Sort: "title", Value: "Начало", ID: "9b165097-1c9f-4ea3-bef0-e505baa4ff63"}))
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		limit          int
+		sort           string
+		desc           bool
+		args           int
+	}{
+		{"Defaults", "", http.StatusOK, defaultListLimit, "title", false, 0},
+		{"Descending sort", "sort=-release_date&limit=10", http.StatusOK, 10, "release_date", true, 0},
+		{"Filters", "age_limit=12&release_date_from=2000-01-01&genre_id=9b165097-1c9f-4ea3-bef0-e505baa4ff63", http.StatusOK, defaultListLimit, "title", false, 3},
+		{"Cursor", "cursor=" + titleCursor, http.StatusOK, defaultListLimit, "title", false, 0},
+		{"Zero limit", "limit=0", http.StatusBadRequest, 0, "", false, 0},
+		{"Too large limit", "limit=1000", http.StatusBadRequest, 0, "", false, 0},
+		{"Unknown sort", "sort=description", http.StatusBadRequest, 0, "", false, 0},
+		{"Bad filter", "release_date_to=вчера", http.StatusBadRequest, 0, "", false, 0},
+		{"Cursor for another sort", "sort=-title&cursor=" + titleCursor, http.StatusBadRequest, 0, "", false, 0},
+		{"Broken cursor", "cursor=abc", http.StatusBadRequest, 0, "", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			q, ok := parseListQuery(w, httptest.NewRequest("GET", "/movies?"+tt.query, nil), movieListSpec)
+			if w.Code != tt.expectedStatus || ok != (tt.expectedStatus == http.StatusOK) {
+				t.Fatalf("Expected status %d, got %d (%s)", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !ok {
+				return
+			}
+			if q.limit != tt.limit || q.sort != tt.sort || q.desc != tt.desc || len(q.args) != tt.args {
+				t.Errorf("Unexpected query %+v", q)
+			}
+		})
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	// Проходим весь список страницами по одному фильму
+	var titles []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > len(MoviesData) {
+			t.Fatal("Pagination does not terminate")
+		}
+		req := createRequest(t, "GET", ts.URL+"/movies?limit=1&sort=-title&cursor="+url.QueryEscape(cursor), "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		var p Page[Movie]
+		parseResponseBody(t, resp, &p)
+		resp.Body.Close()
+
+		if len(p.Items) != 1 {
+			t.Fatalf("Expected one movie per page, got %d", len(p.Items))
+		}
+		titles = append(titles, p.Items[0].Title)
+		if p.NextCursor == nil {
+			break
+		}
+		cursor = *p.NextCursor
+	}
+
+	// Страницы должны сложиться в тот же порядок, что и весь список одним запросом
+	req := createRequest(t, "GET", ts.URL+"/movies?sort=-title&limit=200", "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var all Page[Movie]
+	parseResponseBody(t, resp, &all)
+	resp.Body.Close()
+	var expected []string
+	for _, m := range all.Items {
+		expected = append(expected, m.Title)
+	}
+	if len(expected) != len(MoviesData) || all.NextCursor != nil {
+		t.Errorf("Expected all %d movies on one page, got %d", len(MoviesData), len(expected))
+	}
+	if strings.Join(titles, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected titles %v, got %v", expected, titles)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/movies?age_limit=1000", "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var empty Page[Movie]
+	parseResponseBody(t, resp, &empty)
+	resp.Body.Close()
+	if empty.Items == nil || len(empty.Items) != 0 || empty.NextCursor != nil {
+		t.Errorf("Expected empty page, got %+v", empty)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/movies?genre_id="+GenresData[0].ID+"&release_date_from="+MoviesData[0].ReleaseDate+
+		"&release_date_to="+MoviesData[0].ReleaseDate, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var filtered Page[Movie]
+	parseResponseBody(t, resp, &filtered)
+	resp.Body.Close()
+	for _, m := range filtered.Items {
+		if m.ReleaseDate != MoviesData[0].ReleaseDate {
+			t.Errorf("Unexpected movie %s released %s", m.Title, m.ReleaseDate)
+		}
+	}
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	req = createRequest(t, "GET", ts.URL+"/reviews?rating=0&limit=abc", admin, nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/users?sort=password_hash", admin, nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+}
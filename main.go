@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata" // часовые пояса залов не должны зависеть от базы tzdata в системе
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -37,6 +38,15 @@ func main() {
 	defer UserDB.Close()
 	defer GuestDB.Close()
 
+	if MediaStorage, err = NewStorageFromEnv(); err != nil {
+		log.Fatal("ошибка настройки хранилища медиа: ", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go RunShowStatusUpdater(ctx, AdminDB, time.Minute)
+	go RunSeatHoldReleaser(ctx, AdminDB, time.Minute)
+
 	log.Println("Сервер запущен на http://localhost:8080")
 	http.ListenAndServe(":8080", NewRouter())
 }
@@ -60,10 +70,42 @@ func NewRouter() *http.ServeMux {
 	mux.HandleFunc("PUT /genres/{id}", Midleware(RoleBasedHandler(UpdateGenre)))
 	mux.HandleFunc("DELETE /genres/{id}", Midleware(RoleBasedHandler(DeleteGenre)))
 
+	mux.HandleFunc("GET /people/search", Midleware(RoleBasedHandler(SearchPeople)))
+	mux.HandleFunc("GET /people", Midleware(RoleBasedHandler(GetPeople)))
+	mux.HandleFunc("GET /people/{id}", Midleware(RoleBasedHandler(GetPersonByID)))
+	mux.HandleFunc("GET /people/{id}/movies", Midleware(RoleBasedHandler(GetPersonMovies)))
+	mux.HandleFunc("POST /people", Midleware(RoleBasedHandler(CreatePerson)))
+	mux.HandleFunc("PUT /people/{id}", Midleware(RoleBasedHandler(UpdatePerson)))
+	mux.HandleFunc("DELETE /people/{id}", Midleware(RoleBasedHandler(DeletePerson)))
+
+	mux.HandleFunc("GET /languages", Midleware(RoleBasedHandler(GetLanguages)))
+	mux.HandleFunc("GET /languages/{id}", Midleware(RoleBasedHandler(GetLanguageByID)))
+	mux.HandleFunc("POST /languages", Midleware(RoleBasedHandler(CreateLanguage)))
+	mux.HandleFunc("PUT /languages/{id}", Midleware(RoleBasedHandler(UpdateLanguage)))
+	mux.HandleFunc("DELETE /languages/{id}", Midleware(RoleBasedHandler(DeleteLanguage)))
+
+	mux.HandleFunc("GET /show-formats", Midleware(RoleBasedHandler(GetShowFormats)))
+	mux.HandleFunc("GET /show-formats/{id}", Midleware(RoleBasedHandler(GetShowFormatByID)))
+	mux.HandleFunc("POST /show-formats", Midleware(RoleBasedHandler(CreateShowFormat)))
+	mux.HandleFunc("PUT /show-formats/{id}", Midleware(RoleBasedHandler(UpdateShowFormat)))
+	mux.HandleFunc("DELETE /show-formats/{id}", Midleware(RoleBasedHandler(DeleteShowFormat)))
+
+	mux.HandleFunc("GET /cinemas", Midleware(RoleBasedHandler(GetCinemas)))
+	mux.HandleFunc("GET /cinemas/{id}", Midleware(RoleBasedHandler(GetCinemaByID)))
+	mux.HandleFunc("POST /cinemas", Midleware(RoleBasedHandler(CreateCinema)))
+	mux.HandleFunc("PUT /cinemas/{id}", Midleware(RoleBasedHandler(UpdateCinema)))
+	mux.HandleFunc("DELETE /cinemas/{id}", Midleware(RoleBasedHandler(DeleteCinema)))
+	mux.HandleFunc("GET /cinemas/{id}/admins", Midleware(RoleBasedHandler(GetCinemaAdmins)))
+	mux.HandleFunc("POST /cinemas/{id}/admins", Midleware(RoleBasedHandler(AddCinemaAdmin)))
+	mux.HandleFunc("DELETE /cinemas/{id}/admins/{user_id}", Midleware(RoleBasedHandler(RemoveCinemaAdmin)))
+
 	mux.HandleFunc("GET /halls/by-screen-type", Midleware(RoleBasedHandler(GetHallsByScreenType)))
 	mux.HandleFunc("GET /halls/search", Midleware(RoleBasedHandler(SearchHallsByName)))
 	mux.HandleFunc("GET /halls", Midleware(RoleBasedHandler(GetHalls)))
 	mux.HandleFunc("GET /halls/{id}", Midleware(RoleBasedHandler(GetHallByID)))
+	mux.HandleFunc("GET /halls/{id}/free-slots", Midleware(RoleBasedHandler(GetHallFreeSlots)))
+	mux.HandleFunc("GET /halls/{id}/shows.ics", Midleware(RoleBasedHandler(GetHallCalendar)))
+	mux.HandleFunc("GET /halls/{id}/seat-map.svg", Midleware(RoleBasedHandler(GetHallSeatMap)))
 	mux.HandleFunc("POST /halls", Midleware(RoleBasedHandler(CreateHall)))
 	mux.HandleFunc("PUT /halls/{id}", Midleware(RoleBasedHandler(UpdateHall)))
 	mux.HandleFunc("DELETE /halls/{id}", Midleware(RoleBasedHandler(DeleteHall)))
@@ -71,20 +113,40 @@ func NewRouter() *http.ServeMux {
 	mux.HandleFunc("GET /movies", Midleware(RoleBasedHandler(GetMovies)))
 	mux.HandleFunc("GET /movies/by-title/search", Midleware(RoleBasedHandler(SearchMovies)))
 	mux.HandleFunc("GET /movies/by-genres/search", Midleware(RoleBasedHandler(GetMoviesByAllGenres)))
+	mux.HandleFunc("GET /movies/by-person/search", Midleware(RoleBasedHandler(SearchMoviesByPerson)))
 	mux.HandleFunc("GET /movies/{id}", Midleware(RoleBasedHandler(GetMovieByID)))
 	mux.HandleFunc("POST /movies", Midleware(RoleBasedHandler(CreateMovie)))
 	mux.HandleFunc("PUT /movies/{id}", Midleware(RoleBasedHandler(UpdateMovie)))
 	mux.HandleFunc("DELETE /movies/{id}", Midleware(RoleBasedHandler(DeleteMovie)))
 
+	mux.HandleFunc("GET /movies/{id}/media", Midleware(RoleBasedHandler(GetMovieMedia)))
+	mux.HandleFunc("POST /movies/{id}/media", Midleware(RoleBasedHandler(UploadMovieMedia)))
+	mux.HandleFunc("POST /movies/{id}/media/trailer", Midleware(RoleBasedHandler(AddMovieTrailer)))
+	mux.HandleFunc("DELETE /movie-media/{id}", Midleware(RoleBasedHandler(DeleteMovieMedia)))
+	mux.HandleFunc("GET /media/{key...}", Midleware(RoleBasedHandler(ServeMedia)))
+
 	mux.HandleFunc("GET /movie-shows/upcoming", Midleware(RoleBasedHandler(GetUpcomingShows)))
-	mux.HandleFunc("GET /movie-shows/by-date/{date}", Midleware(RoleBasedHandler(GetShowsByDate)))
+	mux.HandleFunc("GET /dates/{date}/movie-shows", Midleware(RoleBasedHandler(GetShowsByDate)))
 	mux.HandleFunc("GET /movies/{movie_id}/shows", Midleware(RoleBasedHandler(GetShowsByMovie)))
+	mux.HandleFunc("GET /movies/{movie_id}/shows.ics", Midleware(RoleBasedHandler(GetMovieCalendar)))
 	mux.HandleFunc("GET /movie-shows", Midleware(RoleBasedHandler(GetMovieShows)))
 	mux.HandleFunc("GET /movie-shows/{id}", Midleware(RoleBasedHandler(GetMovieShowByID)))
 	mux.HandleFunc("POST /movie-shows", Midleware(RoleBasedHandler(CreateMovieShow)))
+	mux.HandleFunc("POST /movie-shows/bulk", Midleware(RoleBasedHandler(CreateMovieShowsBulk)))
+	mux.HandleFunc("POST /movie-shows/validate", Midleware(RoleBasedHandler(ValidateMovieShow)))
 	mux.HandleFunc("PUT /movie-shows/{id}", Midleware(RoleBasedHandler(UpdateMovieShow)))
+	mux.HandleFunc("PUT /movie-shows/{id}/status", Midleware(RoleBasedHandler(UpdateMovieShowStatus)))
+	mux.HandleFunc("POST /movie-shows/{id}/reschedule", Midleware(RoleBasedHandler(RescheduleMovieShow)))
+	mux.HandleFunc("POST /movie-shows/{id}/cancel", Midleware(RoleBasedHandler(CancelMovieShow)))
+	mux.HandleFunc("GET /movie-shows/{id}/best-seats", Midleware(RoleBasedHandler(GetBestSeats)))
+	mux.HandleFunc("GET /movie-shows/{id}/distancing", Midleware(RoleBasedHandler(GetMovieShowDistancing)))
+	mux.HandleFunc("POST /movie-shows/{id}/best-seats", Midleware(RoleBasedHandler(HoldBestSeats)))
+	mux.HandleFunc("PUT /movie-shows/{id}/distancing", Midleware(RoleBasedHandler(SetMovieShowDistancing)))
+	mux.HandleFunc("DELETE /movie-shows/{id}/distancing", Midleware(RoleBasedHandler(DeleteMovieShowDistancing)))
 	mux.HandleFunc("DELETE /movie-shows/{id}", Midleware(RoleBasedHandler(DeleteMovieShow)))
 
+	mux.HandleFunc("POST /schedule/proposals", Midleware(RoleBasedHandler(ProposeSchedule)))
+
 	mux.HandleFunc("GET /users/{user_id}/reviews", Midleware(RoleBasedHandler(GetReviewsByUserID)))
 	mux.HandleFunc("GET /movies/{movie_id}/reviews", Midleware(RoleBasedHandler(GetReviewsByMovieID)))
 	mux.HandleFunc("GET /reviews", Midleware(RoleBasedHandler(GetReviews)))
@@ -94,11 +156,24 @@ func NewRouter() *http.ServeMux {
 	mux.HandleFunc("DELETE /reviews/{id}", Midleware(RoleBasedHandler(DeleteReview)))
 
 	mux.HandleFunc("GET /halls/{hall_id}/seats", Midleware(RoleBasedHandler(GetSeatsByHallID)))
+	mux.HandleFunc("GET /halls/{id}/layout", Midleware(RoleBasedHandler(GetHallLayout)))
+	mux.HandleFunc("POST /halls/{id}/layout", Midleware(RoleBasedHandler(ReplaceHallLayout)))
+	mux.HandleFunc("GET /halls/{id}/blocked-seats", Midleware(RoleBasedHandler(GetBlockedSeatsByHallID)))
+	mux.HandleFunc("GET /halls/{id}/zones", Midleware(RoleBasedHandler(GetHallZones)))
+	mux.HandleFunc("POST /halls/{id}/zones", Midleware(RoleBasedHandler(CreateHallZone)))
+	mux.HandleFunc("GET /zones/{id}", Midleware(RoleBasedHandler(GetHallZoneByID)))
+	mux.HandleFunc("PUT /zones/{id}", Midleware(RoleBasedHandler(UpdateHallZone)))
+	mux.HandleFunc("DELETE /zones/{id}", Midleware(RoleBasedHandler(DeleteHallZone)))
+	mux.HandleFunc("GET /halls/{id}/maintenance-windows", Midleware(RoleBasedHandler(GetHallMaintenanceWindows)))
+	mux.HandleFunc("POST /halls/{id}/maintenance-windows", Midleware(RoleBasedHandler(CreateHallMaintenanceWindow)))
+	mux.HandleFunc("DELETE /maintenance-windows/{id}", Midleware(RoleBasedHandler(DeleteHallMaintenanceWindow)))
 	mux.HandleFunc("GET /seats", Midleware(RoleBasedHandler(GetSeats)))
 	mux.HandleFunc("GET /seats/{id}", Midleware(RoleBasedHandler(GetSeatByID)))
 	mux.HandleFunc("POST /seats", Midleware(RoleBasedHandler(CreateSeat)))
 	mux.HandleFunc("PUT /seats/{id}", Midleware(RoleBasedHandler(UpdateSeat)))
 	mux.HandleFunc("DELETE /seats/{id}", Midleware(RoleBasedHandler(DeleteSeat)))
+	mux.HandleFunc("PUT /seats/{id}/service", Midleware(RoleBasedHandler(UpdateSeatService)))
+	mux.HandleFunc("PUT /seats/{id}/accessibility", Midleware(RoleBasedHandler(UpdateSeatAccessibility)))
 
 	mux.HandleFunc("GET /seat-types/search", Midleware(RoleBasedHandler(SearchSeatTypes)))
 	mux.HandleFunc("GET /seat-types", Midleware(RoleBasedHandler(GetSeatTypes)))
@@ -110,6 +185,7 @@ func NewRouter() *http.ServeMux {
 	mux.HandleFunc("GET /tickets/available-movie-show/{movie_show_id}", Midleware(RoleBasedHandler(GetAvailableTicketsByMovieShowID)))
 	mux.HandleFunc("PUT /tickets/reserve/{id}", Midleware(RoleBasedHandler(ReserveOrReturnReservedTicket)))
 	mux.HandleFunc("GET /tickets/movie-show/{movie_show_id}", Midleware(RoleBasedHandler(GetTicketsByMovieShowID)))
+	mux.HandleFunc("GET /tickets/seat-map/{movie_show_id}", Midleware(RoleBasedHandler(GetMovieShowSeatMap)))
 	mux.HandleFunc("GET /tickets/user/{user_id}", Midleware(RoleBasedHandler(GetTicketsByUserID)))
 	// mux.HandleFunc("GET /tickets/{id}", Midleware(RoleBasedHandler(GetTicketByID)))
 	mux.HandleFunc("POST /tickets", Midleware(RoleBasedHandler(CreateTicket)))
@@ -120,7 +196,11 @@ func NewRouter() *http.ServeMux {
 	mux.HandleFunc("POST /user/login", Midleware(RoleBasedHandler(LoginUser)))
 	mux.HandleFunc("GET /users", Midleware(RoleBasedHandler(GetUsers)))
 	mux.HandleFunc("GET /users/{id}", Midleware(RoleBasedHandler(GetUserByID)))
+	mux.HandleFunc("GET /users/{user_id}/notifications", Midleware(RoleBasedHandler(GetNotificationsByUserID)))
+	mux.HandleFunc("POST /users/{user_id}/calendar-token", Midleware(RoleBasedHandler(RotateCalendarToken)))
+	mux.HandleFunc("GET /calendar/users/{token}", Midleware(RoleBasedHandler(GetUserCalendar)))
 	mux.HandleFunc("PUT /users/{id}", Midleware(RoleBasedHandler(UpdateUser)))
+	mux.HandleFunc("PUT /users/{id}/accessibility", Midleware(RoleBasedHandler(UpdateUserAccessibility)))
 	mux.HandleFunc("GET /user/{id}", Midleware(RoleBasedHandler(GetUserNickname)))
 	mux.HandleFunc("GET /user/admin-status/{id}", Midleware(RoleBasedHandler(GetAdminStatusUser)))
 	mux.HandleFunc("PUT /user/admin-status/{id}", Midleware(RoleBasedHandler(UpdateAdminStatusUser)))
@@ -115,6 +115,14 @@ func TestMain(m *testing.M) {
 		log.Fatal("ошибка подключения к БД: ", err)
 	}
 
+	mediaDir, err := os.MkdirTemp("", "cinema-media-*")
+	if err != nil {
+		log.Fatal("ошибка создания каталога медиа: ", err)
+	}
+	if MediaStorage, err = NewLocalStorage(mediaDir, "/media"); err != nil {
+		log.Fatal(err)
+	}
+
 	// time_all()
 
 	code := m.Run()
@@ -122,6 +130,7 @@ func TestMain(m *testing.M) {
 	TestAdminDB.Close()
 	TestGuestDB.Close()
 	TestUserDB.Close()
+	os.RemoveAll(mediaDir)
 
 	os.Exit(code)
 }
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const hallMaintenanceColumns = "id, hall_id, lower(period), upper(period), reason"
+
+func validateHallMaintenanceWindowData(w http.ResponseWriter, mw *HallMaintenanceWindowData, now time.Time) bool {
+	mw.Reason = PrepareString(mw.Reason)
+
+	if mw.Reason == "" {
+		http.Error(w, "Причина обслуживания не может быть пустой или состоять только из пробелов", http.StatusBadRequest)
+		return false
+	}
+	if utf8.RuneCountInString(mw.Reason) > 500 {
+		http.Error(w, "Причина обслуживания не может превышать 500 символов", http.StatusBadRequest)
+		return false
+	}
+
+	if mw.StartTime.IsZero() || mw.EndTime.IsZero() {
+		http.Error(w, "Время начала и окончания обслуживания обязательны", http.StatusBadRequest)
+		return false
+	}
+	if !mw.EndTime.After(mw.StartTime) {
+		http.Error(w, "Обслуживание должно закончиться позже, чем начаться", http.StatusBadRequest)
+		return false
+	}
+	if !mw.EndTime.After(now) {
+		http.Error(w, "Окно обслуживания не может закончиться в прошлом", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+var hallMaintenanceListSpec = listSpec{
+	ID:          "id",
+	Sorts:       map[string]listSortField{"start_time": {Expr: "lower(period)", Kind: listTime}},
+	DefaultSort: "start_time",
+}
+
+// @Summary Получить окна обслуживания зала (admin)
+// @Description Возвращает страницу окон обслуживания зала, которые ещё не закончились, по умолчанию в порядке начала.
+// @Tags Кинозалы
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID зала"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: start_time или -start_time (по убыванию)"
+// @Success 200 {object} Page[HallMaintenanceWindow] "Страница окон обслуживания"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID зала или неверные параметры списка"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /halls/{id}/maintenance-windows [get]
+func GetHallMaintenanceWindows(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		q, ok := parseListQuery(w, r, hallMaintenanceListSpec)
+		if !ok {
+			return
+		}
+		q.where("hall_id = $%[1]d AND upper(period) > NOW()", hallID)
+
+		page, err := fetchPage(r.Context(), db, q, hallMaintenanceColumns, "hall_maintenance_windows",
+			func(row pgx.Row) (HallMaintenanceWindow, error) {
+				var mw HallMaintenanceWindow
+				err := row.Scan(&mw.ID, &mw.HallID, &mw.StartTime, &mw.EndTime, &mw.Reason)
+				return mw, err
+			})
+		if HandleDatabaseError(w, err, "окнами обслуживания") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Закрыть зал на обслуживание (admin)
+// @Description Создаёт окно обслуживания зала (ремонт проектора, реконструкция и т.п.). Пока окно действует,
+// @Description новые сеансы, которые вместе с уборкой пересекаются с ним, отклоняются с 409.
+// @Description Уже запланированные сеансы не отменяются: они возвращаются в affected_shows, чтобы их можно было
+// @Description перенести через POST /movie-shows/{id}/reschedule или отменить.
+// @Tags Кинозалы
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID зала"
+// @Param window body HallMaintenanceWindowData true "Окно обслуживания"
+// @Success 201 {object} HallMaintenanceWindowCreated "ID окна и затронутые сеансы"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Кинозал не найден"
+// @Failure 409 {object} ErrorResponse "Окно пересекается с другим окном обслуживания зала"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /halls/{id}/maintenance-windows [post]
+func CreateHallMaintenanceWindow(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var mw HallMaintenanceWindowData
+		if !DecodeJSONBody(w, r, &mw) {
+			return
+		}
+		if !validateHallMaintenanceWindowData(w, &mw, time.Now()) {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfHall, hallID) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		// Блокировка зала упорядочивает окно и сеансы, которые планируются одновременно с ним:
+		// триггер check_movie_show_conflict берёт ту же блокировку, поэтому сеанс либо увидит окно,
+		// либо будет создан раньше и попадёт в список затронутых
+		var timeZone string
+		err = tx.QueryRow(ctx, "SELECT time_zone FROM halls WHERE id = $1 FOR UPDATE", hallID).Scan(&timeZone)
+		if isNoRows(err) {
+			http.Error(w, "Кинозал не найден", http.StatusNotFound)
+			return
+		}
+		if IsError(w, err) {
+			return
+		}
+
+		id := uuid.New()
+		_, err = tx.Exec(ctx,
+			"INSERT INTO hall_maintenance_windows (id, hall_id, period, reason) VALUES ($1, $2, tstzrange($3, $4), $5)",
+			id, hallID, mw.StartTime, mw.EndTime, mw.Reason)
+		if IsError(w, err) {
+			return
+		}
+
+		// Сеансы, которые уже начались, не трогаем: их не перенести
+		rows, err := tx.Query(ctx, `
+			SELECT ms.id, ms.movie_id, ms.start_time, ms.start_time + m.duration, upper(ms.occupied)
+			FROM movie_shows ms
+			JOIN movies m ON m.id = ms.movie_id
+			WHERE ms.hall_id = $1 AND ms.occupied && tstzrange($2, $3)
+			AND ms.status IN ('scheduled', 'on_sale', 'sold_out')
+			ORDER BY ms.start_time`, hallID, mw.StartTime, mw.EndTime)
+		if HandleDatabaseError(w, err, "киносеансами") {
+			return
+		}
+		defer rows.Close()
+
+		result := HallMaintenanceWindowCreated{ID: id.String(), AffectedShows: []MovieShowConflict{}}
+		for rows.Next() {
+			var c MovieShowConflict
+			if err := rows.Scan(&c.ID, &c.MovieID, &c.StartTime, &c.EndTime, &c.AvailableAt); HandleDatabaseError(w, err, "киносеансами") {
+				return
+			}
+			c.StartTime = InTimeZone(c.StartTime, timeZone)
+			c.EndTime = InTimeZone(c.EndTime, timeZone)
+			c.AvailableAt = InTimeZone(c.AvailableAt, timeZone)
+			result.AffectedShows = append(result.AffectedShows, c)
+		}
+		if HandleDatabaseError(w, rows.Err(), "киносеансами") {
+			return
+		}
+		rows.Close()
+
+		if err := tx.Commit(ctx); IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// @Summary Удалить окно обслуживания (admin)
+// @Description Открывает зал для планирования сеансов на время окна.
+// @Tags Кинозалы
+// @Security BearerAuth
+// @Param id path string true "ID окна обслуживания"
+// @Success 204 "Окно обслуживания удалено"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Окно обслуживания не найдено"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /maintenance-windows/{id} [delete]
+func DeleteHallMaintenanceWindow(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfMaintenance, id) {
+			return
+		}
+
+		res, err := db.Exec(r.Context(), "DELETE FROM hall_maintenance_windows WHERE id = $1", id)
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateHallMaintenanceWindow(t *testing.T) {
+	admin := os.Getenv("CLAIM_ROLE_ADMIN")
+	start := time.Now().Add(72 * time.Hour).Truncate(time.Minute)
+	valid := HallMaintenanceWindowData{StartTime: start, EndTime: start.Add(8 * time.Hour), Reason: "Ремонт проектора"}
+
+	tests := []struct {
+		name           string
+		role           string
+		hallID         string
+		body           HallMaintenanceWindowData
+		expectedStatus int
+	}{
+		{"Valid", admin, HallsData[0].ID, valid, http.StatusCreated},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), HallsData[0].ID, valid, http.StatusForbidden},
+		{"Empty reason", admin, HallsData[0].ID, HallMaintenanceWindowData{StartTime: start, EndTime: start.Add(time.Hour), Reason: "  "}, http.StatusBadRequest},
+		{"Reason too long", admin, HallsData[0].ID, HallMaintenanceWindowData{StartTime: start, EndTime: start.Add(time.Hour), Reason: strings.Repeat("а", 501)}, http.StatusBadRequest},
+		{"End before start", admin, HallsData[0].ID, HallMaintenanceWindowData{StartTime: start, EndTime: start.Add(-time.Hour), Reason: "Ремонт"}, http.StatusBadRequest},
+		{"Missing end", admin, HallsData[0].ID, HallMaintenanceWindowData{StartTime: start, Reason: "Ремонт"}, http.StatusBadRequest},
+		{"Ended in the past", admin, HallsData[0].ID, HallMaintenanceWindowData{StartTime: start.Add(-96 * time.Hour), EndTime: start.Add(-90 * time.Hour), Reason: "Ремонт"}, http.StatusBadRequest},
+		{"Non-existent hall", admin, uuid.New().String(), valid, http.StatusNotFound},
+		{"Invalid hall ID", admin, "invalid-uuid", valid, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/halls/"+tt.hallID+"/maintenance-windows", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+			var created HallMaintenanceWindowCreated
+			parseResponseBody(t, resp, &created)
+			if created.ID == "" || len(created.AffectedShows) != 0 {
+				t.Errorf("Expected window without affected shows, got %+v", created)
+			}
+		})
+	}
+}
+
+func TestHallMaintenanceScheduling(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	user := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+	windowsURL := ts.URL + "/halls/" + HallsData[0].ID + "/maintenance-windows"
+	show := MovieShowsData[0]
+
+	req := createRequest(t, "GET", windowsURL, admin, nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var empty Page[HallMaintenanceWindow]
+	parseResponseBody(t, resp, &empty)
+	resp.Body.Close()
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Errorf("Expected no maintenance windows, got %+v", empty)
+	}
+
+	// Окно начинается во время сеанса show и длится сутки
+	window := HallMaintenanceWindowData{
+		StartTime: show.StartTime.Truncate(time.Minute).Add(time.Hour),
+		EndTime:   show.StartTime.Truncate(time.Minute).Add(25 * time.Hour),
+		Reason:    "Замена экрана",
+	}
+	req = createRequest(t, "POST", windowsURL, admin, window)
+	resp = executeRequest(t, req, http.StatusCreated)
+	var created HallMaintenanceWindowCreated
+	parseResponseBody(t, resp, &created)
+	resp.Body.Close()
+	if len(created.AffectedShows) != 1 || created.AffectedShows[0].ID != show.ID {
+		t.Fatalf("Expected show %s to be affected, got %+v", show.ID, created.AffectedShows)
+	}
+
+	overlapping := window
+	overlapping.StartTime = window.EndTime.Add(-time.Hour)
+	overlapping.EndTime = window.EndTime.Add(time.Hour)
+	req = createRequest(t, "POST", windowsURL, admin, overlapping)
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	req = createRequest(t, "GET", windowsURL, user, nil)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "GET", windowsURL, admin, nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var page Page[HallMaintenanceWindow]
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	windows := page.Items
+	if len(windows) != 1 || windows[0].ID != created.ID || windows[0].Reason != window.Reason ||
+		!windows[0].StartTime.Equal(window.StartTime) || !windows[0].EndTime.Equal(window.EndTime) {
+		t.Fatalf("Expected created window, got %+v", windows)
+	}
+
+	inWindow := MovieShowAdmin{
+		MovieID:   MoviesData[1].ID,
+		HallID:    HallsData[0].ID,
+		StartTime: window.StartTime.Add(6 * time.Hour),
+		Language:  "Русский",
+		BasePrice: 300,
+	}
+	req = createRequest(t, "POST", ts.URL+"/movie-shows", admin, inWindow)
+	resp = executeRequest(t, req, http.StatusConflict)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), window.Reason) {
+		t.Errorf("Expected conflict message to mention maintenance reason, got %q", body)
+	}
+
+	req = createRequest(t, "POST", ts.URL+"/movie-shows/validate", admin, MovieShowData{
+		MovieID:   inWindow.MovieID,
+		HallID:    inWindow.HallID,
+		StartTime: inWindow.StartTime,
+		Language:  inWindow.Language,
+	})
+	resp = executeRequest(t, req, http.StatusOK)
+	var validation MovieShowValidation
+	parseResponseBody(t, resp, &validation)
+	resp.Body.Close()
+	if validation.Valid || len(validation.MaintenanceWindows) != 1 || validation.MaintenanceWindows[0].ID != created.ID {
+		t.Errorf("Expected validation to report maintenance window, got %+v", validation)
+	}
+
+	req = createRequest(t, "DELETE", ts.URL+"/maintenance-windows/"+created.ID, user, nil)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "DELETE", ts.URL+"/maintenance-windows/"+created.ID, admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+	req = createRequest(t, "DELETE", ts.URL+"/maintenance-windows/"+created.ID, admin, nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+
+	req = createRequest(t, "POST", ts.URL+"/movie-shows", admin, inWindow)
+	executeRequest(t, req, http.StatusCreated).Body.Close()
+}
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxMediaUploadSize  = 10 << 20 // 10 МБ
+	maxMediaImagePixels = 40_000_000
+	mediaThumbnailSide  = 320
+)
+
+// mediaImageExtensions — допустимые типы изображений (по содержимому файла) и расширения их ключей
+var mediaImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+const movieMediaColumns = "id, kind, storage_key, thumbnail_key, external_url, content_type, size_bytes, width, height"
+
+func scanMovieMedia(row interface{ Scan(...any) error }) (MovieMedia, error) {
+	var m MovieMedia
+	var storageKey, thumbnailKey, externalURL *string
+	err := row.Scan(&m.ID, &m.Kind, &storageKey, &thumbnailKey, &externalURL, &m.ContentType, &m.SizeBytes, &m.Width, &m.Height)
+	if err != nil {
+		return m, err
+	}
+
+	if externalURL != nil {
+		m.URL = *externalURL
+	}
+	if storageKey != nil {
+		m.URL = MediaStorage.URL(*storageKey)
+	}
+	if thumbnailKey != nil {
+		thumbnailURL := MediaStorage.URL(*thumbnailKey)
+		m.ThumbnailURL = &thumbnailURL
+	}
+	return m, nil
+}
+
+// fetchMediaByMovieID возвращает медиа фильма: постер, затем кадры и трейлеры в порядке добавления
+func fetchMediaByMovieID(db *pgxpool.Pool, movieID string) ([]MovieMedia, error) {
+	rows, err := db.Query(context.Background(),
+		"SELECT "+movieMediaColumns+" FROM movie_media WHERE movie_id = $1 ORDER BY kind, created_at, id", movieID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []MovieMedia
+	for rows.Next() {
+		m, err := scanMovieMedia(rows)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, rows.Err()
+}
+
+// deleteMediaObjects удаляет файлы из хранилища после того, как записи о них удалены из базы.
+// Ошибки только логируются: запись уже удалена, а осиротевший файл никому не мешает.
+func deleteMediaObjects(ctx context.Context, keys ...*string) {
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+		if err := MediaStorage.Delete(ctx, *key); err != nil {
+			log.Printf("failed to delete media object %s: %v", *key, err)
+		}
+	}
+}
+
+func validateTrailerURL(raw string) error {
+	if raw == "" {
+		return errors.New("ссылка на трейлер не может быть пустой")
+	}
+	if len(raw) > 2000 {
+		return errors.New("ссылка на трейлер не может превышать 2000 символов")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("ссылка на трейлер должна быть адресом http или https")
+	}
+	return nil
+}
+
+// makeThumbnail уменьшает изображение так, чтобы большая сторона не превышала maxSide, усредняя
+// попадающие в пиксель миниатюры пиксели исходника. Прозрачные области заливаются белым:
+// миниатюры сохраняются в JPEG. Исходник переносится на белый фон полосами по строке миниатюры,
+// поэтому копия изображения в полном разрешении не создаётся.
+func makeThumbnail(src image.Image, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := sw, sh
+	if sw > maxSide || sh > maxSide {
+		if sw >= sh {
+			dw, dh = maxSide, max(1, sh*maxSide/sw)
+		} else {
+			dw, dh = max(1, sw*maxSide/sh), maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	band := image.NewRGBA(image.Rect(0, 0, sw, (sh+dh-1)/dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, (y+1)*sh/dh
+		rows := image.Rect(0, 0, sw, y1-y0)
+		draw.Draw(band, rows, image.White, image.Point{}, draw.Src)
+		draw.Draw(band, rows, src, image.Pt(b.Min.X, b.Min.Y+y0), draw.Over)
+
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, (x+1)*sw/dw
+
+			var sum [4]int
+			for by := 0; by < y1-y0; by++ {
+				row := band.Pix[by*band.Stride+x0*4 : by*band.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			p := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[p+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// mediaImage — проверенное загруженное изображение и его миниатюра
+type mediaImage struct {
+	data        []byte
+	contentType string
+	extension   string
+	width       int
+	height      int
+	thumbnail   []byte
+}
+
+// readMediaUpload читает изображение из поля file формы multipart/form-data, проверяет размер и тип
+// по содержимому и строит миниатюру
+func readMediaUpload(w http.ResponseWriter, r *http.Request) (*mediaImage, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxMediaUploadSize+1<<20) // запас на заголовки формы
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "Размер файла не может превышать 10 МБ", http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		http.Error(w, "Ожидается форма multipart/form-data с файлом в поле file", http.StatusBadRequest)
+		return nil, false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Ожидается форма multipart/form-data с файлом в поле file", http.StatusBadRequest)
+		return nil, false
+	}
+	defer file.Close()
+
+	if header.Size > maxMediaUploadSize {
+		http.Error(w, "Размер файла не может превышать 10 МБ", http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Не удалось прочитать файл", http.StatusBadRequest)
+		return nil, false
+	}
+	if len(data) == 0 {
+		http.Error(w, "Файл не может быть пустым", http.StatusBadRequest)
+		return nil, false
+	}
+
+	// Заявленному клиентом типу не доверяем, тип определяется по содержимому
+	img := &mediaImage{data: data, contentType: http.DetectContentType(data)}
+	var ok bool
+	if img.extension, ok = mediaImageExtensions[img.contentType]; !ok {
+		http.Error(w, "Неподдерживаемый тип файла, допустимы изображения JPEG, PNG и GIF", http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		http.Error(w, "Файл изображения повреждён", http.StatusBadRequest)
+		return nil, false
+	}
+	if cfg.Width*cfg.Height > maxMediaImagePixels {
+		http.Error(w, fmt.Sprintf("Изображение не может содержать больше %d пикселей", maxMediaImagePixels), http.StatusBadRequest)
+		return nil, false
+	}
+	img.width, img.height = cfg.Width, cfg.Height
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		http.Error(w, "Файл изображения повреждён", http.StatusBadRequest)
+		return nil, false
+	}
+	var thumbnail bytes.Buffer
+	if err := jpeg.Encode(&thumbnail, makeThumbnail(decoded, mediaThumbnailSide), &jpeg.Options{Quality: 85}); err != nil {
+		http.Error(w, "Не удалось создать миниатюру", http.StatusInternalServerError)
+		return nil, false
+	}
+	img.thumbnail = thumbnail.Bytes()
+
+	return img, true
+}
+
+// @Summary Получить медиа фильма (guest | user | admin)
+// @Description Возвращает постер, кадры и трейлеры фильма. У постера и кадров есть миниатюра.
+// @Tags Медиа фильмов
+// @Produce json
+// @Param id path string true "ID фильма"
+// @Success 200 {array} MovieMedia "Медиа фильма"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Медиа не найдены"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movies/{id}/media [get]
+func GetMovieMedia(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		media, err := fetchMediaByMovieID(db, id.String())
+		if HandleDatabaseError(w, err, "медиа фильма") {
+			return
+		}
+
+		if len(media) == 0 {
+			http.Error(w, "Медиа не найдены", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(media)
+	}
+}
+
+// @Summary Загрузить постер или кадр фильма (admin)
+// @Description Принимает изображение JPEG, PNG или GIF до 10 МБ в поле file; тип определяется по содержимому файла.
+// @Description Для изображения создаётся миниатюра JPEG со стороной до 320 пикселей.
+// @Description Новый постер заменяет прежний.
+// @Tags Медиа фильмов
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID фильма"
+// @Param kind query string true "Вид изображения" Enums(poster, still)
+// @Param file formData file true "Изображение"
+// @Success 201 {object} MovieMedia "Загруженное изображение"
+// @Failure 400 {object} ErrorResponse "Неверный вид медиа, форма или повреждённый файл"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Фильм не найден"
+// @Failure 413 {object} ErrorResponse "Файл слишком большой"
+// @Failure 415 {object} ErrorResponse "Неподдерживаемый тип файла"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movies/{id}/media [post]
+func UploadMovieMedia(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		movieID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		kind := MediaKindEnumType(r.URL.Query().Get("kind"))
+		if kind != MediaPoster && kind != MediaStill {
+			http.Error(w, "Вид изображения должен быть poster или still; трейлер добавляется ссылкой", http.StatusBadRequest)
+			return
+		}
+
+		ctx := r.Context()
+		var exists bool
+		err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM movies WHERE id = $1)", movieID).Scan(&exists)
+		if IsError(w, err) {
+			return
+		}
+		if !exists {
+			http.Error(w, "Фильм не найден", http.StatusNotFound)
+			return
+		}
+
+		img, ok := readMediaUpload(w, r)
+		if !ok {
+			return
+		}
+
+		id := uuid.New()
+		storageKey := path.Join("movies", movieID.String(), id.String()+img.extension)
+		thumbnailKey := path.Join("movies", movieID.String(), id.String()+"_thumb.jpg")
+
+		if err := MediaStorage.Put(ctx, storageKey, img.contentType, img.data); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при сохранении файла: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if err := MediaStorage.Put(ctx, thumbnailKey, "image/jpeg", img.thumbnail); err != nil {
+			deleteMediaObjects(ctx, &storageKey)
+			http.Error(w, fmt.Sprintf("Ошибка при сохранении миниатюры: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		committed := false
+		defer func() {
+			if !committed {
+				deleteMediaObjects(context.Background(), &storageKey, &thumbnailKey)
+			}
+		}()
+
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		var oldKeys [2]*string
+		if kind == MediaPoster {
+			err = tx.QueryRow(ctx,
+				"DELETE FROM movie_media WHERE movie_id = $1 AND kind = 'poster' RETURNING storage_key, thumbnail_key",
+				movieID).Scan(&oldKeys[0], &oldKeys[1])
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				HandleDatabaseError(w, err, "медиа фильма")
+				return
+			}
+		}
+
+		media, err := scanMovieMedia(tx.QueryRow(ctx, `
+			INSERT INTO movie_media (id, movie_id, kind, storage_key, thumbnail_key, content_type, size_bytes, width, height)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING `+movieMediaColumns,
+			id, movieID, kind, storageKey, thumbnailKey, img.contentType, len(img.data), img.width, img.height))
+		if IsError(w, err) {
+			return
+		}
+
+		if IsError(w, tx.Commit(ctx)) {
+			return
+		}
+		committed = true
+		deleteMediaObjects(ctx, oldKeys[:]...)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(media)
+	}
+}
+
+// @Summary Добавить трейлер фильма (admin)
+// @Description Сохраняет ссылку на трейлер (например, на видеохостинге). Сами видео сервер не хранит.
+// @Tags Медиа фильмов
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID фильма"
+// @Param trailer body MovieTrailerData true "Ссылка на трейлер"
+// @Success 201 {object} MovieMedia "Добавленный трейлер"
+// @Failure 400 {object} ErrorResponse "Неверная ссылка"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Фильм не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movies/{id}/media/trailer [post]
+func AddMovieTrailer(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		movieID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var data MovieTrailerData
+		if !DecodeJSONBody(w, r, &data) {
+			return
+		}
+		data.URL = PrepareString(data.URL)
+		if err := validateTrailerURL(data.URL); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := r.Context()
+		var exists bool
+		err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM movies WHERE id = $1)", movieID).Scan(&exists)
+		if IsError(w, err) {
+			return
+		}
+		if !exists {
+			http.Error(w, "Фильм не найден", http.StatusNotFound)
+			return
+		}
+
+		media, err := scanMovieMedia(db.QueryRow(ctx, `
+			INSERT INTO movie_media (id, movie_id, kind, external_url)
+			VALUES ($1, $2, 'trailer', $3)
+			RETURNING `+movieMediaColumns,
+			uuid.New(), movieID, data.URL))
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(media)
+	}
+}
+
+// @Summary Удалить медиа фильма (admin)
+// @Description Удаляет постер, кадр или трейлер; файлы изображения и миниатюры удаляются из хранилища.
+// @Tags Медиа фильмов
+// @Security BearerAuth
+// @Param id path string true "ID медиа"
+// @Success 204 "Медиа удалено"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Медиа не найдено"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-media/{id} [delete]
+func DeleteMovieMedia(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var storageKey, thumbnailKey *string
+		err := db.QueryRow(r.Context(),
+			"DELETE FROM movie_media WHERE id = $1 RETURNING storage_key, thumbnail_key", id).
+			Scan(&storageKey, &thumbnailKey)
+		if IsError(w, err) {
+			return
+		}
+		deleteMediaObjects(r.Context(), storageKey, thumbnailKey)
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// @Summary Получить медиафайл (guest | user | admin)
+// @Description Отдаёт изображение или миниатюру из хранилища медиа по ключу из url/thumbnail_url.
+// @Tags Медиа фильмов
+// @Produce image/jpeg,image/png,image/gif
+// @Param key path string true "Ключ файла"
+// @Success 200 {file} file "Файл"
+// @Failure 404 {object} ErrorResponse "Файл не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /media/{key} [get]
+func ServeMedia(_ *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc, contentType, err := MediaStorage.Open(r.Context(), r.PathValue("key"))
+		if errors.Is(err, ErrMediaNotFound) {
+			http.Error(w, "Файл не найден", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при чтении файла: %v", err), http.StatusInternalServerError)
+			return
+		}
+		defer rc.Close()
+
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		// Ключи содержат UUID и не переиспользуются, поэтому файл можно кэшировать навсегда
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		io.Copy(w, rc)
+	}
+}
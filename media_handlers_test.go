@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func testImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 200, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func createUploadRequest(t *testing.T, url, token string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if data != nil {
+		part, err := form.CreateFormFile("file", "upload.bin")
+		if err != nil {
+			t.Fatalf("Failed to create form file: %v", err)
+		}
+		part.Write(data)
+	}
+	form.Close()
+
+	req, err := http.NewRequest("POST", url, &body)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	t.Run("Landscape is scaled to max side", func(t *testing.T) {
+		thumb := makeThumbnail(image.NewRGBA(image.Rect(0, 0, 1000, 500)), 320)
+		if thumb.Bounds().Dx() != 320 || thumb.Bounds().Dy() != 160 {
+			t.Errorf("Expected 320x160, got %v", thumb.Bounds())
+		}
+	})
+
+	t.Run("Portrait is scaled to max side", func(t *testing.T) {
+		thumb := makeThumbnail(image.NewRGBA(image.Rect(0, 0, 1000, 1500)), 320)
+		if thumb.Bounds().Dx() != 213 || thumb.Bounds().Dy() != 320 {
+			t.Errorf("Expected 213x320, got %v", thumb.Bounds())
+		}
+	})
+
+	t.Run("Small image keeps size", func(t *testing.T) {
+		thumb := makeThumbnail(image.NewRGBA(image.Rect(10, 10, 110, 60)), 320)
+		if thumb.Bounds().Dx() != 100 || thumb.Bounds().Dy() != 50 {
+			t.Errorf("Expected 100x50, got %v", thumb.Bounds())
+		}
+	})
+
+	t.Run("Pixels are averaged", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+		for x := 0; x < 4; x++ {
+			for y := 0; y < 2; y++ {
+				if (x+y)%2 == 0 {
+					src.Set(x, y, color.Black)
+				} else {
+					src.Set(x, y, color.White)
+				}
+			}
+		}
+		thumb := makeThumbnail(src, 2)
+		if got := thumb.RGBAAt(0, 0); got.R != 127 || got.G != 127 || got.B != 127 || got.A != 255 {
+			t.Errorf("Expected grey pixel, got %v", got)
+		}
+	})
+
+	t.Run("Rows are read from the source origin", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(5, 5, 9, 9))
+		draw.Draw(src, image.Rect(5, 5, 9, 7), image.Black, image.Point{}, draw.Src)
+		draw.Draw(src, image.Rect(5, 7, 9, 9), image.White, image.Point{}, draw.Src)
+		thumb := makeThumbnail(src, 2)
+		if top, bottom := thumb.RGBAAt(1, 0), thumb.RGBAAt(1, 1); top.R != 0 || bottom.R != 255 {
+			t.Errorf("Expected black top and white bottom row, got %v and %v", top, bottom)
+		}
+	})
+
+	t.Run("Transparency becomes white", func(t *testing.T) {
+		thumb := makeThumbnail(image.NewNRGBA(image.Rect(0, 0, 10, 10)), 320)
+		if got := thumb.RGBAAt(5, 5); got != (color.RGBA{255, 255, 255, 255}) {
+			t.Errorf("Expected white pixel, got %v", got)
+		}
+	})
+}
+
+func TestValidateTrailerURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{"HTTPS", "https://www.youtube.com/watch?v=EXeTwQWrcwY", false},
+		{"HTTP", "http://example.com/trailer.mp4", false},
+		{"Empty", "", true},
+		{"No scheme", "www.youtube.com/watch", true},
+		{"Script", "javascript:alert(1)", true},
+		{"No host", "https:///path", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateTrailerURL(tt.url); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestUploadMovieMedia(t *testing.T) {
+	admin := os.Getenv("CLAIM_ROLE_ADMIN")
+	movieID := MoviesData[0].ID
+	validPNG := testImage(t, "png", 800, 1200)
+	corruptPNG := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+
+	tests := []struct {
+		name           string
+		role           string
+		movieID        string
+		kind           string
+		data           []byte
+		expectedStatus int
+	}{
+		{"Poster PNG", admin, movieID, "poster", validPNG, http.StatusCreated},
+		{"Still JPEG", admin, movieID, "still", testImage(t, "jpeg", 1920, 1080), http.StatusCreated},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), movieID, "poster", validPNG, http.StatusForbidden},
+		{"Forbidden Guest", "", movieID, "poster", validPNG, http.StatusForbidden},
+		{"Trailer kind", admin, movieID, "trailer", validPNG, http.StatusBadRequest},
+		{"Missing kind", admin, movieID, "", validPNG, http.StatusBadRequest},
+		{"Missing file", admin, movieID, "poster", nil, http.StatusBadRequest},
+		{"Text file", admin, movieID, "poster", []byte("definitely not an image"), http.StatusUnsupportedMediaType},
+		{"Corrupt image", admin, movieID, "poster", corruptPNG, http.StatusBadRequest},
+		{"Too large", admin, movieID, "still", append(validPNG, make([]byte, maxMediaUploadSize)...), http.StatusRequestEntityTooLarge},
+		{"Non-existent movie", admin, uuid.New().String(), "poster", validPNG, http.StatusNotFound},
+		{"Invalid movie ID", admin, "invalid-uuid", "poster", validPNG, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			token := ""
+			if tt.role != "" {
+				token = generateToken(t, tt.role)
+			}
+			req := createUploadRequest(t, ts.URL+"/movies/"+tt.movieID+"/media?kind="+tt.kind, token, tt.data)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+			var media MovieMedia
+			parseResponseBody(t, resp, &media)
+			if string(media.Kind) != tt.kind || media.ThumbnailURL == nil || media.Width == nil || media.SizeBytes == nil ||
+				*media.SizeBytes != int64(len(tt.data)) {
+				t.Errorf("Unexpected media %+v", media)
+			}
+		})
+	}
+}
+
+func TestMovieMediaLifecycle(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	user := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+
+	req := createRequest(t, "POST", ts.URL+"/movies", admin, MovieData{
+		Title: "Медиа", Duration: "01:30:00", Description: "Фильм с постером", AgeLimit: 12,
+		ReleaseDate: "2020-12-12", GenreIDs: []string{GenresData[0].ID},
+	})
+	resp := executeRequest(t, req, http.StatusCreated)
+	var movieID string
+	parseResponseBody(t, resp, &movieID)
+	resp.Body.Close()
+	movieURL := ts.URL + "/movies/" + movieID
+
+	fetch := func(url string, expectedStatus int) []byte {
+		t.Helper()
+		resp := executeRequest(t, createRequest(t, "GET", ts.URL+url, "", nil), expectedStatus)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return body
+	}
+	upload := func(kind string, data []byte) MovieMedia {
+		t.Helper()
+		resp := executeRequest(t, createUploadRequest(t, movieURL+"/media?kind="+kind, admin, data), http.StatusCreated)
+		defer resp.Body.Close()
+		var media MovieMedia
+		parseResponseBody(t, resp, &media)
+		return media
+	}
+
+	req = createRequest(t, "GET", movieURL+"/media", "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+
+	posterData := testImage(t, "png", 640, 960)
+	poster := upload("poster", posterData)
+	if got := fetch(poster.URL, http.StatusOK); !bytes.Equal(got, posterData) {
+		t.Errorf("Expected uploaded poster back, got %d bytes", len(got))
+	}
+	thumb, err := jpeg.Decode(bytes.NewReader(fetch(*poster.ThumbnailURL, http.StatusOK)))
+	if err != nil {
+		t.Fatalf("Expected JPEG thumbnail: %v", err)
+	}
+	if thumb.Bounds().Dx() != 213 || thumb.Bounds().Dy() != 320 {
+		t.Errorf("Expected 213x320 thumbnail, got %v", thumb.Bounds())
+	}
+
+	// Новый постер заменяет прежний вместе с файлами
+	newPoster := upload("poster", testImage(t, "jpeg", 500, 750))
+	fetch(poster.URL, http.StatusNotFound)
+	fetch(*poster.ThumbnailURL, http.StatusNotFound)
+	still := upload("still", testImage(t, "png", 300, 200))
+
+	req = createRequest(t, "POST", movieURL+"/media/trailer", user, MovieTrailerData{URL: "https://www.youtube.com/watch?v=EXeTwQWrcwY"})
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "POST", movieURL+"/media/trailer", admin, MovieTrailerData{URL: "javascript:alert(1)"})
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+	req = createRequest(t, "POST", movieURL+"/media/trailer", admin, MovieTrailerData{URL: "https://www.youtube.com/watch?v=EXeTwQWrcwY"})
+	resp = executeRequest(t, req, http.StatusCreated)
+	var trailer MovieMedia
+	parseResponseBody(t, resp, &trailer)
+	resp.Body.Close()
+	if trailer.Kind != MediaTrailer || trailer.URL != "https://www.youtube.com/watch?v=EXeTwQWrcwY" || trailer.ThumbnailURL != nil {
+		t.Errorf("Unexpected trailer %+v", trailer)
+	}
+
+	req = createRequest(t, "GET", movieURL, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var movie Movie
+	parseResponseBody(t, resp, &movie)
+	resp.Body.Close()
+	if len(movie.Media) != 3 || movie.Media[0].ID != newPoster.ID || movie.Media[1].ID != still.ID || movie.Media[2].ID != trailer.ID {
+		t.Fatalf("Expected poster, still and trailer in movie, got %+v", movie.Media)
+	}
+
+	req = createRequest(t, "DELETE", ts.URL+"/movie-media/"+still.ID, user, nil)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "DELETE", ts.URL+"/movie-media/"+still.ID, admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+	req = createRequest(t, "DELETE", ts.URL+"/movie-media/"+still.ID, admin, nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	fetch(still.URL, http.StatusNotFound)
+
+	// Удаление фильма убирает его файлы из хранилища
+	req = createRequest(t, "DELETE", movieURL, admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+	fetch(newPoster.URL, http.StatusNotFound)
+	fetch(*newPoster.ThumbnailURL, http.StatusNotFound)
+
+	fetch("/media/movies/../config.env", http.StatusNotFound)
+}
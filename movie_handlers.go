@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"log"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -45,6 +48,11 @@ func validateAllMovieData(w http.ResponseWriter, m MovieData) bool {
 		return false
 	}
 
+	if err := validateMovieCredits(m.Credits); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
 	return true
 }
 
@@ -55,6 +63,21 @@ func ValidateQuery(query string) error {
 	return nil
 }
 
+// fuzzySearchMaxWords — запросы не длиннее стольких слов дополнительно ищутся по сходству триграмм,
+// чтобы находить названия, набранные с опечаткой
+const fuzzySearchMaxWords = 2
+
+func isShortSearchQuery(query string) bool {
+	return len(strings.Fields(query)) <= fuzzySearchMaxWords
+}
+
+// sanitizeHighlight экранирует фрагмент ts_headline для HTML, оставляя только теги выделения <mark>
+func sanitizeHighlight(fragment string) string {
+	escaped := html.EscapeString(fragment)
+	escaped = strings.ReplaceAll(escaped, "&lt;mark&gt;", "<mark>")
+	return strings.ReplaceAll(escaped, "&lt;/mark&gt;", "</mark>")
+}
+
 func validateMovieTitle(title string) error {
 	if !regexp.MustCompile(`\S`).MatchString(title) {
 		return errors.New("название фильма не может быть пустым")
@@ -110,35 +133,183 @@ func validateReleaseDate(date string) error {
 	return nil
 }
 
+func validateMovieCredits(credits []MovieCreditData) error {
+	type creditKey struct {
+		personID string
+		role     CreditRoleEnumType
+	}
+	seen := make(map[creditKey]bool, len(credits))
+
+	for i := range credits {
+		c := &credits[i]
+		if _, err := uuid.Parse(c.PersonID); err != nil {
+			return fmt.Errorf("неверный формат ID человека: %s", c.PersonID)
+		}
+		if !c.Role.IsValid() {
+			return fmt.Errorf("неизвестная роль в фильме: %s", c.Role)
+		}
+		if seen[creditKey{c.PersonID, c.Role}] {
+			return errors.New("человек указан в одной роли несколько раз")
+		}
+		seen[creditKey{c.PersonID, c.Role}] = true
+
+		c.Character = PrepareStringPointer(c.Character)
+		if c.Character == nil {
+			continue
+		}
+		if c.Role != CreditActor {
+			return errors.New("персонаж указывается только для актёров")
+		}
+		if *c.Character == "" || utf8.RuneCountInString(*c.Character) > 200 {
+			return errors.New("имя персонажа не может быть пустым и не может превышать 200 символов")
+		}
+	}
+	return nil
+}
+
 // Вспомогательные функции
-func fetchGenresByMovieID(db *pgxpool.Pool, movieID string) ([]Genre, error) {
-	rows, err := db.Query(context.Background(), `
-		SELECT g.id, g.name, g.description
-		FROM genres g
-		JOIN movies_genres mg ON g.id = mg.genre_id
-		WHERE mg.movie_id = $1`, movieID)
-
-	// rows, err := db.Query(context.Background(), `
-	//     SELECT g.id, g.name, g.description
-	//     FROM genres g
-	//     WHERE EXISTS (
-	//         SELECT 1 FROM movies_genres mg
-	//         WHERE mg.movie_id = $1 AND mg.genre_id = g.id
-	//     )`, movieID)
+// replaceMovieCredits заменяет создателей фильма; порядок в списке задаёт порядок титров
+func replaceMovieCredits(ctx context.Context, tx pgx.Tx, movieID string, credits []MovieCreditData) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM movie_credits WHERE movie_id = $1", movieID); err != nil {
+		return err
+	}
+
+	for i, c := range credits {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO movie_credits (movie_id, person_id, role, character_name, billing_order)
+			VALUES ($1, $2, $3, $4, $5)`,
+			movieID, c.PersonID, c.Role, c.Character, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// movieColumns — столбцы фильма в порядке полей, которые читает scanMovie
+const movieColumns = "m.id, m.title, m.duration, m.description, m.age_limit, m.box_office_revenue, m.release_date"
+
+func scanMovie(row pgx.Row) (Movie, error) {
+	var m Movie
+	var releaseDate time.Time
+	if err := row.Scan(&m.ID, &m.Title, &m.Duration, &m.Description, &m.AgeLimit, &m.BoxOfficeRevenue, &releaseDate); err != nil {
+		return m, err
+	}
+	m.ReleaseDate = releaseDate.Format("2006-01-02")
+	return m, nil
+}
+
+// queueMovieDetail ставит в пакет запрос деталей для фильмов $1. Запрос выбирает movie_id
+// перед столбцами, которые читает scan; add добавляет прочитанное к фильму.
+func queueMovieDetail[T any](batch *pgx.Batch, query string, ids []string, byID map[string]*Movie,
+	scan func(pgx.Row) (T, error), add func(*Movie, T)) {
+	batch.Queue(query, ids).Query(func(rows pgx.Rows) error {
+		for rows.Next() {
+			var movieID string
+			item, err := scan(prefixedRow{row: rows, prefix: []any{&movieID}})
+			if err != nil {
+				return err
+			}
+			if m, ok := byID[movieID]; ok {
+				add(m, item)
+			}
+		}
+		return rows.Err()
+	})
+}
+
+// addMovieDetails дополняет фильмы жанрами, создателями, медиа и средней оценкой.
+// Детали всех фильмов выбираются четырьмя запросами, которые уходят в базу одним пакетом,
+// поэтому число обращений к базе не зависит от числа фильмов.
+func addMovieDetails(ctx context.Context, db *pgxpool.Pool, movies []Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(movies))
+	byID := make(map[string]*Movie, len(movies))
+	for i := range movies {
+		ids[i] = movies[i].ID
+		byID[movies[i].ID] = &movies[i]
+	}
+
+	batch := &pgx.Batch{}
+	queueMovieDetail(batch, `
+		SELECT mg.movie_id, g.id, g.name, g.description
+		FROM movies_genres mg
+		JOIN genres g ON g.id = mg.genre_id
+		WHERE mg.movie_id = ANY($1::uuid[])
+		ORDER BY g.name`, ids, byID,
+		func(row pgx.Row) (Genre, error) {
+			var g Genre
+			err := row.Scan(&g.ID, &g.Name, &g.Description)
+			return g, err
+		},
+		func(m *Movie, g Genre) { m.Genres = append(m.Genres, g) })
+
+	// Сначала актёры в порядке титров, затем режиссёры и сценаристы
+	queueMovieDetail(batch, `
+		SELECT c.movie_id, p.id, p.name, c.role, c.character_name
+		FROM movie_credits c
+		JOIN people p ON p.id = c.person_id
+		WHERE c.movie_id = ANY($1::uuid[])
+		ORDER BY c.role, c.billing_order, p.name`, ids, byID,
+		func(row pgx.Row) (MovieCredit, error) {
+			var c MovieCredit
+			err := row.Scan(&c.PersonID, &c.Name, &c.Role, &c.Character)
+			return c, err
+		},
+		func(m *Movie, c MovieCredit) { m.Credits = append(m.Credits, c) })
+
+	queueMovieDetail(batch, `
+		SELECT movie_id, `+movieMediaColumns+`
+		FROM movie_media
+		WHERE movie_id = ANY($1::uuid[])
+		ORDER BY kind, created_at, id`, ids, byID,
+		func(row pgx.Row) (MovieMedia, error) { return scanMovieMedia(row) },
+		func(m *Movie, media MovieMedia) { m.Media = append(m.Media, media) })
+
+	queueMovieDetail(batch, `
+		SELECT movie_id, AVG(rating)
+		FROM reviews
+		WHERE movie_id = ANY($1::uuid[])
+		GROUP BY movie_id`, ids, byID,
+		func(row pgx.Row) (*float64, error) {
+			var rating *float64
+			err := row.Scan(&rating)
+			return rating, err
+		},
+		func(m *Movie, rating *float64) { m.Rating = rating })
+
+	return db.SendBatch(ctx, batch).Close()
+}
+
+// fetchMoviesWithDetails выполняет запрос, выбирающий movieColumns, и дополняет фильмы деталями.
+// Это общий загрузчик фильмов: всего два обращения к базе при любом числе фильмов.
+func fetchMoviesWithDetails(ctx context.Context, db *pgxpool.Pool, query string, args ...any) ([]Movie, error) {
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var genres []Genre
+	var movies []Movie
 	for rows.Next() {
-		var g Genre
-		if err := rows.Scan(&g.ID, &g.Name, &g.Description); err != nil {
+		m, err := scanMovie(rows)
+		if err != nil {
 			return nil, err
 		}
-		genres = append(genres, g)
+		movies = append(movies, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
-	return genres, nil
+	rows.Close()
+
+	if err := addMovieDetails(ctx, db, movies); err != nil {
+		return nil, err
+	}
+	return movies, nil
 }
 
 func insertMovieGenres(tx pgx.Tx, movieID string, genreIDs []string) error {
@@ -188,61 +359,70 @@ func updateMovieGenres(tx pgx.Tx, ctx context.Context, movieID uuid.UUID, genreI
 	return err
 }
 
+var movieListSpec = listSpec{
+	ID: "m.id",
+	Sorts: map[string]listSortField{
+		"title":              {Expr: "m.title", Kind: listText},
+		"release_date":       {Expr: "m.release_date", Kind: listDate},
+		"age_limit":          {Expr: "m.age_limit", Kind: listInt},
+		"box_office_revenue": {Expr: "m.box_office_revenue", Kind: listNumeric},
+	},
+	DefaultSort: "title",
+	Filters: []listFilter{
+		{Param: "age_limit", Kind: listInt, Cond: "m.age_limit = $%[1]d"},
+		{Param: "max_age_limit", Kind: listInt, Cond: "m.age_limit <= $%[1]d"},
+		{Param: "release_date_from", Kind: listDate, Cond: "m.release_date >= $%[1]d"},
+		{Param: "release_date_to", Kind: listDate, Cond: "m.release_date <= $%[1]d"},
+		{Param: "genre_id", Kind: listUUID, Cond: "EXISTS (SELECT 1 FROM movies_genres mg WHERE mg.movie_id = m.id AND mg.genre_id = $%[1]d::uuid)"},
+	},
+}
+
+// recentMovieListSpec — списки фильмов, в которых по умолчанию первыми идут новые фильмы
+var recentMovieListSpec = listSpec{
+	ID:          movieListSpec.ID,
+	Sorts:       movieListSpec.Sorts,
+	DefaultSort: "-release_date",
+	Filters:     movieListSpec.Filters,
+}
+
+// fetchMoviePage выбирает страницу фильмов с учётом условий q и дополняет их деталями
+func fetchMoviePage(ctx context.Context, db *pgxpool.Pool, q *listQuery) (Page[Movie], error) {
+	page, err := fetchPage(ctx, db, q, movieColumns, "movies m", scanMovie)
+	if err != nil {
+		return page, err
+	}
+	return page, addMovieDetails(ctx, db, page.Items)
+}
+
 // @Summary Получить все фильмы (guest | user | admin)
-// @Description Возвращает список всех фильмов, содержащихся в базе данных.
+// @Description Возвращает страницу фильмов с жанрами, создателями, медиа и средней оценкой.
 // @Tags Фильмы
 // @Produce json
-// @Success 200 {array} Movie "Список фильмов"
-// @Failure 404 {object} ErrorResponse "Фильмы не найдены"
+// @Param age_limit query int false "Возрастное ограничение"
+// @Param max_age_limit query int false "Возрастное ограничение не выше"
+// @Param release_date_from query string false "Дата выхода не раньше (2006-01-02)"
+// @Param release_date_to query string false "Дата выхода не позже (2006-01-02)"
+// @Param genre_id query string false "ID жанра"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: title, release_date, age_limit, box_office_revenue; с префиксом - по убыванию"
+// @Success 200 {object} Page[Movie] "Страница фильмов"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movies [get]
 func GetMovies(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(context.Background(), `
-			SELECT id, title, duration, description, age_limit, box_office_revenue, release_date
-			FROM movies`)
-		if err != nil {
-			http.Error(w, "Ошибка при получении фильмов", http.StatusInternalServerError)
+		q, ok := parseListQuery(w, r, movieListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var movies []Movie
-		var releaseDate time.Time
-		for rows.Next() {
-			var m Movie
-			if err := rows.Scan(&m.ID, &m.Title, &m.Duration, &m.Description, &m.AgeLimit, &m.BoxOfficeRevenue, &releaseDate); err != nil {
-				http.Error(w, fmt.Sprintf("Ошибка при сканировании фильма %v", err), http.StatusInternalServerError)
-				return
-			}
-			m.ReleaseDate = releaseDate.Format("2006-01-02")
 
-			genres, err := fetchGenresByMovieID(db, m.ID)
-			if err != nil {
-				http.Error(w, "Ошибка при получении жанров", http.StatusInternalServerError)
-				return
-			}
-			m.Genres = genres
-
-			err = db.QueryRow(context.Background(), `
-				SELECT AVG(rating)
-				FROM reviews
-				WHERE movie_id = $1`, m.ID).Scan(&m.Rating)
-			if err != nil {
-				http.Error(w, "Ошибка при получении жанров", http.StatusInternalServerError)
-				return
-			}
-
-			movies = append(movies, m)
-		}
-
-		if len(movies) == 0 {
-			http.Error(w, "Фильмы не найдены", http.StatusNotFound)
+		page, err := fetchMoviePage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "фильмами") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(movies)
+		writePage(w, page)
 	}
 }
 
@@ -263,39 +443,22 @@ func GetMovieByID(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		var m Movie
-		var releaseDate time.Time
-		err := db.QueryRow(context.Background(), `
-			SELECT id, title, duration, description, age_limit, box_office_revenue, release_date
-			FROM movies WHERE id = $1`, id).
-			Scan(&m.ID, &m.Title, &m.Duration, &m.Description, &m.AgeLimit, &m.BoxOfficeRevenue, &releaseDate)
+		movies, err := fetchMoviesWithDetails(r.Context(), db, "SELECT "+movieColumns+" FROM movies m WHERE m.id = $1", id)
 		if IsError(w, err) {
 			return
 		}
-		m.ReleaseDate = releaseDate.Format("2006-01-02")
-
-		err = db.QueryRow(context.Background(), `
-			SELECT AVG(rating)
-			FROM reviews
-			WHERE movie_id = $1`, m.ID).Scan(&m.Rating)
-		if err != nil {
-			http.Error(w, "Ошибка при получении жанров", http.StatusInternalServerError)
-			return
-		}
-
-		genres, err := fetchGenresByMovieID(db, m.ID)
-		if IsError(w, err) {
+		if len(movies) == 0 {
+			http.Error(w, "Фильм не найден", http.StatusNotFound)
 			return
 		}
-		m.Genres = genres
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(m)
+		json.NewEncoder(w).Encode(movies[0])
 	}
 }
 
 // @Summary Создать фильм (admin)
-// @Description Создаёт новый фильм.
+// @Description Создаёт новый фильм с жанрами и создателями. Порядок создателей в credits задаёт порядок титров.
 // @Tags Фильмы
 // @Accept json
 // @Produce json
@@ -342,6 +505,10 @@ func CreateMovie(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if err := replaceMovieCredits(ctx, tx, id.String(), data.Credits); IsError(w, err) {
+			return
+		}
+
 		if err := tx.Commit(ctx); IsError(w, err) {
 			return
 		}
@@ -353,7 +520,8 @@ func CreateMovie(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Обновить фильм (admin)
-// @Description Обновляет существующий фильм.
+// @Description Обновляет существующий фильм. Если credits передан, список создателей фильма заменяется целиком,
+// @Description иначе остаётся прежним.
 // @Tags Фильмы
 // @Accept json
 // @Produce json
@@ -403,7 +571,18 @@ func UpdateMovie(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		_, err = db.Exec(r.Context(),
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		_, err = tx.Exec(ctx,
 			"CALL update_movie($1, $2, $3, $4, $5, $6, $7)",
 			id,
 			data.Title,
@@ -417,12 +596,22 @@ func UpdateMovie(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if data.Credits != nil {
+			if err := replaceMovieCredits(ctx, tx, id.String(), data.Credits); IsError(w, err) {
+				return
+			}
+		}
+
+		if IsError(w, tx.Commit(ctx)) {
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
 // @Summary Удалить фильм (admin)
-// @Description Удаляет фильм по ID.
+// @Description Удаляет фильм по ID вместе с его медиафайлами.
 // @Tags Фильмы
 // @Param id path string true "ID фильма"
 // @Security BearerAuth
@@ -440,6 +629,19 @@ func DeleteMovie(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		// Записи о медиа удалятся каскадно, а файлы нужно убрать из хранилища самим
+		rows, err := db.Query(r.Context(), `
+			SELECT storage_key FROM movie_media WHERE movie_id = $1 AND storage_key IS NOT NULL
+			UNION ALL
+			SELECT thumbnail_key FROM movie_media WHERE movie_id = $1 AND thumbnail_key IS NOT NULL`, id)
+		if IsError(w, err) {
+			return
+		}
+		mediaKeys, err := pgx.CollectRows(rows, pgx.RowTo[*string])
+		if IsError(w, err) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(), "DELETE FROM movies WHERE id = $1", id)
 		if IsError(w, err) {
 			return
@@ -447,72 +649,78 @@ func DeleteMovie(db *pgxpool.Pool) http.HandlerFunc {
 		if !CheckRowsAffected(w, res.RowsAffected()) {
 			return
 		}
+		deleteMediaObjects(r.Context(), mediaKeys...)
 
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
-// @Summary Поиск фильмов по названию (guest | user | admin)
-// @Description Возвращает фильмы, в названии которых содержится заданная строка.
+// movieSearchListSpec — результаты поиска фильмов упорядочены только по релевантности.
+// Ранг приводится к numeric, чтобы курсор сравнивался с ним точно, без погрешности real.
+var movieSearchListSpec = listSpec{
+	ID:          "s.movie_id",
+	Sorts:       map[string]listSortField{"rank": {Expr: "s.rank::numeric", Kind: listNumeric}},
+	DefaultSort: "-rank",
+}
+
+// @Summary Поиск фильмов (guest | user | admin)
+// @Description Полнотекстовый поиск по названию и описанию с учётом морфологии русского и английского языков.
+// @Description Синтаксис запроса как у поисковиков: "точная фраза", -исключить, or.
+// @Description Сначала идут совпадения по словам (по убыванию релевантности, название важнее описания),
+// @Description затем фильмы, в названии которых запрос встречается как подстрока. Запросы из одного-двух слов
+// @Description также находят похожие названия, чтобы прощать опечатки; такие фильмы отмечены fuzzy.
 // @Tags Фильмы
 // @Produce json
 // @Param query query string true "Поисковый запрос"
-// @Success 200 {array} Movie "Найденные фильмы"
-// @Failure 400 {object} ErrorResponse "Строка поиска пуста"
-// @Failure 404 {object} ErrorResponse "Данные не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: -rank (по умолчанию) или rank"
+// @Success 200 {object} Page[MovieSearchResult] "Страница найденных фильмов в порядке релевантности"
+// @Failure 400 {object} ErrorResponse "Строка поиска пуста или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movies/by-title/search [get]
 func SearchMovies(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		query := r.URL.Query().Get("query")
+		query := PrepareString(r.URL.Query().Get("query"))
 		if err := ValidateQuery(query); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		rows, err := db.Query(context.Background(), `
-			SELECT id, title, duration, description, age_limit, box_office_revenue, release_date
-			FROM movies WHERE title ILIKE $1`, "%"+query+"%")
-		if IsError(w, err) {
+		q, ok := parseListQuery(w, r, movieSearchListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var movies []Movie
-		var release_date time.Time
-		for rows.Next() {
-			var m Movie
-			err := rows.Scan(&m.ID, &m.Title, &m.Duration, &m.Description, &m.AgeLimit, &m.BoxOfficeRevenue, &release_date)
-			if IsError(w, err) {
-				return
-			}
-			m.ReleaseDate = release_date.Format("2006-01-02")
-
-			genres, err := fetchGenresByMovieID(db, m.ID)
-			if IsError(w, err) {
-				return
-			}
-			m.Genres = genres
-
-			err = db.QueryRow(context.Background(), `
-				SELECT AVG(rating)
-				FROM reviews
-				WHERE movie_id = $1`, m.ID).Scan(&m.Rating)
-			if err != nil {
-				http.Error(w, "Ошибка при получении жанров", http.StatusInternalServerError)
-				return
-			}
+		n := q.param(query, isShortSearchQuery(query))
+		from := fmt.Sprintf("search_movies($%d, $%d) s JOIN movies m ON m.id = s.movie_id", n, n+1)
 
-			movies = append(movies, m)
+		ctx := r.Context()
+		page, err := fetchPage(ctx, db, q, "s.rank, s.title_highlight, s.description_highlight, s.fuzzy, "+movieColumns, from,
+			func(row pgx.Row) (MovieSearchResult, error) {
+				var res MovieSearchResult
+				var err error
+				res.Movie, err = scanMovie(prefixedRow{row: row,
+					prefix: []any{&res.Rank, &res.TitleHighlight, &res.DescriptionHighlight, &res.Fuzzy}})
+				res.TitleHighlight = sanitizeHighlight(res.TitleHighlight)
+				res.DescriptionHighlight = sanitizeHighlight(res.DescriptionHighlight)
+				return res, err
+			})
+		if HandleDatabaseError(w, err, "фильмами") {
+			return
 		}
 
-		if len(movies) == 0 {
-			http.Error(w, "Фильмы не найдены", http.StatusNotFound)
+		movies := make([]Movie, len(page.Items))
+		for i := range page.Items {
+			movies[i] = page.Items[i].Movie
+		}
+		if HandleDatabaseError(w, addMovieDetails(ctx, db, movies), "фильмами") {
 			return
 		}
+		for i := range page.Items {
+			page.Items[i].Movie = movies[i]
+		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(movies)
+		writePage(w, page)
 	}
 }
 
@@ -521,9 +729,11 @@ func SearchMovies(db *pgxpool.Pool) http.HandlerFunc {
 // @Tags Фильмы
 // @Produce json
 // @Param genre_ids query []string true "Список ID жанров" collectionFormat(multi)
-// @Success 200 {array} Movie "Найденные фильмы"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: title, release_date, age_limit, box_office_revenue; с префиксом - по убыванию"
+// @Success 200 {object} Page[Movie] "Страница найденных фильмов"
 // @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
-// @Failure 404 {object} ErrorResponse "Жанры не найдены"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movies/by-genres/search [get]
 func GetMoviesByAllGenres(db *pgxpool.Pool) http.HandlerFunc {
@@ -543,60 +753,77 @@ func GetMoviesByAllGenres(db *pgxpool.Pool) http.HandlerFunc {
 			validGenreIDs = append(validGenreIDs, id)
 		}
 
-		rows, err := db.Query(context.Background(), `
-			SELECT *
-			FROM movies
-			WHERE id IN (
-				SELECT movie_id
-				FROM movies_genres
-				WHERE genre_id = ANY($1::uuid[])
-				GROUP BY movie_id
-        		HAVING COUNT(DISTINCT genre_id) = $2
-			)
-			ORDER BY title`, validGenreIDs, len(validGenreIDs))
-		if err != nil {
-			http.Error(w, "Ошибка при получении фильмов: "+err.Error(), http.StatusInternalServerError)
+		q, ok := parseListQuery(w, r, movieListSpec)
+		if !ok {
+			return
+		}
+		q.and(func(n int) string {
+			return fmt.Sprintf(`
+		AND m.id IN (
+			SELECT movie_id
+			FROM movies_genres
+			WHERE genre_id = ANY($%d::uuid[])
+			GROUP BY movie_id
+			HAVING COUNT(DISTINCT genre_id) = $%d
+		)`, n, n+1)
+		}, validGenreIDs, len(validGenreIDs))
+
+		page, err := fetchMoviePage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "фильмами") {
 			return
 		}
-		defer rows.Close()
-
-		var movies []Movie
-		var release_date time.Time
-		for rows.Next() {
-			var m Movie
-			if err := rows.Scan(&m.ID, &m.Title, &m.Duration, &m.Description,
-				&m.AgeLimit, &m.BoxOfficeRevenue, &release_date); err != nil {
-				http.Error(w, "Ошибка при сканировании фильма", http.StatusInternalServerError)
-				return
-			}
-			m.ReleaseDate = release_date.Format("2006-01-02")
 
-			genres, err := fetchGenresByMovieID(db, m.ID)
-			if err != nil {
-				http.Error(w, "Ошибка при получении жанров", http.StatusInternalServerError)
-				return
-			}
-			m.Genres = genres
+		writePage(w, page)
+	}
+}
 
-			err = db.QueryRow(context.Background(), `
-				SELECT AVG(rating)
-				FROM reviews
-				WHERE movie_id = $1`, m.ID).Scan(&m.Rating)
-			if err != nil {
-				println(err.Error())
-				http.Error(w, "Ошибка при получении жанров", http.StatusInternalServerError)
-				return
-			}
+// @Summary Поиск фильмов по создателю (guest | user | admin)
+// @Description Возвращает фильмы, в создании которых участвовал человек, чьё имя содержит заданную строку.
+// @Description С role учитываются только актёры, режиссёры или сценаристы. По умолчанию новые фильмы идут первыми.
+// @Tags Фильмы
+// @Produce json
+// @Param query query string true "Часть имени человека"
+// @Param role query string false "Роль в фильме" Enums(actor, director, writer)
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: title, release_date, age_limit, box_office_revenue; с префиксом - по убыванию"
+// @Success 200 {object} Page[Movie] "Страница найденных фильмов"
+// @Failure 400 {object} ErrorResponse "Строка поиска пуста, неизвестная роль или неверные параметры списка"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movies/by-person/search [get]
+func SearchMoviesByPerson(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := PrepareString(r.URL.Query().Get("query"))
+		if err := ValidateQuery(query); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-			movies = append(movies, m)
+		role, ok := parseCreditRoleQuery(w, r)
+		if !ok {
+			return
 		}
 
-		if len(movies) == 0 {
-			http.Error(w, "Фильмы не найдены", http.StatusNotFound)
+		q, ok := parseListQuery(w, r, recentMovieListSpec)
+		if !ok {
+			return
+		}
+		q.and(func(n int) string {
+			return fmt.Sprintf(`
+		AND EXISTS (
+			SELECT 1
+			FROM movie_credits c
+			JOIN people p ON p.id = c.person_id
+			WHERE c.movie_id = m.id AND p.name ILIKE $%[1]d
+			AND ($%[2]d::credit_role_enum IS NULL OR c.role = $%[2]d)
+		)`, n, n+1)
+		}, "%"+query+"%", role)
+
+		page, err := fetchMoviePage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "фильмами") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(movies)
+		writePage(w, page)
 	}
 }
@@ -2,15 +2,20 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func TestGetMovies(t *testing.T) {
@@ -20,9 +25,9 @@ func TestGetMovies(t *testing.T) {
 		role           string
 		expectedStatus int
 	}{
-		{"Empty as Guest", false, "", http.StatusNotFound},
-		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Guest", false, "", http.StatusOK},
+		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusOK},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -43,10 +48,14 @@ func TestGetMovies(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var movies []Movie
-				parseResponseBody(t, resp, &movies)
+				var page Page[Movie]
+				parseResponseBody(t, resp, &page)
+				movies := page.Items
 
-				if len(movies) == 0 {
+				if !tt.seedData && (movies == nil || len(movies) != 0) {
+					t.Errorf("Expected empty movies list, got %v", movies)
+				}
+				if tt.seedData && len(movies) == 0 {
 					t.Error("Expected non-empty movies list")
 				}
 			}
@@ -779,19 +788,25 @@ func TestSearchMovies(t *testing.T) {
 			"Нет результатов",
 			"Несуществующий фильм",
 			[]string{},
-			http.StatusNotFound,
+			http.StatusOK,
 		},
 		{
-			"Поиск по описанию (не должен находить)",
-			"червоточину",
-			[]string{},
-			http.StatusNotFound,
+			"Поиск по описанию с учётом морфологии",
+			"червоточина",
+			[]string{"Интерстеллар"},
+			http.StatusOK,
+		},
+		{
+			"Опечатка в коротком запросе",
+			"Интерстелар",
+			[]string{"Интерстеллар"},
+			http.StatusOK,
 		},
 		{
 			"Поиск с SQL-инъекцией",
 			"; DROP TABLE movies;--",
 			[]string{},
-			http.StatusNotFound,
+			http.StatusOK,
 		},
 	}
 
@@ -806,8 +821,9 @@ func TestSearchMovies(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var movies []Movie
-				parseResponseBody(t, resp, &movies)
+				var page Page[MovieSearchResult]
+				parseResponseBody(t, resp, &page)
+				movies := page.Items
 
 				// Проверяем количество результатов
 				if len(movies) != len(tt.expectedTitles) {
@@ -868,6 +884,99 @@ func TestSearchMovies(t *testing.T) {
 	})
 }
 
+func TestSanitizeHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		fragment string
+		expected string
+	}{
+		{"Marks are kept", "<mark>Довод</mark> и время", "<mark>Довод</mark> и время"},
+		{"Markup is escaped", "<script>alert(1)</script> <mark>книга</mark>", "&lt;script&gt;alert(1)&lt;/script&gt; <mark>книга</mark>"},
+		{"Quotes are escaped", `"Кин-дза-дза" & co`, "&#34;Кин-дза-дза&#34; &amp; co"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeHighlight(tt.fragment); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsShortSearchQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"Интерстелар", true},
+		{"темный рыцарь", true},
+		{"фильм про путешествие в космос", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := isShortSearchQuery(tt.query); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearchMoviesRelevance(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	for _, m := range []MovieData{
+		{Title: "Одиссея", Description: "Фильм о звёздном пути человечества.", Duration: "02:00:00", AgeLimit: 12, ReleaseDate: "2020-01-01", GenreIDs: []string{GenresData[3].ID}},
+		{Title: "Звёздный путь", Description: "Экипаж корабля исследует космос.", Duration: "02:00:00", AgeLimit: 12, ReleaseDate: "2020-01-01", GenreIDs: []string{GenresData[3].ID}},
+	} {
+		executeRequest(t, createRequest(t, "POST", ts.URL+"/movies", admin, m), http.StatusCreated).Body.Close()
+	}
+
+	search := func(query string) []MovieSearchResult {
+		t.Helper()
+		req := createRequest(t, "GET", ts.URL+"/movies/by-title/search?query="+url.QueryEscape(query), "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+		var page Page[MovieSearchResult]
+		parseResponseBody(t, resp, &page)
+		return page.Items
+	}
+
+	// Совпадение в названии весит больше, чем в описании
+	results := search("звёздный путь")
+	if len(results) != 2 || results[0].Title != "Звёздный путь" || results[1].Title != "Одиссея" || results[0].Rank <= results[1].Rank {
+		t.Fatalf("Expected title match to rank first, got %+v", results)
+	}
+	if results[0].TitleHighlight != "<mark>Звёздный</mark> <mark>путь</mark>" {
+		t.Errorf("Unexpected title highlight %q", results[0].TitleHighlight)
+	}
+	if !strings.Contains(results[1].DescriptionHighlight, "<mark>звёздном</mark> <mark>пути</mark>") {
+		t.Errorf("Unexpected description highlight %q", results[1].DescriptionHighlight)
+	}
+
+	results = search("червоточина")
+	if len(results) != 1 || results[0].Fuzzy || !strings.Contains(results[0].DescriptionHighlight, "<mark>червоточину</mark>") {
+		t.Errorf("Expected description match with highlight, got %+v", results)
+	}
+	if len(results) == 1 && (len(results[0].Genres) == 0 || results[0].Duration == "") {
+		t.Errorf("Expected full movie details, got %+v", results[0].Movie)
+	}
+
+	// Подстрока названия идёт после совпадений по словам, опечатка — последней
+	results = search("Интерстелар")
+	if len(results) != 1 || !results[0].Fuzzy || results[0].Title != "Интерстеллар" {
+		t.Errorf("Expected fuzzy match, got %+v", results)
+	}
+	results = search("стеллар")
+	if len(results) != 1 || results[0].Fuzzy || results[0].Rank <= 1 {
+		t.Errorf("Expected substring match, got %+v", results)
+	}
+}
+
 func TestGetMoviesByAllGenres(t *testing.T) {
 	// Настройка тестовых данных
 	setupTestData := func(t *testing.T) (*httptest.Server, []string) {
@@ -928,14 +1037,14 @@ func TestGetMoviesByAllGenres(t *testing.T) {
 		{
 			"Несуществующие жанры",
 			[]string{uuid.New().String(), uuid.New().String()},
-			http.StatusNotFound,
+			http.StatusOK,
 			0,
 			nil,
 		},
 		{
 			"Часть жанров не существует",
 			nil,
-			http.StatusNotFound,
+			http.StatusOK,
 			0,
 			func(t *testing.T) []string {
 				ts, genres := setupTestData(t)
@@ -984,8 +1093,9 @@ func TestGetMoviesByAllGenres(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var movies []Movie
-				parseResponseBody(t, resp, &movies)
+				var page Page[Movie]
+				parseResponseBody(t, resp, &page)
+				movies := page.Items
 
 				if len(movies) != tt.expectedCount {
 					t.Errorf("Expected %d movies, got %d", tt.expectedCount, len(movies))
@@ -1053,3 +1163,266 @@ func TestGetMoviesByAllGenres(t *testing.T) {
 		}
 	})
 }
+
+// roundTripCounter считает обращения к базе: отдельные запросы и пакеты запросов
+type roundTripCounter struct {
+	n atomic.Int64
+}
+
+func (c *roundTripCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	c.n.Add(1)
+	return ctx
+}
+
+func (c *roundTripCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+func (c *roundTripCounter) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	c.n.Add(1)
+	return ctx
+}
+
+func (c *roundTripCounter) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+func (c *roundTripCounter) TraceBatchEnd(context.Context, *pgx.Conn, pgx.TraceBatchEndData) {}
+
+func newCountingDB(tb testing.TB) (*pgxpool.Pool, *roundTripCounter) {
+	tb.Helper()
+	config, err := pgxpool.ParseConfig(fmt.Sprintf("user=%s dbname=%s password=%s sslmode=disable",
+		os.Getenv("TEST_ADMIN_USER"),
+		os.Getenv("TEST_DB_NAME"),
+		os.Getenv("TEST_ADMIN_PASSWORD")))
+	if err != nil {
+		tb.Fatalf("Failed to parse database config: %v", err)
+	}
+	counter := &roundTripCounter{}
+	config.ConnConfig.Tracer = counter
+
+	db, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		tb.Fatalf("Failed to connect to database: %v", err)
+	}
+	tb.Cleanup(db.Close)
+	return db, counter
+}
+
+func setupMovieCatalogue(tb testing.TB, n int) {
+	tb.Helper()
+	if err := ClearAll(TestAdminDB); err != nil {
+		tb.Fatal(err)
+	}
+	if err := SeedAll(TestAdminDB); err != nil {
+		tb.Fatal(err)
+	}
+	if err := SeedMovieCatalogue(TestAdminDB, n); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func loadMovieRows(tb testing.TB, db *pgxpool.Pool, limit int) []Movie {
+	tb.Helper()
+	rows, err := db.Query(context.Background(), "SELECT "+movieColumns+" FROM movies m ORDER BY m.title LIMIT $1", limit)
+	if err != nil {
+		tb.Fatalf("Failed to query movies: %v", err)
+	}
+	movies, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Movie, error) { return scanMovie(row) })
+	if err != nil {
+		tb.Fatalf("Failed to scan movies: %v", err)
+	}
+	return movies
+}
+
+func collectDetail[T any](rows pgx.Rows, err error, scan func(pgx.Row) (T, error)) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// addMovieDetailsPerMovie — прежняя загрузка деталей четырьмя запросами на каждый фильм.
+// Служит эталоном результата и точкой сравнения в бенчмарках.
+func addMovieDetailsPerMovie(ctx context.Context, db *pgxpool.Pool, movies []Movie) error {
+	var err error
+	for i := range movies {
+		m := &movies[i]
+		rows, qerr := db.Query(ctx, `
+			SELECT g.id, g.name, g.description
+			FROM genres g JOIN movies_genres mg ON g.id = mg.genre_id
+			WHERE mg.movie_id = $1 ORDER BY g.name`, m.ID)
+		m.Genres, err = collectDetail(rows, qerr, func(row pgx.Row) (Genre, error) {
+			var g Genre
+			err := row.Scan(&g.ID, &g.Name, &g.Description)
+			return g, err
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, qerr = db.Query(ctx, `
+			SELECT p.id, p.name, c.role, c.character_name
+			FROM movie_credits c JOIN people p ON p.id = c.person_id
+			WHERE c.movie_id = $1 ORDER BY c.role, c.billing_order, p.name`, m.ID)
+		m.Credits, err = collectDetail(rows, qerr, func(row pgx.Row) (MovieCredit, error) {
+			var c MovieCredit
+			err := row.Scan(&c.PersonID, &c.Name, &c.Role, &c.Character)
+			return c, err
+		})
+		if err != nil {
+			return err
+		}
+
+		if m.Media, err = fetchMediaByMovieID(db, m.ID); err != nil {
+			return err
+		}
+		if err := db.QueryRow(ctx, "SELECT AVG(rating) FROM reviews WHERE movie_id = $1", m.ID).Scan(&m.Rating); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestAddMovieDetails(t *testing.T) {
+	setupMovieCatalogue(t, 40)
+	ctx := context.Background()
+
+	batched := loadMovieRows(t, TestAdminDB, 1000)
+	perMovie := loadMovieRows(t, TestAdminDB, 1000)
+	if err := addMovieDetails(ctx, TestAdminDB, batched); err != nil {
+		t.Fatalf("Batched loader failed: %v", err)
+	}
+	if err := addMovieDetailsPerMovie(ctx, TestAdminDB, perMovie); err != nil {
+		t.Fatalf("Per-movie loader failed: %v", err)
+	}
+	if len(batched) != len(MoviesData)+40 {
+		t.Fatalf("Expected %d movies, got %d", len(MoviesData)+40, len(batched))
+	}
+	for i := range batched {
+		if !reflect.DeepEqual(batched[i], perMovie[i]) {
+			t.Errorf("Movie %s differs:\nbatched:   %+v\nper-movie: %+v", batched[i].Title, batched[i], perMovie[i])
+		}
+	}
+
+	if err := addMovieDetails(ctx, TestAdminDB, nil); err != nil {
+		t.Errorf("Expected empty list to be a no-op, got %v", err)
+	}
+}
+
+func TestMovieLoaderRoundTrips(t *testing.T) {
+	setupMovieCatalogue(t, 200)
+	db, counter := newCountingDB(t)
+	rows, _ := TestAdminDB.Query(context.Background(), "SELECT id::text FROM genres ORDER BY name LIMIT 2")
+	genres, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil || len(genres) != 2 {
+		t.Fatalf("Failed to get catalogue genres: %v", err)
+	}
+
+	// Число обращений к базе не зависит от числа фильмов: запрос фильмов (у поиска — ещё и ранжирование)
+	// и один пакет запросов деталей
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		url     string
+		minimum int
+	}{
+		{"GetMovies small page", GetMovies(db), "/movies?limit=5", 5},
+		{"GetMovies large page", GetMovies(db), "/movies?limit=200", 200},
+		{"SearchMovies", SearchMovies(db), "/movies/by-title/search?limit=200&query=" + url.QueryEscape("каталог"), 200},
+		{"GetMoviesByAllGenres", GetMoviesByAllGenres(db), "/movies/by-genres/search?genre_ids=" + genres[0] + "&genre_ids=" + genres[1] + "&limit=200", 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter.n.Store(0)
+			w := httptest.NewRecorder()
+			tt.handler(w, httptest.NewRequest("GET", tt.url, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if n := strings.Count(w.Body.String(), `"genres":[`); n < tt.minimum {
+				t.Errorf("Expected at least %d movies with genres, got %d", tt.minimum, n)
+			}
+			if got := counter.n.Load(); got > 3 {
+				t.Errorf("Expected at most 3 round trips, got %d", got)
+			}
+		})
+	}
+}
+
+// BenchmarkMovieDetails сравнивает загрузку деталей пакетом и по фильму на каталоге из 1000 фильмов;
+// roundtrips/op — число обращений к базе на одну загрузку
+func BenchmarkMovieDetails(b *testing.B) {
+	setupMovieCatalogue(b, 1000)
+	db, counter := newCountingDB(b)
+	ctx := context.Background()
+
+	loaders := []struct {
+		name string
+		load func(context.Context, *pgxpool.Pool, []Movie) error
+	}{
+		{"batched", addMovieDetails},
+		{"per_movie", addMovieDetailsPerMovie},
+	}
+
+	for _, size := range []int{50, 200, 1000} {
+		movies := loadMovieRows(b, db, size)
+		for _, loader := range loaders {
+			b.Run(fmt.Sprintf("%s_%d", loader.name, size), func(b *testing.B) {
+				batch := make([]Movie, len(movies))
+				counter.n.Store(0)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					copy(batch, movies)
+					if err := loader.load(ctx, db, batch); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(counter.n.Load())/float64(b.N), "roundtrips/op")
+			})
+		}
+	}
+}
+
+// BenchmarkMovieListEndpoints измеряет обработчики списков фильмов на каталоге из 1000 фильмов
+func BenchmarkMovieListEndpoints(b *testing.B) {
+	setupMovieCatalogue(b, 1000)
+	db, counter := newCountingDB(b)
+
+	rows, _ := TestAdminDB.Query(context.Background(), "SELECT id::text FROM genres ORDER BY name LIMIT 2")
+	genres, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil || len(genres) != 2 {
+		b.Fatalf("Failed to get catalogue genres: %v", err)
+	}
+
+	benchmarks := []struct {
+		name    string
+		handler http.HandlerFunc
+		url     string
+	}{
+		{"GetMovies", GetMovies(db), "/movies?limit=200"},
+		{"SearchMovies", SearchMovies(db), "/movies/by-title/search?limit=200&query=" + url.QueryEscape("каталог")},
+		{"GetMoviesByAllGenres", GetMoviesByAllGenres(db), "/movies/by-genres/search?genre_ids=" + genres[0] + "&genre_ids=" + genres[1] + "&limit=200"},
+	}
+
+	for _, bb := range benchmarks {
+		b.Run(bb.name, func(b *testing.B) {
+			counter.n.Store(0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				bb.handler(w, httptest.NewRequest("GET", bb.url, nil))
+				if w.Code != http.StatusOK {
+					b.Fatalf("Unexpected status %d: %s", w.Code, w.Body.String())
+				}
+			}
+			b.ReportMetric(float64(counter.n.Load())/float64(b.N), "roundtrips/op")
+		})
+	}
+}
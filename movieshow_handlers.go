@@ -3,15 +3,56 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// validateShowLanguage проверяет только форму названия; наличие языка
+// в справочнике проверяет внешний ключ movie_show_language_fkey.
+func validateShowLanguage(language string) error {
+	if PrepareString(language) == "" {
+		return errors.New("не указан язык киносеанса")
+	}
+	if utf8.RuneCountInString(language) > 50 {
+		return errors.New("название языка не может превышать 50 символов")
+	}
+	return nil
+}
+
+func validateShowAttributes(w http.ResponseWriter, language string, subtitleLanguage *string, formatIDs []string) bool {
+	if err := validateShowLanguage(language); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if subtitleLanguage != nil {
+		if err := validateShowLanguage(*subtitleLanguage); err != nil {
+			http.Error(w, "Язык субтитров указан неверно", http.StatusBadRequest)
+			return false
+		}
+	}
+
+	for _, id := range formatIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			http.Error(w, "Неверный формат ID формата показа", http.StatusBadRequest)
+			return false
+		}
+	}
+
+	return true
+}
+
 func validateMovieShowAdmin(w http.ResponseWriter, ms MovieShowAdmin) bool {
 	if _, err := uuid.Parse(ms.MovieID); err != nil {
 		http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
@@ -29,8 +70,7 @@ func validateMovieShowAdmin(w http.ResponseWriter, ms MovieShowAdmin) bool {
 		return false
 	}
 
-	if !ms.Language.IsValid() {
-		http.Error(w, "Неизвестный язык киносеанса", http.StatusBadRequest)
+	if !validateShowAttributes(w, ms.Language, ms.SubtitleLanguage, ms.FormatIDs) {
 		return false
 	}
 
@@ -39,9 +79,139 @@ func validateMovieShowAdmin(w http.ResponseWriter, ms MovieShowAdmin) bool {
 		return false
 	}
 
+	if ms.Status != "" && ms.Status != ShowScheduled && ms.Status != ShowOnSale {
+		http.Error(w, "Новый киносеанс может быть только в статусе scheduled или on_sale", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func (ms MovieShowAdmin) initialStatus() ShowStatusEnumType {
+	if ms.Status == "" {
+		return ShowOnSale
+	}
+	return ms.Status
+}
+
+// formatIDs возвращает непустой массив, чтобы функция не получила NULL вместо '{}'
+func (ms MovieShowAdmin) formatIDs() []string {
+	if ms.FormatIDs == nil {
+		return []string{}
+	}
+	return ms.FormatIDs
+}
+
+// localize переводит время сеанса в часовой пояс его зала
+func (ms *MovieShow) localize() {
+	ms.StartTime = InTimeZone(ms.StartTime, ms.TimeZone)
+	if ms.CancelledAt != nil {
+		cancelledAt := InTimeZone(*ms.CancelledAt, ms.TimeZone)
+		ms.CancelledAt = &cancelledAt
+	}
+}
+
+// isShowError — IsError для записи сеанса в зал hallID: время начала конфликтующего
+// сеанса в ошибке пересечения выводится в часовом поясе этого зала
+func isShowError(ctx context.Context, w http.ResponseWriter, db *pgxpool.Pool, hallID string, err error) bool {
+	if !isExclusionViolation(err) {
+		return IsError(w, err)
+	}
+
+	var timeZone string
+	if err := db.QueryRow(ctx, "SELECT time_zone FROM halls WHERE id = $1", hallID).Scan(&timeZone); err != nil {
+		log.Printf("failed to load hall time zone: %v", err)
+	}
+
+	msg, ok := exclusionConflictMessage(err, timeZone)
+	if !ok {
+		return IsError(w, err)
+	}
+	http.Error(w, msg, http.StatusConflict)
 	return true
 }
 
+// movieShowAttributeColumns — язык субтитров и названия форматов сеанса ms
+const movieShowAttributeColumns = `ms.subtitle_language,
+	ARRAY(SELECT f.name FROM movie_show_formats msf JOIN show_formats f ON f.id = msf.format_id
+		WHERE msf.movie_show_id = ms.id ORDER BY f.name)`
+
+// showListFilter — необязательные фильтры списков сеансов: кинотеатр, язык, субтитры, формат показа
+// и наличие свободного места для коляски
+type showListFilter struct {
+	CinemaID         *string
+	Language         *string
+	SubtitleLanguage *string
+	Format           *string
+	Accessible       bool
+}
+
+func parseShowListFilter(w http.ResponseWriter, r *http.Request) (showListFilter, bool) {
+	param := func(name string) *string {
+		if v := PrepareString(r.URL.Query().Get(name)); v != "" {
+			return &v
+		}
+		return nil
+	}
+
+	cinemaID, ok := parseCinemaFilter(w, r)
+	if !ok {
+		return showListFilter{}, false
+	}
+	accessible, ok := parseAccessibleFilter(w, r)
+	return showListFilter{
+		CinemaID:         cinemaID,
+		Language:         param("language"),
+		SubtitleLanguage: param("subtitle_language"),
+		Format:           param("format"),
+		Accessible:       accessible,
+	}, ok
+}
+
+// where возвращает условие для сеанса ms в зале h; значения фильтра передаются
+// параметрами запроса с номерами от first до first+4.
+func (f showListFilter) where(first int) string {
+	return fmt.Sprintf(`
+		AND ($%[1]d::uuid IS NULL OR h.cinema_id = $%[1]d)
+		AND ($%[2]d::text IS NULL OR ms.language = $%[2]d)
+		AND ($%[3]d::text IS NULL OR ms.subtitle_language = $%[3]d)
+		AND ($%[4]d::text IS NULL OR EXISTS (
+			SELECT 1 FROM movie_show_formats msf JOIN show_formats f ON f.id = msf.format_id
+			WHERE msf.movie_show_id = ms.id AND f.name = $%[4]d))
+		AND (NOT $%[5]d::boolean OR EXISTS (
+			SELECT 1 FROM movie_show_tickets t JOIN seats s ON s.id = t.seat_id
+			WHERE t.movie_show_id = ms.id AND t.ticket_status = 'Available' AND s.wheelchair_space))`,
+		first, first+1, first+2, first+3, first+4)
+}
+
+func (f showListFilter) args() []any {
+	return []any{f.CinemaID, f.Language, f.SubtitleLanguage, f.Format, f.Accessible}
+}
+
+// parseShowStatusFilter разбирает параметр status — список статусов через запятую.
+// Без параметра возвращаются все сеансы, кроме отменённых.
+func parseShowStatusFilter(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	param := r.URL.Query().Get("status")
+	if param == "" {
+		return []string{
+			string(ShowScheduled), string(ShowOnSale), string(ShowSoldOut),
+			string(ShowInProgress), string(ShowFinished),
+		}, true
+	}
+
+	var statuses []string
+	for _, s := range strings.Split(param, ",") {
+		status := ShowStatusEnumType(strings.TrimSpace(s))
+		if !status.IsValid() {
+			http.Error(w, fmt.Sprintf("Неизвестный статус киносеанса: %s", s), http.StatusBadRequest)
+			return nil, false
+		}
+		statuses = append(statuses, string(status))
+	}
+
+	return statuses, true
+}
+
 func validateMovieShowData(w http.ResponseWriter, ms MovieShowData) bool {
 	if _, err := uuid.Parse(ms.MovieID); err != nil {
 		http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
@@ -59,51 +229,99 @@ func validateMovieShowData(w http.ResponseWriter, ms MovieShowData) bool {
 		return false
 	}
 
-	if !ms.Language.IsValid() {
-		http.Error(w, "Неизвестный язык киносеанса", http.StatusBadRequest)
+	if !validateShowAttributes(w, ms.Language, ms.SubtitleLanguage, ms.FormatIDs) {
 		return false
 	}
 
 	return true
 }
 
+var movieShowListSpec = listSpec{
+	ID:          "ms.id",
+	Sorts:       map[string]listSortField{"start_time": {Expr: "ms.start_time", Kind: listTime}},
+	DefaultSort: "start_time",
+	Filters: []listFilter{
+		{Param: "movie_id", Kind: listUUID, Cond: "ms.movie_id = $%[1]d::uuid"},
+		{Param: "hall_id", Kind: listUUID, Cond: "ms.hall_id = $%[1]d::uuid"},
+		{Param: "start_from", Kind: listTime, Cond: "ms.start_time >= $%[1]d"},
+		{Param: "start_to", Kind: listTime, Cond: "ms.start_time <= $%[1]d"},
+	},
+}
+
+// parseShowListQuery разбирает параметры списка сеансов вместе с фильтрами по статусу и атрибутам показа
+func parseShowListQuery(w http.ResponseWriter, r *http.Request) (*listQuery, bool) {
+	statuses, ok := parseShowStatusFilter(w, r)
+	if !ok {
+		return nil, false
+	}
+	filter, ok := parseShowListFilter(w, r)
+	if !ok {
+		return nil, false
+	}
+	q, ok := parseListQuery(w, r, movieShowListSpec)
+	if !ok {
+		return nil, false
+	}
+	q.where("ms.status::text = ANY($%[1]d)", statuses)
+	q.and(filter.where, filter.args()...)
+	return q, true
+}
+
+// fetchShowPage выбирает страницу сеансов с временем в часовом поясе их зала
+func fetchShowPage(ctx context.Context, db *pgxpool.Pool, q *listQuery) (Page[MovieShow], error) {
+	return fetchPage(ctx, db, q,
+		"ms.id, ms.movie_id, ms.hall_id, ms.start_time, h.time_zone, ms.language, "+movieShowAttributeColumns+", ms.status",
+		"movie_shows ms JOIN halls h ON h.id = ms.hall_id",
+		func(row pgx.Row) (MovieShow, error) {
+			var ms MovieShow
+			if err := row.Scan(&ms.ID, &ms.MovieID, &ms.HallID, &ms.StartTime, &ms.TimeZone, &ms.Language, &ms.SubtitleLanguage, &ms.Formats, &ms.Status); err != nil {
+				return ms, err
+			}
+			ms.localize()
+			return ms, nil
+		})
+}
+
 // @Summary Получить все киносеансы (guest | user | admin)
 // @Description Возвращает список всех киносеансов, хранящихся в базе данных.
+// @Description По умолчанию отменённые сеансы не возвращаются.
 // @Tags Киносеансы
 // @Produce json
-// @Success 200 {array} MovieShow "Список киносеансов"
-// @Failure 404 {object} ErrorResponse "Киносеансы не найдены"
+// @Param status query string false "Статусы через запятую (scheduled, on_sale, sold_out, in_progress, finished, cancelled)"
+// @Param cinema_id query string false "ID кинотеатра"
+// @Param language query string false "Язык звуковой дорожки"
+// @Param subtitle_language query string false "Язык субтитров"
+// @Param format query string false "Формат показа (например, 3D)"
+// @Param accessible query bool false "Только сеансы со свободными местами для колясок"
+// @Param movie_id query string false "ID фильма"
+// @Param hall_id query string false "ID зала"
+// @Param start_from query string false "Начало не раньше (RFC 3339)"
+// @Param start_to query string false "Начало не позже (RFC 3339)"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: start_time или -start_time (по убыванию)"
+// @Success 200 {object} Page[MovieShow] "Страница киносеансов"
+// @Failure 400 {object} ErrorResponse "Неизвестный статус, неверный ID кинотеатра или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movie-shows [get]
 func GetMovieShows(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(context.Background(),
-			"SELECT id, movie_id, hall_id, start_time, language FROM movie_shows")
-		if HandleDatabaseError(w, err, "киносеансами фильмов") {
+		q, ok := parseShowListQuery(w, r)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var shows []MovieShow
-		for rows.Next() {
-			var ms MovieShow
-			if err := rows.Scan(&ms.ID, &ms.MovieID, &ms.HallID, &ms.StartTime, &ms.Language); HandleDatabaseError(w, err, "киносеансом фильма") {
-				return
-			}
-			shows = append(shows, ms)
-		}
 
-		if len(shows) == 0 {
-			http.Error(w, "киносеансы фильмов не найдены", http.StatusNotFound)
+		page, err := fetchShowPage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "киносеансами фильмов") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(shows)
+		writePage(w, page)
 	}
 }
 
 // @Summary Получить киносеанс по ID (guest | user | admin)
-// @Description Возвращает даныне о киносеансе по ID.
+// @Description Возвращает даныне о киносеансе по ID. Для отменённых сеансов заполнены cancelled_at и cancel_reason.
 // @Tags Киносеансы
 // @Produce json
 // @Param id path string true "ID киносеанса фильма"
@@ -122,12 +340,16 @@ func GetMovieShowByID(db *pgxpool.Pool) http.HandlerFunc {
 		var ms MovieShow
 		ms.ID = id.String()
 		err := db.QueryRow(context.Background(),
-			"SELECT movie_id, hall_id, start_time, language FROM movie_shows WHERE id = $1", id).
-			Scan(&ms.MovieID, &ms.HallID, &ms.StartTime, &ms.Language)
+			`SELECT ms.movie_id, ms.hall_id, ms.start_time, h.time_zone, ms.language, `+movieShowAttributeColumns+`, ms.status, ms.cancelled_at, ms.cancel_reason
+			FROM movie_shows ms
+			JOIN halls h ON h.id = ms.hall_id
+			WHERE ms.id = $1`, id).
+			Scan(&ms.MovieID, &ms.HallID, &ms.StartTime, &ms.TimeZone, &ms.Language, &ms.SubtitleLanguage, &ms.Formats, &ms.Status, &ms.CancelledAt, &ms.CancelReason)
 
 		if IsError(w, err) {
 			return
 		}
+		ms.localize()
 
 		json.NewEncoder(w).Encode(ms)
 	}
@@ -142,6 +364,7 @@ func GetMovieShowByID(db *pgxpool.Pool) http.HandlerFunc {
 // @Param movie_show body MovieShowAdmin true "Данные киносеанса"
 // @Success 201 {object} CreateResponse "ID созданного киносеанса"
 // @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movie-shows [post]
 func CreateMovieShow(db *pgxpool.Pool) http.HandlerFunc {
@@ -151,13 +374,18 @@ func CreateMovieShow(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfHall, ms.HallID) {
+			return
+		}
+
 		var showID string
 		err := db.QueryRow(context.Background(),
-			`SELECT create_movie_show_with_tickets($1, $2, $3, $4, $5)`,
-			ms.MovieID, ms.HallID, ms.StartTime, ms.Language, ms.BasePrice,
+			`SELECT create_movie_show_with_tickets($1, $2, $3, $4, $5, $6, $7, $8)`,
+			ms.MovieID, ms.HallID, ms.StartTime, ms.Language, ms.BasePrice, ms.initialStatus(),
+			ms.SubtitleLanguage, ms.formatIDs(),
 		).Scan(&showID)
 
-		if IsError(w, err) {
+		if isShowError(r.Context(), w, db, ms.HallID, err) {
 			return
 		}
 
@@ -166,8 +394,163 @@ func CreateMovieShow(db *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
+// @Summary Создать несколько киносеансов (admin)
+// @Description Создаёт киносеансы (и билеты на них) в одной транзакции.
+// @Description Если хотя бы один сеанс конфликтует с расписанием, не создаётся ни один.
+// @Tags Киносеансы
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param movie_shows body MovieShowBulk true "Список киносеансов"
+// @Success 201 {array} string "ID созданных киносеансов"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 409 {object} ErrorResponse "Конфликт расписания"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/bulk [post]
+func CreateMovieShowsBulk(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var bulk MovieShowBulk
+		if !DecodeJSONBody(w, r, &bulk) {
+			return
+		}
+
+		if len(bulk.Shows) == 0 {
+			http.Error(w, "Список киносеансов пуст", http.StatusBadRequest)
+			return
+		}
+
+		for _, ms := range bulk.Shows {
+			if !validateMovieShowAdmin(w, ms) {
+				return
+			}
+		}
+
+		for _, ms := range bulk.Shows {
+			if !requireCinemaAccess(w, r, db, cinemaOfHall, ms.HallID) {
+				return
+			}
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		ids := make([]string, 0, len(bulk.Shows))
+		for _, ms := range bulk.Shows {
+			var showID string
+			err := tx.QueryRow(ctx,
+				`SELECT create_movie_show_with_tickets($1, $2, $3, $4, $5, $6, $7, $8)`,
+				ms.MovieID, ms.HallID, ms.StartTime, ms.Language, ms.BasePrice, ms.initialStatus(),
+				ms.SubtitleLanguage, ms.formatIDs(),
+			).Scan(&showID)
+			if isShowError(ctx, w, db, ms.HallID, err) {
+				return
+			}
+			ids = append(ids, showID)
+		}
+
+		if err := tx.Commit(ctx); IsError(w, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(ids)
+	}
+}
+
+// @Summary Проверить киносеанс перед созданием (admin)
+// @Description Выполняет те же проверки, что и триггер расписания, но ничего не сохраняет:
+// @Description возвращает все конфликтующие сеансы, окна обслуживания зала и признак выхода за часы работы зала.
+// @Description Чтобы проверить перенос существующего сеанса, передайте его ID в show_id.
+// @Tags Киносеансы
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param movie_show body MovieShowData true "Данные киносеанса"
+// @Param show_id query string false "ID переносимого киносеанса, который не считается конфликтом"
+// @Success 200 {object} MovieShowValidation "Результат проверки"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Фильм или кинозал не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/validate [post]
+func ValidateMovieShow(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		excludeID := r.URL.Query().Get("show_id")
+		if excludeID != "" {
+			if _, err := uuid.Parse(excludeID); err != nil {
+				http.Error(w, "Неверный формат ID киносеанса", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var ms MovieShowData
+		if !DecodeJSONBody(w, r, &ms) || !validateMovieShowData(w, ms) {
+			return
+		}
+
+		ctx := r.Context()
+		settings, err := loadHallSettings(ctx, db, ms.HallID)
+		if IsError(w, err) {
+			return
+		}
+
+		duration, err := loadMovieDuration(ctx, db, ms.MovieID)
+		if IsError(w, err) {
+			return
+		}
+
+		start := ms.StartTime.In(settings.location())
+		shows, err := loadHallShows(ctx, db, ms.HallID, start.Add(-24*time.Hour), start.Add(24*time.Hour), settings.location())
+		if HandleDatabaseError(w, err, "киносеансами") {
+			return
+		}
+
+		settings.Maintenance, err = loadHallMaintenance(ctx, db, ms.HallID, start, start.Add(duration+settings.Cleaning), settings.location())
+		if HandleDatabaseError(w, err, "окнами обслуживания") {
+			return
+		}
+
+		result := MovieShowValidation{
+			OutsideOpeningHours: settings.outsideOpeningHours(start, duration),
+			CleaningMinutes:     int(settings.Cleaning / time.Minute),
+			Conflicts:           []MovieShowConflict{},
+			MaintenanceWindows:  []HallMaintenanceWindow{},
+		}
+		for _, c := range conflictingShows(shows, start, duration, settings.Cleaning, excludeID) {
+			result.Conflicts = append(result.Conflicts, MovieShowConflict{
+				ID:          c.ID,
+				MovieID:     c.MovieID,
+				StartTime:   c.Start,
+				EndTime:     c.End,
+				AvailableAt: c.End.Add(settings.Cleaning),
+			})
+		}
+		result.MaintenanceWindows = append(result.MaintenanceWindows, settings.maintenanceConflicts(start, duration)...)
+		result.Valid = !result.OutsideOpeningHours && len(result.Conflicts) == 0 && len(result.MaintenanceWindows) == 0
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
 // @Summary Обновить киносеанс (admin)
-// @Description Обновляет данные о киносеансе.
+// @Description Обновляет данные о киносеансе. Начавшиеся, завершённые и отменённые сеансы изменить нельзя.
+// @Description Зал сеанса, на который созданы билеты, меняется только через POST /movie-shows/{id}/reschedule,
+// @Description а фильм нельзя сменить, если билеты уже проданы или забронированы.
 // @Tags Киносеансы
 // @Accept json
 // @Produce json
@@ -197,15 +580,71 @@ func UpdateMovieShow(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		res, err := db.Exec(context.Background(),
-			"UPDATE movie_shows SET movie_id=$1, hall_id=$2, start_time=$3, language=$4 WHERE id=$5",
-			ms.MovieID, ms.HallID, ms.StartTime, ms.Language, id)
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, id) || !requireCinemaAccess(w, r, db, cinemaOfHall, ms.HallID) {
+			return
+		}
 
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
 		if IsError(w, err) {
 			return
 		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
 
-		if !CheckRowsAffected(w, res.RowsAffected()) {
+		var hallID, movieID string
+		var status ShowStatusEnumType
+		var hasTickets, hasSoldTickets bool
+		err = tx.QueryRow(ctx, `
+			SELECT hall_id, movie_id, status,
+				EXISTS (SELECT 1 FROM tickets WHERE movie_show_id = ms.id),
+				EXISTS (SELECT 1 FROM tickets WHERE movie_show_id = ms.id AND ticket_status IN ('Purchased', 'Reserved'))
+			FROM movie_shows ms
+			WHERE id = $1
+			FOR UPDATE`, id).
+			Scan(&hallID, &movieID, &status, &hasTickets, &hasSoldTickets)
+		if IsError(w, err) {
+			return
+		}
+
+		if status != ShowScheduled && status != ShowOnSale && status != ShowSoldOut {
+			http.Error(w, "Начавшийся, завершённый или отменённый киносеанс изменить нельзя", http.StatusConflict)
+			return
+		}
+		if hallID != ms.HallID && hasTickets {
+			http.Error(w, "Билеты сеанса привязаны к местам зала. Для переноса в другой зал используйте POST /movie-shows/{id}/reschedule", http.StatusConflict)
+			return
+		}
+		if movieID != ms.MovieID && hasSoldTickets {
+			http.Error(w, "Нельзя сменить фильм сеанса, на который уже проданы или забронированы билеты", http.StatusConflict)
+			return
+		}
+
+		_, err = tx.Exec(ctx,
+			"UPDATE movie_shows SET movie_id=$1, hall_id=$2, start_time=$3, language=$4, subtitle_language=$5 WHERE id=$6",
+			ms.MovieID, ms.HallID, ms.StartTime, ms.Language, ms.SubtitleLanguage, id)
+		if isShowError(ctx, w, db, ms.HallID, err) {
+			return
+		}
+
+		if ms.FormatIDs != nil {
+			_, err = tx.Exec(ctx, "DELETE FROM movie_show_formats WHERE movie_show_id = $1", id)
+			if IsError(w, err) {
+				return
+			}
+			_, err = tx.Exec(ctx, `
+				INSERT INTO movie_show_formats (movie_show_id, format_id)
+				SELECT $1, unnest($2::uuid[])
+				ON CONFLICT DO NOTHING`, id, ms.FormatIDs)
+			if IsError(w, err) {
+				return
+			}
+		}
+
+		if err := tx.Commit(ctx); IsError(w, err) {
 			return
 		}
 
@@ -214,7 +653,8 @@ func UpdateMovieShow(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Удалить киносеанс фильма (admin)
-// @Description Удаляет данные о киносеансе.
+// @Description Удаляет данные о киносеансе. Сеанс, на который уже созданы билеты, удалить нельзя —
+// @Description используйте отмену сеанса (POST /movie-shows/{id}/cancel), чтобы сохранить историю продаж.
 // @Tags Киносеансы
 // @Param id path string true "ID киносеанса"
 // @Security BearerAuth
@@ -232,6 +672,10 @@ func DeleteMovieShow(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, id) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(),
 			"DELETE FROM movie_shows WHERE id = $1", id)
 
@@ -247,15 +691,641 @@ func DeleteMovieShow(db *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
+// @Summary Отменить киносеанс (admin)
+// @Description Помечает киносеанс отменённым, не удаляя его: купленные билеты переводятся в статус Refunded
+// @Description (сборы фильма уменьшаются триггером), брони снимаются, а владельцы билетов получают уведомления.
+// @Description Отменённый сеанс не попадает в списки сеансов и не блокирует зал.
+// @Tags Киносеансы
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID киносеанса"
+// @Param cancel body MovieShowCancel true "Причина отмены"
+// @Success 200 {object} MovieShowCancelResult "Результат отмены"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Киносеанс не найден"
+// @Failure 409 {object} ErrorResponse "Киносеанс уже отменён, начался или завершён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/cancel [post]
+func CancelMovieShow(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var c MovieShowCancel
+		if !DecodeJSONBody(w, r, &c) {
+			return
+		}
+
+		c.Reason = PrepareString(c.Reason)
+		if c.Reason == "" || len(c.Reason) > 500 {
+			http.Error(w, "Причина отмены не может быть пустой или превышать 500 символов", http.StatusBadRequest)
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, id) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		var status ShowStatusEnumType
+		err = tx.QueryRow(ctx, "SELECT status FROM movie_shows WHERE id = $1 FOR UPDATE", id).
+			Scan(&status)
+		if IsError(w, err) {
+			return
+		}
+		if status == ShowCancelled {
+			http.Error(w, "Киносеанс уже отменён", http.StatusConflict)
+			return
+		}
+		if !status.CanTransitionTo(ShowCancelled) {
+			http.Error(w, "Начавшийся или завершённый киносеанс нельзя отменить", http.StatusConflict)
+			return
+		}
+
+		_, err = tx.Exec(ctx,
+			`UPDATE movie_shows SET status = $1, cancelled_at = CURRENT_TIMESTAMP, cancel_reason = $2
+			WHERE id = $3`,
+			ShowCancelled, c.Reason, id)
+		if IsError(w, err) {
+			return
+		}
+
+		var result MovieShowCancelResult
+		res, err := tx.Exec(ctx, `
+			INSERT INTO notifications (user_id, movie_show_id, message)
+			SELECT DISTINCT t.user_id, ms.id,
+				'Сеанс фильма «' || m.title || '» ' || to_char(ms.start_time AT TIME ZONE h.time_zone, 'DD.MM.YYYY HH24:MI') ||
+				' отменён: ' || ms.cancel_reason || '. Купленные билеты возвращены, брони сняты.'
+			FROM tickets t
+			JOIN movie_shows ms ON ms.id = t.movie_show_id
+			JOIN movies m ON m.id = ms.movie_id
+			JOIN halls h ON h.id = ms.hall_id
+			WHERE t.movie_show_id = $1
+			AND t.ticket_status IN ('Purchased', 'Reserved')`, id)
+		if IsError(w, err) {
+			return
+		}
+		result.NotifiedUsers = int(res.RowsAffected())
+
+		res, err = tx.Exec(ctx,
+			"UPDATE tickets SET ticket_status = $1 WHERE movie_show_id = $2 AND ticket_status = $3",
+			Refunded, id, Purchased)
+		if IsError(w, err) {
+			return
+		}
+		result.RefundedTickets = int(res.RowsAffected())
+
+		res, err = tx.Exec(ctx,
+			"UPDATE tickets SET ticket_status = $1, user_id = NULL WHERE movie_show_id = $2 AND ticket_status = $3",
+			Available, id, Reserved)
+		if IsError(w, err) {
+			return
+		}
+		result.ReleasedReservations = int(res.RowsAffected())
+
+		if err := tx.Commit(ctx); IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// soldSeat — проданный или забронированный билет и место, которое он занимает
+type soldSeat struct {
+	TicketID string
+	Seat     Seat
+}
+
+// remapSeats подбирает проданным местам места в новом зале: сначала того же типа с тем же рядом
+// и номером, затем ближайшее свободное место того же типа. Места, для которых замены нет,
+// возвращаются отдельно.
+// seats должны содержать только места, доступные на новое время сеанса.
+func remapSeats(sold []soldSeat, seats []Seat) ([]SeatRemap, []UnmappableSeat) {
+	taken := make(map[string]bool)
+	assigned := make([]string, len(sold))
+
+	for i, s := range sold {
+		for _, seat := range seats {
+			if !taken[seat.ID] && seat.SeatTypeID == s.Seat.SeatTypeID &&
+				seat.RowNumber == s.Seat.RowNumber && seat.SeatNumber == s.Seat.SeatNumber {
+				assigned[i] = seat.ID
+				taken[seat.ID] = true
+				break
+			}
+		}
+	}
+
+	abs := func(v int) int {
+		if v < 0 {
+			return -v
+		}
+		return v
+	}
+
+	for i, s := range sold {
+		if assigned[i] != "" {
+			continue
+		}
+
+		best := -1
+		for j, seat := range seats {
+			if taken[seat.ID] || seat.SeatTypeID != s.Seat.SeatTypeID {
+				continue
+			}
+			if best == -1 {
+				best = j
+				continue
+			}
+			dRow, dSeat := abs(seat.RowNumber-s.Seat.RowNumber), abs(seat.SeatNumber-s.Seat.SeatNumber)
+			bRow, bSeat := abs(seats[best].RowNumber-s.Seat.RowNumber), abs(seats[best].SeatNumber-s.Seat.SeatNumber)
+			if dRow < bRow || (dRow == bRow && dSeat < bSeat) {
+				best = j
+			}
+		}
+
+		if best != -1 {
+			assigned[i] = seats[best].ID
+			taken[seats[best].ID] = true
+		}
+	}
+
+	remaps := []SeatRemap{}
+	var unmappable []UnmappableSeat
+	for i, s := range sold {
+		if assigned[i] == "" {
+			unmappable = append(unmappable, s.unmappable())
+			continue
+		}
+		remaps = append(remaps, SeatRemap{TicketID: s.TicketID, FromSeatID: s.Seat.ID, ToSeatID: assigned[i]})
+	}
+
+	return remaps, unmappable
+}
+
+// unavailableSeats возвращает проданные места, которых нет среди доступных на новое время:
+// при переносе без смены зала билеты не пересаживаются.
+func unavailableSeats(sold []soldSeat, seats []Seat) []UnmappableSeat {
+	available := make(map[string]bool, len(seats))
+	for _, seat := range seats {
+		available[seat.ID] = true
+	}
+
+	var unmappable []UnmappableSeat
+	for _, s := range sold {
+		if !available[s.Seat.ID] {
+			unmappable = append(unmappable, s.unmappable())
+		}
+	}
+
+	return unmappable
+}
+
+func (s soldSeat) unmappable() UnmappableSeat {
+	return UnmappableSeat{
+		TicketID:   s.TicketID,
+		SeatID:     s.Seat.ID,
+		SeatTypeID: s.Seat.SeatTypeID,
+		RowNumber:  s.Seat.RowNumber,
+		SeatNumber: s.Seat.SeatNumber,
+	}
+}
+
+func loadSoldSeats(ctx context.Context, tx pgx.Tx, showID uuid.UUID) ([]soldSeat, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT t.id, s.id, s.hall_id, COALESCE(s.seat_type_id::text, ''), s.row_number, s.seat_number
+		FROM tickets t
+		JOIN seats s ON s.id = t.seat_id
+		WHERE t.movie_show_id = $1
+		AND t.ticket_status IN ('Purchased', 'Reserved')
+		ORDER BY s.row_number, s.seat_number, t.id`, showID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sold []soldSeat
+	for rows.Next() {
+		var s soldSeat
+		if err := rows.Scan(&s.TicketID, &s.Seat.ID, &s.Seat.HallID, &s.Seat.SeatTypeID, &s.Seat.RowNumber, &s.Seat.SeatNumber); err != nil {
+			return nil, err
+		}
+		sold = append(sold, s)
+	}
+
+	return sold, rows.Err()
+}
+
+func loadHallSeats(ctx context.Context, tx pgx.Tx, hallID string) ([]Seat, error) {
+	return querySeats(ctx, tx, `
+		SELECT id, hall_id, COALESCE(seat_type_id::text, ''), row_number, seat_number
+		FROM seats
+		WHERE hall_id = $1
+		ORDER BY row_number, seat_number`, hallID)
+}
+
+// loadServiceableSeats загружает места зала, не выведенные из обслуживания на время показа фильма
+// с началом в startTime
+func loadServiceableSeats(ctx context.Context, tx pgx.Tx, hallID, movieID string, startTime time.Time) ([]Seat, error) {
+	return querySeats(ctx, tx, `
+		SELECT id, hall_id, COALESCE(seat_type_id::text, ''), row_number, seat_number
+		FROM seats
+		WHERE hall_id = $1
+		AND NOT seat_out_of_service(service_state, service_from, service_until, movie_show_occupied($2, $1, $3))
+		ORDER BY row_number, seat_number`, hallID, movieID, startTime)
+}
+
+func querySeats(ctx context.Context, tx pgx.Tx, sql string, args ...any) ([]Seat, error) {
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seats []Seat
+	for rows.Next() {
+		var seat Seat
+		if err := rows.Scan(&seat.ID, &seat.HallID, &seat.SeatTypeID, &seat.RowNumber, &seat.SeatNumber); err != nil {
+			return nil, err
+		}
+		seats = append(seats, seat)
+	}
+
+	return seats, rows.Err()
+}
+
+// @Summary Перенести киносеанс (admin)
+// @Description Переносит киносеанс на другое время и/или в другой зал, сохраняя проданные билеты.
+// @Description При смене времени билеты остаются прежними. При смене зала проданные и забронированные места
+// @Description переносятся на места того же типа (по возможности с тем же рядом и номером, иначе на ближайшие),
+// @Description а свободные билеты вычисляются по схеме нового зала. Места, выведенные из обслуживания
+// @Description на новое время сеанса, для переноса не используются. Если для части мест замены нет
+// @Description (или при смене времени место билета недоступно), ничего не меняется и возвращается 409 со списком таких мест.
+// @Description Владельцы билетов получают уведомления о переносе.
+// @Tags Киносеансы
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID киносеанса"
+// @Param reschedule body MovieShowReschedule true "Новое время и/или зал"
+// @Success 200 {object} MovieShowRescheduleResult "Результат переноса"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Киносеанс или кинозал не найден"
+// @Failure 409 {object} MovieShowRescheduleConflict "Места без замены, конфликт расписания или сеанс уже начался"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/reschedule [post]
+func RescheduleMovieShow(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var req MovieShowReschedule
+		if !DecodeJSONBody(w, r, &req) {
+			return
+		}
+
+		if req.StartTime == nil && req.HallID == nil {
+			http.Error(w, "Укажите новое время начала или новый кинозал", http.StatusBadRequest)
+			return
+		}
+		if req.HallID != nil {
+			if _, err := uuid.Parse(*req.HallID); err != nil {
+				http.Error(w, "Неверный формат ID зала", http.StatusBadRequest)
+				return
+			}
+		}
+		if req.StartTime != nil && req.StartTime.Before(time.Date(1895, 3, 22, 0, 0, 0, 0, time.UTC)) {
+			http.Error(w, "Время начала киносеанса должно быть позже 22 марта 1895 года (первый в мире киносеанс)", http.StatusBadRequest)
+			return
+		}
+		if req.BasePrice != nil && *req.BasePrice <= 0 {
+			http.Error(w, "Начальная цена должна быть положительной", http.StatusBadRequest)
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, id) {
+			return
+		}
+		if req.HallID != nil && !requireCinemaAccess(w, r, db, cinemaOfHall, *req.HallID) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		var movieID, hallID string
+		var startTime time.Time
+		var status ShowStatusEnumType
+		err = tx.QueryRow(ctx, "SELECT movie_id, hall_id, start_time, status FROM movie_shows WHERE id = $1 FOR UPDATE", id).
+			Scan(&movieID, &hallID, &startTime, &status)
+		if IsError(w, err) {
+			return
+		}
+
+		if status != ShowScheduled && status != ShowOnSale && status != ShowSoldOut {
+			http.Error(w, "Перенести можно только сеанс, который ещё не начался и не отменён", http.StatusConflict)
+			return
+		}
+
+		newHallID, newStartTime := hallID, startTime
+		if req.HallID != nil {
+			newHallID = *req.HallID
+		}
+		if req.StartTime != nil {
+			newStartTime = *req.StartTime
+		}
+		hallChanged := newHallID != hallID
+
+		result := MovieShowRescheduleResult{Remaps: []SeatRemap{}}
+		if hallChanged {
+			var hallExists bool
+			err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM halls WHERE id = $1)", newHallID).Scan(&hallExists)
+			if IsError(w, err) {
+				return
+			}
+			if !hallExists {
+				http.Error(w, "Кинозал не найден", http.StatusNotFound)
+				return
+			}
+		}
+
+		sold, err := loadSoldSeats(ctx, tx, id)
+		if IsError(w, err) {
+			return
+		}
+		seats, err := loadServiceableSeats(ctx, tx, newHallID, movieID, newStartTime)
+		if IsError(w, err) {
+			return
+		}
+
+		var unmappable []UnmappableSeat
+		message := "Часть проданных и забронированных мест выведена из обслуживания на новое время сеанса"
+		if hallChanged {
+			result.Remaps, unmappable = remapSeats(sold, seats)
+			message = "Не для всех проданных и забронированных мест есть доступные места того же типа в новом зале"
+		} else {
+			unmappable = unavailableSeats(sold, seats)
+		}
+		if len(unmappable) > 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(MovieShowRescheduleConflict{
+				Message:         message,
+				UnmappableSeats: unmappable,
+			})
+			return
+		}
+
+		if hallChanged {
+			// Сеансам без базовой цены она восстанавливается по сохранённым билетам
+			basePrice := req.BasePrice
+			if basePrice == nil {
+				err = tx.QueryRow(ctx, `
+					SELECT COALESCE(
+						(SELECT base_price FROM movie_show_prices WHERE movie_show_id = $1),
+						(SELECT ROUND(AVG(t.price / (scr.price_modifier * st.price_modifier * COALESCE(z.price_modifier, 1))), 2)
+						FROM tickets t
+						JOIN seats s ON s.id = t.seat_id
+						JOIN seat_types st ON st.id = s.seat_type_id
+						LEFT JOIN hall_zones z ON z.id = s.zone_id
+						JOIN movie_shows ms ON ms.id = t.movie_show_id
+						JOIN halls h ON h.id = ms.hall_id
+						JOIN screen_types scr ON scr.id = h.screen_type_id
+						WHERE t.movie_show_id = $1)
+					)::float8`, id).Scan(&basePrice)
+				if IsError(w, err) {
+					return
+				}
+				if basePrice == nil && len(seats) > 0 {
+					http.Error(w, "Не удалось определить начальную цену по билетам сеанса, укажите base_price", http.StatusBadRequest)
+					return
+				}
+			}
+
+			if basePrice != nil {
+				_, err = tx.Exec(ctx, `
+					INSERT INTO movie_show_prices (movie_show_id, base_price) VALUES ($1, $2)
+					ON CONFLICT (movie_show_id) DO UPDATE SET base_price = EXCLUDED.base_price`,
+					id, *basePrice)
+				if IsError(w, err) {
+					return
+				}
+			}
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE movie_shows SET hall_id = $1, start_time = $2 WHERE id = $3",
+			newHallID, newStartTime, id)
+		if isShowError(ctx, w, db, newHallID, err) {
+			return
+		}
+
+		if hallChanged {
+			// Сохранённые свободные билеты ссылаются на места старого зала
+			_, err = tx.Exec(ctx, "DELETE FROM tickets WHERE movie_show_id = $1 AND ticket_status = $2", id, Available)
+			if IsError(w, err) {
+				return
+			}
+
+			ticketIDs := make([]string, len(result.Remaps))
+			seatIDs := make([]string, len(result.Remaps))
+			for i, m := range result.Remaps {
+				ticketIDs[i], seatIDs[i] = m.TicketID, m.ToSeatID
+			}
+
+			_, err = tx.Exec(ctx, `
+				UPDATE tickets t
+				SET seat_id = m.seat_id
+				FROM unnest($1::uuid[], $2::uuid[]) AS m(ticket_id, seat_id)
+				WHERE t.id = m.ticket_id`, ticketIDs, seatIDs)
+			if IsError(w, err) {
+				return
+			}
+
+			err = tx.QueryRow(ctx,
+				"SELECT COUNT(*) FROM movie_show_tickets WHERE movie_show_id = $1 AND NOT stored", id).
+				Scan(&result.RegeneratedTickets)
+			if IsError(w, err) {
+				return
+			}
+
+			_, err = tx.Exec(ctx, "SELECT sync_movie_show_sold_out($1)", id)
+			if IsError(w, err) {
+				return
+			}
+		}
+		result.RemappedTickets = len(result.Remaps)
+
+		res, err := tx.Exec(ctx, `
+			INSERT INTO notifications (user_id, movie_show_id, message)
+			SELECT DISTINCT t.user_id, ms.id,
+				'Сеанс фильма «' || m.title || '» перенесён на ' || to_char(ms.start_time AT TIME ZONE h.time_zone, 'DD.MM.YYYY HH24:MI') ||
+				' в зал «' || h.name || '». Проверьте места в ваших билетах.'
+			FROM tickets t
+			JOIN movie_shows ms ON ms.id = t.movie_show_id
+			JOIN movies m ON m.id = ms.movie_id
+			JOIN halls h ON h.id = ms.hall_id
+			WHERE t.movie_show_id = $1
+			AND t.ticket_status IN ('Purchased', 'Reserved')`, id)
+		if IsError(w, err) {
+			return
+		}
+		result.NotifiedUsers = int(res.RowsAffected())
+
+		if err := tx.Commit(ctx); IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// @Summary Изменить статус киносеанса (admin)
+// @Description Переводит киносеанс в новый статус. Допустимые переходы:
+// @Description scheduled → on_sale | in_progress; on_sale → scheduled | sold_out | in_progress;
+// @Description sold_out → on_sale | in_progress; in_progress → finished.
+// @Description Статусы sold_out/on_sale и in_progress/finished также меняются автоматически — по заполненности зала и по времени.
+// @Description Для отмены используйте POST /movie-shows/{id}/cancel.
+// @Tags Киносеансы
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "ID киносеанса"
+// @Param status body MovieShowStatusUpdate true "Новый статус"
+// @Success 200 "Статус киносеанса изменён"
+// @Failure 400 {object} ErrorResponse "Неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Киносеанс не найден"
+// @Failure 409 {object} ErrorResponse "Недопустимый переход статуса"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /movie-shows/{id}/status [put]
+func UpdateMovieShowStatus(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var u MovieShowStatusUpdate
+		if !DecodeJSONBody(w, r, &u) {
+			return
+		}
+
+		if !u.Status.IsValid() {
+			http.Error(w, "Неизвестный статус киносеанса", http.StatusBadRequest)
+			return
+		}
+		if u.Status == ShowCancelled {
+			http.Error(w, "Для отмены киносеанса используйте POST /movie-shows/{id}/cancel", http.StatusBadRequest)
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, id) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		var current ShowStatusEnumType
+		err = tx.QueryRow(ctx, "SELECT status FROM movie_shows WHERE id = $1 FOR UPDATE", id).Scan(&current)
+		if IsError(w, err) {
+			return
+		}
+
+		if !current.CanTransitionTo(u.Status) {
+			http.Error(w, fmt.Sprintf("Недопустимый переход статуса киносеанса: %s -> %s", current, u.Status), http.StatusConflict)
+			return
+		}
+
+		_, err = tx.Exec(ctx, "UPDATE movie_shows SET status = $1 WHERE id = $2", u.Status, id)
+		if IsError(w, err) {
+			return
+		}
+
+		if err := tx.Commit(ctx); IsError(w, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// RefreshMovieShowStatuses переводит начавшиеся сеансы в in_progress, а завершившиеся — в finished.
+func RefreshMovieShowStatuses(ctx context.Context, db *pgxpool.Pool, now time.Time) (int, error) {
+	var updated int
+	err := db.QueryRow(ctx, "SELECT refresh_movie_show_statuses($1)", now).Scan(&updated)
+	return updated, err
+}
+
+// RunShowStatusUpdater периодически обновляет статусы сеансов по времени, пока не отменён ctx.
+func RunShowStatusUpdater(ctx context.Context, db *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := RefreshMovieShowStatuses(ctx, db, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("failed to refresh movie show statuses: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // @Summary Получить киносеансы по ID фильма (guest | user | admin)
-// @Description Возвращает киносеансы для указанного фильма в ближайшие N часов.
+// @Description Возвращает страницу киносеансов для указанного фильма в ближайшие N часов.
 // @Tags Киносеансы
 // @Produce json
 // @Param movie_id path string true "ID фильма"
 // @Param hours query integer false "Период в часах (по умолчанию 24)"
-// @Success 200 {array} MovieShow "Данные о найденных киносеансах"
-// @Failure 400 {object} ErrorResponse "Неверный формат ID фильма или параметра hours"
-// @Failure 404 {object} ErrorResponse "Киносеансы для данного фильма не найдены"
+// @Param status query string false "Статусы через запятую (по умолчанию все, кроме cancelled)"
+// @Param cinema_id query string false "ID кинотеатра"
+// @Param language query string false "Язык звуковой дорожки"
+// @Param subtitle_language query string false "Язык субтитров"
+// @Param format query string false "Формат показа (например, 3D)"
+// @Param accessible query bool false "Только сеансы со свободными местами для колясок"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: start_time или -start_time (по убыванию)"
+// @Success 200 {object} Page[MovieShow] "Страница найденных киносеансов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID фильма, параметра hours, статуса или параметров списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movies/{movie_id}/shows [get]
 func GetShowsByMovie(db *pgxpool.Pool) http.HandlerFunc {
@@ -275,51 +1345,42 @@ func GetShowsByMovie(db *pgxpool.Pool) http.HandlerFunc {
 			hours = parsedHours
 		}
 
-		now := time.Now()
-		endTime := now.Add(time.Duration(hours) * time.Hour)
-
-		rows, err := db.Query(r.Context(), `
-            SELECT id, movie_id, hall_id, start_time, language 
-            FROM movie_shows 
-            WHERE movie_id = $1 
-            AND start_time BETWEEN $2 AND $3
-            ORDER BY start_time`,
-			movieID, now, endTime)
-
-		if IsError(w, err) {
+		q, ok := parseShowListQuery(w, r)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var shows []MovieShow
-		for rows.Next() {
-			var ms MovieShow
-			if err := rows.Scan(&ms.ID, &ms.MovieID, &ms.HallID, &ms.StartTime, &ms.Language); HandleDatabaseError(w, err, "сеансом") {
-				return
-			}
-			shows = append(shows, ms)
-		}
+		now := time.Now()
+		q.where("ms.movie_id = $%[1]d", movieID)
+		q.where("ms.start_time >= $%[1]d", now)
+		q.where("ms.start_time <= $%[1]d", now.Add(time.Duration(hours)*time.Hour))
 
-		if len(shows) == 0 {
-			http.Error(w, "Киносеансы для данного фильма не найдены в указанный период", http.StatusNotFound)
+		page, err := fetchShowPage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "киносеансами фильмов") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(shows)
+		writePage(w, page)
 	}
 }
 
 // @Summary Получить сеансы на указанную дату (guest | user | admin)
-// @Description Возвращает сеансы, начинающиеся в указанный день.
+// @Description Возвращает страницу сеансов, начинающихся в указанный день по местному времени их кинозала.
 // @Tags Киносеансы
 // @Produce json
 // @Param date path string true "Дата (YYYY-MM-DD)"
-// @Success 200 {array} MovieShow "Данные о киносеансах"
-// @Failure 400 {object} ErrorResponse "Неверный формат даты"
-// @Failure 404 {object} ErrorResponse "Киносеансы в указанную дату не найдены"
+// @Param status query string false "Статусы через запятую (по умолчанию все, кроме cancelled)"
+// @Param cinema_id query string false "ID кинотеатра"
+// @Param language query string false "Язык звуковой дорожки"
+// @Param subtitle_language query string false "Язык субтитров"
+// @Param format query string false "Формат показа (например, 3D)"
+// @Param accessible query bool false "Только сеансы со свободными местами для колясок"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: start_time или -start_time (по убыванию)"
+// @Success 200 {object} Page[MovieShow] "Страница киносеансов"
+// @Failure 400 {object} ErrorResponse "Неверный формат даты, статуса или параметров списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
-// @Router /movie-shows/by-date/{date} [get]
+// @Router /dates/{date}/movie-shows [get]
 func GetShowsByDate(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		dateStr := r.PathValue("date")
@@ -329,44 +1390,40 @@ func GetShowsByDate(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		nextDay := date.AddDate(0, 0, 1)
-		rows, err := db.Query(r.Context(), `
-            SELECT id, movie_id, hall_id, start_time, language 
-            FROM movie_shows 
-            WHERE start_time >= $1 AND start_time < $2
-            ORDER BY start_time`, date, nextDay)
-		if IsError(w, err) {
+		q, ok := parseShowListQuery(w, r)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var shows []MovieShow
-		for rows.Next() {
-			var ms MovieShow
-			if err := rows.Scan(&ms.ID, &ms.MovieID, &ms.HallID, &ms.StartTime, &ms.Language); HandleDatabaseError(w, err, "сеансом") {
-				return
-			}
-			shows = append(shows, ms)
-		}
+		// Дата сравнивается с местной датой зала; грубые границы в UTC позволяют использовать индекс
+		q.where("ms.start_time >= $%[1]d", date.Add(-24*time.Hour))
+		q.where("ms.start_time < $%[1]d", date.Add(48*time.Hour))
+		q.where("(ms.start_time AT TIME ZONE h.time_zone)::date = $%[1]d::date", dateStr)
 
-		if len(shows) == 0 {
-			http.Error(w, "Киносеансы в указанную дату не найдены", http.StatusNotFound)
+		page, err := fetchShowPage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "киносеансами фильмов") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(shows)
+		writePage(w, page)
 	}
 }
 
 // @Summary Получить ближайшие сеансы (guest | user | admin)
-// @Description Возвращает сеансы, начинающиеся в ближайшие N часов.
+// @Description Возвращает страницу сеансов, начинающихся в ближайшие N часов.
 // @Tags Киносеансы
 // @Produce json
 // @Param hours query integer false "Период в часах (по умолчанию 24)"
-// @Success 200 {array} MovieShow "Данные о киносеансах"
-// @Failure 400 {object} ErrorResponse "Неверный формат даты"
-// @Failure 404 {object} ErrorResponse "Киносеансы в указанную дату не найдены"
+// @Param status query string false "Статусы через запятую (по умолчанию все, кроме cancelled)"
+// @Param cinema_id query string false "ID кинотеатра"
+// @Param language query string false "Язык звуковой дорожки"
+// @Param subtitle_language query string false "Язык субтитров"
+// @Param format query string false "Формат показа (например, 3D)"
+// @Param accessible query bool false "Только сеансы со свободными местами для колясок"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: start_time или -start_time (по убыванию)"
+// @Success 200 {object} Page[MovieShow] "Страница киносеансов"
+// @Failure 400 {object} ErrorResponse "Неверный формат периода, статуса или параметров списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movie-shows/upcoming [get]
 func GetUpcomingShows(db *pgxpool.Pool) http.HandlerFunc {
@@ -381,34 +1438,19 @@ func GetUpcomingShows(db *pgxpool.Pool) http.HandlerFunc {
 			}
 		}
 
-		now := time.Now()
-		endTime := now.Add(time.Duration(hours) * time.Hour)
-
-		rows, err := db.Query(r.Context(), `
-            SELECT id, movie_id, hall_id, start_time, language 
-            FROM movie_shows 
-            WHERE start_time BETWEEN $1 AND $2
-            ORDER BY start_time`, now, endTime)
-		if IsError(w, err) {
+		q, ok := parseShowListQuery(w, r)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var shows []MovieShow
-		for rows.Next() {
-			var ms MovieShow
-			if err := rows.Scan(&ms.ID, &ms.MovieID, &ms.HallID, &ms.StartTime, &ms.Language); HandleDatabaseError(w, err, "сеансом") {
-				return
-			}
-			shows = append(shows, ms)
-		}
+		now := time.Now()
+		q.where("ms.start_time >= $%[1]d", now)
+		q.where("ms.start_time <= $%[1]d", now.Add(time.Duration(hours)*time.Hour))
 
-		if len(shows) == 0 {
-			http.Error(w, fmt.Sprintf("Киносеансы в ближайшие %d часа(ов) не найдены", hours), http.StatusNotFound)
+		page, err := fetchShowPage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "киносеансами фильмов") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(shows)
+		writePage(w, page)
 	}
 }
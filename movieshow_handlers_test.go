@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +19,8 @@ import (
 	"unsafe"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -27,9 +31,9 @@ func TestGetMovieShows(t *testing.T) {
 		role           string
 		expectedStatus int
 	}{
-		{"Empty as Guest", false, "", http.StatusNotFound},
-		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Guest", false, "", http.StatusOK},
+		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusOK},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -50,10 +54,14 @@ func TestGetMovieShows(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var shows []MovieShow
-				parseResponseBody(t, resp, &shows)
+				var page Page[MovieShow]
+				parseResponseBody(t, resp, &page)
+				shows := page.Items
 
-				if len(shows) == 0 {
+				if !tt.seedData && (shows == nil || len(shows) != 0) {
+					t.Errorf("Expected empty movie shows list, got %v", shows)
+				}
+				if tt.seedData && len(shows) == 0 {
 					t.Error("Expected non-empty movie shows list")
 				}
 			}
@@ -149,7 +157,7 @@ func TestCreateMovieShow(t *testing.T) {
 		MovieID:   MoviesData[0].ID,
 		HallID:    HallsData[1].ID,
 		StartTime: time.Now().Add(124 * time.Hour),
-		Language:  Russian,
+		Language:  "Русский",
 		BasePrice: 300,
 	}
 
@@ -254,7 +262,7 @@ func TestCreateMovieShow(t *testing.T) {
 				MovieID:   "invalid",
 				HallID:    HallsData[0].ID,
 				StartTime: time.Now().Add(24 * time.Hour),
-				Language:  Russian,
+				Language:  "Русский",
 				BasePrice: 300,
 			},
 			func(t *testing.T) {
@@ -269,7 +277,7 @@ func TestCreateMovieShow(t *testing.T) {
 				MovieID:   MoviesData[0].ID,
 				HallID:    "invalid",
 				StartTime: time.Now().Add(24 * time.Hour),
-				Language:  Russian,
+				Language:  "Русский",
 				BasePrice: 300,
 			},
 			nil,
@@ -282,7 +290,7 @@ func TestCreateMovieShow(t *testing.T) {
 				MovieID:   MoviesData[0].ID,
 				HallID:    HallsData[0].ID,
 				StartTime: time.Now().Add(-24 * time.Hour),
-				Language:  Russian,
+				Language:  "Русский",
 				BasePrice: 300,
 			},
 			nil,
@@ -338,7 +346,7 @@ func TestUpdateMovieShow(t *testing.T) {
 		MovieID:   MoviesData[0].ID,
 		HallID:    HallsData[0].ID,
 		StartTime: time.Now().Add(48 * time.Hour),
-		Language:  English,
+		Language:  "English",
 	}
 
 	invalidUpdateData := MovieShowData{
@@ -422,6 +430,22 @@ func TestUpdateMovieShow(t *testing.T) {
 			setupExistingShow,
 			http.StatusOK,
 		},
+		{
+			"Hall change with tickets",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			"",
+			MovieShowData{MovieID: MoviesData[0].ID, HallID: HallsData[3].ID, StartTime: validUpdateData.StartTime, Language: "English"},
+			setupExistingShow,
+			http.StatusConflict,
+		},
+		{
+			"Movie change with sold tickets",
+			os.Getenv("CLAIM_ROLE_ADMIN"),
+			"",
+			MovieShowData{MovieID: MoviesData[1].ID, HallID: HallsData[0].ID, StartTime: validUpdateData.StartTime, Language: "English"},
+			setupExistingShow,
+			http.StatusConflict,
+		},
 	}
 
 	for _, tt := range tests {
@@ -515,7 +539,7 @@ func TestCreateMovieShowDBError(t *testing.T) {
 			MovieID:   uuid.New().String(),
 			HallID:    uuid.New().String(),
 			StartTime: time.Now().Add(24 * time.Hour),
-			Language:  Russian,
+			Language:  "Русский",
 		})
 	resp := executeRequest(t, req, http.StatusInternalServerError)
 	defer resp.Body.Close()
@@ -525,8 +549,864 @@ func TestCreateMovieShowDBError(t *testing.T) {
 	}
 }
 
-func TestMovieShowConflictTrigger(t *testing.T) {
-	t.Run("No conflict - different halls", func(t *testing.T) {
+func TestCreateMovieShowsBulk(t *testing.T) {
+	start := time.Now().Add(200 * time.Hour)
+
+	validBulk := MovieShowBulk{Shows: []MovieShowAdmin{
+		{MovieID: MoviesData[0].ID, HallID: HallsData[3].ID, StartTime: start, Language: "Русский", BasePrice: 300},
+		{MovieID: MoviesData[1].ID, HallID: HallsData[3].ID, StartTime: start.Add(4 * time.Hour), Language: "English", BasePrice: 350},
+	}}
+
+	conflictingBulk := MovieShowBulk{Shows: []MovieShowAdmin{
+		{MovieID: MoviesData[0].ID, HallID: HallsData[3].ID, StartTime: start, Language: "Русский", BasePrice: 300},
+		{MovieID: MoviesData[1].ID, HallID: HallsData[3].ID, StartTime: start.Add(time.Hour), Language: "English", BasePrice: 350},
+	}}
+
+	invalidBulk := MovieShowBulk{Shows: []MovieShowAdmin{
+		{MovieID: MoviesData[0].ID, HallID: HallsData[3].ID, StartTime: start, Language: "Русский", BasePrice: 300},
+		{MovieID: "invalid", HallID: HallsData[3].ID, StartTime: start, Language: "Русский", BasePrice: 300},
+	}}
+
+	tests := []struct {
+		name           string
+		role           string
+		body           interface{}
+		expectedStatus int
+		expectedShows  int
+	}{
+		{"Forbidden Guest", "", validBulk, http.StatusForbidden, 0},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), validBulk, http.StatusForbidden, 0},
+		{"Empty list", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowBulk{}, http.StatusBadRequest, 0},
+		{"Invalid show", os.Getenv("CLAIM_ROLE_ADMIN"), invalidBulk, http.StatusBadRequest, 0},
+		{"Conflict rolls back all", os.Getenv("CLAIM_ROLE_ADMIN"), conflictingBulk, http.StatusConflict, 0},
+		{"Success Admin", os.Getenv("CLAIM_ROLE_ADMIN"), validBulk, http.StatusCreated, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/movie-shows/bulk", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus == http.StatusCreated {
+				var ids []string
+				parseResponseBody(t, resp, &ids)
+				if len(ids) != len(validBulk.Shows) {
+					t.Errorf("Expected %d IDs, got %d", len(validBulk.Shows), len(ids))
+				}
+			}
+
+			var count int
+			err := TestAdminDB.QueryRow(context.Background(),
+				"SELECT COUNT(*) FROM movie_shows WHERE hall_id = $1", HallsData[3].ID).Scan(&count)
+			if err != nil {
+				t.Fatalf("Failed to count movie shows: %v", err)
+			}
+			if count != tt.expectedShows {
+				t.Errorf("Expected %d shows in hall, got %d", tt.expectedShows, count)
+			}
+		})
+	}
+}
+
+func TestValidateMovieShow(t *testing.T) {
+	existing := MovieShowsData[0]
+
+	overlapping := MovieShowData{
+		MovieID:   MoviesData[1].ID,
+		HallID:    existing.HallID,
+		StartTime: existing.StartTime.Add(30 * time.Minute),
+		Language:  "Русский",
+	}
+
+	free := overlapping
+	free.StartTime = existing.StartTime.Add(12 * time.Hour)
+
+	tests := []struct {
+		name              string
+		role              string
+		query             string
+		body              interface{}
+		expectedStatus    int
+		expectedValid     bool
+		expectedConflicts int
+	}{
+		{"Forbidden Guest", "", "", overlapping, http.StatusForbidden, false, 0},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), "", overlapping, http.StatusForbidden, false, 0},
+		{"Invalid JSON", os.Getenv("CLAIM_ROLE_ADMIN"), "", "{invalid json}", http.StatusBadRequest, false, 0},
+		{"Invalid show ID", os.Getenv("CLAIM_ROLE_ADMIN"), "?show_id=abc", overlapping, http.StatusBadRequest, false, 0},
+		{"Unknown hall", os.Getenv("CLAIM_ROLE_ADMIN"), "", MovieShowData{MovieID: MoviesData[1].ID, HallID: uuid.New().String(), StartTime: free.StartTime, Language: "Русский"}, http.StatusNotFound, false, 0},
+		{"Conflicting show", os.Getenv("CLAIM_ROLE_ADMIN"), "", overlapping, http.StatusOK, false, 1},
+		{"Conflict with itself is ignored", os.Getenv("CLAIM_ROLE_ADMIN"), "?show_id=" + existing.ID, overlapping, http.StatusOK, true, 0},
+		{"Free time", os.Getenv("CLAIM_ROLE_ADMIN"), "", free, http.StatusOK, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/movie-shows/validate"+tt.query, generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result MovieShowValidation
+			parseResponseBody(t, resp, &result)
+			if result.Valid != tt.expectedValid {
+				t.Errorf("Expected valid=%v, got %v", tt.expectedValid, result.Valid)
+			}
+			if len(result.Conflicts) != tt.expectedConflicts {
+				t.Fatalf("Expected %d conflicts, got %d", tt.expectedConflicts, len(result.Conflicts))
+			}
+			if tt.expectedConflicts > 0 && result.Conflicts[0].ID != existing.ID {
+				t.Errorf("Expected conflict with %s, got %s", existing.ID, result.Conflicts[0].ID)
+			}
+
+			var count int
+			if err := TestAdminDB.QueryRow(context.Background(), "SELECT COUNT(*) FROM movie_shows").Scan(&count); err != nil {
+				t.Fatalf("Failed to count movie shows: %v", err)
+			}
+			if count != len(MovieShowsData) {
+				t.Errorf("Expected validation to leave %d shows, got %d", len(MovieShowsData), count)
+			}
+		})
+	}
+}
+
+func TestCancelMovieShow(t *testing.T) {
+	validCancel := MovieShowCancel{Reason: "Техническая неисправность проектора"}
+
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		body           interface{}
+		setup          func(t *testing.T)
+		expectedStatus int
+		expectedResult MovieShowCancelResult
+	}{
+		{"Forbidden Guest", "", MovieShowsData[0].ID, validCancel, nil, http.StatusForbidden, MovieShowCancelResult{}},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), MovieShowsData[0].ID, validCancel, nil, http.StatusForbidden, MovieShowCancelResult{}},
+		{"Invalid ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", validCancel, nil, http.StatusBadRequest, MovieShowCancelResult{}},
+		{"Empty reason", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowCancel{Reason: "   "}, nil, http.StatusBadRequest, MovieShowCancelResult{}},
+		{"Non-existent show", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), validCancel, nil, http.StatusNotFound, MovieShowCancelResult{}},
+		{"Already cancelled", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, validCancel, func(t *testing.T) {
+			_, err := TestAdminDB.Exec(context.Background(),
+				"UPDATE movie_shows SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP, cancel_reason = 'Тест' WHERE id = $1", MovieShowsData[0].ID)
+			if err != nil {
+				t.Fatalf("Failed to cancel movie show: %v", err)
+			}
+		}, http.StatusConflict, MovieShowCancelResult{}},
+		{"Finished show", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, validCancel, func(t *testing.T) {
+			_, err := RefreshMovieShowStatuses(context.Background(), TestAdminDB, MovieShowsData[0].StartTime.Add(10*time.Hour))
+			if err != nil {
+				t.Fatalf("Failed to refresh movie show statuses: %v", err)
+			}
+		}, http.StatusConflict, MovieShowCancelResult{}},
+		{"Refunds purchased tickets", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, validCancel, nil, http.StatusOK,
+			MovieShowCancelResult{RefundedTickets: 1, ReleasedReservations: 0, NotifiedUsers: 1}},
+		{"Releases reservations", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[1].ID, validCancel, nil, http.StatusOK,
+			MovieShowCancelResult{RefundedTickets: 0, ReleasedReservations: 1, NotifiedUsers: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+
+			req := createRequest(t, "POST", ts.URL+"/movie-shows/"+tt.id+"/cancel", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var result MovieShowCancelResult
+			parseResponseBody(t, resp, &result)
+			if result != tt.expectedResult {
+				t.Errorf("Expected %+v, got %+v", tt.expectedResult, result)
+			}
+
+			var activeTickets int
+			err := TestAdminDB.QueryRow(context.Background(),
+				"SELECT COUNT(*) FROM tickets WHERE movie_show_id = $1 AND ticket_status IN ('Purchased', 'Reserved')", tt.id).
+				Scan(&activeTickets)
+			if err != nil {
+				t.Fatalf("Failed to count tickets: %v", err)
+			}
+			if activeTickets != 0 {
+				t.Errorf("Expected no purchased or reserved tickets, got %d", activeTickets)
+			}
+
+			var show MovieShow
+			showReq := createRequest(t, "GET", ts.URL+"/movie-shows/"+tt.id, generateToken(t, tt.role), nil)
+			showResp := executeRequest(t, showReq, http.StatusOK)
+			defer showResp.Body.Close()
+			parseResponseBody(t, showResp, &show)
+			if show.CancelledAt == nil || show.CancelReason == nil || *show.CancelReason != validCancel.Reason {
+				t.Errorf("Expected show to be marked cancelled, got %+v", show)
+			}
+
+			var shows Page[MovieShow]
+			listReq := createRequest(t, "GET", ts.URL+"/movie-shows", generateToken(t, tt.role), nil)
+			listResp := executeRequest(t, listReq, http.StatusOK)
+			defer listResp.Body.Close()
+			parseResponseBody(t, listResp, &shows)
+			for _, s := range shows.Items {
+				if s.ID == tt.id {
+					t.Error("Expected cancelled show to be excluded from listing")
+				}
+			}
+		})
+	}
+
+	t.Run("Revenue is reduced by refunded tickets", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		defer ts.Close()
+
+		req := createRequest(t, "POST", ts.URL+"/movie-shows/"+MovieShowsData[0].ID+"/cancel", generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), validCancel)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+
+		var revenue float64
+		err := TestAdminDB.QueryRow(context.Background(),
+			"SELECT box_office_revenue FROM movies WHERE id = $1", MoviesData[0].ID).Scan(&revenue)
+		if err != nil {
+			t.Fatalf("Failed to get revenue: %v", err)
+		}
+		expected := MoviesData[0].BoxOfficeRevenue - TicketsData[0].Price
+		if math.Abs(revenue-expected) > 0.01 {
+			t.Errorf("Expected revenue %.2f, got %.2f", expected, revenue)
+		}
+	})
+
+	t.Run("Cancelled show frees the hall", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		defer ts.Close()
+
+		req := createRequest(t, "POST", ts.URL+"/movie-shows/"+MovieShowsData[0].ID+"/cancel", generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), validCancel)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+
+		_, err := TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			MoviesData[1].ID, MovieShowsData[0].HallID, MovieShowsData[0].StartTime, "Русский")
+		if err != nil {
+			t.Errorf("Expected hall to be free after cancellation, got error: %v", err)
+		}
+	})
+}
+
+func TestRemapSeats(t *testing.T) {
+	standard, vip := "standard", "vip"
+	seats := []Seat{
+		{ID: "new-1-1", SeatTypeID: standard, RowNumber: 1, SeatNumber: 1},
+		{ID: "new-1-2", SeatTypeID: standard, RowNumber: 1, SeatNumber: 2},
+		{ID: "new-5-1", SeatTypeID: standard, RowNumber: 5, SeatNumber: 1},
+		{ID: "new-9-9", SeatTypeID: vip, RowNumber: 9, SeatNumber: 9},
+	}
+
+	t.Run("Same position is preferred", func(t *testing.T) {
+		sold := []soldSeat{
+			{TicketID: "t1", Seat: Seat{ID: "old-1-2", SeatTypeID: standard, RowNumber: 1, SeatNumber: 2}},
+			{TicketID: "t2", Seat: Seat{ID: "old-9-9", SeatTypeID: vip, RowNumber: 9, SeatNumber: 9}},
+		}
+		remaps, unmappable := remapSeats(sold, seats)
+		if len(unmappable) != 0 {
+			t.Fatalf("Expected all seats to be mapped, got %v", unmappable)
+		}
+		if remaps[0].ToSeatID != "new-1-2" || remaps[1].ToSeatID != "new-9-9" {
+			t.Errorf("Expected seats at the same position, got %v", remaps)
+		}
+	})
+
+	t.Run("Nearest seat of the same type", func(t *testing.T) {
+		sold := []soldSeat{
+			{TicketID: "t1", Seat: Seat{ID: "old-4-3", SeatTypeID: standard, RowNumber: 4, SeatNumber: 3}},
+			{TicketID: "t2", Seat: Seat{ID: "old-1-1", SeatTypeID: standard, RowNumber: 1, SeatNumber: 1}},
+		}
+		remaps, unmappable := remapSeats(sold, seats)
+		if len(unmappable) != 0 {
+			t.Fatalf("Expected all seats to be mapped, got %v", unmappable)
+		}
+		if remaps[0].ToSeatID != "new-5-1" {
+			t.Errorf("Expected nearest row for t1, got %s", remaps[0].ToSeatID)
+		}
+		if remaps[1].ToSeatID != "new-1-1" {
+			t.Errorf("Expected exact seat to be kept for t2, got %s", remaps[1].ToSeatID)
+		}
+	})
+
+	t.Run("Exact matches are assigned before nearest ones", func(t *testing.T) {
+		sold := []soldSeat{
+			{TicketID: "t1", Seat: Seat{ID: "old-2-1", SeatTypeID: standard, RowNumber: 2, SeatNumber: 1}},
+			{TicketID: "t2", Seat: Seat{ID: "old-1-1", SeatTypeID: standard, RowNumber: 1, SeatNumber: 1}},
+		}
+		remaps, _ := remapSeats(sold, seats)
+		if remaps[1].ToSeatID != "new-1-1" {
+			t.Errorf("Expected exact seat for t2, got %s", remaps[1].ToSeatID)
+		}
+		if remaps[0].ToSeatID == "new-1-1" {
+			t.Error("Expected t1 not to take the seat exactly matching t2")
+		}
+	})
+
+	t.Run("Missing seat type is unmappable", func(t *testing.T) {
+		sold := []soldSeat{
+			{TicketID: "t1", Seat: Seat{ID: "old-9-9", SeatTypeID: vip, RowNumber: 9, SeatNumber: 9}},
+			{TicketID: "t2", Seat: Seat{ID: "old-9-8", SeatTypeID: vip, RowNumber: 9, SeatNumber: 8}},
+			{TicketID: "t3", Seat: Seat{ID: "old-lux", SeatTypeID: "lux", RowNumber: 1, SeatNumber: 1}},
+		}
+		remaps, unmappable := remapSeats(sold, seats)
+		if len(remaps) != 1 || remaps[0].TicketID != "t1" {
+			t.Errorf("Expected only t1 to be mapped, got %v", remaps)
+		}
+		if len(unmappable) != 2 || unmappable[0].TicketID != "t2" || unmappable[1].TicketID != "t3" {
+			t.Errorf("Expected t2 and t3 to be unmappable, got %v", unmappable)
+		}
+	})
+
+	t.Run("Unavailable seats are not moved", func(t *testing.T) {
+		sold := []soldSeat{
+			{TicketID: "t1", Seat: seats[0]},
+			{TicketID: "t2", Seat: Seat{ID: "blocked-1-3", SeatTypeID: standard, RowNumber: 1, SeatNumber: 3}},
+		}
+		unmappable := unavailableSeats(sold, seats)
+		if len(unmappable) != 1 || unmappable[0].TicketID != "t2" || unmappable[0].SeatID != "blocked-1-3" {
+			t.Errorf("Expected only t2 to be reported, got %v", unmappable)
+		}
+	})
+}
+
+func TestRescheduleMovieShow(t *testing.T) {
+	newStart := MovieShowsData[0].StartTime.Add(time.Hour)
+	newHall := HallsData[3].ID
+	emptyHall := HallsData[2].ID
+	unknownHall := uuid.New().String()
+
+	newSeats := []Seat{
+		{ID: uuid.New().String(), HallID: newHall, SeatTypeID: SeatsData[0].SeatTypeID, RowNumber: 1, SeatNumber: 1},
+		{ID: uuid.New().String(), HallID: newHall, SeatTypeID: SeatsData[0].SeatTypeID, RowNumber: 1, SeatNumber: 2},
+	}
+	seedNewHall := func(t *testing.T) {
+		for _, seat := range newSeats {
+			_, err := TestAdminDB.Exec(context.Background(),
+				"INSERT INTO seats (id, hall_id, seat_type_id, row_number, seat_number) VALUES ($1, $2, $3, $4, $5)",
+				seat.ID, seat.HallID, seat.SeatTypeID, seat.RowNumber, seat.SeatNumber)
+			if err != nil {
+				t.Fatalf("Failed to insert seat: %v", err)
+			}
+		}
+	}
+	blockSeat := func(t *testing.T, seatID string, from time.Time) {
+		_, err := TestAdminDB.Exec(context.Background(),
+			"UPDATE seats SET service_state = 'blocked', service_reason = 'Ремонт', service_from = $1 WHERE id = $2", from, seatID)
+		if err != nil {
+			t.Fatalf("Failed to block seat: %v", err)
+		}
+	}
+	// Место проданного билета выводится из обслуживания за два часа до позднего сеанса: текущий сеанс это не затрагивает
+	lateStart := MovieShowsData[0].StartTime.Add(12 * time.Hour)
+	blockBeforeLateStart := func(t *testing.T) {
+		blockSeat(t, TicketsData[0].SeatID, lateStart.Add(-2*time.Hour))
+	}
+
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		body           interface{}
+		setup          func(t *testing.T)
+		expectedStatus int
+		check          func(t *testing.T, resp *http.Response)
+	}{
+		{"Forbidden Guest", "", MovieShowsData[0].ID, MovieShowReschedule{StartTime: &newStart}, nil, http.StatusForbidden, nil},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), MovieShowsData[0].ID, MovieShowReschedule{StartTime: &newStart}, nil, http.StatusForbidden, nil},
+		{"Invalid ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", MovieShowReschedule{StartTime: &newStart}, nil, http.StatusBadRequest, nil},
+		{"Nothing to change", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{}, nil, http.StatusBadRequest, nil},
+		{"Non-existent show", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), MovieShowReschedule{StartTime: &newStart}, nil, http.StatusNotFound, nil},
+		{"Unknown hall", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{HallID: &unknownHall}, nil, http.StatusNotFound, nil},
+		{"Unmappable seats", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{HallID: &emptyHall}, nil, http.StatusConflict,
+			func(t *testing.T, resp *http.Response) {
+				var conflict MovieShowRescheduleConflict
+				parseResponseBody(t, resp, &conflict)
+				if len(conflict.UnmappableSeats) != 1 || conflict.UnmappableSeats[0].TicketID != TicketsData[0].ID {
+					t.Errorf("Expected purchased ticket to be reported as unmappable, got %+v", conflict.UnmappableSeats)
+				}
+
+				var hallID string
+				err := TestAdminDB.QueryRow(context.Background(),
+					"SELECT hall_id FROM movie_shows WHERE id = $1", MovieShowsData[0].ID).Scan(&hallID)
+				if err != nil {
+					t.Fatalf("Failed to get movie show: %v", err)
+				}
+				if hallID != MovieShowsData[0].HallID {
+					t.Error("Expected show to stay in its hall")
+				}
+			}},
+		{"Time change keeps tickets", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{StartTime: &newStart}, nil, http.StatusOK,
+			func(t *testing.T, resp *http.Response) {
+				var result MovieShowRescheduleResult
+				parseResponseBody(t, resp, &result)
+				if result.RemappedTickets != 0 || result.RegeneratedTickets != 0 || result.NotifiedUsers != 1 {
+					t.Errorf("Unexpected result %+v", result)
+				}
+
+				var seatID string
+				err := TestAdminDB.QueryRow(context.Background(),
+					"SELECT seat_id FROM tickets WHERE id = $1", TicketsData[0].ID).Scan(&seatID)
+				if err != nil {
+					t.Fatalf("Failed to get ticket: %v", err)
+				}
+				if seatID != TicketsData[0].SeatID {
+					t.Error("Expected ticket to keep its seat")
+				}
+			}},
+		{"Time change onto a blocked seat", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{StartTime: &lateStart}, blockBeforeLateStart, http.StatusConflict,
+			func(t *testing.T, resp *http.Response) {
+				var conflict MovieShowRescheduleConflict
+				parseResponseBody(t, resp, &conflict)
+				if len(conflict.UnmappableSeats) != 1 || conflict.UnmappableSeats[0].TicketID != TicketsData[0].ID {
+					t.Errorf("Expected purchased ticket to be reported as unmappable, got %+v", conflict.UnmappableSeats)
+				}
+
+				var startTime time.Time
+				err := TestAdminDB.QueryRow(context.Background(),
+					"SELECT start_time FROM movie_shows WHERE id = $1", MovieShowsData[0].ID).Scan(&startTime)
+				if err != nil {
+					t.Fatalf("Failed to get movie show: %v", err)
+				}
+				if startTime.Sub(MovieShowsData[0].StartTime).Abs() > time.Millisecond {
+					t.Errorf("Expected show to keep its start time, got %s", startTime)
+				}
+			}},
+		{"Time change before the seat is blocked", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{StartTime: &newStart},
+			func(t *testing.T) { blockSeat(t, TicketsData[0].SeatID, lateStart) }, http.StatusOK, nil},
+		{"Hall change skips blocked seats", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{HallID: &newHall},
+			func(t *testing.T) {
+				seedNewHall(t)
+				blockSeat(t, newSeats[0].ID, time.Now())
+			}, http.StatusOK,
+			func(t *testing.T, resp *http.Response) {
+				var result MovieShowRescheduleResult
+				parseResponseBody(t, resp, &result)
+				if len(result.Remaps) != 1 || result.Remaps[0].ToSeatID != newSeats[1].ID {
+					t.Errorf("Expected ticket to move to the serviceable seat %s, got %+v", newSeats[1].ID, result.Remaps)
+				}
+			}},
+		{"Hall change remaps tickets", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowReschedule{HallID: &newHall}, seedNewHall, http.StatusOK,
+			func(t *testing.T, resp *http.Response) {
+				var result MovieShowRescheduleResult
+				parseResponseBody(t, resp, &result)
+				if result.RemappedTickets != 1 || result.RegeneratedTickets != 1 || result.NotifiedUsers != 1 {
+					t.Errorf("Unexpected result %+v", result)
+				}
+
+				var seatID string
+				var status TicketStatusEnumType
+				err := TestAdminDB.QueryRow(context.Background(),
+					"SELECT seat_id, ticket_status FROM tickets WHERE id = $1", TicketsData[0].ID).Scan(&seatID, &status)
+				if err != nil {
+					t.Fatalf("Failed to get ticket: %v", err)
+				}
+				if seatID != newSeats[0].ID || status != Purchased {
+					t.Errorf("Expected purchased ticket on seat %s, got %s (%s)", newSeats[0].ID, seatID, status)
+				}
+
+				var show MovieShow
+				err = TestAdminDB.QueryRow(context.Background(),
+					"SELECT hall_id, status FROM movie_shows WHERE id = $1", MovieShowsData[0].ID).Scan(&show.HallID, &show.Status)
+				if err != nil {
+					t.Fatalf("Failed to get movie show: %v", err)
+				}
+				if show.HallID != newHall || show.Status != ShowOnSale {
+					t.Errorf("Expected show on sale in the new hall, got %+v", show)
+				}
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+
+			req := createRequest(t, "POST", ts.URL+"/movie-shows/"+tt.id+"/reschedule", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+		})
+	}
+}
+
+func TestShowStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from, to ShowStatusEnumType
+		allowed  bool
+	}{
+		{ShowScheduled, ShowOnSale, true},
+		{ShowOnSale, ShowSoldOut, true},
+		{ShowSoldOut, ShowOnSale, true},
+		{ShowOnSale, ShowInProgress, true},
+		{ShowInProgress, ShowFinished, true},
+		{ShowOnSale, ShowCancelled, true},
+		{ShowOnSale, ShowOnSale, true},
+		{ShowScheduled, ShowSoldOut, false},
+		{ShowOnSale, ShowFinished, false},
+		{ShowInProgress, ShowCancelled, false},
+		{ShowFinished, ShowInProgress, false},
+		{ShowCancelled, ShowOnSale, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s to %s", tt.from, tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.allowed {
+				t.Errorf("Expected %v, got %v", tt.allowed, got)
+			}
+		})
+	}
+}
+
+func TestUpdateMovieShowStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Forbidden Guest", "", MovieShowsData[2].ID, MovieShowStatusUpdate{Status: ShowScheduled}, http.StatusForbidden},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), MovieShowsData[2].ID, MovieShowStatusUpdate{Status: ShowScheduled}, http.StatusForbidden},
+		{"Invalid ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", MovieShowStatusUpdate{Status: ShowScheduled}, http.StatusBadRequest},
+		{"Unknown status", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[2].ID, MovieShowStatusUpdate{Status: "postponed"}, http.StatusBadRequest},
+		{"Cancel through status", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[2].ID, MovieShowStatusUpdate{Status: ShowCancelled}, http.StatusBadRequest},
+		{"Non-existent show", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), MovieShowStatusUpdate{Status: ShowScheduled}, http.StatusNotFound},
+		{"Invalid transition", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowStatusUpdate{Status: ShowFinished}, http.StatusConflict},
+		{"Close sales", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[2].ID, MovieShowStatusUpdate{Status: ShowScheduled}, http.StatusOK},
+		{"Start show", os.Getenv("CLAIM_ROLE_ADMIN"), MovieShowsData[0].ID, MovieShowStatusUpdate{Status: ShowInProgress}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "PUT", ts.URL+"/movie-shows/"+tt.id+"/status", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var show MovieShow
+			showReq := createRequest(t, "GET", ts.URL+"/movie-shows/"+tt.id, generateToken(t, tt.role), nil)
+			showResp := executeRequest(t, showReq, http.StatusOK)
+			defer showResp.Body.Close()
+			parseResponseBody(t, showResp, &show)
+			if show.Status != tt.body.(MovieShowStatusUpdate).Status {
+				t.Errorf("Expected status %s, got %s", tt.body.(MovieShowStatusUpdate).Status, show.Status)
+			}
+		})
+	}
+
+	t.Run("Trigger rejects invalid transition", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		defer ts.Close()
+
+		_, err := TestAdminDB.Exec(context.Background(),
+			"UPDATE movie_shows SET status = 'finished' WHERE id = $1", MovieShowsData[2].ID)
+		if err == nil || !strings.Contains(err.Error(), "Недопустимый переход статуса киносеанса") {
+			t.Errorf("Expected transition error, got %v", err)
+		}
+	})
+}
+
+func TestMovieShowAutomaticStatus(t *testing.T) {
+	getStatus := func(t *testing.T, id string) ShowStatusEnumType {
+		t.Helper()
+		var status ShowStatusEnumType
+		err := TestAdminDB.QueryRow(context.Background(),
+			"SELECT status FROM movie_shows WHERE id = $1", id).Scan(&status)
+		if err != nil {
+			t.Fatalf("Failed to get movie show status: %v", err)
+		}
+		return status
+	}
+
+	t.Run("Sold out and back on sale", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		defer ts.Close()
+
+		if status := getStatus(t, MovieShowsData[0].ID); status != ShowSoldOut {
+			t.Errorf("Expected show without available tickets to be sold out, got %s", status)
+		}
+		if status := getStatus(t, MovieShowsData[2].ID); status != ShowOnSale {
+			t.Fatalf("Expected show with available tickets to be on sale, got %s", status)
+		}
+
+		body := TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: true}
+		req := createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+TicketsData[2].ID, generateToken(t, os.Getenv("CLAIM_ROLE_USER")), body)
+		resp := executeRequest(t, req, http.StatusOK)
+		resp.Body.Close()
+
+		if status := getStatus(t, MovieShowsData[2].ID); status != ShowSoldOut {
+			t.Errorf("Expected show to be sold out after the last ticket is reserved, got %s", status)
+		}
+
+		body.Reserve = false
+		req = createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+TicketsData[2].ID, generateToken(t, os.Getenv("CLAIM_ROLE_USER")), body)
+		resp = executeRequest(t, req, http.StatusOK)
+		resp.Body.Close()
+
+		if status := getStatus(t, MovieShowsData[2].ID); status != ShowOnSale {
+			t.Errorf("Expected show to be back on sale after the reservation is returned, got %s", status)
+		}
+	})
+
+	t.Run("Time based transitions", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		defer ts.Close()
+
+		ctx := context.Background()
+		updated, err := RefreshMovieShowStatuses(ctx, TestAdminDB, MovieShowsData[0].StartTime.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to refresh movie show statuses: %v", err)
+		}
+		if updated != 1 {
+			t.Errorf("Expected 1 updated show, got %d", updated)
+		}
+		if status := getStatus(t, MovieShowsData[0].ID); status != ShowInProgress {
+			t.Errorf("Expected started show to be in progress, got %s", status)
+		}
+		if status := getStatus(t, MovieShowsData[1].ID); status != ShowSoldOut {
+			t.Errorf("Expected future show to keep its status, got %s", status)
+		}
+
+		if _, err := RefreshMovieShowStatuses(ctx, TestAdminDB, MovieShowsData[0].StartTime.Add(3*time.Hour)); err != nil {
+			t.Fatalf("Failed to refresh movie show statuses: %v", err)
+		}
+		if status := getStatus(t, MovieShowsData[0].ID); status != ShowFinished {
+			t.Errorf("Expected ended show to be finished, got %s", status)
+		}
+	})
+}
+
+func TestMovieShowStatusFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{"Default excludes cancelled", "/movie-shows",
+			http.StatusOK, []string{MovieShowsData[0].ID, MovieShowsData[1].ID, MovieShowsData[3].ID}},
+		{"Sold out only", "/movie-shows?status=sold_out",
+			http.StatusOK, []string{MovieShowsData[0].ID, MovieShowsData[1].ID}},
+		{"Cancelled only", "/movie-shows?status=cancelled",
+			http.StatusOK, []string{MovieShowsData[2].ID}},
+		{"Several statuses", "/movie-shows/upcoming?hours=72&status=on_sale,cancelled",
+			http.StatusOK, []string{MovieShowsData[2].ID, MovieShowsData[3].ID}},
+		{"By movie", "/movies/" + MoviesData[2].ID + "/shows?hours=72&status=cancelled",
+			http.StatusOK, []string{MovieShowsData[2].ID}},
+		{"By date", "/dates/" + MovieShowsData[0].StartTime.UTC().Format(time.DateOnly) + "/movie-shows?status=finished",
+			http.StatusOK, nil},
+		{"Unknown status", "/movie-shows?status=postponed", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			_, err := TestAdminDB.Exec(context.Background(),
+				"UPDATE movie_shows SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP, cancel_reason = 'Тест' WHERE id = $1",
+				MovieShowsData[2].ID)
+			if err != nil {
+				t.Fatalf("Failed to cancel movie show: %v", err)
+			}
+
+			req := createRequest(t, "GET", ts.URL+tt.url, generateToken(t, ""), nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var page Page[MovieShow]
+			parseResponseBody(t, resp, &page)
+			shows := page.Items
+
+			got := make(map[string]bool)
+			for _, s := range shows {
+				got[s.ID] = true
+			}
+			if len(got) != len(tt.expectedIDs) {
+				t.Errorf("Expected %d shows, got %d", len(tt.expectedIDs), len(got))
+			}
+			for _, id := range tt.expectedIDs {
+				if !got[id] {
+					t.Errorf("Expected show %s in response", id)
+				}
+			}
+		})
+	}
+}
+
+func TestMovieShowConflictTrigger(t *testing.T) {
+	t.Run("No conflict - different halls", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
+			t.Fatalf("Failed to clear database")
+		}
+		defer ts.Close()
+
+		hall1 := HallsData[0].ID
+		hall2 := HallsData[1].ID
+		movieID := MoviesData[2].ID
+
+		startTime1 := time.Now().Add(24 * time.Hour)
+		_, err := TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hall1, startTime1, "Русский")
+		if err != nil {
+			t.Fatalf("Failed to insert first movie show: %v", err)
+		}
+
+		_, err = TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hall2, startTime1, "Русский")
+		if err != nil {
+			t.Errorf("Expected no conflict for different halls, got error: %v", err)
+		}
+	})
+
+	t.Run("No conflict - same hall, different times", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
+			t.Fatalf("Failed to clear database")
+		}
+		defer ts.Close()
+
+		hallID := HallsData[0].ID
+		movieID := MoviesData[1].ID
+
+		startTime1 := time.Now().Add(24 * time.Hour)
+		_, err := TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hallID, startTime1, "Русский")
+		if err != nil {
+			t.Fatalf("Failed to insert first movie show: %v", err)
+		}
+
+		startTime2 := startTime1.Add(3*time.Hour + 30*time.Minute)
+		_, err = TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hallID, startTime2, "Русский")
+		if err != nil {
+			t.Errorf("Expected no conflict for sufficient time gap, got error: %v", err)
+		}
+	})
+
+	t.Run("Conflict - same hall, overlapping times", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
+			t.Fatalf("Failed to clear database")
+		}
+		defer ts.Close()
+
+		hallID := HallsData[0].ID
+		movieID := MoviesData[1].ID
+
+		startTime1 := time.Now().Add(24 * time.Hour)
+		_, err := TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hallID, startTime1, "Русский")
+		if err != nil {
+			t.Fatalf("Failed to insert first movie show: %v", err)
+		}
+
+		startTime2 := startTime1.Add(1 * time.Hour)
+		_, err = TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hallID, startTime2, "Русский")
+
+		if err == nil {
+			t.Error("Expected conflict error, got no error")
+		} else {
+			if !isExclusionViolation(err) {
+				t.Errorf("Expected exclusion violation, got: %v", err)
+			}
+		}
+	})
+
+	t.Run("Conflict - cleaning time between shows", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
+			t.Fatalf("Failed to clear database")
+		}
+		defer ts.Close()
+
+		hallID := HallsData[0].ID
+		movieID := MoviesData[1].ID
+
+		startTime1 := time.Now().Add(24 * time.Hour)
+		_, err := TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hallID, startTime1, "Русский")
+		if err != nil {
+			t.Fatalf("Failed to insert first movie show: %v", err)
+		}
+
+		startTime2 := startTime1.Add(1 * time.Hour)
+		_, err = TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hallID, startTime2, "Русский")
+
+		if err == nil {
+			t.Error("Expected conflict error for cleaning time, got no error")
+		} else {
+			if !isExclusionViolation(err) {
+				t.Errorf("Expected cleaning time exclusion violation, got: %v", err)
+			}
+		}
+	})
+
+	t.Run("No conflict - exact cleaning time between shows", func(t *testing.T) {
 		ts := setupTestServer()
 		SeedAll(TestAdminDB)
 		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
@@ -534,27 +1414,30 @@ func TestMovieShowConflictTrigger(t *testing.T) {
 		}
 		defer ts.Close()
 
-		hall1 := HallsData[0].ID
-		hall2 := HallsData[1].ID
-		movieID := MoviesData[2].ID
+		hallID := HallsData[0].ID
+		movieID := MoviesData[1].ID
+		movieDuration := MoviesData[1].Duration
 
 		startTime1 := time.Now().Add(24 * time.Hour)
 		_, err := TestAdminDB.Exec(context.Background(),
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hall1, startTime1, Russian)
+			movieID, hallID, startTime1, "Русский")
 		if err != nil {
 			t.Fatalf("Failed to insert first movie show: %v", err)
 		}
 
+		parsedDuration, _ := time.Parse("15:04:05", movieDuration)
+		startTime2 := startTime1.Add(parsedDuration.Sub(time.Time{}) + 10*time.Minute)
 		_, err = TestAdminDB.Exec(context.Background(),
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hall2, startTime1, Russian)
+			movieID, hallID, startTime2, "Русский")
+
 		if err != nil {
-			t.Errorf("Expected no conflict for different halls, got error: %v", err)
+			t.Errorf("Expected no conflict with exact cleaning time gap, got error: %v", err)
 		}
 	})
 
-	t.Run("No conflict - same hall, different times", func(t *testing.T) {
+	t.Run("Conflict - error names conflicting show", func(t *testing.T) {
 		ts := setupTestServer()
 		SeedAll(TestAdminDB)
 		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
@@ -565,24 +1448,33 @@ func TestMovieShowConflictTrigger(t *testing.T) {
 		hallID := HallsData[0].ID
 		movieID := MoviesData[1].ID
 
+		var existingID string
 		startTime1 := time.Now().Add(24 * time.Hour)
-		_, err := TestAdminDB.Exec(context.Background(),
-			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime1, Russian)
+		err := TestAdminDB.QueryRow(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4) RETURNING id",
+			movieID, hallID, startTime1, "Русский").Scan(&existingID)
 		if err != nil {
 			t.Fatalf("Failed to insert first movie show: %v", err)
 		}
 
-		startTime2 := startTime1.Add(3*time.Hour + 30*time.Minute)
-		_, err = TestAdminDB.Exec(context.Background(),
-			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime2, Russian)
-		if err != nil {
-			t.Errorf("Expected no conflict for sufficient time gap, got error: %v", err)
+		show := MovieShowAdmin{
+			MovieID:   movieID,
+			HallID:    hallID,
+			StartTime: startTime1.Add(30 * time.Minute),
+			Language:  "Русский",
+			BasePrice: 300,
+		}
+		req := createRequest(t, "POST", ts.URL+"/movie-shows", generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), show)
+		resp := executeRequest(t, req, http.StatusConflict)
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if !strings.Contains(string(body), existingID) {
+			t.Errorf("Expected conflict message to name show %s, got: %s", existingID, body)
 		}
 	})
 
-	t.Run("Conflict - same hall, overlapping times", func(t *testing.T) {
+	t.Run("Conflict - hall cleaning time overrides default", func(t *testing.T) {
 		ts := setupTestServer()
 		SeedAll(TestAdminDB)
 		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
@@ -592,30 +1484,39 @@ func TestMovieShowConflictTrigger(t *testing.T) {
 
 		hallID := HallsData[0].ID
 		movieID := MoviesData[1].ID
+		parsedDuration, _ := time.Parse("15:04:05", MoviesData[1].Duration)
+
+		if _, err := TestAdminDB.Exec(context.Background(),
+			"UPDATE halls SET cleaning_minutes = 30 WHERE id = $1", hallID); err != nil {
+			t.Fatalf("Failed to update hall: %v", err)
+		}
 
 		startTime1 := time.Now().Add(24 * time.Hour)
 		_, err := TestAdminDB.Exec(context.Background(),
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime1, Russian)
+			movieID, hallID, startTime1, "Русский")
 		if err != nil {
 			t.Fatalf("Failed to insert first movie show: %v", err)
 		}
 
-		startTime2 := startTime1.Add(1 * time.Hour)
+		startTime2 := startTime1.Add(parsedDuration.Sub(time.Time{}) + 20*time.Minute)
 		_, err = TestAdminDB.Exec(context.Background(),
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime2, Russian)
+			movieID, hallID, startTime2, "Русский")
+		if !isExclusionViolation(err) {
+			t.Errorf("Expected cleaning time conflict with 30 minutes, got: %v", err)
+		}
 
-		if err == nil {
-			t.Error("Expected conflict error, got no error")
-		} else {
-			if !strings.Contains(err.Error(), "Невозможно запланировать показ") {
-				t.Errorf("Expected conflict error message, got: %v", err)
-			}
+		startTime3 := startTime1.Add(parsedDuration.Sub(time.Time{}) + 30*time.Minute)
+		_, err = TestAdminDB.Exec(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+			movieID, hallID, startTime3, "Русский")
+		if err != nil {
+			t.Errorf("Expected no conflict after hall cleaning time, got error: %v", err)
 		}
 	})
 
-	t.Run("Conflict - cleaning time between shows", func(t *testing.T) {
+	t.Run("Conflict - outside hall opening hours", func(t *testing.T) {
 		ts := setupTestServer()
 		SeedAll(TestAdminDB)
 		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
@@ -626,56 +1527,414 @@ func TestMovieShowConflictTrigger(t *testing.T) {
 		hallID := HallsData[0].ID
 		movieID := MoviesData[1].ID
 
-		startTime1 := time.Now().Add(24 * time.Hour)
+		if _, err := TestAdminDB.Exec(context.Background(),
+			"UPDATE halls SET opens_at = '10:00', closes_at = '22:00' WHERE id = $1", hallID); err != nil {
+			t.Fatalf("Failed to update hall: %v", err)
+		}
+
+		tomorrow := time.Now().Add(24 * time.Hour)
+		day := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC)
+
+		for _, start := range []time.Time{day.Add(8 * time.Hour), day.Add(21 * time.Hour)} {
+			_, err := TestAdminDB.Exec(context.Background(),
+				"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+				movieID, hallID, start, "Русский")
+			if err == nil || !strings.Contains(err.Error(), "вне часов работы") {
+				t.Errorf("Expected opening hours error for %v, got: %v", start, err)
+			}
+		}
+
 		_, err := TestAdminDB.Exec(context.Background(),
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime1, Russian)
+			movieID, hallID, day.Add(12*time.Hour), "Русский")
 		if err != nil {
-			t.Fatalf("Failed to insert first movie show: %v", err)
+			t.Errorf("Expected show within opening hours to be created, got error: %v", err)
+		}
+	})
+}
+
+func TestMovieShowConcurrentOverlap(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
+		t.Fatalf("Failed to clear database")
+	}
+	defer ts.Close()
+
+	ctx := context.Background()
+	startTime := time.Now().Add(24 * time.Hour)
+
+	txs := make([]pgx.Tx, 2)
+	for i := range txs {
+		tx, err := TestAdminDB.Begin(ctx)
+		if err != nil {
+			t.Fatalf("Failed to begin transaction: %v", err)
 		}
+		defer tx.Rollback(ctx)
+		txs[i] = tx
+	}
 
-		startTime2 := startTime1.Add(1 * time.Hour)
-		_, err = TestAdminDB.Exec(context.Background(),
+	_, err := txs[0].Exec(ctx,
+		"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
+		MoviesData[1].ID, HallsData[0].ID, startTime, "Русский")
+	if err != nil {
+		t.Fatalf("Failed to insert first movie show: %v", err)
+	}
+
+	// Вторая вставка ждёт завершения первой транзакции и получает нарушение ограничения
+	errc := make(chan error, 1)
+	go func() {
+		_, err := txs[1].Exec(ctx,
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime2, Russian)
+			MoviesData[1].ID, HallsData[0].ID, startTime.Add(30*time.Minute), "Русский")
+		errc <- err
+	}()
 
-		if err == nil {
-			t.Error("Expected conflict error for cleaning time, got no error")
-		} else {
-			if !strings.Contains(err.Error(), "будет проводиться уборка") {
-				t.Errorf("Expected cleaning time conflict error, got: %v", err)
+	time.Sleep(100 * time.Millisecond)
+	if err := txs[0].Commit(ctx); err != nil {
+		t.Fatalf("Failed to commit first transaction: %v", err)
+	}
+
+	if err := <-errc; !isExclusionViolation(err) {
+		t.Errorf("Expected exclusion violation for concurrent insert, got: %v", err)
+	}
+}
+
+func TestExclusionConflictMessage(t *testing.T) {
+	showID := uuid.New().String()
+	hallID := uuid.New().String()
+
+	tests := []struct {
+		name     string
+		err      error
+		timeZone string
+		ok       bool
+		contains []string
+	}{
+		{"Show overlap", &pgconn.PgError{
+			Code:           "23P01",
+			ConstraintName: "no_overlapping_movie_shows",
+			Detail: fmt.Sprintf(`Key (hall_id, occupied, id)=(%s, ["2026-10-19 10:30:00","2026-10-19 12:40:00"), %s) conflicts with existing key (hall_id, occupied, id)=(%s, ["2026-10-19 10:00:00.123","2026-10-19 12:10:00"), %s).`,
+				hallID, uuid.New(), hallID, showID),
+		}, "", true, []string{showID, "2026-10-19 10:00"}},
+		{"Show overlap in hall time zone", &pgconn.PgError{
+			Code:           "23P01",
+			ConstraintName: "no_overlapping_movie_shows",
+			Detail: fmt.Sprintf(`Key (hall_id, occupied, id)=(%s, ["2026-10-19 10:30:00+00","2026-10-19 12:40:00+00"), %s) conflicts with existing key (hall_id, occupied, id)=(%s, ["2026-10-19 10:00:00+00","2026-10-19 12:10:00+00"), %s).`,
+				hallID, uuid.New(), hallID, showID),
+		}, "Asia/Yekaterinburg", true, []string{showID, "2026-10-19 15:00"}},
+		{"Unparsable detail", &pgconn.PgError{Code: "23P01", ConstraintName: "no_overlapping_movie_shows"}, "", true, []string{"Невозможно запланировать показ"}},
+		{"Other constraint", &pgconn.PgError{Code: "23P01", ConstraintName: "other"}, "", false, nil},
+		{"Other error", fmt.Errorf("boom"), "", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, ok := exclusionConflictMessage(tt.err, tt.timeZone)
+			if ok != tt.ok {
+				t.Fatalf("Expected ok=%v, got %v", tt.ok, ok)
 			}
-		}
-	})
+			for _, s := range tt.contains {
+				if !strings.Contains(msg, s) {
+					t.Errorf("Expected message to contain %q, got %q", s, msg)
+				}
+			}
+		})
+	}
+}
 
-	t.Run("No conflict - exact cleaning time between shows", func(t *testing.T) {
+func TestUnknownReferenceMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+		ok       bool
+	}{
+		{"Unknown language on insert", &pgconn.PgError{Code: "23503", ConstraintName: "movie_show_language_fkey", TableName: "movie_shows"},
+			"Неизвестный язык киносеанса", true},
+		{"Unknown format on insert", &pgconn.PgError{Code: "23503", ConstraintName: "movie_show_format_fkey", TableName: "movie_show_formats"},
+			"Неизвестный формат показа", true},
+		{"Language still in use", &pgconn.PgError{Code: "23503", ConstraintName: "movie_show_language_fkey", TableName: "languages"}, "", false},
+		{"Other constraint", &pgconn.PgError{Code: "23503", ConstraintName: "tickets_seat_id_fkey", TableName: "tickets"}, "", false},
+		{"Not a pg error", errors.New("boom"), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, ok := unknownReferenceMessage(tt.err)
+			if ok != tt.ok || msg != tt.expected {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tt.expected, tt.ok, msg, ok)
+			}
+		})
+	}
+}
+
+func TestMovieShowTimeZones(t *testing.T) {
+	zone := CinemasData[1].TimeZone // Asia/Vladivostok: UTC+10 без перехода на летнее время
+
+	setup := func(t *testing.T) *httptest.Server {
+		t.Helper()
 		ts := setupTestServer()
 		SeedAll(TestAdminDB)
 		if err := ClearTable(TestAdminDB, "movie_shows"); err != nil {
 			t.Fatalf("Failed to clear database")
 		}
+		// Зал получает часовой пояс кинотеатра, в который его перенесли
+		if _, err := TestAdminDB.Exec(context.Background(),
+			"UPDATE halls SET cinema_id = $1 WHERE id = $2", CinemasData[1].ID, HallsData[0].ID); err != nil {
+			t.Fatalf("Failed to update hall: %v", err)
+		}
+		return ts
+	}
+
+	loc, err := LoadTimeZone(zone)
+	if err != nil {
+		t.Fatalf("Failed to load time zone: %v", err)
+	}
+	day := time.Now().UTC().AddDate(0, 0, 3).Truncate(24 * time.Hour)
+
+	t.Run("Start time with offset is rendered in hall zone", func(t *testing.T) {
+		ts := setup(t)
 		defer ts.Close()
 
-		hallID := HallsData[0].ID
-		movieID := MoviesData[1].ID
-		movieDuration := MoviesData[1].Duration
+		start := time.Date(day.Year(), day.Month(), day.Day(), 10, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+		show := MovieShowAdmin{MovieID: MoviesData[1].ID, HallID: HallsData[0].ID, StartTime: start, Language: "Русский", BasePrice: 300}
+		req := createRequest(t, "POST", ts.URL+"/movie-shows", generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), show)
+		resp := executeRequest(t, req, http.StatusCreated)
+		defer resp.Body.Close()
 
-		startTime1 := time.Now().Add(24 * time.Hour)
+		var id string
+		parseResponseBody(t, resp, &id)
+
+		getReq := createRequest(t, "GET", ts.URL+"/movie-shows/"+id, generateToken(t, ""), nil)
+		getResp := executeRequest(t, getReq, http.StatusOK)
+		defer getResp.Body.Close()
+
+		var body map[string]any
+		parseResponseBody(t, getResp, &body)
+
+		expected := start.In(loc).Format(time.RFC3339)
+		if body["start_time"] != expected || body["time_zone"] != zone {
+			t.Errorf("Expected start_time %s in %s, got %v in %v", expected, zone, body["start_time"], body["time_zone"])
+		}
+	})
+
+	t.Run("By date uses hall local date", func(t *testing.T) {
+		ts := setup(t)
+		defer ts.Close()
+
+		// 23:30 UTC — это 09:30 следующего дня по местному времени зала
+		var id string
+		err := TestAdminDB.QueryRow(context.Background(),
+			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4) RETURNING id",
+			MoviesData[1].ID, HallsData[0].ID, day.Add(23*time.Hour+30*time.Minute), "Русский").Scan(&id)
+		if err != nil {
+			t.Fatalf("Failed to insert movie show: %v", err)
+		}
+
+		req := createRequest(t, "GET", ts.URL+"/dates/"+day.AddDate(0, 0, 1).Format(time.DateOnly)+"/movie-shows", generateToken(t, ""), nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+
+		var page Page[MovieShow]
+		parseResponseBody(t, resp, &page)
+		shows := page.Items
+		if len(shows) != 1 || shows[0].ID != id {
+			t.Errorf("Expected show %s on the local date, got %+v", id, shows)
+		}
+
+		utcReq := createRequest(t, "GET", ts.URL+"/dates/"+day.Format(time.DateOnly)+"/movie-shows", generateToken(t, ""), nil)
+		utcResp := executeRequest(t, utcReq, http.StatusOK)
+		defer utcResp.Body.Close()
+
+		var utcPage Page[MovieShow]
+		parseResponseBody(t, utcResp, &utcPage)
+		if len(utcPage.Items) != 0 {
+			t.Errorf("Expected no shows on the UTC date, got %+v", utcPage.Items)
+		}
+	})
+
+	t.Run("Opening hours use hall local time", func(t *testing.T) {
+		ts := setup(t)
+		defer ts.Close()
+
+		if _, err := TestAdminDB.Exec(context.Background(),
+			"UPDATE halls SET opens_at = '10:00', closes_at = '22:00' WHERE id = $1", HallsData[0].ID); err != nil {
+			t.Fatalf("Failed to update hall: %v", err)
+		}
+
+		// 01:00 UTC = 11:00 по местному времени, 13:00 UTC = 23:00
 		_, err := TestAdminDB.Exec(context.Background(),
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime1, Russian)
+			MoviesData[1].ID, HallsData[0].ID, day.Add(time.Hour), "Русский")
 		if err != nil {
-			t.Fatalf("Failed to insert first movie show: %v", err)
+			t.Errorf("Expected show within local opening hours, got error: %v", err)
 		}
 
-		parsedDuration, _ := time.Parse("15:04:05", movieDuration)
-		startTime2 := startTime1.Add(parsedDuration.Sub(time.Time{}) + 10*time.Minute)
 		_, err = TestAdminDB.Exec(context.Background(),
 			"INSERT INTO movie_shows (movie_id, hall_id, start_time, language) VALUES ($1, $2, $3, $4)",
-			movieID, hallID, startTime2, Russian)
+			MoviesData[1].ID, HallsData[0].ID, day.Add(13*time.Hour), "Русский")
+		if err == nil || !strings.Contains(err.Error(), "вне часов работы") {
+			t.Errorf("Expected opening hours error, got: %v", err)
+		}
+	})
+}
 
-		if err != nil {
-			t.Errorf("Expected no conflict with exact cleaning time gap, got error: %v", err)
+func TestMovieShowLanguagesAndFormats(t *testing.T) {
+	show := func(mutate func(ms *MovieShowAdmin)) MovieShowAdmin {
+		ms := MovieShowAdmin{
+			MovieID:   MoviesData[0].ID,
+			HallID:    HallsData[1].ID,
+			StartTime: time.Now().Add(124 * time.Hour),
+			Language:  "English",
+			BasePrice: 300,
+		}
+		mutate(&ms)
+		return ms
+	}
+
+	createTests := []struct {
+		name           string
+		body           MovieShowAdmin
+		setup          func(t *testing.T)
+		expectedStatus int
+		expectedShow   *MovieShow
+	}{
+		{"Subtitles and formats", show(func(ms *MovieShowAdmin) {
+			ms.SubtitleLanguage = ptr("Русский")
+			ms.FormatIDs = []string{ShowFormatsData[3].ID, ShowFormatsData[1].ID}
+		}), nil, http.StatusCreated, &MovieShow{Language: "English", SubtitleLanguage: ptr("Русский"), Formats: []string{"3D", "Оригинал"}}},
+		{"Without formats", show(func(ms *MovieShowAdmin) {}), nil, http.StatusCreated,
+			&MovieShow{Language: "English", Formats: []string{}}},
+		{"New language", show(func(ms *MovieShowAdmin) { ms.Language = "日本語" }), func(t *testing.T) {
+			if _, err := TestAdminDB.Exec(context.Background(), "INSERT INTO languages (name) VALUES ('日本語')"); err != nil {
+				t.Fatalf("Failed to insert language: %v", err)
+			}
+		}, http.StatusCreated, &MovieShow{Language: "日本語", Formats: []string{}}},
+		{"Unknown language", show(func(ms *MovieShowAdmin) { ms.Language = "日本語" }), nil, http.StatusBadRequest, nil},
+		{"Empty language", show(func(ms *MovieShowAdmin) { ms.Language = "  " }), nil, http.StatusBadRequest, nil},
+		{"Unknown subtitle language", show(func(ms *MovieShowAdmin) { ms.SubtitleLanguage = ptr("Klingon") }), nil, http.StatusBadRequest, nil},
+		{"Unknown format", show(func(ms *MovieShowAdmin) { ms.FormatIDs = []string{uuid.New().String()} }), nil, http.StatusBadRequest, nil},
+		{"Invalid format ID", show(func(ms *MovieShowAdmin) { ms.FormatIDs = []string{"3D"} }), nil, http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range createTests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+
+			req := createRequest(t, "POST", ts.URL+"/movie-shows", generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedShow == nil {
+				return
+			}
+
+			var id string
+			parseResponseBody(t, resp, &id)
+
+			req = createRequest(t, "GET", ts.URL+"/movie-shows/"+id, "", nil)
+			getResp := executeRequest(t, req, http.StatusOK)
+			defer getResp.Body.Close()
+
+			var got MovieShow
+			parseResponseBody(t, getResp, &got)
+			if got.Language != tt.expectedShow.Language {
+				t.Errorf("Expected language %q, got %q", tt.expectedShow.Language, got.Language)
+			}
+			subtitles := func(ms MovieShow) string {
+				if ms.SubtitleLanguage == nil {
+					return "<нет>"
+				}
+				return *ms.SubtitleLanguage
+			}
+			if subtitles(got) != subtitles(*tt.expectedShow) {
+				t.Errorf("Expected subtitles %s, got %s", subtitles(*tt.expectedShow), subtitles(got))
+			}
+			if strings.Join(got.Formats, ",") != strings.Join(tt.expectedShow.Formats, ",") || got.Formats == nil {
+				t.Errorf("Expected formats %v, got %v", tt.expectedShow.Formats, got.Formats)
+			}
+		})
+	}
+
+	filterTests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{"By language", "language=English", http.StatusOK, []string{MovieShowsData[1].ID}},
+		{"By subtitles", "subtitle_language=%D0%A0%D1%83%D1%81%D1%81%D0%BA%D0%B8%D0%B9", http.StatusOK, []string{MovieShowsData[1].ID}},
+		{"By format", "format=3D", http.StatusOK, []string{MovieShowsData[2].ID}},
+		{"Language and format", "language=English&format=3D", http.StatusOK, nil},
+		{"Unknown language", "language=Klingon", http.StatusOK, nil},
+	}
+
+	for _, tt := range filterTests {
+		t.Run("Filter "+tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "GET", ts.URL+"/movie-shows?"+tt.query, "", nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var shows Page[MovieShow]
+			parseResponseBody(t, resp, &shows)
+			var ids []string
+			for _, ms := range shows.Items {
+				ids = append(ids, ms.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.expectedIDs, ",") {
+				t.Errorf("Expected shows %v, got %v", tt.expectedIDs, ids)
+			}
+		})
+	}
+
+	t.Run("Update keeps formats unless given", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedAll(TestAdminDB)
+		defer ts.Close()
+
+		formats := func() []string {
+			var names []string
+			err := TestAdminDB.QueryRow(context.Background(), `
+				SELECT ARRAY(SELECT f.name FROM movie_show_formats msf JOIN show_formats f ON f.id = msf.format_id
+					WHERE msf.movie_show_id = $1 ORDER BY f.name)`, MovieShowsData[2].ID).Scan(&names)
+			if err != nil {
+				t.Fatalf("Failed to get show formats: %v", err)
+			}
+			return names
+		}
+
+		update := MovieShowData{
+			MovieID:   MovieShowsData[2].MovieID,
+			HallID:    MovieShowsData[2].HallID,
+			StartTime: MovieShowsData[2].StartTime,
+			Language:  "English",
+		}
+		req := createRequest(t, "PUT", ts.URL+"/movie-shows/"+MovieShowsData[2].ID, generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), update)
+		executeRequest(t, req, http.StatusOK).Body.Close()
+		if got := formats(); len(got) != 2 {
+			t.Errorf("Expected formats to stay unchanged, got %v", got)
+		}
+
+		update.FormatIDs = []string{ShowFormatsData[0].ID}
+		req = createRequest(t, "PUT", ts.URL+"/movie-shows/"+MovieShowsData[2].ID, generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), update)
+		executeRequest(t, req, http.StatusOK).Body.Close()
+		if got := formats(); strings.Join(got, ",") != "2D" {
+			t.Errorf("Expected formats [2D], got %v", got)
 		}
 	})
 }
@@ -729,7 +1988,7 @@ func TestGetShowsByMovie(t *testing.T) {
 			uuid.New().String(),
 			"",
 			setupWithData,
-			http.StatusNotFound,
+			http.StatusOK,
 			0,
 		},
 		{
@@ -783,13 +2042,13 @@ func TestGetShowsByMovie(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var shows []MovieShow
-				if err := json.NewDecoder(resp.Body).Decode(&shows); err != nil {
+				var page Page[MovieShow]
+				if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 					t.Fatalf("Could not decode response: %v", err)
 				}
 
-				if len(shows) != tt.expectedCount {
-					t.Errorf("Expected %d shows, got %d", tt.expectedCount, len(shows))
+				if len(page.Items) != tt.expectedCount {
+					t.Errorf("Expected %d shows, got %d", tt.expectedCount, len(page.Items))
 				}
 			}
 		})
@@ -809,13 +2068,15 @@ func TestGetShowsByDate(t *testing.T) {
 		date           string
 		setup          func(t *testing.T) *httptest.Server
 		expectedStatus int
+		expectShows    bool
 	}{
 		{
 			"Valid date",
 			os.Getenv("CLAIM_ROLE_USER"),
-			time.Now().Add(48 * time.Hour).Format("2006-01-02"),
+			time.Now().Add(48 * time.Hour).UTC().Format("2006-01-02"),
 			setupWithData,
 			http.StatusOK,
+			true,
 		},
 		{
 			"Invalid date format",
@@ -823,20 +2084,23 @@ func TestGetShowsByDate(t *testing.T) {
 			"2023\\01\\01",
 			setupWithData,
 			http.StatusBadRequest,
+			false,
 		},
 		{
 			"Date with no shows",
 			os.Getenv("CLAIM_ROLE_USER"),
 			"1900-01-01",
 			setupWithData,
-			http.StatusNotFound,
+			http.StatusOK,
+			false,
 		},
 		{
 			"Guest access allowed",
 			"",
-			time.Now().Add(48 * time.Hour).Format("2006-01-02"),
+			time.Now().Add(48 * time.Hour).UTC().Format("2006-01-02"),
 			setupWithData,
 			http.StatusOK,
+			true,
 		},
 	}
 
@@ -846,19 +2110,22 @@ func TestGetShowsByDate(t *testing.T) {
 			SeedUsers(TestAdminDB)
 			defer ts.Close()
 
-			req := createRequest(t, "GET", ts.URL+"/movie-shows/by-date/"+tt.date, generateToken(t, tt.role), nil)
+			req := createRequest(t, "GET", ts.URL+"/dates/"+tt.date+"/movie-shows", generateToken(t, tt.role), nil)
 			resp := executeRequest(t, req, tt.expectedStatus)
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var shows []MovieShow
-				if err := json.NewDecoder(resp.Body).Decode(&shows); err != nil {
+				var page Page[MovieShow]
+				if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 					t.Fatalf("Could not decode response: %v", err)
 				}
 
-				if len(shows) == 0 {
+				if tt.expectShows && len(page.Items) == 0 {
 					t.Error("Expected at least one show, got zero")
 				}
+				if !tt.expectShows && len(page.Items) != 0 {
+					t.Errorf("Expected no shows, got %d", len(page.Items))
+				}
 			}
 		})
 	}
@@ -938,12 +2205,12 @@ func TestGetUpcomingShows(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var shows []MovieShow
-				if err := json.NewDecoder(resp.Body).Decode(&shows); err != nil {
+				var page Page[MovieShow]
+				if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 					t.Fatalf("Could not decode response: %v", err)
 				}
 
-				if len(shows) == 0 {
+				if len(page.Items) == 0 {
 					t.Error("Expected at least one show, got zero")
 				}
 			}
@@ -1526,3 +2793,122 @@ func getHalls(ctx context.Context, db *pgxpool.Pool) ([]struct{ ID string }, err
 	}
 	return halls, nil
 }
+
+// BenchmarkMovieShowTicketInventory сравнивает создание сеанса с вычисляемыми билетами
+// и прежний вариант, в котором на каждое место заранее записывалась строка в tickets
+func BenchmarkMovieShowTicketInventory(b *testing.B) {
+	const rows, seatsPerRow = 20, 25
+
+	db, err := pgxpool.New(context.Background(), fmt.Sprintf("user=%s dbname=%s password=%s sslmode=disable",
+		os.Getenv("TEST_ADMIN_USER"),
+		os.Getenv("TEST_DB_NAME"),
+		os.Getenv("TEST_ADMIN_PASSWORD")))
+	if err != nil {
+		b.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	hallID := HallsData[2].ID
+
+	benchmarks := []struct {
+		name  string
+		eager bool
+	}{
+		{"lazy", false},
+		{"eager", true},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			ClearAll(db)
+			SeedAll(db)
+
+			_, err := db.Exec(ctx, `
+				INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+				SELECT $1, $2, r, n FROM generate_series(1, $3) r, generate_series(1, $4) n`,
+				hallID, SeatTypesData[0].ID, rows, seatsPerRow)
+			if err != nil {
+				b.Fatalf("Failed to create seats: %v", err)
+			}
+
+			var sizeBefore int64
+			if err := db.QueryRow(ctx, "SELECT pg_total_relation_size('tickets')").Scan(&sizeBefore); err != nil {
+				b.Fatalf("Failed to get tickets size: %v", err)
+			}
+
+			startTime := time.Now().Add(7 * 24 * time.Hour)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var showID string
+				err := db.QueryRow(ctx, "SELECT create_movie_show_with_tickets($1, $2, $3, $4, $5)",
+					MoviesData[0].ID, hallID, startTime.Add(time.Duration(i)*12*time.Hour), "Русский", 300.0).Scan(&showID)
+				if err != nil {
+					b.Fatalf("Failed to create movie show: %v", err)
+				}
+
+				if bm.eager {
+					_, err = db.Exec(ctx, `
+						INSERT INTO tickets (id, movie_show_id, seat_id, ticket_status, price)
+						SELECT id, movie_show_id, seat_id, ticket_status, price
+						FROM movie_show_tickets WHERE movie_show_id = $1`, showID)
+					if err != nil {
+						b.Fatalf("Failed to create tickets: %v", err)
+					}
+				}
+			}
+			b.StopTimer()
+
+			var sizeAfter int64
+			if err := db.QueryRow(ctx, "SELECT pg_total_relation_size('tickets')").Scan(&sizeAfter); err != nil {
+				b.Fatalf("Failed to get tickets size: %v", err)
+			}
+			b.ReportMetric(float64(sizeAfter-sizeBefore)/float64(b.N), "tickets-bytes/op")
+		})
+	}
+}
+
+func TestAccessibleShowFilter(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	if _, err := TestAdminDB.Exec(context.Background(),
+		"INSERT INTO movie_show_prices (movie_show_id, base_price) VALUES ($1, 300)", MovieShowsData[3].ID); err != nil {
+		t.Fatalf("Failed to set base price: %v", err)
+	}
+
+	req := createRequest(t, "GET", ts.URL+"/movie-shows?accessible=true", "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var page Page[MovieShow]
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	if len(page.Items) != 0 {
+		t.Errorf("Expected no accessible shows, got %+v", page.Items)
+	}
+
+	req = createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[2].ID+"/accessibility",
+		generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), SeatAccessibility{WheelchairSpace: true})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	req = createRequest(t, "GET", ts.URL+"/movie-shows?accessible=true", "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	if len(page.Items) != 1 || page.Items[0].ID != MovieShowsData[3].ID {
+		t.Errorf("/movie-shows: expected only show %s, got %+v", MovieShowsData[3].ID, page.Items)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/movies/"+MovieShowsData[3].MovieID+"/shows?hours=72&accessible=true", "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	page = Page[MovieShow]{}
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	if len(page.Items) != 1 || page.Items[0].ID != MovieShowsData[3].ID {
+		t.Errorf("/movies/{id}/shows: expected only show %s, got %+v", MovieShowsData[3].ID, page.Items)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/movie-shows?accessible=1", "", nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func validateAllPersonData(w http.ResponseWriter, p *PersonData) bool {
+	p.Name = PrepareString(p.Name)
+	p.Biography = PrepareStringPointer(p.Biography)
+
+	if err := validatePersonName(p.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if p.BirthDate != nil {
+		if err := validatePersonBirthDate(*p.BirthDate, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	if p.Biography != nil {
+		if err := validatePersonBiography(*p.Biography); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	return true
+}
+
+func validatePersonName(name string) error {
+	if name == "" {
+		return errors.New("имя человека не может быть пустым")
+	}
+	if utf8.RuneCountInString(name) > 200 {
+		return errors.New("имя человека не может превышать 200 символов")
+	}
+	return nil
+}
+
+func validatePersonBirthDate(date string, now time.Time) error {
+	birthDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return errors.New("неверный формат даты рождения, используйте YYYY-MM-DD")
+	}
+	if birthDate.After(now) {
+		return errors.New("дата рождения не может быть в будущем")
+	}
+	return nil
+}
+
+func validatePersonBiography(biography string) error {
+	if biography == "" {
+		return errors.New("биография не может быть пустой или состоять только из пробелов")
+	}
+	if utf8.RuneCountInString(biography) > 2000 {
+		return errors.New("биография не может превышать 2000 символов")
+	}
+	return nil
+}
+
+// parseCreditRoleQuery разбирает необязательный параметр role; nil означает любую роль
+func parseCreditRoleQuery(w http.ResponseWriter, r *http.Request) (*CreditRoleEnumType, bool) {
+	value := r.URL.Query().Get("role")
+	if value == "" {
+		return nil, true
+	}
+
+	role := CreditRoleEnumType(value)
+	if !role.IsValid() {
+		http.Error(w, "Неизвестная роль в фильме, используйте actor, director или writer", http.StatusBadRequest)
+		return nil, false
+	}
+	return &role, true
+}
+
+var personListSpec = listSpec{
+	ID:          "id",
+	Sorts:       map[string]listSortField{"name": {Expr: "name", Kind: listText}},
+	DefaultSort: "name",
+	Filters: []listFilter{
+		{Param: "name", Kind: listText, Cond: "strpos(lower(name), lower($%[1]d::text)) > 0"},
+	},
+}
+
+func scanPerson(row interface{ Scan(...any) error }) (Person, error) {
+	var p Person
+	var birthDate *time.Time
+	if err := row.Scan(&p.ID, &p.Name, &birthDate, &p.Biography); err != nil {
+		return p, err
+	}
+	if birthDate != nil {
+		date := birthDate.Format("2006-01-02")
+		p.BirthDate = &date
+	}
+	return p, nil
+}
+
+// @Summary Получить всех создателей фильмов (guest | user | admin)
+// @Description Возвращает актёров, режиссёров и сценаристов, упорядоченных по имени.
+// @Tags Создатели фильмов
+// @Produce json
+// @Param name query string false "Часть имени"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Person] "Страница людей"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /people [get]
+func GetPeople(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, ok := parseListQuery(w, r, personListSpec)
+		if !ok {
+			return
+		}
+
+		page, err := fetchPage(context.Background(), db, q, "id, name, birth_date, biography", "people",
+			func(row pgx.Row) (Person, error) { return scanPerson(row) })
+		if HandleDatabaseError(w, err, "людьми") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Получить создателя фильмов по ID (guest | user | admin)
+// @Description Возвращает человека по ID.
+// @Tags Создатели фильмов
+// @Produce json
+// @Param id path string true "ID человека"
+// @Success 200 {object} Person "Человек"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Человек не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /people/{id} [get]
+func GetPersonByID(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		p, err := scanPerson(db.QueryRow(context.Background(),
+			"SELECT id, name, birth_date, biography FROM people WHERE id = $1", id))
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}
+}
+
+// @Summary Поиск создателей фильмов по имени (guest | user | admin)
+// @Description Возвращает страницу людей, имена которых содержат указанную строку (регистронезависимый поиск).
+// @Tags Создатели фильмов
+// @Produce json
+// @Param query query string true "Строка для поиска"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[Person] "Страница найденных людей"
+// @Failure 400 {object} ErrorResponse "Строка поиска пуста или неверные параметры списка"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /people/search [get]
+func SearchPeople(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := PrepareString(r.URL.Query().Get("query"))
+		if query == "" {
+			http.Error(w, "Строка поиска пуста", http.StatusBadRequest)
+			return
+		}
+
+		q, ok := parseListQuery(w, r, personListSpec)
+		if !ok {
+			return
+		}
+		q.where("name ILIKE $%[1]d", "%"+query+"%")
+
+		page, err := fetchPage(context.Background(), db, q, "id, name, birth_date, biography", "people",
+			func(row pgx.Row) (Person, error) { return scanPerson(row) })
+		if HandleDatabaseError(w, err, "людьми") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Получить фильмы создателя (guest | user | admin)
+// @Description Возвращает фильмы, в создании которых участвовал человек, по умолчанию начиная с новых.
+// @Description С role учитываются только фильмы, где у человека эта роль.
+// @Tags Создатели фильмов
+// @Produce json
+// @Param id path string true "ID человека"
+// @Param role query string false "Роль в фильме" Enums(actor, director, writer)
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: title, release_date, age_limit, box_office_revenue; с префиксом - по убыванию"
+// @Success 200 {object} Page[Movie] "Страница фильмов человека"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID, неизвестная роль или неверные параметры списка"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /people/{id}/movies [get]
+func GetPersonMovies(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		role, ok := parseCreditRoleQuery(w, r)
+		if !ok {
+			return
+		}
+
+		q, ok := parseListQuery(w, r, recentMovieListSpec)
+		if !ok {
+			return
+		}
+		q.and(func(n int) string {
+			return fmt.Sprintf(`
+		AND EXISTS (
+			SELECT 1 FROM movie_credits c
+			WHERE c.movie_id = m.id AND c.person_id = $%[1]d
+			AND ($%[2]d::credit_role_enum IS NULL OR c.role = $%[2]d)
+		)`, n, n+1)
+		}, id, role)
+
+		page, err := fetchMoviePage(r.Context(), db, q)
+		if HandleDatabaseError(w, err, "фильмами") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Создать создателя фильмов (admin)
+// @Description Добавляет актёра, режиссёра или сценариста. Роли в фильмах задаются при создании и обновлении фильма.
+// @Tags Создатели фильмов
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param person body PersonData true "Данные человека"
+// @Success 201 {object} CreateResponse "ID созданного человека"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /people [post]
+func CreatePerson(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var p PersonData
+		if !DecodeJSONBody(w, r, &p) {
+			return
+		}
+
+		if !validateAllPersonData(w, &p) {
+			return
+		}
+
+		id := uuid.New()
+		_, err := db.Exec(context.Background(),
+			"INSERT INTO people (id, name, birth_date, biography) VALUES ($1, $2, $3, $4)",
+			id, p.Name, p.BirthDate, p.Biography)
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(id.String())
+	}
+}
+
+// @Summary Обновить создателя фильмов (admin)
+// @Description Обновляет данные человека.
+// @Tags Создатели фильмов
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID человека"
+// @Param person body PersonData true "Новые данные человека"
+// @Success 200 "Данные о человеке успешно обновлены"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Человек не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /people/{id} [put]
+func UpdatePerson(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var p PersonData
+		if !DecodeJSONBody(w, r, &p) {
+			return
+		}
+
+		if !validateAllPersonData(w, &p) {
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			"UPDATE people SET name = $1, birth_date = $2, biography = $3 WHERE id = $4",
+			p.Name, p.BirthDate, p.Biography, id)
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// @Summary Удалить создателя фильмов (admin)
+// @Description Удаляет человека по ID. Человека, указанного среди создателей фильмов, удалить нельзя.
+// @Tags Создатели фильмов
+// @Param id path string true "ID человека"
+// @Security BearerAuth
+// @Success 204 "Данные о человеке успешно удалены"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Человек не найден"
+// @Failure 409 {object} ErrorResponse "Человек указан среди создателей фильмов"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /people/{id} [delete]
+func DeletePerson(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		res, err := db.Exec(context.Background(), "DELETE FROM people WHERE id = $1", id)
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateMovieCredits(t *testing.T) {
+	personID := uuid.New().String()
+
+	tests := []struct {
+		name    string
+		credits []MovieCreditData
+		wantErr bool
+	}{
+		{"No credits", nil, false},
+		{"Director and writer", []MovieCreditData{{PersonID: personID, Role: CreditDirector}, {PersonID: personID, Role: CreditWriter}}, false},
+		{"Actor with character", []MovieCreditData{{PersonID: personID, Role: CreditActor, Character: ptr(" Кобб ")}}, false},
+		{"Invalid person ID", []MovieCreditData{{PersonID: "invalid", Role: CreditActor}}, true},
+		{"Unknown role", []MovieCreditData{{PersonID: personID, Role: "composer"}}, true},
+		{"Duplicate role", []MovieCreditData{{PersonID: personID, Role: CreditActor}, {PersonID: personID, Role: CreditActor}}, true},
+		{"Character for director", []MovieCreditData{{PersonID: personID, Role: CreditDirector, Character: ptr("Кобб")}}, true},
+		{"Empty character", []MovieCreditData{{PersonID: personID, Role: CreditActor, Character: ptr("  ")}}, true},
+		{"Character too long", []MovieCreditData{{PersonID: personID, Role: CreditActor, Character: ptr(strings.Repeat("я", 201))}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateMovieCredits(tt.credits); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCreatePerson(t *testing.T) {
+	admin := os.Getenv("CLAIM_ROLE_ADMIN")
+
+	tests := []struct {
+		name           string
+		role           string
+		body           PersonData
+		expectedStatus int
+	}{
+		{"Valid", admin, PersonData{Name: "Ханс Циммер", BirthDate: ptr("1957-09-12"), Biography: ptr("Композитор")}, http.StatusCreated},
+		{"Name only", admin, PersonData{Name: "Энн Хэтэуэй"}, http.StatusCreated},
+		{"Forbidden Guest", "", PersonData{Name: "Энн Хэтэуэй"}, http.StatusForbidden},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), PersonData{Name: "Энн Хэтэуэй"}, http.StatusForbidden},
+		{"Empty name", admin, PersonData{Name: "   "}, http.StatusBadRequest},
+		{"Name too long", admin, PersonData{Name: strings.Repeat("я", 201)}, http.StatusBadRequest},
+		{"Invalid birth date", admin, PersonData{Name: "Энн Хэтэуэй", BirthDate: ptr("12.11.1982")}, http.StatusBadRequest},
+		{"Birth date in future", admin, PersonData{Name: "Энн Хэтэуэй", BirthDate: ptr(time.Now().AddDate(1, 0, 0).Format("2006-01-02"))}, http.StatusBadRequest},
+		{"Empty biography", admin, PersonData{Name: "Энн Хэтэуэй", Biography: ptr(" ")}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/people", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+			var id string
+			parseResponseBody(t, resp, &id)
+
+			req = createRequest(t, "GET", ts.URL+"/people/"+id, "", nil)
+			resp = executeRequest(t, req, http.StatusOK)
+			defer resp.Body.Close()
+			var p Person
+			parseResponseBody(t, resp, &p)
+			if p.Name != tt.body.Name || (p.BirthDate == nil) != (tt.body.BirthDate == nil) ||
+				(p.BirthDate != nil && *p.BirthDate != *tt.body.BirthDate) {
+				t.Errorf("Expected %+v, got %+v", tt.body, p)
+			}
+		})
+	}
+}
+
+func TestPersonLifecycle(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	user := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+
+	req := createRequest(t, "GET", ts.URL+"/people", "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var page Page[Person]
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	if len(page.Items) < len(PeopleData) {
+		t.Errorf("Expected at least %d people, got %d", len(PeopleData), len(page.Items))
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/people/search?query="+url.QueryEscape("нолан"), "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	page = Page[Person]{}
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	if len(page.Items) != 1 || page.Items[0].ID != PeopleData[0].ID {
+		t.Errorf("Expected to find %s, got %+v", PeopleData[0].Name, page.Items)
+	}
+	req = createRequest(t, "GET", ts.URL+"/people/search?query=%20", "", nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+
+	update := PersonData{Name: "Вигго Питер Мортенсен", BirthDate: ptr("1958-10-20")}
+	personURL := ts.URL + "/people/" + PeopleData[4].ID
+	req = createRequest(t, "PUT", personURL, user, update)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/people/"+uuid.New().String(), admin, update)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "PUT", personURL, admin, update)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	req = createRequest(t, "GET", personURL, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var p Person
+	parseResponseBody(t, resp, &p)
+	resp.Body.Close()
+	if p.Name != update.Name || p.BirthDate == nil || *p.BirthDate != *update.BirthDate {
+		t.Errorf("Expected updated person, got %+v", p)
+	}
+
+	// Человека с ролями в фильмах удалить нельзя
+	req = createRequest(t, "DELETE", ts.URL+"/people/"+PeopleData[0].ID, admin, nil)
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	req = createRequest(t, "DELETE", personURL, user, nil)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "DELETE", personURL, admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+	req = createRequest(t, "GET", personURL, "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/people/invalid-uuid", "", nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+}
+
+func TestGetPersonMovies(t *testing.T) {
+	tests := []struct {
+		name           string
+		personID       string
+		query          string
+		expectedStatus int
+		expectedMovies []string
+	}{
+		// Новые фильмы идут первыми
+		{"Director", PeopleData[0].ID, "", http.StatusOK, []string{MoviesData[0].ID, MoviesData[1].ID, MoviesData[3].ID}},
+		{"Writer only", PeopleData[0].ID, "?role=writer", http.StatusOK, []string{MoviesData[1].ID}},
+		{"Actor", PeopleData[1].ID, "", http.StatusOK, []string{MoviesData[1].ID}},
+		{"No movies in role", PeopleData[1].ID, "?role=director", http.StatusOK, nil},
+		{"Person without movies", PeopleData[4].ID, "", http.StatusOK, nil},
+		{"Unknown role", PeopleData[0].ID, "?role=composer", http.StatusBadRequest, nil},
+		{"Invalid ID", "invalid-uuid", "", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "GET", ts.URL+"/people/"+tt.personID+"/movies"+tt.query, "", nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var page Page[Movie]
+			parseResponseBody(t, resp, &page)
+			movies := page.Items
+			if len(movies) != len(tt.expectedMovies) {
+				t.Fatalf("Expected %d movies, got %+v", len(tt.expectedMovies), movies)
+			}
+			for i, m := range movies {
+				if m.ID != tt.expectedMovies[i] {
+					t.Errorf("Expected movie %d to be %s, got %s", i, tt.expectedMovies[i], m.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchMoviesByPerson(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"By name", "?query=" + url.QueryEscape("нолан"), http.StatusOK, 3},
+		{"By name and role", "?query=" + url.QueryEscape("нолан") + "&role=writer", http.StatusOK, 1},
+		{"Actor", "?query=" + url.QueryEscape("Бейл") + "&role=actor", http.StatusOK, 1},
+		{"No match", "?query=" + url.QueryEscape("Тарантино"), http.StatusOK, 0},
+		{"Empty query", "?query=%20", http.StatusBadRequest, 0},
+		{"Unknown role", "?query=" + url.QueryEscape("нолан") + "&role=composer", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "GET", ts.URL+"/movies/by-person/search"+tt.query, "", nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var page Page[Movie]
+			parseResponseBody(t, resp, &page)
+			movies := page.Items
+			if len(movies) != tt.expectedCount {
+				t.Errorf("Expected %d movies, got %d", tt.expectedCount, len(movies))
+			}
+		})
+	}
+}
+
+func TestMovieCredits(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	movieURL := ts.URL + "/movies/" + MoviesData[1].ID
+
+	getMovie := func() Movie {
+		t.Helper()
+		req := createRequest(t, "GET", movieURL, "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+		var m Movie
+		parseResponseBody(t, resp, &m)
+		return m
+	}
+
+	// Сначала актёры, затем режиссёры и сценаристы
+	m := getMovie()
+	if len(m.Credits) != 3 || m.Credits[0].PersonID != PeopleData[1].ID || m.Credits[0].Role != CreditActor ||
+		m.Credits[0].Character == nil || *m.Credits[0].Character != "Кобб" || m.Credits[1].Role != CreditDirector {
+		t.Fatalf("Unexpected credits %+v", m.Credits)
+	}
+
+	data := MovieData{
+		Title:       MoviesData[1].Title,
+		Duration:    MoviesData[1].Duration,
+		Description: MoviesData[1].Description,
+		AgeLimit:    MoviesData[1].AgeLimit,
+		ReleaseDate: MoviesData[1].ReleaseDate,
+	}
+
+	// Без credits создатели фильма не меняются
+	req := createRequest(t, "PUT", movieURL, admin, data)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	if m := getMovie(); len(m.Credits) != 3 {
+		t.Errorf("Expected credits to be kept, got %+v", m.Credits)
+	}
+
+	data.Credits = []MovieCreditData{
+		{PersonID: PeopleData[2].ID, Role: CreditActor, Character: ptr("Артур")},
+		{PersonID: PeopleData[1].ID, Role: CreditActor, Character: ptr("Кобб")},
+		{PersonID: PeopleData[0].ID, Role: CreditDirector},
+	}
+	req = createRequest(t, "PUT", movieURL, admin, data)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	m = getMovie()
+	if len(m.Credits) != 3 || m.Credits[0].PersonID != PeopleData[2].ID || m.Credits[1].PersonID != PeopleData[1].ID {
+		t.Errorf("Expected credits in billing order, got %+v", m.Credits)
+	}
+
+	data.Credits = []MovieCreditData{{PersonID: uuid.New().String(), Role: CreditActor}}
+	req = createRequest(t, "PUT", movieURL, admin, data)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+
+	data.Title = "Фильм с создателями"
+	data.Credits = []MovieCreditData{{PersonID: PeopleData[4].ID, Role: CreditActor, Character: ptr("Арагорн")}}
+	req = createRequest(t, "POST", ts.URL+"/movies", admin, data)
+	resp := executeRequest(t, req, http.StatusCreated)
+	var id string
+	parseResponseBody(t, resp, &id)
+	resp.Body.Close()
+
+	req = createRequest(t, "GET", ts.URL+"/people/"+PeopleData[4].ID+"/movies", "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var page Page[Movie]
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	movies := page.Items
+	if len(movies) != 1 || movies[0].ID != id || len(movies[0].Credits) != 1 {
+		t.Errorf("Expected created movie with credits, got %+v", movies)
+	}
+
+	req = createRequest(t, "DELETE", ts.URL+"/movies/"+id, admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+}
@@ -9,6 +9,7 @@ import (
 	"regexp"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -70,44 +71,63 @@ func validateReviewComment(comment string) error {
 	return nil
 }
 
+const reviewColumns = "id, user_id, movie_id, rating, review_comment"
+
+func scanReview(row pgx.Row) (Review, error) {
+	var review Review
+	err := row.Scan(&review.ID, &review.UserID, &review.MovieID, &review.Rating, &review.Comment)
+	return review, err
+}
+
+var reviewListSpec = listSpec{
+	ID: "id",
+	Sorts: map[string]listSortField{
+		"id":     {Expr: "id", Kind: listUUID},
+		"rating": {Expr: "rating", Kind: listInt},
+	},
+	DefaultSort: "id",
+	Filters: []listFilter{
+		{Param: "movie_id", Kind: listUUID, Cond: "movie_id = $%[1]d::uuid"},
+		{Param: "user_id", Kind: listUUID, Cond: "user_id = $%[1]d::uuid"},
+		{Param: "rating", Kind: listInt, Cond: "rating = $%[1]d"},
+	},
+}
+
 // @Summary Получить все отзывы (admin)
 // @Description Возвращает список всех отзывов, хранящихся в базе данных.
 // @Tags Отзывы
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} Review "Список отзывов"
-// @Failure 404 {object} ErrorResponse "Отзывы не найдены"
+// @Param movie_id query string false "ID фильма"
+// @Param user_id query string false "ID пользователя"
+// @Param rating query int false "Оценка"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: id, rating; с префиксом - по убыванию"
+// @Success 200 {object} Page[Review] "Страница отзывов"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /reviews [get]
 func GetReviews(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(context.Background(), "SELECT id, user_id, movie_id, rating, review_comment FROM reviews")
-		if HandleDatabaseError(w, err, "отзывами") {
-			return
-		}
-		defer rows.Close()
-
 		role := r.Header.Get("Role")
 		if role != os.Getenv("CLAIM_ROLE_ADMIN") {
 			http.Error(w, "Доступ запрещён", http.StatusForbidden)
 			return
 		}
 
-		var reviews []Review
-		for rows.Next() {
-			var review Review
-			if err := rows.Scan(&review.ID, &review.UserID, &review.MovieID, &review.Rating, &review.Comment); HandleDatabaseError(w, err, "отзывом") {
-				return
-			}
-			reviews = append(reviews, review)
+		q, ok := parseListQuery(w, r, reviewListSpec)
+		if !ok {
+			return
 		}
 
-		if len(reviews) == 0 {
-			http.Error(w, "Отзывы не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q, reviewColumns, "reviews", scanReview)
+		if HandleDatabaseError(w, err, "отзывами") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(reviews)
+		writePage(w, page)
 	}
 }
 
@@ -294,13 +314,16 @@ func DeleteReview(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Получить отзывы по ID фильма (guest | user | admin)
-// @Description Возвращает все отзывы для указанного фильма.
+// @Description Возвращает страницу отзывов для указанного фильма.
 // @Tags Отзывы
 // @Produce json
 // @Param movie_id path string true "ID фильма"
-// @Success 200 {array} Review "Список отзывов"
-// @Failure 400 {object} ErrorResponse "Неверный формат ID фильма"
-// @Failure 404 {object} ErrorResponse "Отзывы не найдены"
+// @Param rating query int false "Оценка"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: id, rating; с префиксом - по убыванию"
+// @Success 200 {object} Page[Review] "Страница отзывов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID фильма или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /movies/{movie_id}/reviews [get]
 func GetReviewsByMovieID(db *pgxpool.Pool) http.HandlerFunc {
@@ -310,41 +333,35 @@ func GetReviewsByMovieID(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(),
-			"SELECT id, user_id, movie_id, rating, review_comment FROM reviews WHERE movie_id = $1",
-			movieID)
-		if IsError(w, err) {
+		q, ok := parseListQuery(w, r, reviewListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var reviews []Review
-		for rows.Next() {
-			var review Review
-			if err := rows.Scan(&review.ID, &review.UserID, &review.MovieID, &review.Rating, &review.Comment); HandleDatabaseError(w, err, "отзывом") {
-				return
-			}
-			reviews = append(reviews, review)
-		}
+		q.where("movie_id = $%[1]d", movieID)
 
-		if len(reviews) == 0 {
-			http.Error(w, "Отзывы не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q, reviewColumns, "reviews", scanReview)
+		if HandleDatabaseError(w, err, "отзывами") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(reviews)
+		writePage(w, page)
 	}
 }
 
 // @Summary Получить отзывы пользователя (user* | admin)
-// @Description Возвращает все отзывы указанного пользователя.
+// @Description Возвращает страницу отзывов указанного пользователя.
 // @Tags Отзывы
 // @Produce json
 // @Security BearerAuth
 // @Param user_id path string true "ID пользователя"
-// @Success 200 {array} Review "Список отзывов"
-// @Failure 400 {object} ErrorResponse "Неверный формат ID пользователя"
-// @Failure 404 {object} ErrorResponse "Отзывы не найдены"
+// @Param movie_id query string false "ID фильма"
+// @Param rating query int false "Оценка"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: id, rating; с префиксом - по убыванию"
+// @Success 200 {object} Page[Review] "Страница отзывов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID пользователя или неверные параметры списка"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /users/{user_id}/reviews [get]
 func GetReviewsByUserID(db *pgxpool.Pool) http.HandlerFunc {
@@ -362,39 +379,17 @@ func GetReviewsByUserID(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		query := `
-            SELECT r.id, r.user_id, r.movie_id, r.rating, r.review_comment
-            FROM reviews r
-            WHERE r.user_id = $1`
-
-		rows, err := db.Query(context.Background(), query, userID)
-		if IsError(w, err) {
+		q, ok := parseListQuery(w, r, reviewListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var reviews []Review
-		for rows.Next() {
-			var r Review
-			if err := rows.Scan(
-				&r.ID,
-				&r.UserID,
-				&r.MovieID,
-				&r.Rating,
-				&r.Comment,
-			); err != nil {
-				http.Error(w, "Ошибка обработки данных", http.StatusInternalServerError)
-				return
-			}
-			reviews = append(reviews, r)
-		}
+		q.where("user_id = $%[1]d", userID)
 
-		if len(reviews) == 0 {
-			http.Error(w, "Отзывы не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q, reviewColumns, "reviews", scanReview)
+		if HandleDatabaseError(w, err, "отзывами") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(reviews)
+		writePage(w, page)
 	}
 }
@@ -21,7 +21,7 @@ func TestGetReviews(t *testing.T) {
 	}{
 		{"Empty as Guest", false, "", http.StatusForbidden},
 		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusForbidden},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusForbidden},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusForbidden},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -42,10 +42,14 @@ func TestGetReviews(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var reviews []Review
-				parseResponseBody(t, resp, &reviews)
+				var page Page[Review]
+				parseResponseBody(t, resp, &page)
+				reviews := page.Items
 
-				if len(reviews) == 0 {
+				if !tt.seedData && (reviews == nil || len(reviews) != 0) {
+					t.Errorf("Expected empty reviews list, got %v", reviews)
+				}
+				if tt.seedData && len(reviews) == 0 {
 					t.Error("Expected non-empty reviews list")
 				}
 			}
@@ -756,6 +760,7 @@ func TestGetReviewsByMovieID(t *testing.T) {
 		movieID        string
 		role           string
 		expectedStatus int
+		expectEmpty    bool
 	}{
 		{
 			"Valid movie ID with reviews",
@@ -763,6 +768,7 @@ func TestGetReviewsByMovieID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusOK,
+			false,
 		},
 		{
 			"Invalid movie ID format",
@@ -770,6 +776,7 @@ func TestGetReviewsByMovieID(t *testing.T) {
 			"invalid-uuid",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusBadRequest,
+			false,
 		},
 		{
 			"Non-existent movie ID",
@@ -780,7 +787,8 @@ func TestGetReviewsByMovieID(t *testing.T) {
 			},
 			"",
 			os.Getenv("CLAIM_ROLE_USER"),
-			http.StatusNotFound,
+			http.StatusOK,
+			true,
 		},
 		{
 			"Movie without reviews",
@@ -792,7 +800,8 @@ func TestGetReviewsByMovieID(t *testing.T) {
 			},
 			"",
 			os.Getenv("CLAIM_ROLE_USER"),
-			http.StatusNotFound,
+			http.StatusOK,
+			true,
 		},
 		{
 			"As guest user",
@@ -800,6 +809,7 @@ func TestGetReviewsByMovieID(t *testing.T) {
 			"",
 			"",
 			http.StatusOK,
+			false,
 		},
 		{
 			"As admin user",
@@ -807,6 +817,7 @@ func TestGetReviewsByMovieID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			http.StatusOK,
+			false,
 		},
 	}
 
@@ -827,10 +838,14 @@ func TestGetReviewsByMovieID(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var reviews []Review
-				parseResponseBody(t, resp, &reviews)
+				var page Page[Review]
+				parseResponseBody(t, resp, &page)
+				reviews := page.Items
 
-				if len(reviews) == 0 {
+				if tt.expectEmpty && len(reviews) != 0 {
+					t.Errorf("Expected empty reviews list, got %v", reviews)
+				}
+				if !tt.expectEmpty && len(reviews) == 0 {
 					t.Error("Expected non-empty reviews list")
 				}
 
@@ -858,6 +873,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 		userID         string
 		role           string
 		expectedStatus int
+		expectEmpty    bool
 	}{
 		{
 			"Valid user ID with reviews as Admin",
@@ -865,6 +881,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			http.StatusOK,
+			false,
 		},
 		{
 			"Valid user ID with reviews as that User",
@@ -876,6 +893,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusOK,
+			false,
 		},
 		{
 			"Valid user ID with reviews as other User",
@@ -883,6 +901,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusForbidden,
+			false,
 		},
 		{
 			"Invalid user ID format",
@@ -890,6 +909,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 			"invalid-uuid",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusBadRequest,
+			false,
 		},
 		{
 			"Non-existent user ID as User",
@@ -901,6 +921,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusForbidden,
+			false,
 		},
 		{
 			"Non-existent user ID as Admin",
@@ -911,7 +932,8 @@ func TestGetReviewsByUserID(t *testing.T) {
 			},
 			"",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
-			http.StatusNotFound,
+			http.StatusOK,
+			true,
 		},
 		{
 			"As guest",
@@ -919,6 +941,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 			"",
 			"",
 			http.StatusForbidden,
+			false,
 		},
 		{
 			"As admin",
@@ -926,6 +949,7 @@ func TestGetReviewsByUserID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
 			http.StatusOK,
+			false,
 		},
 	}
 
@@ -946,10 +970,14 @@ func TestGetReviewsByUserID(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var reviews []Review
-				parseResponseBody(t, resp, &reviews)
+				var page Page[Review]
+				parseResponseBody(t, resp, &page)
+				reviews := page.Items
 
-				if len(reviews) == 0 {
+				if tt.expectEmpty && len(reviews) != 0 {
+					t.Errorf("Expected empty reviews list, got %v", reviews)
+				}
+				if !tt.expectEmpty && len(reviews) == 0 {
 					t.Error("Expected non-empty reviews list")
 				}
 
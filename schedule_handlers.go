@@ -0,0 +1,962 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// Время уборки зала, если оно не задано ни для зала, ни для типа экрана
+	defaultCleaningMinutes = 10
+	// Ожидаемая заполняемость для фильмов без истории продаж
+	defaultOccupancy = 0.3
+	// Приоритет сеансов, попадающих в prime-time
+	primeTimeBoost = 1.25
+	// Штраф за каждый повторный сеанс фильма в тот же день
+	sameDayPenalty = 0.8
+)
+
+var errScheduleNotFound = errors.New("не найден")
+
+type timeInterval struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (i timeInterval) overlaps(o timeInterval) bool {
+	return i.Start.Before(o.End) && o.Start.Before(i.End)
+}
+
+type scheduleMovie struct {
+	ID         string
+	Duration   time.Duration
+	Screenings int
+	Language   string
+	BasePrice  float64
+}
+
+type scheduleHall struct {
+	ID       string
+	Capacity int
+	OpenFrom time.Duration
+	OpenTo   time.Duration
+	Cleaning time.Duration
+	// Часовой пояс зала; nil — UTC
+	Location *time.Location
+}
+
+func (h scheduleHall) location() *time.Location {
+	if h.Location == nil {
+		return time.UTC
+	}
+	return h.Location
+}
+
+type clockWindow struct {
+	From time.Duration
+	To   time.Duration
+}
+
+// occupancyModel оценивает заполняемость сеанса по истории продаж:
+// сначала по фильму и часу начала, затем по фильму с поправкой на час,
+// и наконец по средней заполняемости в этот час.
+type occupancyModel struct {
+	byMovieHour map[string]map[int]float64
+	byMovie     map[string]float64
+	byHour      map[int]float64
+	overall     float64
+}
+
+func (m occupancyModel) hourFactor(hour int) float64 {
+	if v, ok := m.byHour[hour]; ok && m.overall > 0 {
+		return v / m.overall
+	}
+	return 1
+}
+
+func (m occupancyModel) expected(movieID string, hour int) float64 {
+	if hours, ok := m.byMovieHour[movieID]; ok {
+		if v, ok := hours[hour]; ok {
+			return v
+		}
+	}
+
+	base := defaultOccupancy
+	if v, ok := m.byMovie[movieID]; ok {
+		base = v
+	} else if m.overall > 0 {
+		base = m.overall
+	}
+
+	return min(base*m.hourFactor(hour), 1)
+}
+
+type scheduleInput struct {
+	Start     time.Time
+	Days      int
+	Step      time.Duration
+	Movies    []scheduleMovie
+	Halls     []scheduleHall
+	PrimeTime []clockWindow
+	Busy      map[string][]timeInterval
+	Model     occupancyModel
+}
+
+func (in scheduleInput) isPrimeTime(offset time.Duration) bool {
+	for _, w := range in.PrimeTime {
+		if offset >= w.From && offset < w.To {
+			return true
+		}
+	}
+	return false
+}
+
+type schedulePlacement struct {
+	hall      int
+	day       int
+	start     time.Time
+	occupancy float64
+	score     float64
+	prime     bool
+}
+
+// proposeSchedule жадно расставляет сеансы: на каждом круге каждый фильм,
+// которому ещё не хватает показов, получает лучший свободный слот.
+// Слот оценивается ожидаемым числом проданных билетов с учётом prime-time
+// и штрафа за повторные показы фильма в один день.
+func proposeSchedule(in scheduleInput) ScheduleProposal {
+	busy := make(map[string][]timeInterval, len(in.Halls))
+	for _, h := range in.Halls {
+		busy[h.ID] = append([]timeInterval(nil), in.Busy[h.ID]...)
+	}
+
+	remaining := make([]int, len(in.Movies))
+	perDay := make([]map[int]int, len(in.Movies))
+	for i, m := range in.Movies {
+		remaining[i] = m.Screenings
+		perDay[i] = make(map[int]int)
+	}
+
+	proposal := ScheduleProposal{Shows: []ProposedShow{}, Unscheduled: []UnscheduledMovie{}}
+
+	for {
+		order := make([]int, 0, len(in.Movies))
+		for i := range in.Movies {
+			if remaining[i] > 0 {
+				order = append(order, i)
+			}
+		}
+		if len(order) == 0 {
+			break
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return remaining[order[a]] > remaining[order[b]]
+		})
+
+		placed := false
+		for _, mi := range order {
+			movie := in.Movies[mi]
+			best, ok := bestPlacement(in, busy, movie, perDay[mi])
+			if !ok {
+				continue
+			}
+
+			hall := in.Halls[best.hall]
+			busy[hall.ID] = append(busy[hall.ID], timeInterval{
+				Start: best.start,
+				End:   best.start.Add(movie.Duration + hall.Cleaning),
+			})
+			remaining[mi]--
+			perDay[mi][best.day]++
+			placed = true
+
+			tickets := best.occupancy * float64(hall.Capacity)
+			proposal.TotalExpectedTickets += tickets
+			proposal.Shows = append(proposal.Shows, ProposedShow{
+				Show: MovieShowAdmin{
+					MovieID:   movie.ID,
+					HallID:    hall.ID,
+					StartTime: best.start,
+					Language:  movie.Language,
+					BasePrice: movie.BasePrice,
+				},
+				ExpectedOccupancy: best.occupancy,
+				ExpectedTickets:   tickets,
+				PrimeTime:         best.prime,
+			})
+		}
+
+		if !placed {
+			break
+		}
+	}
+
+	for i, m := range in.Movies {
+		if remaining[i] > 0 {
+			proposal.Unscheduled = append(proposal.Unscheduled, UnscheduledMovie{MovieID: m.ID, Missing: remaining[i]})
+		}
+	}
+
+	sort.SliceStable(proposal.Shows, func(a, b int) bool {
+		sa, sb := proposal.Shows[a].Show, proposal.Shows[b].Show
+		if !sa.StartTime.Equal(sb.StartTime) {
+			return sa.StartTime.Before(sb.StartTime)
+		}
+		return sa.HallID < sb.HallID
+	})
+
+	return proposal
+}
+
+func bestPlacement(in scheduleInput, busy map[string][]timeInterval, movie scheduleMovie, perDay map[int]int) (schedulePlacement, bool) {
+	var best schedulePlacement
+	found := false
+
+	for hi, hall := range in.Halls {
+		for day := 0; day < in.Days; day++ {
+			// Дни отсчитываются по местному времени зала
+			midnight := time.Date(in.Start.Year(), in.Start.Month(), in.Start.Day()+day, 0, 0, 0, 0, hall.location())
+			for offset := hall.OpenFrom; offset+movie.Duration <= hall.OpenTo; offset += in.Step {
+				start := midnight.Add(offset)
+				slot := timeInterval{Start: start, End: start.Add(movie.Duration + hall.Cleaning)}
+
+				free := true
+				for _, b := range busy[hall.ID] {
+					if slot.overlaps(b) {
+						free = false
+						break
+					}
+				}
+				if !free {
+					continue
+				}
+
+				occupancy := in.Model.expected(movie.ID, start.Hour())
+				prime := in.isPrimeTime(offset)
+				score := occupancy * float64(hall.Capacity)
+				if prime {
+					score *= primeTimeBoost
+				}
+				for range perDay[day] {
+					score *= sameDayPenalty
+				}
+
+				if !found || score > best.score {
+					best = schedulePlacement{hall: hi, day: day, start: start, occupancy: occupancy, score: score, prime: prime}
+					found = true
+				}
+			}
+		}
+	}
+
+	return best, found
+}
+
+func validateScheduleRequest(w http.ResponseWriter, req ScheduleRequest) bool {
+	if _, err := time.Parse(time.DateOnly, req.WeekStart); err != nil {
+		http.Error(w, "Неверный формат даты начала недели, используйте YYYY-MM-DD", http.StatusBadRequest)
+		return false
+	}
+
+	if req.Days < 0 || req.Days > 31 {
+		http.Error(w, "Количество дней должно быть от 1 до 31 (0 — по умолчанию 7 дней)", http.StatusBadRequest)
+		return false
+	}
+
+	if req.StepMinutes < 0 || req.StepMinutes > 24*60 {
+		http.Error(w, "Шаг сетки должен быть от 1 до 1440 минут (0 — по умолчанию 15 минут)", http.StatusBadRequest)
+		return false
+	}
+
+	if len(req.Movies) == 0 {
+		http.Error(w, "Не указаны фильмы для планирования", http.StatusBadRequest)
+		return false
+	}
+
+	if req.CinemaID != nil {
+		if _, err := uuid.Parse(*req.CinemaID); err != nil {
+			http.Error(w, "Неверный формат ID кинотеатра", http.StatusBadRequest)
+			return false
+		}
+	} else if len(req.Halls) == 0 {
+		http.Error(w, "Не указаны кинозалы или кинотеатр для планирования", http.StatusBadRequest)
+		return false
+	}
+
+	for _, m := range req.Movies {
+		if _, err := uuid.Parse(m.MovieID); err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return false
+		}
+		if m.Screenings <= 0 {
+			http.Error(w, "Количество показов должно быть положительным", http.StatusBadRequest)
+			return false
+		}
+		if err := validateShowLanguage(m.Language); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+		if m.BasePrice <= 0 {
+			http.Error(w, "Начальная цена должна быть положительной", http.StatusBadRequest)
+			return false
+		}
+	}
+
+	for _, h := range req.Halls {
+		if err := validateHallID(h.HallID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+		if (h.AvailableFrom == "") != (h.AvailableTo == "") {
+			http.Error(w, "Часы работы зала должны быть указаны вместе", http.StatusBadRequest)
+			return false
+		}
+		if h.AvailableFrom != "" {
+			if _, _, err := parseClockWindow(h.AvailableFrom, h.AvailableTo); err != nil {
+				http.Error(w, fmt.Sprintf("Часы работы зала: %v", err), http.StatusBadRequest)
+				return false
+			}
+		}
+		if err := validateCleaningMinutes(&h.CleaningMinutes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	for _, p := range req.PrimeTime {
+		if _, _, err := parseClockWindow(p.From, p.To); err != nil {
+			http.Error(w, fmt.Sprintf("Prime-time: %v", err), http.StatusBadRequest)
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseClockWindow(from, to string) (time.Duration, time.Duration, error) {
+	f, err := ParseClock(from)
+	if err != nil {
+		return 0, 0, errors.New("неверный формат времени, используйте HH:MM")
+	}
+	t, err := ParseClock(to)
+	if err != nil {
+		return 0, 0, errors.New("неверный формат времени, используйте HH:MM")
+	}
+	if f >= t || t > 24*time.Hour {
+		return 0, 0, errors.New("время начала должно быть раньше времени окончания")
+	}
+	return f, t, nil
+}
+
+func loadScheduleMovies(ctx context.Context, db *pgxpool.Pool, req []ScheduleMovie) ([]scheduleMovie, error) {
+	ids := make([]string, len(req))
+	for i, m := range req {
+		ids[i] = m.MovieID
+	}
+
+	rows, err := db.Query(ctx, "SELECT id, duration FROM movies WHERE id = ANY($1::uuid[])", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	durations := make(map[string]time.Duration)
+	for rows.Next() {
+		var id, duration string
+		if err := rows.Scan(&id, &duration); err != nil {
+			return nil, err
+		}
+		d, err := ParseClock(duration)
+		if err != nil {
+			return nil, err
+		}
+		durations[id] = d
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	languages := make([]string, len(req))
+	for i, m := range req {
+		languages[i] = m.Language
+	}
+	var unknown []string
+	err = db.QueryRow(ctx, `
+		SELECT ARRAY(SELECT unnest($1::text[]) EXCEPT SELECT name FROM languages)`, languages).
+		Scan(&unknown)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("язык %s %w", unknown[0], errScheduleNotFound)
+	}
+
+	movies := make([]scheduleMovie, 0, len(req))
+	for _, m := range req {
+		d, ok := durations[m.MovieID]
+		if !ok {
+			return nil, fmt.Errorf("фильм %s %w", m.MovieID, errScheduleNotFound)
+		}
+		movies = append(movies, scheduleMovie{
+			ID:         m.MovieID,
+			Duration:   d,
+			Screenings: m.Screenings,
+			Language:   m.Language,
+			BasePrice:  m.BasePrice,
+		})
+	}
+	return movies, nil
+}
+
+// loadCinemaScheduleHalls возвращает все залы кинотеатра для планирования без ограничений по часам
+func loadCinemaScheduleHalls(ctx context.Context, db *pgxpool.Pool, cinemaID string) ([]ScheduleHall, error) {
+	rows, err := db.Query(ctx, "SELECT id FROM halls WHERE cinema_id = $1 ORDER BY name", cinemaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var halls []ScheduleHall
+	for rows.Next() {
+		var h ScheduleHall
+		if err := rows.Scan(&h.HallID); err != nil {
+			return nil, err
+		}
+		halls = append(halls, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(halls) == 0 {
+		return nil, fmt.Errorf("кинозалы кинотеатра %s %w", cinemaID, errScheduleNotFound)
+	}
+	return halls, nil
+}
+
+// loadScheduleHalls загружает вместимость, время уборки и часы работы залов.
+// Окно из запроса сужает часы работы зала, а время уборки не может быть
+// меньше заданного для зала, иначе сеансы не пройдут проверку триггера.
+// Если cinemaID не nil, залы других кинотеатров считаются ненайденными.
+func loadScheduleHalls(ctx context.Context, db *pgxpool.Pool, req []ScheduleHall, cinemaID *string) ([]scheduleHall, error) {
+	ids := make([]string, len(req))
+	for i, h := range req {
+		ids[i] = h.HallID
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT h.id,
+			(SELECT COUNT(*) FROM seats s WHERE s.hall_id = h.id),
+			COALESCE(h.cleaning_minutes, st.default_cleaning_minutes, $2),
+			COALESCE(h.opens_at, '00:00'), COALESCE(h.closes_at, '24:00'), h.time_zone
+		FROM halls h
+		LEFT JOIN screen_types st ON st.id = h.screen_type_id
+		WHERE h.id = ANY($1::uuid[]) AND ($3::uuid IS NULL OR h.cinema_id = $3)`, ids, defaultCleaningMinutes, cinemaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := make(map[string]scheduleHall)
+	for rows.Next() {
+		var hall scheduleHall
+		var cleaning int
+		var opensAt, closesAt, timeZone string
+		if err := rows.Scan(&hall.ID, &hall.Capacity, &cleaning, &opensAt, &closesAt, &timeZone); err != nil {
+			return nil, err
+		}
+		if hall.Location, err = LoadTimeZone(timeZone); err != nil {
+			return nil, err
+		}
+		if hall.OpenFrom, err = ParseClock(opensAt); err != nil {
+			return nil, err
+		}
+		if hall.OpenTo, err = ParseClock(closesAt); err != nil {
+			return nil, err
+		}
+		hall.Cleaning = time.Duration(cleaning) * time.Minute
+		stored[hall.ID] = hall
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	halls := make([]scheduleHall, 0, len(req))
+	for _, h := range req {
+		hall, ok := stored[h.HallID]
+		if !ok {
+			return nil, fmt.Errorf("кинозал %s %w", h.HallID, errScheduleNotFound)
+		}
+		if h.AvailableFrom != "" {
+			from, to, _ := parseClockWindow(h.AvailableFrom, h.AvailableTo)
+			hall.OpenFrom = max(hall.OpenFrom, from)
+			hall.OpenTo = min(hall.OpenTo, to)
+		}
+		hall.Cleaning = max(hall.Cleaning, time.Duration(h.CleaningMinutes)*time.Minute)
+		halls = append(halls, hall)
+	}
+	return halls, nil
+}
+
+// loadBusyIntervals возвращает уже запланированные сеансы в залах
+// (вместе со временем уборки зала) и окна обслуживания в пределах планируемого периода.
+func loadBusyIntervals(ctx context.Context, db *pgxpool.Pool, halls []scheduleHall, from, to time.Time) (map[string][]timeInterval, error) {
+	hallIDs := make([]string, len(halls))
+	cleaning := make(map[string]time.Duration, len(halls))
+	for i, h := range halls {
+		hallIDs[i] = h.ID
+		cleaning[h.ID] = h.Cleaning
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT ms.hall_id, ms.start_time, m.duration
+		FROM movie_shows ms
+		JOIN movies m ON m.id = ms.movie_id
+		WHERE ms.hall_id = ANY($1::uuid[])
+		AND ms.start_time >= $2 AND ms.start_time < $3
+		AND ms.cancelled_at IS NULL`,
+		hallIDs, from.Add(-24*time.Hour), to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	busy := make(map[string][]timeInterval)
+	for rows.Next() {
+		var hallID, duration string
+		var start time.Time
+		if err := rows.Scan(&hallID, &start, &duration); err != nil {
+			return nil, err
+		}
+		d, err := ParseClock(duration)
+		if err != nil {
+			return nil, err
+		}
+		busy[hallID] = append(busy[hallID], timeInterval{
+			Start: start,
+			End:   start.Add(d + cleaning[hallID]),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(ctx, `
+		SELECT hall_id, lower(period), upper(period)
+		FROM hall_maintenance_windows
+		WHERE hall_id = ANY($1::uuid[]) AND period && tstzrange($2, $3)`,
+		hallIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hallID string
+		var mw timeInterval
+		if err := rows.Scan(&hallID, &mw.Start, &mw.End); err != nil {
+			return nil, err
+		}
+		busy[hallID] = append(busy[hallID], mw)
+	}
+	return busy, rows.Err()
+}
+
+// loadOccupancyModel считает заполняемость прошедших сеансов
+// как долю купленных билетов от числа мест в зале. Час начала берётся по местному времени зала.
+func loadOccupancyModel(ctx context.Context, db *pgxpool.Pool, now time.Time) (occupancyModel, error) {
+	model := occupancyModel{
+		byMovieHour: make(map[string]map[int]float64),
+		byMovie:     make(map[string]float64),
+		byHour:      make(map[int]float64),
+	}
+
+	rows, err := db.Query(ctx, `
+		SELECT ms.movie_id, EXTRACT(HOUR FROM ms.start_time AT TIME ZONE h.time_zone)::int, sold.cnt::float8 / cap.cnt
+		FROM movie_shows ms
+		JOIN halls h ON h.id = ms.hall_id
+		JOIN LATERAL (
+			SELECT COUNT(*) AS cnt FROM tickets t
+			WHERE t.movie_show_id = ms.id AND t.ticket_status = 'Purchased'
+		) sold ON TRUE
+		JOIN LATERAL (
+			SELECT COUNT(*) AS cnt FROM seats s WHERE s.hall_id = ms.hall_id
+		) cap ON TRUE
+		WHERE ms.start_time < $1 AND cap.cnt > 0 AND ms.cancelled_at IS NULL`, now)
+	if err != nil {
+		return model, err
+	}
+	defer rows.Close()
+
+	type acc struct {
+		sum float64
+		n   int
+	}
+	movieHour := make(map[string]map[int]*acc)
+	movie := make(map[string]*acc)
+	hour := make(map[int]*acc)
+	var total acc
+
+	add := func(a *acc, v float64) {
+		a.sum += v
+		a.n++
+	}
+
+	for rows.Next() {
+		var movieID string
+		var h int
+		var occupancy float64
+		if err := rows.Scan(&movieID, &h, &occupancy); err != nil {
+			return model, err
+		}
+
+		if movieHour[movieID] == nil {
+			movieHour[movieID] = make(map[int]*acc)
+		}
+		if movieHour[movieID][h] == nil {
+			movieHour[movieID][h] = &acc{}
+		}
+		if movie[movieID] == nil {
+			movie[movieID] = &acc{}
+		}
+		if hour[h] == nil {
+			hour[h] = &acc{}
+		}
+
+		add(movieHour[movieID][h], occupancy)
+		add(movie[movieID], occupancy)
+		add(hour[h], occupancy)
+		add(&total, occupancy)
+	}
+	if err := rows.Err(); err != nil {
+		return model, err
+	}
+
+	for id, hours := range movieHour {
+		model.byMovieHour[id] = make(map[int]float64)
+		for h, a := range hours {
+			model.byMovieHour[id][h] = a.sum / float64(a.n)
+		}
+	}
+	for id, a := range movie {
+		model.byMovie[id] = a.sum / float64(a.n)
+	}
+	for h, a := range hour {
+		model.byHour[h] = a.sum / float64(a.n)
+	}
+	if total.n > 0 {
+		model.overall = total.sum / float64(total.n)
+	}
+
+	return model, nil
+}
+
+// hallSettings — время уборки, часы работы и окна обслуживания зала, которые проверяют ограничение
+// no_overlapping_movie_shows и триггер check_movie_show_conflict. Часы работы могут быть не заданы.
+type hallSettings struct {
+	Cleaning time.Duration
+	OpensAt  *time.Duration
+	ClosesAt *time.Duration
+	// Часовой пояс, в котором заданы часы работы; nil — UTC
+	Location *time.Location
+	// Окна обслуживания, загруженные loadHallMaintenance за нужный период
+	Maintenance []HallMaintenanceWindow
+}
+
+func (s hallSettings) location() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
+}
+
+// hallShow — сеанс в зале; End — окончание фильма без учёта уборки.
+type hallShow struct {
+	ID      string
+	MovieID string
+	Start   time.Time
+	End     time.Time
+}
+
+func loadHallSettings(ctx context.Context, db *pgxpool.Pool, hallID string) (hallSettings, error) {
+	var s hallSettings
+	var cleaning int
+	var opensAt, closesAt *string
+	var timeZone string
+	err := db.QueryRow(ctx, `
+		SELECT COALESCE(h.cleaning_minutes, st.default_cleaning_minutes, $2), h.opens_at, h.closes_at, h.time_zone
+		FROM halls h
+		LEFT JOIN screen_types st ON st.id = h.screen_type_id
+		WHERE h.id = $1`, hallID, defaultCleaningMinutes).
+		Scan(&cleaning, &opensAt, &closesAt, &timeZone)
+	if err != nil {
+		return s, err
+	}
+
+	if s.Location, err = LoadTimeZone(timeZone); err != nil {
+		return s, err
+	}
+
+	s.Cleaning = time.Duration(cleaning) * time.Minute
+	if opensAt != nil && closesAt != nil {
+		opens, err := ParseClock(*opensAt)
+		if err != nil {
+			return s, err
+		}
+		closes, err := ParseClock(*closesAt)
+		if err != nil {
+			return s, err
+		}
+		s.OpensAt, s.ClosesAt = &opens, &closes
+	}
+	return s, nil
+}
+
+func loadMovieDuration(ctx context.Context, db *pgxpool.Pool, movieID string) (time.Duration, error) {
+	var duration string
+	if err := db.QueryRow(ctx, "SELECT duration FROM movies WHERE id = $1", movieID).Scan(&duration); err != nil {
+		return 0, err
+	}
+	return ParseClock(duration)
+}
+
+// loadHallMaintenance возвращает окна обслуживания зала, пересекающиеся с интервалом [from, to),
+// со временем в часовом поясе loc.
+func loadHallMaintenance(ctx context.Context, db *pgxpool.Pool, hallID string, from, to time.Time, loc *time.Location) ([]HallMaintenanceWindow, error) {
+	rows, err := db.Query(ctx, `
+		SELECT `+hallMaintenanceColumns+`
+		FROM hall_maintenance_windows
+		WHERE hall_id = $1 AND period && tstzrange($2, $3)
+		ORDER BY lower(period)`, hallID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []HallMaintenanceWindow
+	for rows.Next() {
+		var mw HallMaintenanceWindow
+		if err := rows.Scan(&mw.ID, &mw.HallID, &mw.StartTime, &mw.EndTime, &mw.Reason); err != nil {
+			return nil, err
+		}
+		mw.StartTime, mw.EndTime = mw.StartTime.In(loc), mw.EndTime.In(loc)
+		windows = append(windows, mw)
+	}
+	return windows, rows.Err()
+}
+
+// loadHallShows возвращает сеансы зала, начинающиеся в интервале [from, to),
+// со временем в часовом поясе loc.
+func loadHallShows(ctx context.Context, db *pgxpool.Pool, hallID string, from, to time.Time, loc *time.Location) ([]hallShow, error) {
+	rows, err := db.Query(ctx, `
+		SELECT ms.id, ms.movie_id, ms.start_time, m.duration
+		FROM movie_shows ms
+		JOIN movies m ON m.id = ms.movie_id
+		WHERE ms.hall_id = $1 AND ms.start_time >= $2 AND ms.start_time < $3
+		AND ms.cancelled_at IS NULL
+		ORDER BY ms.start_time`, hallID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shows []hallShow
+	for rows.Next() {
+		var s hallShow
+		var duration string
+		if err := rows.Scan(&s.ID, &s.MovieID, &s.Start, &duration); err != nil {
+			return nil, err
+		}
+		d, err := ParseClock(duration)
+		if err != nil {
+			return nil, err
+		}
+		s.Start = s.Start.In(loc)
+		s.End = s.Start.Add(d)
+		shows = append(shows, s)
+	}
+	return shows, rows.Err()
+}
+
+// outsideOpeningHours повторяет проверку часов работы из триггера:
+// сеанс должен начаться не раньше открытия и закончиться не позже закрытия зала
+// по местному времени зала.
+func (s hallSettings) outsideOpeningHours(start time.Time, duration time.Duration) bool {
+	if s.OpensAt == nil {
+		return false
+	}
+	start = start.In(s.location())
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	return start.Before(day.Add(*s.OpensAt)) || start.Add(duration).After(day.Add(*s.ClosesAt))
+}
+
+// maintenanceConflicts повторяет проверку обслуживания из триггера:
+// сеанс вместе с уборкой не должен пересекаться с окном обслуживания зала.
+func (s hallSettings) maintenanceConflicts(start time.Time, duration time.Duration) []HallMaintenanceWindow {
+	slot := timeInterval{Start: start, End: start.Add(duration + s.Cleaning)}
+
+	var conflicts []HallMaintenanceWindow
+	for _, mw := range s.Maintenance {
+		if slot.overlaps(timeInterval{Start: mw.StartTime, End: mw.EndTime}) {
+			conflicts = append(conflicts, mw)
+		}
+	}
+	return conflicts
+}
+
+// conflictingShows возвращает сеансы, с которыми пересекается новый сеанс
+// с учётом уборки зала после каждого показа.
+func conflictingShows(shows []hallShow, start time.Time, duration, cleaning time.Duration, excludeID string) []hallShow {
+	slot := timeInterval{Start: start, End: start.Add(duration + cleaning)}
+
+	var conflicts []hallShow
+	for _, s := range shows {
+		if s.ID == excludeID {
+			continue
+		}
+		if slot.overlaps(timeInterval{Start: s.Start, End: s.End.Add(cleaning)}) {
+			conflicts = append(conflicts, s)
+		}
+	}
+	return conflicts
+}
+
+// computeFreeSlots перебирает с шагом step все начала сеанса в течение дня
+// и оставляет те, что укладываются в часы работы зала и не конфликтуют
+// с существующими сеансами и обслуживанием зала.
+func computeFreeSlots(day time.Time, duration time.Duration, settings hallSettings, shows []hallShow, step time.Duration) []FreeSlot {
+	from, to := day, day.Add(24*time.Hour-time.Nanosecond)
+	if settings.OpensAt != nil {
+		from = day.Add(*settings.OpensAt)
+		to = day.Add(*settings.ClosesAt - duration)
+	}
+
+	var slots []FreeSlot
+	for start := from; !start.After(to); start = start.Add(step) {
+		if len(conflictingShows(shows, start, duration, settings.Cleaning, "")) == 0 &&
+			len(settings.maintenanceConflicts(start, duration)) == 0 {
+			slots = append(slots, FreeSlot{StartTime: start, EndTime: start.Add(duration)})
+		}
+	}
+	return slots
+}
+
+// @Summary Предложить расписание на неделю (admin)
+// @Description Составляет бесконфликтное расписание сеансов, максимизирующее ожидаемую заполняемость
+// @Description по истории продаж билетов. Расписание не сохраняется: после проверки его можно
+// @Description передать в POST /movie-shows/bulk. Если часы работы и время уборки не указаны,
+// @Description используются настройки зала. С cinema_id планирование ограничено залами кинотеатра.
+// @Tags Расписание
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ScheduleRequest true "Параметры планирования"
+// @Success 200 {object} ScheduleProposal "Предлагаемое расписание"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Фильм или кинозал не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /schedule/proposals [post]
+func ProposeSchedule(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		role := r.Header.Get("Role")
+		if role != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		var req ScheduleRequest
+		if !DecodeJSONBody(w, r, &req) || !validateScheduleRequest(w, req) {
+			return
+		}
+
+		if req.CinemaID != nil && !requireCinemaAccess(w, r, db, cinemaByID, *req.CinemaID) {
+			return
+		}
+		for _, h := range req.Halls {
+			if !requireCinemaAccess(w, r, db, cinemaOfHall, h.HallID) {
+				return
+			}
+		}
+
+		ctx := r.Context()
+		movies, err := loadScheduleMovies(ctx, db, req.Movies)
+		if errors.Is(err, errScheduleNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if HandleDatabaseError(w, err, "фильмами") {
+			return
+		}
+
+		if len(req.Halls) == 0 {
+			req.Halls, err = loadCinemaScheduleHalls(ctx, db, *req.CinemaID)
+			if errors.Is(err, errScheduleNotFound) {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			if HandleDatabaseError(w, err, "залами") {
+				return
+			}
+		}
+
+		halls, err := loadScheduleHalls(ctx, db, req.Halls, req.CinemaID)
+		if errors.Is(err, errScheduleNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if HandleDatabaseError(w, err, "залами") {
+			return
+		}
+
+		days := req.Days
+		if days == 0 {
+			days = 7
+		}
+		step := req.StepMinutes
+		if step == 0 {
+			step = 15
+		}
+
+		start, _ := time.Parse(time.DateOnly, req.WeekStart)
+		end := start.AddDate(0, 0, days+1)
+
+		busy, err := loadBusyIntervals(ctx, db, halls, start, end)
+		if HandleDatabaseError(w, err, "киносеансами") {
+			return
+		}
+
+		model, err := loadOccupancyModel(ctx, db, time.Now())
+		if HandleDatabaseError(w, err, "историей продаж") {
+			return
+		}
+
+		primeTime := make([]clockWindow, 0, len(req.PrimeTime))
+		for _, p := range req.PrimeTime {
+			from, to, _ := parseClockWindow(p.From, p.To)
+			primeTime = append(primeTime, clockWindow{From: from, To: to})
+		}
+
+		proposal := proposeSchedule(scheduleInput{
+			Start:     start,
+			Days:      days,
+			Step:      time.Duration(step) * time.Minute,
+			Movies:    movies,
+			Halls:     halls,
+			PrimeTime: primeTime,
+			Busy:      busy,
+			Model:     model,
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(proposal)
+	}
+}
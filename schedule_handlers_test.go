@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestProposeScheduleAlgorithm(t *testing.T) {
+	start := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	baseInput := func() scheduleInput {
+		return scheduleInput{
+			Start: start,
+			Days:  2,
+			Step:  15 * time.Minute,
+			Movies: []scheduleMovie{
+				{ID: "popular", Duration: 2 * time.Hour, Screenings: 3, Language: "Русский", BasePrice: 300},
+				{ID: "niche", Duration: 90 * time.Minute, Screenings: 3, Language: "English", BasePrice: 250},
+			},
+			Halls: []scheduleHall{
+				{ID: "big", Capacity: 100, OpenFrom: 10 * time.Hour, OpenTo: 23 * time.Hour, Cleaning: 15 * time.Minute},
+				{ID: "small", Capacity: 20, OpenFrom: 12 * time.Hour, OpenTo: 22 * time.Hour, Cleaning: 10 * time.Minute},
+			},
+			PrimeTime: []clockWindow{{From: 18 * time.Hour, To: 22 * time.Hour}},
+			Model: occupancyModel{
+				byMovieHour: map[string]map[int]float64{},
+				byMovie:     map[string]float64{"popular": 0.8, "niche": 0.2},
+				byHour:      map[int]float64{},
+			},
+		}
+	}
+
+	durations := map[string]time.Duration{"popular": 2 * time.Hour, "niche": 90 * time.Minute}
+	cleaning := map[string]time.Duration{"big": 15 * time.Minute, "small": 10 * time.Minute}
+
+	checkNoConflicts := func(t *testing.T, in scheduleInput, p ScheduleProposal) {
+		t.Helper()
+		byHall := make(map[string][]timeInterval)
+		for hallID, busy := range in.Busy {
+			byHall[hallID] = append(byHall[hallID], busy...)
+		}
+		for _, s := range p.Shows {
+			slot := timeInterval{
+				Start: s.Show.StartTime,
+				End:   s.Show.StartTime.Add(durations[s.Show.MovieID] + cleaning[s.Show.HallID]),
+			}
+			for _, other := range byHall[s.Show.HallID] {
+				if slot.overlaps(other) {
+					t.Fatalf("Show %v in hall %s overlaps with %v", slot, s.Show.HallID, other)
+				}
+			}
+			byHall[s.Show.HallID] = append(byHall[s.Show.HallID], slot)
+		}
+	}
+
+	t.Run("All screenings placed without conflicts", func(t *testing.T) {
+		in := baseInput()
+		p := proposeSchedule(in)
+
+		if len(p.Shows) != 6 {
+			t.Fatalf("Expected 6 shows, got %d", len(p.Shows))
+		}
+		if len(p.Unscheduled) != 0 {
+			t.Errorf("Expected no unscheduled movies, got %v", p.Unscheduled)
+		}
+		checkNoConflicts(t, in, p)
+	})
+
+	t.Run("Shows respect hall hours", func(t *testing.T) {
+		in := baseInput()
+		p := proposeSchedule(in)
+
+		for _, s := range p.Shows {
+			var hall scheduleHall
+			for _, h := range in.Halls {
+				if h.ID == s.Show.HallID {
+					hall = h
+				}
+			}
+			day := time.Date(s.Show.StartTime.Year(), s.Show.StartTime.Month(), s.Show.StartTime.Day(), 0, 0, 0, 0, time.UTC)
+			offset := s.Show.StartTime.Sub(day)
+			if offset < hall.OpenFrom || offset+durations[s.Show.MovieID] > hall.OpenTo {
+				t.Errorf("Show at %v is outside hall %s hours", s.Show.StartTime, hall.ID)
+			}
+		}
+	})
+
+	t.Run("Popular movie gets prime time in big hall", func(t *testing.T) {
+		in := baseInput()
+		p := proposeSchedule(in)
+
+		found := false
+		for _, s := range p.Shows {
+			if s.Show.MovieID == "popular" && s.Show.HallID == "big" && s.PrimeTime {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected popular movie in prime time in the big hall")
+		}
+	})
+
+	t.Run("Existing shows are avoided", func(t *testing.T) {
+		in := baseInput()
+		in.Busy = map[string][]timeInterval{
+			"big": {{Start: start.Add(17 * time.Hour), End: start.Add(23 * time.Hour)}},
+		}
+		p := proposeSchedule(in)
+		checkNoConflicts(t, in, p)
+	})
+
+	t.Run("Unschedulable screenings are reported", func(t *testing.T) {
+		in := baseInput()
+		in.Days = 1
+		in.Movies[0].Screenings = 50
+		p := proposeSchedule(in)
+
+		if len(p.Unscheduled) != 1 || p.Unscheduled[0].MovieID != "popular" {
+			t.Fatalf("Expected popular movie to be reported as unscheduled, got %v", p.Unscheduled)
+		}
+		checkNoConflicts(t, in, p)
+	})
+}
+
+func TestProposeSchedule(t *testing.T) {
+	weekStart := time.Now().AddDate(0, 0, 10).Format(time.DateOnly)
+
+	validRequest := ScheduleRequest{
+		WeekStart: weekStart,
+		Days:      2,
+		Movies: []ScheduleMovie{
+			{MovieID: MoviesData[0].ID, Screenings: 2, Language: "Русский", BasePrice: 300},
+			{MovieID: MoviesData[1].ID, Screenings: 2, Language: "English", BasePrice: 300},
+		},
+		Halls: []ScheduleHall{
+			{HallID: HallsData[0].ID, AvailableFrom: "10:00", AvailableTo: "23:00", CleaningMinutes: 15},
+			{HallID: HallsData[1].ID, AvailableFrom: "12:00", AvailableTo: "24:00"},
+		},
+		PrimeTime: []PrimeTimeSlot{{From: "18:00", To: "22:00"}},
+	}
+
+	invalidHours := validRequest
+	invalidHours.Halls = []ScheduleHall{{HallID: HallsData[0].ID, AvailableFrom: "23:00", AvailableTo: "10:00"}}
+
+	unknownMovie := validRequest
+	unknownMovie.Movies = []ScheduleMovie{{MovieID: uuid.New().String(), Screenings: 1, Language: "Русский", BasePrice: 300}}
+
+	tests := []struct {
+		name           string
+		role           string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Forbidden Guest", "", validRequest, http.StatusForbidden},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), validRequest, http.StatusForbidden},
+		{"Invalid JSON", os.Getenv("CLAIM_ROLE_ADMIN"), "{invalid json}", http.StatusBadRequest},
+		{"Invalid hall hours", os.Getenv("CLAIM_ROLE_ADMIN"), invalidHours, http.StatusBadRequest},
+		{"Unknown movie", os.Getenv("CLAIM_ROLE_ADMIN"), unknownMovie, http.StatusNotFound},
+		{"Success Admin", os.Getenv("CLAIM_ROLE_ADMIN"), validRequest, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/schedule/proposals", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var proposal ScheduleProposal
+			parseResponseBody(t, resp, &proposal)
+			if len(proposal.Shows) != 4 {
+				t.Fatalf("Expected 4 proposed shows, got %d", len(proposal.Shows))
+			}
+
+			bulk := MovieShowBulk{}
+			for _, s := range proposal.Shows {
+				bulk.Shows = append(bulk.Shows, s.Show)
+			}
+
+			commit := createRequest(t, "POST", ts.URL+"/movie-shows/bulk", generateToken(t, tt.role), bulk)
+			commitResp := executeRequest(t, commit, http.StatusCreated)
+			defer commitResp.Body.Close()
+
+			var count int
+			err := TestAdminDB.QueryRow(context.Background(),
+				"SELECT COUNT(*) FROM movie_shows WHERE start_time >= $1", MustParseTime(weekStart)).Scan(&count)
+			if err != nil {
+				t.Fatalf("Failed to count movie shows: %v", err)
+			}
+			if count != 4 {
+				t.Errorf("Expected 4 committed shows, got %d", count)
+			}
+		})
+	}
+}
+
+func TestComputeFreeSlots(t *testing.T) {
+	day := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	opens, closes := 10*time.Hour, 22*time.Hour
+	settings := hallSettings{Cleaning: 15 * time.Minute, OpensAt: &opens, ClosesAt: &closes}
+	duration := 2 * time.Hour
+
+	shows := []hallShow{
+		{ID: "afternoon", Start: day.Add(14 * time.Hour), End: day.Add(16 * time.Hour)},
+	}
+
+	slots := computeFreeSlots(day, duration, settings, shows, 15*time.Minute)
+	if len(slots) == 0 {
+		t.Fatal("Expected free slots")
+	}
+
+	for _, s := range slots {
+		if settings.outsideOpeningHours(s.StartTime, duration) {
+			t.Errorf("Slot %v is outside opening hours", s.StartTime)
+		}
+		if c := conflictingShows(shows, s.StartTime, duration, settings.Cleaning, ""); len(c) != 0 {
+			t.Errorf("Slot %v conflicts with %v", s.StartTime, c)
+		}
+	}
+
+	first, last := slots[0].StartTime, slots[len(slots)-1].StartTime
+	if !first.Equal(day.Add(10 * time.Hour)) {
+		t.Errorf("Expected first slot at opening, got %v", first)
+	}
+	if !last.Equal(day.Add(20 * time.Hour)) {
+		t.Errorf("Expected last slot to end at closing, got %v", last)
+	}
+
+	contains := func(start time.Time) bool {
+		for _, s := range slots {
+			if s.StartTime.Equal(start) {
+				return true
+			}
+		}
+		return false
+	}
+	if !contains(day.Add(11*time.Hour + 45*time.Minute)) {
+		t.Error("Expected slot ending with cleaning exactly at the next show")
+	}
+	if contains(day.Add(12 * time.Hour)) {
+		t.Error("Expected slot overlapping cleaning time to be excluded")
+	}
+	if !contains(day.Add(16*time.Hour + 15*time.Minute)) {
+		t.Error("Expected slot right after cleaning of the previous show")
+	}
+
+	t.Run("Hall without opening hours", func(t *testing.T) {
+		slots := computeFreeSlots(day, duration, hallSettings{Cleaning: 10 * time.Minute}, nil, time.Hour)
+		if len(slots) != 24 {
+			t.Errorf("Expected 24 hourly slots, got %d", len(slots))
+		}
+	})
+
+	t.Run("Maintenance window", func(t *testing.T) {
+		closed := settings
+		closed.Maintenance = []HallMaintenanceWindow{
+			{ID: "repair", StartTime: day.Add(19 * time.Hour), EndTime: day.Add(24 * time.Hour)},
+		}
+
+		slots := computeFreeSlots(day, duration, closed, shows, 15*time.Minute)
+		last := slots[len(slots)-1].StartTime
+		// Сеанс с уборкой должен закончиться к началу обслуживания
+		if !last.Equal(day.Add(16*time.Hour + 45*time.Minute)) {
+			t.Errorf("Expected last slot to end with cleaning at maintenance start, got %v", last)
+		}
+		if c := closed.maintenanceConflicts(day.Add(17*time.Hour), duration); len(c) != 1 || c[0].ID != "repair" {
+			t.Errorf("Expected conflict with maintenance window, got %v", c)
+		}
+	})
+
+	t.Run("Opening hours in hall time zone", func(t *testing.T) {
+		loc, err := LoadTimeZone("Asia/Vladivostok")
+		if err != nil {
+			t.Fatalf("Failed to load time zone: %v", err)
+		}
+		local := hallSettings{Cleaning: 15 * time.Minute, OpensAt: &opens, ClosesAt: &closes, Location: loc}
+
+		// 01:00 UTC = 11:00, 13:00 UTC = 23:00 по Владивостоку
+		if local.outsideOpeningHours(day.Add(time.Hour), duration) {
+			t.Error("Expected 11:00 local time to be within opening hours")
+		}
+		if !local.outsideOpeningHours(day.Add(13*time.Hour), duration) {
+			t.Error("Expected 23:00 local time to be outside opening hours")
+		}
+
+		localDay := time.Date(2025, 6, 2, 0, 0, 0, 0, loc)
+		slots := computeFreeSlots(localDay, duration, local, nil, time.Hour)
+		if len(slots) == 0 || !slots[0].StartTime.Equal(localDay.Add(10*time.Hour)) {
+			t.Errorf("Expected first slot at local opening, got %v", slots)
+		}
+	})
+}
+
+func TestLoadTimeZone(t *testing.T) {
+	for _, name := range []string{"UTC", "Europe/Moscow", "Asia/Vladivostok"} {
+		if _, err := LoadTimeZone(name); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "Local", "Mars/Olympus"} {
+		if _, err := LoadTimeZone(name); err == nil {
+			t.Errorf("Expected %q to be rejected", name)
+		}
+	}
+}
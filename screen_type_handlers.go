@@ -8,6 +8,7 @@ import (
 	"regexp"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -30,6 +31,11 @@ func validateAllScreenTypeAdmin(w http.ResponseWriter, e ScreenTypeAdmin) bool {
 		return false
 	}
 
+	if err := validateCleaningMinutes(e.DefaultCleaningMinutes); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
 	return true
 }
 
@@ -76,33 +82,31 @@ func validateScreenTypeDesctiption(description string) error {
 // @Description Возвращает список всех типов экранов, содержащихся в базе данных.
 // @Tags Типы экранов
 // @Produce json
-// @Success 200 {array} ScreenType "Список типов экранов"
-// @Failure 404 {object} ErrorResponse "Типы экранов не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[ScreenType] "Страница типов экранов"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /screen-types [get]
 func GetScreenTypes(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(context.Background(), "SELECT id, name, description FROM screen_types")
-		if HandleDatabaseError(w, err, "типами экранов") {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var types []ScreenType
-		for rows.Next() {
-			var e ScreenType
-			if err := rows.Scan(&e.ID, &e.Name, &e.Description); HandleDatabaseError(w, err, "типом экранов") {
-				return
-			}
-			types = append(types, e)
-		}
 
-		if len(types) == 0 {
-			http.Error(w, "Типы экранов не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q, "id, name, description, default_cleaning_minutes", "screen_types",
+			func(row pgx.Row) (ScreenType, error) {
+				var e ScreenType
+				err := row.Scan(&e.ID, &e.Name, &e.Description, &e.DefaultCleaningMinutes)
+				return e, err
+			})
+		if HandleDatabaseError(w, err, "типами экранов") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(types)
+		writePage(w, page)
 	}
 }
 
@@ -126,8 +130,8 @@ func GetScreenTypeByID(db *pgxpool.Pool) http.HandlerFunc {
 		var e ScreenType
 		e.ID = id.String()
 		err := db.QueryRow(context.Background(),
-			"SELECT name, description FROM screen_types WHERE id = $1", id).
-			Scan(&e.Name, &e.Description)
+			"SELECT name, description, default_cleaning_minutes FROM screen_types WHERE id = $1", id).
+			Scan(&e.Name, &e.Description, &e.DefaultCleaningMinutes)
 
 		if IsError(w, err) {
 			return
@@ -138,7 +142,7 @@ func GetScreenTypeByID(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Создать тип экрана (admin)
-// @Description Создаёт новый тип экрана.
+// @Description Создаёт новый тип экрана. Время уборки по умолчанию для залов этого типа — 10 минут, если не указано иное.
 // @Tags Типы экранов
 // @Accept json
 // @Produce json
@@ -161,8 +165,9 @@ func CreateScreenType(db *pgxpool.Pool) http.HandlerFunc {
 
 		id := uuid.New()
 		_, err := db.Exec(context.Background(),
-			"INSERT INTO screen_types (id, name, description, price_modifier) VALUES ($1, $2, $3, $4)",
-			id, e.Name, e.Description, e.PriceModifier)
+			`INSERT INTO screen_types (id, name, description, price_modifier, default_cleaning_minutes)
+			VALUES ($1, $2, $3, $4, COALESCE($5::int, $6))`,
+			id, e.Name, e.Description, e.PriceModifier, e.DefaultCleaningMinutes, defaultCleaningMinutes)
 
 		if IsError(w, err) {
 			return
@@ -174,7 +179,7 @@ func CreateScreenType(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Обновить тип экрана (admin)
-// @Description Обновляет существующий тип экрана.
+// @Description Обновляет существующий тип экрана. Если время уборки по умолчанию не передано, оно не изменяется.
 // @Tags Типы экранов
 // @Accept json
 // @Produce json
@@ -203,8 +208,9 @@ func UpdateScreenType(db *pgxpool.Pool) http.HandlerFunc {
 		}
 
 		res, err := db.Exec(context.Background(),
-			"UPDATE screen_types SET name=$1, description=$2, price_modifier=$3 WHERE id=$4",
-			e.Name, e.Description, e.PriceModifier, id)
+			`UPDATE screen_types SET name=$1, description=$2, price_modifier=$3,
+			default_cleaning_minutes=COALESCE($4, default_cleaning_minutes) WHERE id=$5`,
+			e.Name, e.Description, e.PriceModifier, e.DefaultCleaningMinutes, id)
 
 		if IsError(w, err) {
 			return
@@ -251,13 +257,15 @@ func DeleteScreenType(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Поиск типов экранов по названию (guest | user | admin)
-// @Description Возвращает типы экранов, название которых содержит указанную строку.
+// @Description Возвращает страницу типов экранов, название которых содержит указанную строку.
 // @Tags Типы экранов
 // @Produce json
 // @Param query query string true "Поисковый запрос"
-// @Success 200 {array} ScreenType "Список типов экранов"
-// @Failure 400 {object} ErrorResponse "Строка поиска пуста"
-// @Failure 404 {object} ErrorResponse "Типы экранов не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[ScreenType] "Страница типов экранов"
+// @Failure 400 {object} ErrorResponse "Строка поиска пуста или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /screen-types/search [get]
 func SearchScreenTypes(db *pgxpool.Pool) http.HandlerFunc {
@@ -269,27 +277,22 @@ func SearchScreenTypes(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(),
-			"SELECT id, name, description FROM screen_types WHERE name ILIKE $1", "%"+query+"%")
-		if IsError(w, err) {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var types []ScreenType
-		for rows.Next() {
-			var e ScreenType
-			if err := rows.Scan(&e.ID, &e.Name, &e.Description); IsError(w, err) {
-				return
-			}
-			types = append(types, e)
-		}
-
-		if len(types) == 0 {
-			http.Error(w, "Типы экранов не найдены", http.StatusNotFound)
+		q.where("name ILIKE $%[1]d", "%"+query+"%")
+
+		page, err := fetchPage(context.Background(), db, q, "id, name, description, default_cleaning_minutes", "screen_types",
+			func(row pgx.Row) (ScreenType, error) {
+				var e ScreenType
+				err := row.Scan(&e.ID, &e.Name, &e.Description, &e.DefaultCleaningMinutes)
+				return e, err
+			})
+		if HandleDatabaseError(w, err, "типами экранов") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(types)
+		writePage(w, page)
 	}
 }
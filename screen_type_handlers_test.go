@@ -19,9 +19,9 @@ func TestGetScreenTypes(t *testing.T) {
 		role           string
 		expectedStatus int
 	}{
-		{"Empty as Guest", false, "", http.StatusNotFound},
-		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Guest", false, "", http.StatusOK},
+		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusOK},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -52,10 +52,14 @@ func TestGetScreenTypes(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var screenTypes []ScreenType
-				parseResponseBody(t, resp, &screenTypes)
+				var page Page[ScreenType]
+				parseResponseBody(t, resp, &page)
+				screenTypes := page.Items
 
-				if len(screenTypes) == 0 {
+				if !tt.seedData && (screenTypes == nil || len(screenTypes) != 0) {
+					t.Errorf("Expected empty screen types list, got %v", screenTypes)
+				}
+				if tt.seedData && len(screenTypes) == 0 {
 					t.Error("Expected non-empty screen types list")
 				}
 			}
@@ -244,9 +248,9 @@ func TestSearchScreenTypes(t *testing.T) {
 		{"Short query as Guest", "Л", "", http.StatusOK, 1},
 		{"Short query as User", "Л", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
 		{"Short query as Admin", "Л", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
-		{"No matches as Guest", "Плазма", "", http.StatusNotFound, 0},
-		{"No matches as User", "Плазма", os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound, 0},
-		{"No matches as Admin", "Плазма", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound, 0},
+		{"No matches as Guest", "Плазма", "", http.StatusOK, 0},
+		{"No matches as User", "Плазма", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 0},
+		{"No matches as Admin", "Плазма", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 0},
 		{"Exact match as Guest", "LED", "", http.StatusOK, 2},
 		{"Exact match as User", "LED", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 2},
 		{"Exact match as Admin", "LED", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 2},
@@ -262,9 +266,9 @@ func TestSearchScreenTypes(t *testing.T) {
 		{"Partial with spaces as Guest", "  система    ", "", http.StatusOK, 1},
 		{"Partial with spaces as User", "  система    ", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
 		{"Partial with spaces as Admin", "  система    ", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
-		{"Special chars as Guest", "LED/", "", http.StatusNotFound, 0},
-		{"Special chars as User", "LED/", os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound, 0},
-		{"Special chars as Admin", "LED/", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound, 0},
+		{"Special chars as Guest", "LED/", "", http.StatusOK, 0},
+		{"Special chars as User", "LED/", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 0},
+		{"Special chars as Admin", "LED/", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 0},
 		{"DBError as Guest", "LED", "", http.StatusInternalServerError, 0},
 		{"DBError as User", "LED", os.Getenv("CLAIM_ROLE_USER"), http.StatusInternalServerError, 0},
 		{"DBError as Admin", "LED", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusInternalServerError, 0},
@@ -288,8 +292,9 @@ func TestSearchScreenTypes(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var screenTypes []ScreenType
-				parseResponseBody(t, resp, &screenTypes)
+				var page Page[ScreenType]
+				parseResponseBody(t, resp, &page)
+				screenTypes := page.Items
 
 				if len(screenTypes) != tt.expectedCount {
 					t.Errorf("Expected %d screen types, got %d", tt.expectedCount, len(screenTypes))
@@ -358,6 +363,8 @@ func TestCreateScreenType(t *testing.T) {
 		{"1001 chars desc as Guest", "", ScreenTypeAdmin{Name: "Test", Description: strings.Repeat("a", 1001), PriceModifier: 1}, nil, http.StatusBadRequest},
 		{"1001 chars desc as User", os.Getenv("CLAIM_ROLE_USER"), ScreenTypeAdmin{Name: "Test", Description: strings.Repeat("a", 1001), PriceModifier: 1}, nil, http.StatusBadRequest},
 		{"1001 chars desc as Admin", os.Getenv("CLAIM_ROLE_ADMIN"), ScreenTypeAdmin{Name: "Test", Description: strings.Repeat("a", 1001), PriceModifier: 1}, nil, http.StatusBadRequest},
+		{"Cleaning time as Admin", os.Getenv("CLAIM_ROLE_ADMIN"), ScreenTypeAdmin{Name: "Test", Description: "Test", PriceModifier: 1, DefaultCleaningMinutes: ptr(20)}, nil, http.StatusCreated},
+		{"Negative cleaning time as Admin", os.Getenv("CLAIM_ROLE_ADMIN"), ScreenTypeAdmin{Name: "Test", Description: "Test", PriceModifier: 1, DefaultCleaningMinutes: ptr(-1)}, nil, http.StatusBadRequest},
 		{"DBError as Guest", "", ScreenTypeAdmin{Name: "Test", Description: "Test", PriceModifier: 1}, func(t *testing.T) {
 			TestAdminDB.Close()
 			TestGuestDB.Close()
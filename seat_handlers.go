@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 	"os"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -64,14 +68,130 @@ func validateSeatNumber(seatNumber int) error {
 	return nil
 }
 
+const seatColumns = `id, hall_id, seat_type_id, row_number, seat_number, service_state, service_reason, service_from, service_until,
+	wheelchair_space, companion_seat_id, zone_id`
+
+// scanSeat читает строку со столбцами seatColumns
+func scanSeat(row pgx.Row, s *Seat) error {
+	var service SeatService
+	var reason *string
+	var from *time.Time
+	if err := row.Scan(&s.ID, &s.HallID, &s.SeatTypeID, &s.RowNumber, &s.SeatNumber,
+		&service.State, &reason, &from, &service.Until, &s.WheelchairSpace, &s.CompanionSeatID, &s.ZoneID); err != nil {
+		return err
+	}
+
+	if service.State != SeatActive && reason != nil && from != nil {
+		service.Reason, service.From = *reason, *from
+		s.Service = &service
+	}
+	return nil
+}
+
+// parseAccessibleFilter разбирает параметр accessible: true оставляет только то, что подходит маломобильным зрителям
+func parseAccessibleFilter(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	switch r.URL.Query().Get("accessible") {
+	case "", "false":
+		return false, true
+	case "true":
+		return true, true
+	default:
+		http.Error(w, "Параметр accessible должен быть true или false", http.StatusBadRequest)
+		return false, false
+	}
+}
+
+func validateSeatAccessibility(w http.ResponseWriter, id uuid.UUID, data SeatAccessibility) bool {
+	if data.CompanionSeatID == nil {
+		return true
+	}
+	if !data.WheelchairSpace {
+		http.Error(w, "Место сопровождающего можно указать только для места под коляску", http.StatusBadRequest)
+		return false
+	}
+	companionID, err := uuid.Parse(*data.CompanionSeatID)
+	if err != nil {
+		http.Error(w, "Неверный формат ID места сопровождающего", http.StatusBadRequest)
+		return false
+	}
+	if companionID == id {
+		http.Error(w, "Место не может быть местом своего сопровождающего", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func validateSeatService(w http.ResponseWriter, data *SeatServiceData) bool {
+	if !data.State.IsValid() {
+		http.Error(w, "Недопустимое состояние места", http.StatusBadRequest)
+		return false
+	}
+	if data.State == SeatActive {
+		data.Reason, data.From, data.Until = nil, nil, nil
+		return true
+	}
+
+	if data.Reason == nil || PrepareString(*data.Reason) == "" {
+		http.Error(w, "Укажите причину вывода места из обслуживания", http.StatusBadRequest)
+		return false
+	}
+	reason := PrepareString(*data.Reason)
+	if utf8.RuneCountInString(reason) > 500 {
+		http.Error(w, "Причина не должна превышать 500 символов", http.StatusBadRequest)
+		return false
+	}
+	data.Reason = &reason
+
+	if data.From == nil {
+		now := time.Now()
+		data.From = &now
+	}
+	if data.Until != nil && !data.Until.After(*data.From) {
+		http.Error(w, "Окончание периода должно быть позже начала", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+var seatListSpec = listSpec{
+	ID: "id",
+	Sorts: map[string]listSortField{
+		"id":          {Expr: "id", Kind: listUUID},
+		"row_number":  {Expr: "row_number", Kind: listInt},
+		"seat_number": {Expr: "seat_number", Kind: listInt},
+		// Ряд в старших 32 битах, место в младших: порядок мест в зале по рядам
+		"position": {Expr: "(row_number::bigint << 32) + seat_number", Kind: listInt},
+	},
+	DefaultSort: "id",
+	Filters: []listFilter{
+		{Param: "hall_id", Kind: listUUID, Cond: "hall_id = $%[1]d::uuid"},
+		{Param: "seat_type_id", Kind: listUUID, Cond: "seat_type_id = $%[1]d::uuid"},
+		{Param: "wheelchair_space", Kind: listBool, Cond: "wheelchair_space = $%[1]d"},
+	},
+}
+
+// hallSeatListSpec — места зала, по умолчанию по рядам и номерам мест
+var hallSeatListSpec = listSpec{
+	ID:          seatListSpec.ID,
+	Sorts:       seatListSpec.Sorts,
+	DefaultSort: "position",
+	Filters:     seatListSpec.Filters,
+}
+
 // @Summary Получить все места (admin)
 // @Description Возвращает список всех мест, содержащихся в базе данных.
 // @Tags Места
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} Seat "Список мест"
+// @Param hall_id query string false "ID зала"
+// @Param seat_type_id query string false "ID типа места"
+// @Param wheelchair_space query bool false "Только места для колясок (true) или только обычные (false)"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: id, row_number, seat_number, position (ряд, затем место); с префиксом - по убыванию"
+// @Success 200 {object} Page[Seat] "Страница мест"
+// @Failure 400 {string} string "Неверные параметры списка"
 // @Failure 403 {string} string "Доступ запрещён"
-// @Failure 404 {string} string "Места не найдены"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /seats [get]
 func GetSeats(db *pgxpool.Pool) http.HandlerFunc {
@@ -82,30 +202,22 @@ func GetSeats(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(), `
-			SELECT id, hall_id, seat_type_id, row_number, seat_number 
-			FROM seats`)
-		if HandleDatabaseError(w, err, "местами") {
+		q, ok := parseListQuery(w, r, seatListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
 
-		var seats []Seat
-		for rows.Next() {
-			var s Seat
-			if err := rows.Scan(&s.ID, &s.HallID, &s.SeatTypeID, &s.RowNumber, &s.SeatNumber); HandleDatabaseError(w, err, "местом") {
-				return
-			}
-			seats = append(seats, s)
-		}
-
-		if len(seats) == 0 {
-			http.Error(w, "Места не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q, seatColumns, "seats",
+			func(row pgx.Row) (Seat, error) {
+				var s Seat
+				err := scanSeat(row, &s)
+				return s, err
+			})
+		if HandleDatabaseError(w, err, "местами") {
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(seats)
+		writePage(w, page)
 	}
 }
 
@@ -127,11 +239,9 @@ func GetSeatByID(db *pgxpool.Pool) http.HandlerFunc {
 		}
 
 		var s Seat
-		s.ID = id.String()
-		err := db.QueryRow(context.Background(), `
-			SELECT hall_id, seat_type_id, row_number, seat_number 
-			FROM seats WHERE id = $1`, id).
-			Scan(&s.HallID, &s.SeatTypeID, &s.RowNumber, &s.SeatNumber)
+		err := scanSeat(db.QueryRow(context.Background(), `
+			SELECT `+seatColumns+`
+			FROM seats WHERE id = $1`, id), &s)
 
 		if IsError(w, err) {
 			return
@@ -164,6 +274,10 @@ func CreateSeat(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfHall, s.HallID) {
+			return
+		}
+
 		id := uuid.New()
 		_, err := db.Exec(context.Background(), `
 			INSERT INTO seats (id, hall_id, seat_type_id, row_number, seat_number) 
@@ -209,6 +323,10 @@ func UpdateSeat(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfSeat, id) || !requireCinemaAccess(w, r, db, cinemaOfHall, s.HallID) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(), `
 			UPDATE seats 
 			SET hall_id=$1, seat_type_id=$2, row_number=$3, seat_number=$4 
@@ -246,6 +364,10 @@ func DeleteSeat(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfSeat, id) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(),
 			"DELETE FROM seats WHERE id = $1", id)
 
@@ -262,13 +384,17 @@ func DeleteSeat(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Получить места по ID зала (guest | user | admin)
-// @Description Возвращает список мест в указанном зале.
+// @Description Возвращает страницу мест в указанном зале, по умолчанию по рядам и номерам мест.
 // @Tags Места
 // @Produce json
 // @Param hall_id path string true "ID зала"
-// @Success 200 {array} Seat "Список мест"
-// @Failure 400 {string} string "Неверный формат ID зала"
-// @Failure 404 {string} string "Места не найдены"
+// @Param accessible query bool false "Только места для колясок и места сопровождающих"
+// @Param seat_type_id query string false "ID типа места"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: position (по умолчанию), id, row_number, seat_number; с префиксом - по убыванию"
+// @Success 200 {object} Page[Seat] "Страница мест"
+// @Failure 400 {string} string "Неверный формат ID зала, параметра accessible или параметров списка"
 // @Failure 500 {string} string "Ошибка сервера"
 // @Router /halls/{hall_id}/seats [get]
 func GetSeatsByHallID(db *pgxpool.Pool) http.HandlerFunc {
@@ -278,31 +404,263 @@ func GetSeatsByHallID(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(), `
-            SELECT s.id, s.hall_id, s.seat_type_id, s.row_number, s.seat_number
-            FROM seats s
-            WHERE s.hall_id = $1
-            ORDER BY s.row_number, s.seat_number`, hallID)
+		accessible, ok := parseAccessibleFilter(w, r)
+		if !ok {
+			return
+		}
+
+		q, ok := parseListQuery(w, r, hallSeatListSpec)
+		if !ok {
+			return
+		}
+		q.where("hall_id = $%[1]d", hallID)
+		if accessible {
+			q.where("(wheelchair_space OR id IN (SELECT companion_seat_id FROM seats WHERE hall_id = $%[1]d))", hallID)
+		}
+
+		page, err := fetchPage(context.Background(), db, q, seatColumns, "seats",
+			func(row pgx.Row) (Seat, error) {
+				var s Seat
+				err := scanSeat(row, &s)
+				return s, err
+			})
+		if HandleDatabaseError(w, err, "местами") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Изменить состояние обслуживания места (admin)
+// @Description Выводит место из обслуживания (blocked, maintenance) на период [from, until) или возвращает его (active).
+// @Description Место остаётся в схеме зала, но на сеансы, пересекающиеся с периодом, не продаётся.
+// @Description Если на такие сеансы на место уже проданы или забронированы билеты, возвращается 409 со списком билетов.
+// @Tags Места
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID места"
+// @Param service body SeatServiceData true "Состояние места"
+// @Success 200 {object} Seat "Место с новым состоянием"
+// @Failure 400 {string} string "В запросе предоставлены неверные данные"
+// @Failure 403 {string} string "Доступ запрещён"
+// @Failure 404 {string} string "Место не найдено"
+// @Failure 409 {object} SeatServiceConflict "На место проданы билеты"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /seats/{id}/service [put]
+func UpdateSeatService(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var data SeatServiceData
+		if !DecodeJSONBody(w, r, &data) {
+			return
+		}
+		if !validateSeatService(w, &data) {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfSeat, id) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		// Блокировка места не даёт продать на него билет, пока проверяются сеансы
+		var lockedID string
+		err = tx.QueryRow(ctx, "SELECT id FROM seats WHERE id = $1 FOR UPDATE", id).Scan(&lockedID)
 		if IsError(w, err) {
 			return
 		}
-		defer rows.Close()
 
-		var seats []Seat
-		for rows.Next() {
-			var s Seat
-			if err := rows.Scan(&s.ID, &s.HallID, &s.SeatTypeID, &s.RowNumber, &s.SeatNumber); IsError(w, err) {
+		if data.State != SeatActive {
+			rows, err := tx.Query(ctx, `
+				SELECT t.id, ms.id, ms.start_time, t.ticket_status
+				FROM tickets t
+				JOIN movie_shows ms ON ms.id = t.movie_show_id
+				WHERE t.seat_id = $1
+				AND t.ticket_status IN ('Purchased', 'Reserved')
+				AND ms.status NOT IN ('cancelled', 'finished')
+				AND seat_out_of_service($2, $3, $4, ms.occupied)
+				ORDER BY ms.start_time`, id, data.State, data.From, data.Until)
+			if IsError(w, err) {
 				return
 			}
-			seats = append(seats, s)
+
+			var tickets []SeatServiceTicket
+			for rows.Next() {
+				var t SeatServiceTicket
+				if err := rows.Scan(&t.TicketID, &t.MovieShowID, &t.StartTime, &t.Status); IsError(w, err) {
+					rows.Close()
+					return
+				}
+				tickets = append(tickets, t)
+			}
+			rows.Close()
+			if IsError(w, rows.Err()) {
+				return
+			}
+
+			if len(tickets) > 0 {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(SeatServiceConflict{
+					Message: "На место в этот период проданы или забронированы билеты",
+					Tickets: tickets,
+				})
+				return
+			}
+		}
+
+		var s Seat
+		err = scanSeat(tx.QueryRow(ctx, `
+			UPDATE seats
+			SET service_state = $1, service_reason = $2, service_from = $3, service_until = $4
+			WHERE id = $5
+			RETURNING `+seatColumns,
+			data.State, data.Reason, data.From, data.Until, id), &s)
+		if IsError(w, err) {
+			return
+		}
+
+		if IsError(w, tx.Commit(ctx)) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+	}
+}
+
+// @Summary Изменить доступность места для маломобильных зрителей (admin)
+// @Description Отмечает место как место для кресла-коляски и связывает его с местом сопровождающего в том же зале.
+// @Description Оба места придерживаются от общей продажи до accessible_release_minutes минут до начала сеанса:
+// @Description раньше их могут забронировать только пользователи с needs_accessibility.
+// @Tags Места
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID места"
+// @Param accessibility body SeatAccessibility true "Признаки доступности"
+// @Success 200 {object} Seat "Место с новыми признаками"
+// @Failure 400 {string} string "В запросе предоставлены неверные данные"
+// @Failure 403 {string} string "Доступ запрещён"
+// @Failure 404 {string} string "Место не найдено"
+// @Failure 409 {string} string "Место сопровождающего уже занято или находится в другом зале"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /seats/{id}/accessibility [put]
+func UpdateSeatAccessibility(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var data SeatAccessibility
+		if !DecodeJSONBody(w, r, &data) {
+			return
+		}
+		if !validateSeatAccessibility(w, id, data) {
+			return
 		}
 
-		if len(seats) == 0 {
-			http.Error(w, "Места не найдены", http.StatusNotFound)
+		if !requireCinemaAccess(w, r, db, cinemaOfSeat, id) {
+			return
+		}
+
+		var s Seat
+		err := scanSeat(db.QueryRow(r.Context(), `
+			UPDATE seats SET wheelchair_space = $1, companion_seat_id = $2
+			WHERE id = $3
+			RETURNING `+seatColumns,
+			data.WheelchairSpace, data.CompanionSeatID, id), &s)
+		if IsError(w, err) {
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(seats)
+		json.NewEncoder(w).Encode(s)
+	}
+}
+
+// @Summary Отчёт о местах вне обслуживания в зале (admin)
+// @Description Возвращает страницу мест зала, выведенных из обслуживания сейчас или в будущем, с причиной, периодом
+// @Description и числом предстоящих сеансов, на которые место не продаётся.
+// @Tags Места
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID зала"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: position (по умолчанию), id, row_number, seat_number; с префиксом - по убыванию"
+// @Success 200 {object} Page[BlockedSeat] "Страница мест вне обслуживания"
+// @Failure 400 {string} string "Неверный формат ID зала или неверные параметры списка"
+// @Failure 403 {string} string "Доступ запрещён"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /halls/{id}/blocked-seats [get]
+func GetBlockedSeatsByHallID(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		q, ok := parseListQuery(w, r, hallSeatListSpec)
+		if !ok {
+			return
+		}
+		q.where("hall_id = $%[1]d AND service_state <> 'active' AND (service_until IS NULL OR service_until > NOW())", hallID)
+
+		page, err := fetchPage(r.Context(), db, q, seatColumns+`,
+				(SELECT COUNT(*) FROM movie_shows ms
+				WHERE ms.hall_id = seats.hall_id
+				AND ms.status NOT IN ('cancelled', 'finished')
+				AND seat_out_of_service(service_state, service_from, service_until, ms.occupied))`, "seats",
+			func(row pgx.Row) (BlockedSeat, error) {
+				var s BlockedSeat
+				var service SeatService
+				var reason *string
+				var from *time.Time
+				if err := row.Scan(&s.ID, &s.HallID, &s.SeatTypeID, &s.RowNumber, &s.SeatNumber,
+					&service.State, &reason, &from, &service.Until, &s.WheelchairSpace, &s.CompanionSeatID, &s.ZoneID, &s.AffectedShows); err != nil {
+					return s, err
+				}
+				service.Reason, service.From = *reason, *from
+				s.Service = &service
+				return s, nil
+			})
+		if IsError(w, err) {
+			return
+		}
+
+		writePage(w, page)
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -21,7 +22,7 @@ func TestGetSeats(t *testing.T) {
 	}{
 		{"Empty as Guest", false, "", http.StatusForbidden},
 		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusForbidden},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusForbidden},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusForbidden},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -42,10 +43,14 @@ func TestGetSeats(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var seats []Seat
-				parseResponseBody(t, resp, &seats)
+				var page Page[Seat]
+				parseResponseBody(t, resp, &page)
+				seats := page.Items
 
-				if len(seats) == 0 {
+				if !tt.seedData && (seats == nil || len(seats) != 0) {
+					t.Errorf("Expected empty seats list, got %v", seats)
+				}
+				if tt.seedData && len(seats) == 0 {
 					t.Error("Expected non-empty seats list")
 				}
 			}
@@ -755,10 +760,10 @@ func TestGetSeatsByHallID(t *testing.T) {
 			0,
 		},
 		{
-			"Зал не найден - ошибка",
+			"Зал не найден - пустой список",
 			uuid.New().String(),
 			setupWithHallsAndSeats,
-			http.StatusNotFound,
+			http.StatusOK,
 			0,
 		},
 		{
@@ -788,10 +793,11 @@ func TestGetSeatsByHallID(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var seats []Seat
-				if err := json.NewDecoder(resp.Body).Decode(&seats); err != nil {
+				var page Page[Seat]
+				if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 					t.Fatalf("Could not decode response: %v", err)
 				}
+				seats := page.Items
 
 				if len(seats) != tt.expectedCount {
 					t.Errorf("Expected %d seats, got %d: %v", tt.expectedCount, len(seats), seats)
@@ -800,3 +806,220 @@ func TestGetSeatsByHallID(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateSeatService(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name           string
+		role           string
+		seatID         string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), SeatsData[3].ID,
+			SeatServiceData{State: SeatMaintenance, Reason: ptr("Сломано кресло")}, http.StatusForbidden},
+		{"Maintenance", os.Getenv("CLAIM_ROLE_ADMIN"), SeatsData[3].ID,
+			SeatServiceData{State: SeatMaintenance, Reason: ptr("Сломано кресло"), Until: ptr(now.Add(72 * time.Hour))}, http.StatusOK},
+		{"Sold seat", os.Getenv("CLAIM_ROLE_ADMIN"), SeatsData[0].ID,
+			SeatServiceData{State: SeatBlocked, Reason: ptr("Протечка")}, http.StatusConflict},
+		{"Sold seat after the show", os.Getenv("CLAIM_ROLE_ADMIN"), SeatsData[0].ID,
+			SeatServiceData{State: SeatBlocked, Reason: ptr("Протечка"), From: ptr(now.Add(96 * time.Hour))}, http.StatusOK},
+		{"Back to service", os.Getenv("CLAIM_ROLE_ADMIN"), SeatsData[3].ID,
+			SeatServiceData{State: SeatActive, Reason: ptr("игнорируется")}, http.StatusOK},
+		{"Missing reason", os.Getenv("CLAIM_ROLE_ADMIN"), SeatsData[3].ID,
+			SeatServiceData{State: SeatBlocked, Reason: ptr("  ")}, http.StatusBadRequest},
+		{"Invalid period", os.Getenv("CLAIM_ROLE_ADMIN"), SeatsData[3].ID,
+			SeatServiceData{State: SeatBlocked, Reason: ptr("Ремонт"), From: ptr(now), Until: ptr(now)}, http.StatusBadRequest},
+		{"Invalid state", os.Getenv("CLAIM_ROLE_ADMIN"), SeatsData[3].ID,
+			SeatServiceData{State: "broken", Reason: ptr("Ремонт")}, http.StatusBadRequest},
+		{"Non-existent seat", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(),
+			SeatServiceData{State: SeatBlocked, Reason: ptr("Ремонт")}, http.StatusNotFound},
+		{"Invalid seat ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid",
+			SeatServiceData{State: SeatBlocked, Reason: ptr("Ремонт")}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "PUT", ts.URL+"/seats/"+tt.seatID+"/service", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			switch tt.expectedStatus {
+			case http.StatusOK:
+				var s Seat
+				parseResponseBody(t, resp, &s)
+				data := tt.body.(SeatServiceData)
+				if data.State == SeatActive {
+					if s.Service != nil {
+						t.Errorf("Expected active seat without service details, got %+v", s.Service)
+					}
+					return
+				}
+				if s.Service == nil || s.Service.State != data.State || s.Service.Reason != *data.Reason {
+					t.Errorf("Expected service state %s, got %+v", data.State, s.Service)
+				}
+			case http.StatusConflict:
+				var conflict SeatServiceConflict
+				parseResponseBody(t, resp, &conflict)
+				if len(conflict.Tickets) != 1 || conflict.Tickets[0].TicketID != TicketsData[0].ID {
+					t.Errorf("Expected ticket %s in conflict, got %+v", TicketsData[0].ID, conflict.Tickets)
+				}
+			}
+		})
+	}
+}
+
+func TestSeatOutOfServiceAvailability(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	if _, err := TestAdminDB.Exec(context.Background(),
+		"INSERT INTO movie_show_prices (movie_show_id, base_price) VALUES ($1, 300)", MovieShowsData[0].ID); err != nil {
+		t.Fatalf("Failed to set show price: %v", err)
+	}
+	available := func() int {
+		var count int
+		if err := TestAdminDB.QueryRow(context.Background(), `
+			SELECT COUNT(*) FROM movie_show_tickets
+			WHERE movie_show_id = $1 AND seat_id = $2 AND ticket_status = 'Available'`,
+			MovieShowsData[0].ID, SeatsData[3].ID).Scan(&count); err != nil {
+			t.Fatalf("Failed to count tickets: %v", err)
+		}
+		return count
+	}
+
+	if got := available(); got != 1 {
+		t.Fatalf("Expected seat to be available before blocking, got %d", got)
+	}
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	block := SeatServiceData{State: SeatMaintenance, Reason: ptr("Сломано кресло"), Until: ptr(time.Now().Add(48 * time.Hour))}
+	req := createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[3].ID+"/service", admin, block)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	if got := available(); got != 0 {
+		t.Errorf("Expected blocked seat to be excluded from availability, got %d", got)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[0].ID+"/blocked-seats", admin, nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var blockedPage Page[BlockedSeat]
+	parseResponseBody(t, resp, &blockedPage)
+	blocked := blockedPage.Items
+	resp.Body.Close()
+	if len(blocked) != 1 || blocked[0].ID != SeatsData[3].ID || blocked[0].AffectedShows != 1 {
+		t.Errorf("Expected seat %s affecting 1 show, got %+v", SeatsData[3].ID, blocked)
+	}
+
+	// Блокировка после сеанса не влияет на его продажи
+	block.From, block.Until = ptr(time.Now().Add(72*time.Hour)), nil
+	req = createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[3].ID+"/service", admin, block)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	if got := available(); got != 1 {
+		t.Errorf("Expected seat to be available for the earlier show, got %d", got)
+	}
+
+	req = createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[3].ID+"/service", admin, SeatServiceData{State: SeatActive})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[0].ID+"/blocked-seats", admin, nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	blockedPage = Page[BlockedSeat]{}
+	parseResponseBody(t, resp, &blockedPage)
+	resp.Body.Close()
+	if len(blockedPage.Items) != 0 {
+		t.Errorf("Expected no blocked seats, got %+v", blockedPage.Items)
+	}
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[0].ID+"/blocked-seats", generateToken(t, os.Getenv("CLAIM_ROLE_USER")), nil)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+}
+
+func TestUpdateSeatAccessibility(t *testing.T) {
+	admin := os.Getenv("CLAIM_ROLE_ADMIN")
+
+	tests := []struct {
+		name           string
+		role           string
+		seatID         string
+		body           SeatAccessibility
+		expectedStatus int
+	}{
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), SeatsData[3].ID, SeatAccessibility{WheelchairSpace: true}, http.StatusForbidden},
+		{"Wheelchair space", admin, SeatsData[3].ID, SeatAccessibility{WheelchairSpace: true}, http.StatusOK},
+		{"With companion", admin, SeatsData[3].ID, SeatAccessibility{WheelchairSpace: true, CompanionSeatID: &SeatsData[1].ID}, http.StatusOK},
+		{"Companion without wheelchair", admin, SeatsData[3].ID, SeatAccessibility{CompanionSeatID: &SeatsData[1].ID}, http.StatusBadRequest},
+		{"Own companion", admin, SeatsData[3].ID, SeatAccessibility{WheelchairSpace: true, CompanionSeatID: &SeatsData[3].ID}, http.StatusBadRequest},
+		{"Invalid companion ID", admin, SeatsData[3].ID, SeatAccessibility{WheelchairSpace: true, CompanionSeatID: ptr("invalid-uuid")}, http.StatusBadRequest},
+		{"Unknown companion", admin, SeatsData[3].ID, SeatAccessibility{WheelchairSpace: true, CompanionSeatID: ptr(uuid.New().String())}, http.StatusBadRequest},
+		{"Companion in another hall", admin, SeatsData[3].ID, SeatAccessibility{WheelchairSpace: true, CompanionSeatID: &SeatsData[2].ID}, http.StatusConflict},
+		{"Non-existent seat", admin, uuid.New().String(), SeatAccessibility{WheelchairSpace: true}, http.StatusNotFound},
+		{"Invalid seat ID", admin, "invalid-uuid", SeatAccessibility{WheelchairSpace: true}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "PUT", ts.URL+"/seats/"+tt.seatID+"/accessibility", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var s Seat
+			parseResponseBody(t, resp, &s)
+			if s.WheelchairSpace != tt.body.WheelchairSpace ||
+				(tt.body.CompanionSeatID == nil) != (s.CompanionSeatID == nil) ||
+				(s.CompanionSeatID != nil && *s.CompanionSeatID != *tt.body.CompanionSeatID) {
+				t.Errorf("Expected %+v, got %+v", tt.body, s)
+			}
+		})
+	}
+}
+
+func TestAccessibleSeatsFilter(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	body := SeatAccessibility{WheelchairSpace: true, CompanionSeatID: &SeatsData[1].ID}
+	req := createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[3].ID+"/accessibility", admin, body)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	// Место сопровождающего не может стать местом для коляски
+	req = createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[1].ID+"/accessibility", admin, SeatAccessibility{WheelchairSpace: true})
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	// Одно место сопровождающего на одно место для коляски
+	req = createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[0].ID+"/accessibility", admin, body)
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[0].ID+"/seats?accessible=true", "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var page Page[Seat]
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	seats := page.Items
+	if len(seats) != 2 || seats[0].ID != SeatsData[1].ID || seats[1].ID != SeatsData[3].ID {
+		t.Errorf("Expected companion and wheelchair seats, got %+v", seats)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[1].ID+"/seats?accessible=true", "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	page = Page[Seat]{}
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+	if len(page.Items) != 0 {
+		t.Errorf("Expected no accessible seats in the small hall, got %+v", page.Items)
+	}
+	req = createRequest(t, "GET", ts.URL+"/halls/"+HallsData[0].ID+"/seats?accessible=yes", "", nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+}
@@ -8,6 +8,7 @@ import (
 	"regexp"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -71,33 +72,31 @@ func validateSeatTypeDescription(desc string) error {
 // @Description Возвращает список всех типов мест, содержащихся в базе данных.
 // @Tags Типы мест
 // @Produce json
-// @Success 200 {array} SeatType "Список типов мест"
-// @Failure 404 {object} ErrorResponse "Типы мест не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[SeatType] "Страница типов мест"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /seat-types [get]
 func GetSeatTypes(db *pgxpool.Pool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		rows, err := db.Query(context.Background(), "SELECT id, name, description FROM seat_types")
-		if HandleDatabaseError(w, err, "типами мест") {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var types []SeatType
-		for rows.Next() {
-			var s SeatType
-			if err := rows.Scan(&s.ID, &s.Name, &s.Description); HandleDatabaseError(w, err, "типом места") {
-				return
-			}
-			types = append(types, s)
-		}
 
-		if len(types) == 0 {
-			http.Error(w, "Типы мест не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q, "id, name, description", "seat_types",
+			func(row pgx.Row) (SeatType, error) {
+				var s SeatType
+				err := row.Scan(&s.ID, &s.Name, &s.Description)
+				return s, err
+			})
+		if HandleDatabaseError(w, err, "типами мест") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(types)
+		writePage(w, page)
 	}
 }
 
@@ -245,13 +244,15 @@ func DeleteSeatType(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Поиск типов места по названию (guest | user | admin)
-// @Description Возвращает типы места, название которых содержит указанную строку.
+// @Description Возвращает страницу типов места, название которых содержит указанную строку.
 // @Tags Типы мест
 // @Produce json
 // @Param query query string true "Поисковый запрос"
-// @Success 200 {array} SeatType "Список типов мест"
-// @Failure 400 {object} ErrorResponse "Строка поиска пуста"
-// @Failure 404 {object} ErrorResponse "Типы мест не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[SeatType] "Страница типов мест"
+// @Failure 400 {object} ErrorResponse "Строка поиска пуста или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /seat-types/search [get]
 func SearchSeatTypes(db *pgxpool.Pool) http.HandlerFunc {
@@ -263,27 +264,22 @@ func SearchSeatTypes(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(),
-			"SELECT id, name, description FROM seat_types WHERE name ILIKE $1", "%"+query+"%")
-		if IsError(w, err) {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var types []SeatType
-		for rows.Next() {
-			var e SeatType
-			if err := rows.Scan(&e.ID, &e.Name, &e.Description); IsError(w, err) {
-				return
-			}
-			types = append(types, e)
-		}
-
-		if len(types) == 0 {
-			http.Error(w, "Типы мест не найдены", http.StatusNotFound)
+		q.where("name ILIKE $%[1]d", "%"+query+"%")
+
+		page, err := fetchPage(context.Background(), db, q, "id, name, description", "seat_types",
+			func(row pgx.Row) (SeatType, error) {
+				var s SeatType
+				err := row.Scan(&s.ID, &s.Name, &s.Description)
+				return s, err
+			})
+		if HandleDatabaseError(w, err, "типами мест") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(types)
+		writePage(w, page)
 	}
 }
@@ -19,9 +19,9 @@ func TestGetSeatTypes(t *testing.T) {
 		role           string
 		expectedStatus int
 	}{
-		{"Empty as Guest", false, "", http.StatusNotFound},
-		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Guest", false, "", http.StatusOK},
+		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusOK},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusOK},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -52,10 +52,14 @@ func TestGetSeatTypes(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var seatTypes []SeatType
-				parseResponseBody(t, resp, &seatTypes)
+				var page Page[SeatType]
+				parseResponseBody(t, resp, &page)
+				seatTypes := page.Items
 
-				if len(seatTypes) == 0 {
+				if !tt.seedData && (seatTypes == nil || len(seatTypes) != 0) {
+					t.Errorf("Expected empty seat types list, got %v", seatTypes)
+				}
+				if tt.seedData && len(seatTypes) == 0 {
 					t.Error("Expected non-empty seat types list")
 				}
 			}
@@ -244,9 +248,9 @@ func TestSearchSeatTypes(t *testing.T) {
 		{"Short query as Guest", "С", "", http.StatusOK, 7},
 		{"Short query as User", "С", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 7},
 		{"Short query as Admin", "С", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 7},
-		{"No matches as Guest", "Кресло для отдыха", "", http.StatusNotFound, 0},
-		{"No matches as User", "Кресло для отдыха", os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound, 0},
-		{"No matches as Admin", "Кресло для отдыха", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound, 0},
+		{"No matches as Guest", "Кресло для отдыха", "", http.StatusOK, 0},
+		{"No matches as User", "Кресло для отдыха", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 0},
+		{"No matches as Admin", "Кресло для отдыха", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 0},
 		{"Exact match as Guest", "VIP", "", http.StatusOK, 1},
 		{"Exact match as User", "VIP", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 1},
 		{"Exact match as Admin", "VIP", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 1},
@@ -262,9 +266,9 @@ func TestSearchSeatTypes(t *testing.T) {
 		{"Partial with spaces as Guest", "  кресло    ", "", http.StatusOK, 3},
 		{"Partial with spaces as User", "  кресло    ", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 3},
 		{"Partial with spaces as Admin", "  кресло    ", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 3},
-		{"Special chars as Guest", "VIP/", "", http.StatusNotFound, 0},
-		{"Special chars as User", "VIP/", os.Getenv("CLAIM_ROLE_USER"), http.StatusNotFound, 0},
-		{"Special chars as Admin", "VIP/", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound, 0},
+		{"Special chars as Guest", "VIP/", "", http.StatusOK, 0},
+		{"Special chars as User", "VIP/", os.Getenv("CLAIM_ROLE_USER"), http.StatusOK, 0},
+		{"Special chars as Admin", "VIP/", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK, 0},
 		{"DBError as Guest", "VIP", "", http.StatusInternalServerError, 0},
 		{"DBError as User", "VIP", os.Getenv("CLAIM_ROLE_USER"), http.StatusInternalServerError, 0},
 		{"DBError as Admin", "VIP", os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusInternalServerError, 0},
@@ -288,8 +292,9 @@ func TestSearchSeatTypes(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var seatTypes []SeatType
-				parseResponseBody(t, resp, &seatTypes)
+				var page Page[SeatType]
+				parseResponseBody(t, resp, &page)
+				seatTypes := page.Items
 
 				if len(seatTypes) != tt.expectedCount {
 					t.Errorf("Expected %d seat types, got %d", tt.expectedCount, len(seatTypes))
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	seatMapSeatSize  = 24
+	seatMapPitch     = 30
+	seatMapMargin    = 20
+	seatMapLabel     = 30
+	seatMapScreen    = 60
+	seatMapLegendRow = 26
+)
+
+// Цвета типов мест назначаются по порядку названий, поэтому совпадают во всех залах
+var seatTypePalette = []string{"#5c6bc0", "#26a69a", "#ab47bc", "#ef6c00", "#8d6e63", "#29b6f6", "#d4e157", "#ec407a"}
+
+var ticketStatusColors = map[TicketStatusEnumType]string{
+	Available: "#43a047",
+	Reserved:  "#fdd835",
+	Purchased: "#e53935",
+	Refunded:  "#757575",
+}
+
+var ticketStatusLabels = map[TicketStatusEnumType]string{
+	Available: "Свободно",
+	Reserved:  "Забронировано",
+	Purchased: "Куплено",
+	Refunded:  "Возвращено",
+}
+
+// Цвет мест сеанса без базовой цены: билеты на них не продаются
+const seatMapNotForSale = "#cfd8dc"
+
+// Обводка мест вне зон
+const seatMapStroke = "#455a64"
+
+// Обводки зон назначаются по порядку названий зон внутри зала
+var seatZonePalette = []string{"#d81b60", "#1e88e5", "#fb8c00", "#00897b", "#6d4c41", "#8e24aa"}
+
+// seatMapSeat — одно место на схеме
+type seatMapSeat struct {
+	Row    int
+	Number int
+	Fill   string
+	Title  string
+	// Место выведено из обслуживания и перечёркнуто
+	Crossed bool
+	// Цвет обводки зоны, пусто — место вне зон
+	Stroke string
+}
+
+type seatMapLegendItem struct {
+	Fill    string
+	Label   string
+	Crossed bool
+	Stroke  string
+}
+
+// seatMapZones собирает зоны мест схемы для легенды
+type seatMapZones map[int]seatMapLegendItem
+
+// add обводит место цветом зоны с порядковым номером index и дописывает зону в подсказку
+func (z seatMapZones) add(s *seatMapSeat, name *string, index int, modifier float64) {
+	if name == nil {
+		return
+	}
+
+	s.Stroke = seatZonePalette[index%len(seatZonePalette)]
+	s.Title += ", зона " + *name
+	z[index] = seatMapLegendItem{
+		Fill:   "#ffffff",
+		Label:  "Зона " + *name + " ×" + strconv.FormatFloat(modifier, 'f', -1, 64),
+		Stroke: s.Stroke,
+	}
+}
+
+// legend возвращает зоны в порядке их названий
+func (z seatMapZones) legend() []seatMapLegendItem {
+	indexes := make([]int, 0, len(z))
+	for i := range z {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	items := make([]seatMapLegendItem, len(indexes))
+	for i, index := range indexes {
+		items[i] = z[index]
+	}
+	return items
+}
+
+func seatMapStrokeAttrs(stroke string) string {
+	if stroke == "" {
+		return `stroke="` + seatMapStroke + `"`
+	}
+	return `stroke="` + stroke + `" stroke-width="3"`
+}
+
+func writeSeatMapCross(b *strings.Builder, x, y, size int) {
+	fmt.Fprintf(b, `<path d="M %d %d L %d %d M %d %d L %d %d" stroke="#263238" stroke-width="2"/>`,
+		x+3, y+3, x+size-3, y+size-3, x+size-3, y+3, x+3, y+size-3)
+}
+
+// renderSeatMapSVG рисует места по их ряду и номеру. Пропущенные номера и ряды
+// остаются пустыми, поэтому проходы на схеме совпадают с проходами в зале.
+func renderSeatMapSVG(title string, seats []seatMapSeat, legend []seatMapLegendItem) string {
+	minRow, maxRow, maxNumber := 0, 0, 0
+	for i, s := range seats {
+		if i == 0 || s.Row < minRow {
+			minRow = s.Row
+		}
+		maxRow = max(maxRow, s.Row)
+		maxNumber = max(maxNumber, s.Number)
+	}
+
+	gridWidth := maxNumber * seatMapPitch
+	gridHeight := 0
+	if len(seats) > 0 {
+		gridHeight = (maxRow - minRow + 1) * seatMapPitch
+	}
+	width := 2*seatMapMargin + 2*seatMapLabel + gridWidth
+	gridTop := seatMapMargin + seatMapScreen
+	height := gridTop + gridHeight + seatMapMargin + len(legend)*seatMapLegendRow + seatMapMargin
+	gridLeft := seatMapMargin + seatMapLabel
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`,
+		width, height, width, height)
+	fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(title))
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`, width, height)
+
+	// Экран — дуга над первым рядом
+	screenTop := seatMapMargin + 10
+	fmt.Fprintf(&b, `<path d="M %d %d Q %d %d %d %d" fill="none" stroke="#90a4ae" stroke-width="4"/>`,
+		gridLeft, screenTop+10, gridLeft+gridWidth/2, screenTop-10, gridLeft+gridWidth, screenTop+10)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" text-anchor="middle" fill="#607d8b">Экран</text>`,
+		gridLeft+gridWidth/2, screenTop+28)
+
+	rows := make(map[int]bool)
+	for _, s := range seats {
+		rows[s.Row] = true
+	}
+	for row := minRow; row <= maxRow && len(seats) > 0; row++ {
+		if !rows[row] {
+			continue
+		}
+		y := gridTop + (row-minRow)*seatMapPitch + seatMapSeatSize/2 + 4
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" text-anchor="middle" fill="#37474f">%d</text>`,
+			seatMapMargin+seatMapLabel/2, y, row)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" text-anchor="middle" fill="#37474f">%d</text>`,
+			gridLeft+gridWidth+seatMapLabel/2, y, row)
+	}
+
+	for _, s := range seats {
+		x := gridLeft + (s.Number-1)*seatMapPitch
+		y := gridTop + (s.Row-minRow)*seatMapPitch
+		fmt.Fprintf(&b, `<g><title>%s</title>`, html.EscapeString(s.Title))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="%s" %s/>`,
+			x, y, seatMapSeatSize, seatMapSeatSize, s.Fill, seatMapStrokeAttrs(s.Stroke))
+		if s.Crossed {
+			writeSeatMapCross(&b, x, y, seatMapSeatSize)
+		} else {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="10" text-anchor="middle" fill="#263238">%d</text>`,
+				x+seatMapSeatSize/2, y+seatMapSeatSize/2+4, s.Number)
+		}
+		b.WriteString(`</g>`)
+	}
+
+	legendTop := gridTop + gridHeight + seatMapMargin
+	for i, item := range legend {
+		y := legendTop + i*seatMapLegendRow
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="16" height="16" rx="3" fill="%s" %s/>`,
+			seatMapMargin, y, item.Fill, seatMapStrokeAttrs(item.Stroke))
+		if item.Crossed {
+			writeSeatMapCross(&b, seatMapMargin, y, 16)
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="12" fill="#263238">%s</text>`,
+			seatMapMargin+24, y+13, html.EscapeString(item.Label))
+	}
+
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// Название, порядковый номер среди зон зала и наценка зоны места (z — hall_zones)
+const seatMapZoneColumns = `z.name,
+				(SELECT COUNT(*) FROM hall_zones x WHERE x.hall_id = z.hall_id AND x.name < z.name),
+				COALESCE(z.price_modifier, 1)`
+
+func writeSeatMapSVG(w http.ResponseWriter, svg string) {
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.Write([]byte(svg))
+}
+
+// @Summary Схема мест зала в SVG (guest | user | admin)
+// @Description Рисует места зала с подписями рядов, проходами и экраном. Цвет места соответствует его типу, расшифровка приведена в легенде.
+// @Description Места, выведенные из обслуживания в данный момент, перечёркнуты, места зон обведены цветом зоны.
+// @Tags Места
+// @Produce image/svg+xml
+// @Param id path string true "ID зала"
+// @Success 200 {string} string "Схема зала"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Зал не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /halls/{id}/seat-map.svg [get]
+func GetHallSeatMap(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var name string
+		err := db.QueryRow(r.Context(), "SELECT name FROM halls WHERE id = $1", hallID).Scan(&name)
+		if IsError(w, err) {
+			return
+		}
+
+		rows, err := db.Query(r.Context(), `
+			SELECT s.row_number, s.seat_number, COALESCE(st.name, ''),
+				(SELECT COUNT(*) FROM seat_types x WHERE x.name < st.name),
+				CASE WHEN seat_out_of_service(s.service_state, s.service_from, s.service_until, tstzrange(NOW(), NOW(), '[]'))
+					THEN s.service_reason END,
+				`+seatMapZoneColumns+`
+			FROM seats s
+			LEFT JOIN seat_types st ON st.id = s.seat_type_id
+			LEFT JOIN hall_zones z ON z.id = s.zone_id
+			WHERE s.hall_id = $1
+			ORDER BY s.row_number, s.seat_number`, hallID)
+		if IsError(w, err) {
+			return
+		}
+		defer rows.Close()
+
+		var seats []seatMapSeat
+		var legend []seatMapLegendItem
+		seen := make(map[string]bool)
+		zones := make(seatMapZones)
+		outOfService := false
+		for rows.Next() {
+			var s seatMapSeat
+			var typeName string
+			var typeIndex int
+			var serviceReason, zoneName *string
+			var zoneIndex int
+			var zoneModifier float64
+			if err := rows.Scan(&s.Row, &s.Number, &typeName, &typeIndex, &serviceReason,
+				&zoneName, &zoneIndex, &zoneModifier); IsError(w, err) {
+				return
+			}
+
+			s.Fill = seatTypePalette[typeIndex%len(seatTypePalette)]
+			s.Title = fmt.Sprintf("Ряд %d, место %d — %s", s.Row, s.Number, typeName)
+			if serviceReason != nil {
+				s.Crossed = true
+				s.Title += ", не обслуживается: " + *serviceReason
+				outOfService = true
+			}
+			zones.add(&s, zoneName, zoneIndex, zoneModifier)
+			seats = append(seats, s)
+
+			if !seen[typeName] {
+				seen[typeName] = true
+				legend = append(legend, seatMapLegendItem{Fill: s.Fill, Label: typeName})
+			}
+		}
+		if IsError(w, rows.Err()) {
+			return
+		}
+
+		if outOfService {
+			legend = append(legend, seatMapLegendItem{Fill: "#ffffff", Label: "Не обслуживается", Crossed: true})
+		}
+		legend = append(legend, zones.legend()...)
+		writeSeatMapSVG(w, renderSeatMapSVG("Зал "+name, seats, legend))
+	}
+}
+
+// @Summary Схема мест киносеанса в SVG (guest | user | admin)
+// @Description Рисует места зала киносеанса, окрашенные по статусу билета: свободные, забронированные, купленные и возвращённые.
+// @Description Места сеанса без базовой цены не продаются и отображаются серым, места вне обслуживания перечёркнуты,
+// @Description места зон обведены цветом зоны.
+// @Tags Билеты
+// @Produce image/svg+xml
+// @Param movie_show_id path string true "ID киносеанса"
+// @Success 200 {string} string "Схема мест сеанса"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Киносеанс не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /tickets/seat-map/{movie_show_id} [get]
+func GetMovieShowSeatMap(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		showID, ok := ParseUUIDFromPath(w, r.PathValue("movie_show_id"))
+		if !ok {
+			return
+		}
+
+		var hallID, title, hallName string
+		err := db.QueryRow(r.Context(), `
+			SELECT ms.hall_id, m.title, h.name
+			FROM movie_shows ms
+			JOIN movies m ON m.id = ms.movie_id
+			JOIN halls h ON h.id = ms.hall_id
+			WHERE ms.id = $1`, showID).Scan(&hallID, &title, &hallName)
+		if IsError(w, err) {
+			return
+		}
+
+		rows, err := db.Query(r.Context(), `
+			SELECT s.row_number, s.seat_number, t.ticket_status,
+				CASE WHEN seat_out_of_service(s.service_state, s.service_from, s.service_until, ms.occupied)
+					THEN s.service_reason END,
+				`+seatMapZoneColumns+`
+			FROM seats s
+			JOIN movie_shows ms ON ms.id = $2
+			LEFT JOIN movie_show_tickets t ON t.seat_id = s.id AND t.movie_show_id = $2
+			LEFT JOIN hall_zones z ON z.id = s.zone_id
+			WHERE s.hall_id = $1
+			ORDER BY s.row_number, s.seat_number`, hallID, showID)
+		if IsError(w, err) {
+			return
+		}
+		defer rows.Close()
+
+		var seats []seatMapSeat
+		zones := make(seatMapZones)
+		for rows.Next() {
+			var s seatMapSeat
+			var status *TicketStatusEnumType
+			var serviceReason, zoneName *string
+			var zoneIndex int
+			var zoneModifier float64
+			if err := rows.Scan(&s.Row, &s.Number, &status, &serviceReason,
+				&zoneName, &zoneIndex, &zoneModifier); IsError(w, err) {
+				return
+			}
+
+			s.Fill, s.Title = seatMapNotForSale, fmt.Sprintf("Ряд %d, место %d — не продаётся", s.Row, s.Number)
+			switch {
+			case status != nil:
+				s.Fill = ticketStatusColors[*status]
+				s.Title = fmt.Sprintf("Ряд %d, место %d — %s", s.Row, s.Number, ticketStatusLabels[*status])
+			case serviceReason != nil:
+				s.Crossed = true
+				s.Title = fmt.Sprintf("Ряд %d, место %d — не обслуживается: %s", s.Row, s.Number, *serviceReason)
+			}
+			zones.add(&s, zoneName, zoneIndex, zoneModifier)
+			seats = append(seats, s)
+		}
+		if IsError(w, rows.Err()) {
+			return
+		}
+
+		legend := []seatMapLegendItem{
+			{Fill: ticketStatusColors[Available], Label: ticketStatusLabels[Available]},
+			{Fill: ticketStatusColors[Reserved], Label: ticketStatusLabels[Reserved]},
+			{Fill: ticketStatusColors[Purchased], Label: ticketStatusLabels[Purchased]},
+			{Fill: ticketStatusColors[Refunded], Label: ticketStatusLabels[Refunded]},
+			{Fill: seatMapNotForSale, Label: "Не продаётся"},
+			{Fill: seatMapNotForSale, Label: "Не обслуживается", Crossed: true},
+		}
+		legend = append(legend, zones.legend()...)
+		writeSeatMapSVG(w, renderSeatMapSVG(title+", зал "+hallName, seats, legend))
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRenderSeatMapSVG(t *testing.T) {
+	seats := []seatMapSeat{
+		{Row: 1, Number: 1, Fill: "#111111", Title: "Ряд 1, место 1"},
+		{Row: 1, Number: 3, Fill: "#111111", Title: "Ряд 1, место 3"},
+		{Row: 3, Number: 1, Fill: "#222222", Title: `Ряд 3, место 1 — <"VIP">`},
+	}
+	legend := []seatMapLegendItem{{Fill: "#111111", Label: "Стандарт"}, {Fill: "#222222", Label: "VIP & Co"}}
+
+	svg := renderSeatMapSVG("Зал <1>", seats, legend)
+
+	// Экранирование не должно ломать разметку
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Invalid SVG: %v\n%s", err, svg)
+		}
+	}
+
+	for _, want := range []string{
+		"Экран",
+		`fill="#222222"`,
+		"VIP &amp; Co",
+		"Зал &lt;1&gt;",
+		// Место 3 стоит через проход от места 1
+		`<rect x="110" y="80"`,
+		// Пропущенный ряд 2 оставляет пустую полосу
+		`<rect x="50" y="140"`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("Expected SVG to contain %q:\n%s", want, svg)
+		}
+	}
+
+	if got := strings.Count(svg, `rx="4"`); got != len(seats) {
+		t.Errorf("Expected %d seats, got %d", len(seats), got)
+	}
+	// Подписи рядов с обеих сторон, кроме пропущенного
+	if strings.Contains(svg, ">2</text>") {
+		t.Error("Row 2 has no seats and must not be labelled")
+	}
+}
+
+func TestSeatMapZones(t *testing.T) {
+	vip, front := "VIP", "Первые ряды"
+	zones := make(seatMapZones)
+	seats := []seatMapSeat{
+		{Row: 1, Number: 1, Fill: "#111111", Title: "Ряд 1, место 1"},
+		{Row: 1, Number: 2, Fill: "#111111", Title: "Ряд 1, место 2"},
+		{Row: 2, Number: 1, Fill: "#111111", Title: "Ряд 2, место 1"},
+	}
+	zones.add(&seats[0], &front, 1, 0.9)
+	zones.add(&seats[1], nil, 0, 1)
+	zones.add(&seats[2], &vip, 0, 1.5)
+
+	legend := zones.legend()
+	if len(legend) != 2 || legend[0].Label != "Зона VIP ×1.5" || legend[1].Label != "Зона Первые ряды ×0.9" {
+		t.Fatalf("Expected zones in name order, got %+v", legend)
+	}
+	if seats[0].Stroke != seatZonePalette[1] || seats[1].Stroke != "" || seats[2].Stroke != seatZonePalette[0] {
+		t.Errorf("Unexpected zone strokes: %+v", seats)
+	}
+	if seats[2].Title != "Ряд 2, место 1, зона VIP" {
+		t.Errorf("Expected zone in title, got %q", seats[2].Title)
+	}
+
+	svg := renderSeatMapSVG("Зал", seats, legend)
+	if got := strings.Count(svg, `stroke="`+seatZonePalette[0]+`" stroke-width="3"`); got != 2 {
+		t.Errorf("Expected VIP seat and legend to be outlined, got %d:\n%s", got, svg)
+	}
+	if !strings.Contains(svg, `stroke="`+seatMapStroke+`"/>`) {
+		t.Errorf("Expected seat outside zones to keep default stroke:\n%s", svg)
+	}
+}
+
+func TestRenderEmptySeatMapSVG(t *testing.T) {
+	svg := renderSeatMapSVG("Пустой зал", nil, nil)
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>") {
+		t.Errorf("Expected a complete SVG document, got %s", svg)
+	}
+}
+
+func TestGetSeatMaps(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		expectedStatus int
+		contains       []string
+	}{
+		{"Hall", "/halls/" + HallsData[0].ID + "/seat-map.svg", http.StatusOK,
+			[]string{"Зал " + HallsData[0].Name, SeatTypesData[0].Name, SeatTypesData[2].Name, "Экран"}},
+		{"Hall without seats", "/halls/" + HallsData[4].ID + "/seat-map.svg", http.StatusOK, []string{"Экран"}},
+		{"Unknown hall", "/halls/" + uuid.New().String() + "/seat-map.svg", http.StatusNotFound, nil},
+		{"Invalid hall ID", "/halls/invalid-uuid/seat-map.svg", http.StatusBadRequest, nil},
+		{"Show", "/tickets/seat-map/" + MovieShowsData[0].ID, http.StatusOK,
+			[]string{ticketStatusColors[Purchased], "Ряд 1, место 1 — " + ticketStatusLabels[Purchased]}},
+		{"Unknown show", "/tickets/seat-map/" + uuid.New().String(), http.StatusNotFound, nil},
+		{"Invalid show ID", "/tickets/seat-map/invalid-uuid", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createRequest(t, "GET", ts.URL+tt.url, "", nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "image/svg+xml") {
+				t.Errorf("Expected SVG content type, got %s", ct)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("Expected SVG to contain %q", want)
+				}
+			}
+		})
+	}
+}
@@ -51,15 +51,71 @@ func SeedGenres(db *pgxpool.Pool) error {
 	return nil
 }
 
+func SeedLanguages(db *pgxpool.Pool) error {
+	for _, l := range LanguagesData {
+		_, err := db.Exec(context.Background(), `INSERT INTO languages (id, name)
+            VALUES ($1, $2)
+            ON CONFLICT (name) DO UPDATE SET id = EXCLUDED.id`,
+			l.ID, l.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func SeedShowFormats(db *pgxpool.Pool) error {
+	for _, f := range ShowFormatsData {
+		_, err := db.Exec(context.Background(), `INSERT INTO show_formats (id, name, description)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (name) DO UPDATE SET
+                description = EXCLUDED.description,
+                id = EXCLUDED.id`,
+			f.ID, f.Name, f.Description)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func SeedCinemas(db *pgxpool.Pool) error {
+	for _, c := range CinemasData {
+		_, err := db.Exec(context.Background(), `INSERT INTO cinemas (id, name, address, time_zone, phone, email)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (id) DO UPDATE SET
+                name = EXCLUDED.name,
+                address = EXCLUDED.address,
+                time_zone = EXCLUDED.time_zone,
+                phone = EXCLUDED.phone,
+                email = EXCLUDED.email`,
+			c.ID, c.Name, c.Address, c.TimeZone, c.Phone, c.Email)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func SeedHalls(db *pgxpool.Pool) error {
 	for _, h := range HallsData {
-		_, err := db.Exec(context.Background(), `INSERT INTO halls (id, name, screen_type_id, description)
-            VALUES ($1, $2, $3, $4)
+		_, err := db.Exec(context.Background(), `INSERT INTO halls (id, cinema_id, name, screen_type_id, description, cleaning_minutes, opens_at, closes_at, accessible_release_minutes)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
             ON CONFLICT (id) DO UPDATE SET
+                cinema_id = EXCLUDED.cinema_id,
                 screen_type_id = EXCLUDED.screen_type_id,
                 description = EXCLUDED.description,
-                name = EXCLUDED.name`,
-			h.ID, h.Name, h.ScreenTypeID, h.Description)
+                name = EXCLUDED.name,
+                cleaning_minutes = EXCLUDED.cleaning_minutes,
+                opens_at = EXCLUDED.opens_at,
+                closes_at = EXCLUDED.closes_at,
+                accessible_release_minutes = EXCLUDED.accessible_release_minutes`,
+			h.ID, h.CinemaID, h.Name, h.ScreenTypeID, h.Description, h.CleaningMinutes, h.OpensAt, h.ClosesAt, h.AccessibleReleaseMinutes)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec(context.Background(), "DELETE FROM hall_maintenance_windows WHERE hall_id = $1", h.ID)
 		if err != nil {
 			return err
 		}
@@ -73,6 +129,7 @@ func SeedScreenTypes(db *pgxpool.Pool) error {
             VALUES ($1, $2, $3)
             ON CONFLICT (name) DO UPDATE SET
                 description = EXCLUDED.description,
+                default_cleaning_minutes = DEFAULT,
                 id = EXCLUDED.id`,
 			e.ID, e.Name, e.Description)
 		if err != nil {
@@ -130,6 +187,44 @@ func SeedMoviesGenres(db *pgxpool.Pool) error {
 	return nil
 }
 
+func SeedPeople(db *pgxpool.Pool) error {
+	for _, p := range PeopleData {
+		_, err := db.Exec(context.Background(), `INSERT INTO people
+            (id, name, birth_date, biography) VALUES ($1, $2, $3, $4)
+            ON CONFLICT (id) DO UPDATE SET
+                name = EXCLUDED.name,
+                birth_date = EXCLUDED.birth_date,
+                biography = EXCLUDED.biography`,
+			p.ID, p.Name, p.BirthDate, p.Biography)
+		if err != nil {
+			return fmt.Errorf("ошибка при вставке человека %s: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// SeedMovieCredits восстанавливает создателей тестовых фильмов, которых могли изменить тесты
+func SeedMovieCredits(db *pgxpool.Pool) error {
+	movieIDs := make([]string, len(MoviesData))
+	for i, m := range MoviesData {
+		movieIDs[i] = m.ID
+	}
+	_, err := db.Exec(context.Background(), "DELETE FROM movie_credits WHERE movie_id = ANY($1::uuid[])", movieIDs)
+	if err != nil {
+		return fmt.Errorf("ошибка при очищении создателей фильмов: %v", err)
+	}
+
+	for i, mc := range MovieCreditsData {
+		_, err := db.Exec(context.Background(), `INSERT INTO movie_credits
+            (movie_id, person_id, role, character_name, billing_order) VALUES ($1, $2, $3, $4, $5)`,
+			mc.MovieID, mc.Credit.PersonID, mc.Credit.Role, mc.Credit.Character, i)
+		if err != nil {
+			return fmt.Errorf("ошибка при связывании фильма и человека: %v", err)
+		}
+	}
+	return nil
+}
+
 func SeedUsers(db *pgxpool.Pool) error {
 	for _, u := range UsersData {
 		_, err := db.Exec(context.Background(), `INSERT INTO users 
@@ -151,17 +246,26 @@ func SeedUsers(db *pgxpool.Pool) error {
 func SeedMovieShows(db *pgxpool.Pool) error {
 	for _, ms := range MovieShowsData {
 		_, err := db.Exec(context.Background(), `INSERT INTO movie_shows 
-            (id, movie_id, hall_id, start_time, language)
-            VALUES ($1, $2, $3, $4, $5)
+            (id, movie_id, hall_id, start_time, language, subtitle_language)
+            VALUES ($1, $2, $3, $4, $5, $6)
             ON CONFLICT (id) DO UPDATE SET
                 movie_id = EXCLUDED.movie_id,
                 hall_id = EXCLUDED.hall_id,
                 start_time = EXCLUDED.start_time,
-                language = EXCLUDED.language`,
-			ms.ID, ms.MovieID, ms.HallID, ms.StartTime, ms.Language)
+                language = EXCLUDED.language,
+                subtitle_language = EXCLUDED.subtitle_language`,
+			ms.ID, ms.MovieID, ms.HallID, ms.StartTime, ms.Language, ms.SubtitleLanguage)
 		if err != nil {
 			return fmt.Errorf("ошибка при вставке киносеанса: %v", err)
 		}
+
+		_, err = db.Exec(context.Background(), `INSERT INTO movie_show_formats (movie_show_id, format_id)
+            SELECT $1, id FROM show_formats WHERE name = ANY($2)
+            ON CONFLICT DO NOTHING`,
+			ms.ID, ms.Formats)
+		if err != nil {
+			return fmt.Errorf("ошибка при связывании киносеанса и форматов: %v", err)
+		}
 	}
 	return nil
 }
@@ -175,7 +279,14 @@ func SeedSeats(db *pgxpool.Pool) error {
                 hall_id = EXCLUDED.hall_id,
                 seat_type_id = EXCLUDED.seat_type_id,
                 row_number = EXCLUDED.row_number,
-                seat_number = EXCLUDED.seat_number`,
+                seat_number = EXCLUDED.seat_number,
+                service_state = 'active',
+                service_reason = NULL,
+                service_from = NULL,
+                service_until = NULL,
+                wheelchair_space = FALSE,
+                companion_seat_id = NULL,
+                zone_id = NULL`,
 			s.ID, s.HallID, s.SeatTypeID, s.RowNumber, s.SeatNumber)
 		if err != nil {
 			return fmt.Errorf("ошибка при вставке места: %v", err)
@@ -246,6 +357,10 @@ func SeedAll(db *pgxpool.Pool) error {
 		return fmt.Errorf("ошибка при вставке типов мест: %v", err)
 	}
 
+	if err := SeedCinemas(db); err != nil {
+		return fmt.Errorf("ошибка при вставке кинотеатров: %v", err)
+	}
+
 	if err := SeedHalls(db); err != nil {
 		return fmt.Errorf("ошибка при вставке кинозалов: %v", err)
 	}
@@ -258,6 +373,14 @@ func SeedAll(db *pgxpool.Pool) error {
 		return fmt.Errorf("ошибка при связывании фильмов и жанров: %v", err)
 	}
 
+	if err := SeedPeople(db); err != nil {
+		return fmt.Errorf("ошибка при вставке создателей фильмов: %v", err)
+	}
+
+	if err := SeedMovieCredits(db); err != nil {
+		return fmt.Errorf("ошибка при связывании фильмов и создателей: %v", err)
+	}
+
 	if err := SeedUsers(db); err != nil {
 		return fmt.Errorf("ошибка при вставке пользователей: %v", err)
 	}
@@ -281,6 +404,34 @@ func SeedAll(db *pgxpool.Pool) error {
 	return nil
 }
 
+// SeedMovieCatalogue добавляет к данным SeedAll n фильмов «Каталог i» с двумя жанрами, режиссёром,
+// трейлером и отзывами всех пользователей — большой каталог для проверки загрузки списков фильмов
+func SeedMovieCatalogue(db *pgxpool.Pool, n int) error {
+	_, err := db.Exec(context.Background(), `
+		WITH new_movies AS (
+			INSERT INTO movies (title, duration, description, age_limit, release_date)
+			SELECT 'Каталог ' || i, '01:40:00', 'Фильм из большого каталога номер ' || i,
+				(ARRAY[0, 6, 12, 16, 18])[1 + i % 5], DATE '2000-01-01' + i
+			FROM generate_series(1, $1::int) AS i
+			RETURNING id
+		), new_genres AS (
+			INSERT INTO movies_genres (movie_id, genre_id)
+			SELECT m.id, g.id FROM new_movies m CROSS JOIN (SELECT id FROM genres ORDER BY name LIMIT 2) g
+		), new_credits AS (
+			INSERT INTO movie_credits (movie_id, person_id, role)
+			SELECT m.id, p.id, 'director' FROM new_movies m CROSS JOIN (SELECT id FROM people ORDER BY name LIMIT 1) p
+		), new_media AS (
+			INSERT INTO movie_media (movie_id, kind, external_url)
+			SELECT id, 'trailer', 'https://example.com/trailers/' || id FROM new_movies
+		)
+		INSERT INTO reviews (user_id, movie_id, rating)
+		SELECT u.id, m.id, 1 + (random() * 9)::int FROM new_movies m CROSS JOIN users u`, n)
+	if err != nil {
+		return fmt.Errorf("ошибка при вставке каталога фильмов: %v", err)
+	}
+	return nil
+}
+
 func ClearTable(db *pgxpool.Pool, tableName string) error {
 	query := fmt.Sprintf("TRUNCATE TABLE %s CASCADE", tableName)
 
@@ -308,6 +459,10 @@ func ClearAll(db *pgxpool.Pool) error {
 		return fmt.Errorf("ошибка при очищении кинозалов: %v", err)
 	}
 
+	if err := ClearTable(db, "cinemas, cinema_admins"); err != nil {
+		return fmt.Errorf("ошибка при очищении кинотеатров: %v", err)
+	}
+
 	if err := ClearTable(db, "movies"); err != nil {
 		return fmt.Errorf("ошибка при очищении фильмов: %v", err)
 	}
@@ -316,6 +471,14 @@ func ClearAll(db *pgxpool.Pool) error {
 		return fmt.Errorf("ошибка при очищении таблицы связи фильмов и жанров: %v", err)
 	}
 
+	if err := ClearTable(db, "people, movie_credits"); err != nil {
+		return fmt.Errorf("ошибка при очищении создателей фильмов: %v", err)
+	}
+
+	if err := ClearTable(db, "movie_media"); err != nil {
+		return fmt.Errorf("ошибка при очищении медиа фильмов: %v", err)
+	}
+
 	if err := ClearTable(db, "users"); err != nil {
 		return fmt.Errorf("ошибка при очищении пользователей: %v", err)
 	}
@@ -332,9 +495,26 @@ func ClearAll(db *pgxpool.Pool) error {
 		return fmt.Errorf("ошибка при очищении билетов: %v", err)
 	}
 
+	if err := ClearTable(db, "notifications"); err != nil {
+		return fmt.Errorf("ошибка при очищении уведомлений: %v", err)
+	}
+
 	if err := ClearTable(db, "reviews"); err != nil {
 		return fmt.Errorf("ошибка при очищении отзывов: %v", err)
 	}
 
+	// Справочники языков и форматов создаёт схема, поэтому после очистки они восстанавливаются
+	if err := ClearTable(db, "languages, show_formats"); err != nil {
+		return fmt.Errorf("ошибка при очищении языков и форматов показа: %v", err)
+	}
+
+	if err := SeedLanguages(db); err != nil {
+		return fmt.Errorf("ошибка при вставке языков: %v", err)
+	}
+
+	if err := SeedShowFormats(db); err != nil {
+		return fmt.Errorf("ошибка при вставке форматов показа: %v", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func validateAllShowFormatData(w http.ResponseWriter, f *ShowFormatData) bool {
+	f.Name = PrepareString(f.Name)
+	f.Description = PrepareStringPointer(f.Description)
+
+	if err := validateShowFormatName(f.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	if err := validateShowFormatDescription(f.Description); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func validateShowFormatName(name string) error {
+	if name == "" {
+		return errors.New("название формата не может быть пустым или состоять только из пробелов")
+	}
+	if utf8.RuneCountInString(name) > 50 {
+		return errors.New("название формата не может превышать 50 символов")
+	}
+	return nil
+}
+
+func validateShowFormatDescription(description *string) error {
+	if description == nil {
+		return nil
+	}
+	if *description == "" {
+		return errors.New("описание формата не может состоять только из пробелов")
+	}
+	if utf8.RuneCountInString(*description) > 500 {
+		return errors.New("описание формата не может превышать 500 символов")
+	}
+	return nil
+}
+
+// @Summary Получить все форматы показа (guest | user | admin)
+// @Description Возвращает справочник форматов показа (2D, 3D, дубляж, оригинальная дорожка и т.п.).
+// @Tags Форматы показа
+// @Produce json
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[ShowFormat] "Страница форматов"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /show-formats [get]
+func GetShowFormats(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, ok := parseListQuery(w, r, nameListSpec("id", "name"))
+		if !ok {
+			return
+		}
+
+		page, err := fetchPage(context.Background(), db, q, "id, name, description", "show_formats",
+			func(row pgx.Row) (ShowFormat, error) {
+				var f ShowFormat
+				err := row.Scan(&f.ID, &f.Name, &f.Description)
+				return f, err
+			})
+		if HandleDatabaseError(w, err, "форматами показа") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Получить формат показа по ID (guest | user | admin)
+// @Description Возвращает формат показа по ID.
+// @Tags Форматы показа
+// @Produce json
+// @Param id path string true "ID формата"
+// @Success 200 {object} ShowFormat "Формат показа"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 404 {object} ErrorResponse "Формат не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /show-formats/{id} [get]
+func GetShowFormatByID(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var f ShowFormat
+		f.ID = id.String()
+		err := db.QueryRow(context.Background(),
+			"SELECT name, description FROM show_formats WHERE id = $1", id).
+			Scan(&f.Name, &f.Description)
+
+		if IsError(w, err) {
+			return
+		}
+
+		json.NewEncoder(w).Encode(f)
+	}
+}
+
+// @Summary Создать формат показа (admin)
+// @Description Добавляет формат показа в справочник.
+// @Tags Форматы показа
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param show_format body ShowFormatData true "Данные формата"
+// @Success 201 {object} CreateResponse "ID созданного формата"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 409 {object} ErrorResponse "Формат уже существует"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /show-formats [post]
+func CreateShowFormat(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var f ShowFormatData
+		if !DecodeJSONBody(w, r, &f) {
+			return
+		}
+
+		if !validateAllShowFormatData(w, &f) {
+			return
+		}
+
+		id := uuid.New()
+		_, err := db.Exec(context.Background(),
+			"INSERT INTO show_formats (id, name, description) VALUES ($1, $2, $3)",
+			id, f.Name, f.Description)
+
+		if IsError(w, err) {
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(id.String())
+	}
+}
+
+// @Summary Обновить формат показа (admin)
+// @Description Обновляет существующий формат показа.
+// @Tags Форматы показа
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID формата"
+// @Param show_format body ShowFormatData true "Новые данные формата"
+// @Success 200 "Данные о формате успешно обновлены"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Формат не найден"
+// @Failure 409 {object} ErrorResponse "Формат с таким названием уже существует"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /show-formats/{id} [put]
+func UpdateShowFormat(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var f ShowFormatData
+		if !DecodeJSONBody(w, r, &f) {
+			return
+		}
+
+		if !validateAllShowFormatData(w, &f) {
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			"UPDATE show_formats SET name=$1, description=$2 WHERE id=$3",
+			f.Name, f.Description, id)
+
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// @Summary Удалить формат показа (admin)
+// @Description Удаляет формат показа по ID. Формат, который указан у сеансов, удалить нельзя.
+// @Tags Форматы показа
+// @Param id path string true "ID формата"
+// @Security BearerAuth
+// @Success 204 "Формат успешно удалён"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Формат не найден"
+// @Failure 409 {object} ErrorResponse "Формат используется в сеансах"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /show-formats/{id} [delete]
+func DeleteShowFormat(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			"DELETE FROM show_formats WHERE id = $1", id)
+
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetShowFormats(t *testing.T) {
+	ts := setupTestServer()
+	defer ts.Close()
+
+	req := createRequest(t, "GET", ts.URL+"/show-formats", "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var page Page[ShowFormat]
+	parseResponseBody(t, resp, &page)
+	resp.Body.Close()
+
+	if len(page.Items) != len(ShowFormatsData) {
+		t.Errorf("Expected %d formats, got %d", len(ShowFormatsData), len(page.Items))
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/show-formats/"+ShowFormatsData[1].ID, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var f ShowFormat
+	parseResponseBody(t, resp, &f)
+	resp.Body.Close()
+	if f.Name != ShowFormatsData[1].Name {
+		t.Errorf("Expected %q, got %q", ShowFormatsData[1].Name, f.Name)
+	}
+
+	req = createRequest(t, "GET", ts.URL+"/show-formats/"+uuid.New().String(), "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+}
+
+func TestCreateShowFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		body           interface{}
+		expectedStatus int
+	}{
+		{"Valid as Guest", "", ShowFormatData{Name: "IMAX 3D"}, http.StatusForbidden},
+		{"Valid as User", os.Getenv("CLAIM_ROLE_USER"), ShowFormatData{Name: "IMAX 3D"}, http.StatusForbidden},
+		{"Valid as Admin", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatData{Name: "IMAX 3D"}, http.StatusCreated},
+		{"With description", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatData{Name: "4DX", Description: ptr("Движущиеся кресла и спецэффекты")}, http.StatusCreated},
+		{"Invalid JSON", os.Getenv("CLAIM_ROLE_ADMIN"), "{invalid json}", http.StatusBadRequest},
+		{"Whitespace name", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatData{Name: "  "}, http.StatusBadRequest},
+		{"51 chars name", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatData{Name: strings.Repeat("a", 51)}, http.StatusBadRequest},
+		{"Whitespace description", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatData{Name: "4DX", Description: ptr("  ")}, http.StatusBadRequest},
+		{"501 chars description", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatData{Name: "4DX", Description: ptr(strings.Repeat("a", 501))}, http.StatusBadRequest},
+		{"Duplicate name", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatData{Name: "3D"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedUsers(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/show-formats", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+}
+
+func TestUpdateShowFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		body           ShowFormatData
+		expectedStatus int
+	}{
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), ShowFormatsData[1].ID, ShowFormatData{Name: "Stereo 3D"}, http.StatusForbidden},
+		{"Valid", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatsData[1].ID, ShowFormatData{Name: "Stereo 3D"}, http.StatusOK},
+		{"Duplicate name", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatsData[1].ID, ShowFormatData{Name: "2D"}, http.StatusConflict},
+		{"Non-existent", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), ShowFormatData{Name: "Stereo 3D"}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "PUT", ts.URL+"/show-formats/"+tt.id, generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+}
+
+func TestDeleteShowFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		role           string
+		id             string
+		expectedStatus int
+	}{
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), ShowFormatsData[1].ID, http.StatusForbidden},
+		{"Used by show", os.Getenv("CLAIM_ROLE_ADMIN"), ShowFormatsData[1].ID, http.StatusConflict},
+		{"Non-existent", os.Getenv("CLAIM_ROLE_ADMIN"), uuid.New().String(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "DELETE", ts.URL+"/show-formats/"+tt.id, generateToken(t, tt.role), nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+
+	t.Run("Unused format", func(t *testing.T) {
+		ts := setupTestServer()
+		SeedUsers(TestAdminDB)
+		defer ts.Close()
+
+		req := createRequest(t, "DELETE", ts.URL+"/show-formats/"+ShowFormatsData[1].ID, generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), nil)
+		executeRequest(t, req, http.StatusNoContent).Body.Close()
+	})
+}
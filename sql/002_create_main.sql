@@ -1,4 +1,6 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
 CREATE TABLE IF NOT EXISTS users (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -7,6 +9,7 @@ CREATE TABLE IF NOT EXISTS users (
     password_hash VARCHAR(255) NOT NULL,
     birth_date DATE NOT NULL,
     is_admin BOOLEAN DEFAULT FALSE,
+    accessibility_needs BOOLEAN NOT NULL DEFAULT FALSE, -- может бронировать придержанные места для маломобильных зрителей
     CONSTRAINT valid_name CHECK (name ~ '\S'),
     CONSTRAINT email_format CHECK (email ~* '^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Z|a-z]{2,}$'),
     CONSTRAINT valid_birth_date CHECK (birth_date <= CURRENT_DATE AND birth_date >= CURRENT_DATE - INTERVAL '100 years')
@@ -16,6 +19,9 @@ CREATE TABLE IF NOT EXISTS genres (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(64) NOT NULL UNIQUE,
     description VARCHAR(1000) NOT NULL,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', name), 'A') || setweight(to_tsvector('russian', description), 'B')
+    ) STORED,
     CONSTRAINT valid_name CHECK (name ~ '^[A-Za-zА-Яа-яЁё\s-]+$' AND name ~ '\S'),
     CONSTRAINT valid_description CHECK (description ~ '\S')
 );
@@ -28,12 +34,80 @@ CREATE TABLE IF NOT EXISTS movies (
     age_limit INT NOT NULL DEFAULT 0,
     box_office_revenue DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (box_office_revenue >= 0),
     release_date DATE NOT NULL, -- can be IN the future
+    -- Конфигурация russian приводит русские слова к основе стеммером Snowball для русского,
+    -- а слова латиницей — английским, поэтому морфология работает для названий на обоих языках
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', title), 'A') || setweight(to_tsvector('russian', description), 'B')
+    ) STORED,
     CONSTRAINT valid_title CHECK (title ~ '\S'),
     CONSTRAINT valid_duration CHECK (duration > '00:00:00'),
     CONSTRAINT valid_description CHECK (description ~ '\S'),
     CONSTRAINT valid_age_limit CHECK (age_limit IN (0, 6, 12, 16, 18))
 );
 
+CREATE INDEX IF NOT EXISTS idx_genres_search ON genres USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_genres_name_trgm ON genres USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_movies_search ON movies USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_movies_title_trgm ON movies USING GIN (title gin_trgm_ops);
+
+-- Поиск фильмов. Выше всех идут совпадения по словам с учётом морфологии, упорядоченные по ts_rank_cd
+-- (название весит больше описания), затем названия, содержащие запрос как подстроку,
+-- затем при p_fuzzy названия, похожие на запрос по триграммам (запрос с опечаткой).
+-- rank отражает эти уровни: > 2, (1, 2] и (0, 1]; fuzzy отмечает найденные только по сходству.
+-- Выделение в ts_headline — теги <mark>, остальной текст не экранируется.
+CREATE OR REPLACE FUNCTION search_movies(p_query TEXT, p_fuzzy BOOLEAN)
+RETURNS TABLE (movie_id UUID, rank REAL, title_highlight TEXT, description_highlight TEXT, fuzzy BOOLEAN) AS $$
+    WITH q AS (
+        SELECT websearch_to_tsquery('russian', p_query) AS tsq
+    ), found AS (
+        SELECT m.id, m.title, m.description, q.tsq,
+            m.search_vector @@ q.tsq AS matched,
+            ts_rank_cd(m.search_vector, q.tsq, 32) AS text_rank,
+            strpos(lower(m.title), lower(p_query)) > 0 AS in_title,
+            word_similarity(p_query, m.title) AS similarity
+        FROM movies m, q
+        WHERE m.search_vector @@ q.tsq
+        OR strpos(lower(m.title), lower(p_query)) > 0
+        OR (p_fuzzy AND p_query <% m.title)
+    )
+    SELECT id,
+        CASE WHEN matched THEN 2 + text_rank WHEN in_title THEN 1 + similarity ELSE similarity END,
+        ts_headline('russian', title, tsq, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+        ts_headline('russian', description, tsq,
+            'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "'),
+        NOT matched AND NOT in_title
+    FROM found
+    ORDER BY 2 DESC, title;
+$$ LANGUAGE sql STABLE
+SET pg_trgm.word_similarity_threshold = 0.3;
+
+-- Поиск жанров по названию и описанию; уровни ранжирования те же, что у search_movies
+CREATE OR REPLACE FUNCTION search_genres(p_query TEXT, p_fuzzy BOOLEAN)
+RETURNS TABLE (genre_id UUID, rank REAL, name_highlight TEXT, description_highlight TEXT, fuzzy BOOLEAN) AS $$
+    WITH q AS (
+        SELECT websearch_to_tsquery('russian', p_query) AS tsq
+    ), found AS (
+        SELECT g.id, g.name, g.description, q.tsq,
+            g.search_vector @@ q.tsq AS matched,
+            ts_rank_cd(g.search_vector, q.tsq, 32) AS text_rank,
+            strpos(lower(g.name), lower(p_query)) > 0 AS in_name,
+            word_similarity(p_query, g.name) AS similarity
+        FROM genres g, q
+        WHERE g.search_vector @@ q.tsq
+        OR strpos(lower(g.name), lower(p_query)) > 0
+        OR (p_fuzzy AND p_query <% g.name)
+    )
+    SELECT id,
+        CASE WHEN matched THEN 2 + text_rank WHEN in_name THEN 1 + similarity ELSE similarity END,
+        ts_headline('russian', name, tsq, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+        ts_headline('russian', description, tsq,
+            'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=" … "'),
+        NOT matched AND NOT in_name
+    FROM found
+    ORDER BY 2 DESC, name;
+$$ LANGUAGE sql STABLE
+SET pg_trgm.word_similarity_threshold = 0.3;
+
 CREATE TABLE IF NOT EXISTS screen_types (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(100) NOT NULL UNIQUE,
@@ -45,61 +119,377 @@ CREATE TABLE IF NOT EXISTS screen_types (
 ALTER TABLE screen_types ADD COLUMN price_modifier DECIMAL(3,2) 
 DEFAULT 1.0 CHECK (price_modifier > 0);
 
+ALTER TABLE screen_types ADD COLUMN default_cleaning_minutes INT
+NOT NULL DEFAULT 10 CHECK (default_cleaning_minutes >= 0);
+
+-- Проверяет, что имя часового пояса известно PostgreSQL (IANA, например Europe/Moscow)
+CREATE OR REPLACE FUNCTION is_valid_time_zone(p_time_zone TEXT)
+RETURNS BOOLEAN AS $$
+BEGIN
+    PERFORM now() AT TIME ZONE p_time_zone;
+    RETURN TRUE;
+EXCEPTION WHEN invalid_parameter_value THEN
+    RETURN FALSE;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Кинотеатры сети; часовой пояс кинотеатра действует для всех его залов
+CREATE TABLE IF NOT EXISTS cinemas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    address VARCHAR(300) NOT NULL,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    phone VARCHAR(20),
+    email VARCHAR(100),
+    CONSTRAINT valid_name CHECK (name ~ '\S'),
+    CONSTRAINT valid_address CHECK (address ~ '\S'),
+    CONSTRAINT valid_time_zone CHECK (is_valid_time_zone(time_zone)),
+    CONSTRAINT valid_phone CHECK (phone IS NULL OR phone ~ '^\+?[0-9\s()-]{5,20}$'),
+    CONSTRAINT email_format CHECK (email IS NULL OR email ~* '^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Z|a-z]{2,}$')
+);
+
+-- Администраторы, ограниченные отдельными кинотеатрами.
+-- Администратор без строк в этой таблице управляет всей сетью.
+CREATE TABLE IF NOT EXISTS cinema_admins (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    cinema_id UUID REFERENCES cinemas(id) ON DELETE CASCADE,
+    PRIMARY KEY (user_id, cinema_id)
+);
+
+-- NULL вместо кинотеатра означает, что объект не найден: проверять нечего, функция возвращает NULL
+CREATE OR REPLACE FUNCTION admin_can_manage_cinema(p_user_id UUID, p_cinema_id UUID)
+RETURNS BOOLEAN AS $$
+    SELECT NOT EXISTS (SELECT 1 FROM cinema_admins WHERE user_id = p_user_id)
+        OR EXISTS (SELECT 1 FROM cinema_admins WHERE user_id = p_user_id AND cinema_id = p_cinema_id);
+$$ LANGUAGE sql STABLE STRICT;
+
 CREATE TABLE IF NOT EXISTS halls (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cinema_id UUID NOT NULL REFERENCES cinemas(id),
     screen_type_id UUID REFERENCES screen_types(id),
-    name VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
     description VARCHAR(1000),
+    cleaning_minutes INT, -- NULL: берётся значение по умолчанию для типа экрана
+    opens_at TIME,
+    closes_at TIME,
+    time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC', -- копия пояса кинотеатра: часы работы и даты сеансов задаются в нём
+    accessible_release_minutes INT, -- NULL: места для маломобильных зрителей поступают в общую продажу за 120 минут до начала
+    CONSTRAINT hall_name_per_cinema UNIQUE (cinema_id, name),
     CONSTRAINT valid_name CHECK (
         name ~ '^[a-zA-Zа-яА-Я0-9\s\.\-_#№]+$' AND
         name ~ '\S' AND
         length(name) <= 100
     ),
-    CONSTRAINT valid_description CHECK (description IS NULL OR description ~ '\S')
+    CONSTRAINT valid_description CHECK (description IS NULL OR description ~ '\S'),
+    CONSTRAINT valid_cleaning_minutes CHECK (cleaning_minutes IS NULL OR cleaning_minutes >= 0),
+    CONSTRAINT valid_accessible_release_minutes CHECK (accessible_release_minutes IS NULL OR accessible_release_minutes >= 0),
+    CONSTRAINT valid_opening_hours CHECK (
+        (opens_at IS NULL AND closes_at IS NULL) OR
+        (opens_at IS NOT NULL AND closes_at IS NOT NULL AND opens_at < closes_at)
+    ),
+    CONSTRAINT valid_time_zone CHECK (is_valid_time_zone(time_zone))
 );
 
+CREATE INDEX IF NOT EXISTS idx_halls_cinema ON halls(cinema_id);
+
+-- Зал всегда живёт в часовом поясе своего кинотеатра
+CREATE OR REPLACE FUNCTION set_hall_time_zone()
+RETURNS TRIGGER AS $$
+BEGIN
+    SELECT time_zone INTO NEW.time_zone FROM cinemas WHERE id = NEW.cinema_id;
+    NEW.time_zone := COALESCE(NEW.time_zone, 'UTC');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_hall_time_zone_trigger
+BEFORE INSERT OR UPDATE ON halls
+FOR EACH ROW
+EXECUTE FUNCTION set_hall_time_zone();
+
+CREATE OR REPLACE FUNCTION propagate_cinema_time_zone()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE halls SET time_zone = NEW.time_zone WHERE cinema_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER propagate_cinema_time_zone_trigger
+AFTER UPDATE OF time_zone ON cinemas
+FOR EACH ROW
+WHEN (OLD.time_zone IS DISTINCT FROM NEW.time_zone)
+EXECUTE FUNCTION propagate_cinema_time_zone();
+
 CREATE TABLE IF NOT EXISTS movies_genres (
     movie_id UUID REFERENCES movies(id) ON DELETE CASCADE,
     genre_id UUID REFERENCES genres(id),
     PRIMARY KEY (movie_id, genre_id)
 );
 
-CREATE TYPE language_enum AS ENUM (
-    'English',
-    'Spanish',
-    'French',
-    'German',
-    'Italian',
-    'Русский'
+-- Создатели фильмов: актёры, режиссёры, сценаристы
+CREATE TABLE IF NOT EXISTS people (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(200) NOT NULL,
+    birth_date DATE,
+    biography VARCHAR(2000),
+    CONSTRAINT valid_name CHECK (name ~ '\S'),
+    CONSTRAINT valid_biography CHECK (biography ~ '\S')
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_name ON people (lower(name));
+
+CREATE TYPE credit_role_enum AS ENUM ('actor', 'director', 'writer');
+
+-- Участие человека в фильме; один человек может быть и режиссёром, и сценаристом.
+-- Человека с ролями в фильмах удалить нельзя.
+CREATE TABLE IF NOT EXISTS movie_credits (
+    movie_id UUID NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    person_id UUID NOT NULL REFERENCES people(id),
+    role credit_role_enum NOT NULL,
+    character_name VARCHAR(200), -- только для актёров
+    billing_order INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (movie_id, person_id, role),
+    CONSTRAINT valid_character_name CHECK (character_name IS NULL OR (role = 'actor' AND character_name ~ '\S'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_credits_person ON movie_credits (person_id);
+
+CREATE TYPE media_kind_enum AS ENUM ('poster', 'still', 'trailer');
+
+-- Медиа фильма. Постер и кадры лежат в файловом хранилище под storage_key вместе с миниатюрой,
+-- трейлер — внешняя ссылка. У фильма не больше одного постера.
+CREATE TABLE IF NOT EXISTS movie_media (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    movie_id UUID NOT NULL REFERENCES movies(id) ON DELETE CASCADE,
+    kind media_kind_enum NOT NULL,
+    storage_key VARCHAR(300) UNIQUE,
+    thumbnail_key VARCHAR(300) UNIQUE,
+    external_url VARCHAR(2000),
+    content_type VARCHAR(100),
+    size_bytes BIGINT,
+    width INT,
+    height INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT valid_media_source CHECK (
+        (kind = 'trailer' AND external_url ~ '^https?://' AND storage_key IS NULL AND thumbnail_key IS NULL)
+        OR (kind <> 'trailer' AND external_url IS NULL AND storage_key IS NOT NULL AND thumbnail_key IS NOT NULL
+            AND content_type IS NOT NULL AND size_bytes > 0 AND width > 0 AND height > 0)
+    )
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS one_poster_per_movie ON movie_media (movie_id) WHERE kind = 'poster';
+CREATE INDEX IF NOT EXISTS idx_movie_media_movie ON movie_media (movie_id, created_at);
+
+-- Языки звуковой дорожки и субтитров; сеансы ссылаются на название, поэтому переименование каскадируется
+CREATE TABLE IF NOT EXISTS languages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL UNIQUE,
+    CONSTRAINT valid_name CHECK (name ~ '\S')
+);
+
+INSERT INTO languages (name) VALUES
+('English'),
+('Spanish'),
+('French'),
+('German'),
+('Italian'),
+('Русский')
+ON CONFLICT (name) DO NOTHING;
+
+-- Форматы показа: 2D/3D, дубляж, оригинальная дорожка и т.п.
+CREATE TABLE IF NOT EXISTS show_formats (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(500),
+    CONSTRAINT valid_name CHECK (name ~ '\S'),
+    CONSTRAINT valid_description CHECK (description IS NULL OR description ~ '\S')
+);
+
+INSERT INTO show_formats (name, description) VALUES
+('2D', 'Обычный показ'),
+('3D', 'Стереоскопический показ, нужны 3D-очки'),
+('Дубляж', 'Звуковая дорожка переведена'),
+('Оригинал', 'Оригинальная звуковая дорожка')
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TYPE show_status_enum AS ENUM (
+    'scheduled', -- продажа билетов ещё не открыта
+    'on_sale',
+    'sold_out',
+    'in_progress',
+    'finished',
+    'cancelled'
 );
 
 CREATE TABLE IF NOT EXISTS movie_shows ( -- Тут надо проверять при вставке, что не конфликтуют показы между собой
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     movie_id UUID REFERENCES movies(id),
     hall_id UUID REFERENCES halls(id),
-    start_time TIMESTAMP NOT NULL CHECK (start_time > '1895-03-22'),
-    language language_enum NOT NULL
+    start_time TIMESTAMPTZ NOT NULL CHECK (start_time > '1895-03-22'),
+    language VARCHAR(50) NOT NULL,
+    subtitle_language VARCHAR(50), -- NULL: без субтитров
+    status show_status_enum NOT NULL DEFAULT 'on_sale',
+    cancelled_at TIMESTAMPTZ, -- отменённые сеансы не удаляются, чтобы сохранить историю продаж
+    cancel_reason VARCHAR(500),
+    occupied TSTZRANGE, -- время показа вместе с уборкой зала, заполняется триггером check_movie_show_conflict
+    revision INT NOT NULL DEFAULT 0, -- растёт при переносе и отмене, используется как SEQUENCE в календарях
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT movie_show_language_fkey FOREIGN KEY (language) REFERENCES languages(name) ON UPDATE CASCADE,
+    CONSTRAINT movie_show_subtitle_language_fkey FOREIGN KEY (subtitle_language) REFERENCES languages(name) ON UPDATE CASCADE,
+    CONSTRAINT valid_cancel_reason CHECK (cancel_reason IS NULL OR cancel_reason ~ '\S'),
+    CONSTRAINT valid_cancel_status CHECK ((status = 'cancelled') = (cancelled_at IS NOT NULL)),
+    -- id входит в ограничение, чтобы текст ошибки содержал конфликтующий сеанс
+    CONSTRAINT no_overlapping_movie_shows EXCLUDE USING gist (
+        hall_id WITH =,
+        occupied WITH &&,
+        id WITH <>
+    ) WHERE (status <> 'cancelled')
+);
+
+CREATE INDEX IF NOT EXISTS idx_movie_shows_status ON movie_shows(status);
+CREATE INDEX IF NOT EXISTS idx_movie_shows_language ON movie_shows(language);
+
+CREATE TABLE IF NOT EXISTS movie_show_formats (
+    movie_show_id UUID REFERENCES movie_shows(id) ON DELETE CASCADE,
+    format_id UUID,
+    PRIMARY KEY (movie_show_id, format_id),
+    CONSTRAINT movie_show_format_fkey FOREIGN KEY (format_id) REFERENCES show_formats(id)
 );
 
+-- Допустимые переходы между статусами киносеанса (дублируются в ShowStatusEnumType.CanTransitionTo)
+CREATE OR REPLACE FUNCTION show_status_transition_allowed(p_from show_status_enum, p_to show_status_enum)
+RETURNS BOOLEAN AS $$
+    SELECT p_from = p_to OR CASE p_from
+        WHEN 'scheduled' THEN p_to IN ('on_sale', 'in_progress', 'cancelled')
+        WHEN 'on_sale' THEN p_to IN ('scheduled', 'sold_out', 'in_progress', 'cancelled')
+        WHEN 'sold_out' THEN p_to IN ('on_sale', 'in_progress', 'cancelled')
+        WHEN 'in_progress' THEN p_to = 'finished'
+        ELSE FALSE
+    END;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION check_movie_show_status_transition()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NOT show_status_transition_allowed(OLD.status, NEW.status) THEN
+        RAISE EXCEPTION 'Недопустимый переход статуса киносеанса: % -> %', OLD.status, NEW.status;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER check_movie_show_status_on_update
+BEFORE UPDATE ON movie_shows
+FOR EACH ROW
+WHEN (OLD.status IS DISTINCT FROM NEW.status)
+EXECUTE FUNCTION check_movie_show_status_transition();
+
+-- Переводит начавшиеся сеансы в in_progress, а завершившиеся — в finished.
+-- Вызывается периодически приложением; p_now передаётся снаружи, чтобы момент проверки задавало приложение.
+CREATE OR REPLACE FUNCTION refresh_movie_show_statuses(p_now TIMESTAMPTZ)
+RETURNS INT AS $$
+DECLARE
+    v_started INT;
+    v_finished INT;
+BEGIN
+    UPDATE movie_shows
+    SET status = 'in_progress'
+    WHERE status IN ('scheduled', 'on_sale', 'sold_out')
+    AND start_time <= p_now;
+    GET DIAGNOSTICS v_started = ROW_COUNT;
+
+    UPDATE movie_shows ms
+    SET status = 'finished'
+    FROM movies m
+    WHERE m.id = ms.movie_id
+    AND ms.status = 'in_progress'
+    AND ms.start_time + m.duration <= p_now;
+    GET DIAGNOSTICS v_finished = ROW_COUNT;
+
+    RETURN v_started + v_finished;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+-- Время уборки зала: собственное значение зала или значение по умолчанию для типа экрана
+CREATE OR REPLACE FUNCTION hall_cleaning_interval(p_hall_id UUID)
+RETURNS INTERVAL AS $$
+    SELECT make_interval(mins => COALESCE(h.cleaning_minutes, st.default_cleaning_minutes, 10))
+    FROM halls h
+    LEFT JOIN screen_types st ON st.id = h.screen_type_id
+    WHERE h.id = p_hall_id;
+$$ LANGUAGE sql STABLE;
+
+-- Интервал, на который сеанс занимает зал: фильм и следующая за ним уборка
+CREATE OR REPLACE FUNCTION movie_show_occupied(p_movie_id UUID, p_hall_id UUID, p_start_time TIMESTAMPTZ)
+RETURNS TSTZRANGE AS $$
+    SELECT tstzrange(
+        p_start_time,
+        p_start_time + m.duration + COALESCE(hall_cleaning_interval(p_hall_id), INTERVAL '10 minutes'),
+        '[)')
+    FROM movies m
+    WHERE m.id = p_movie_id;
+$$ LANGUAGE sql STABLE;
+
+-- Зал закрыт на ремонт проектора, реконструкцию и т.п. Окна одного зала не пересекаются.
+CREATE TABLE IF NOT EXISTS hall_maintenance_windows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hall_id UUID NOT NULL REFERENCES halls(id) ON DELETE CASCADE,
+    period TSTZRANGE NOT NULL,
+    reason VARCHAR(500) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_reason CHECK (reason ~ '\S'),
+    CONSTRAINT valid_period CHECK (NOT isempty(period) AND NOT lower_inf(period) AND NOT upper_inf(period)),
+    CONSTRAINT no_overlapping_hall_maintenance EXCLUDE USING gist (
+        hall_id WITH =,
+        period WITH &&
+    )
+);
+
+-- Пересечение сеансов проверяет ограничение no_overlapping_movie_shows, здесь часы работы и обслуживание зала
 CREATE OR REPLACE FUNCTION check_movie_show_conflict()
 RETURNS TRIGGER AS $$
+DECLARE
+    v_duration INTERVAL;
+    v_opens_at TIME;
+    v_closes_at TIME;
+    v_local_start TIMESTAMP;
+    v_time_zone VARCHAR(64);
+    v_window hall_maintenance_windows%ROWTYPE;
 BEGIN
-    IF EXISTS (
-        SELECT 1
-        FROM movie_shows
-        WHERE hall_id = NEW.hall_id
-        AND id <> NEW.id
-        AND (
-            (start_time < NEW.start_time + (SELECT duration FROM movies WHERE id = NEW.movie_id) + INTERVAL '10 minutes' 
-             AND 
-             start_time + (SELECT duration FROM movies WHERE id = movie_shows.movie_id) + INTERVAL '10 minutes' > NEW.start_time)
-            OR 
-            (NEW.start_time < start_time + (SELECT duration FROM movies WHERE id = movie_shows.movie_id) + INTERVAL '10 minutes' 
-             AND 
-             NEW.start_time + (SELECT duration FROM movies WHERE id = NEW.movie_id) + INTERVAL '10 minutes' > start_time)
-        )
+    SELECT duration::interval INTO v_duration FROM movies WHERE id = NEW.movie_id;
+
+    -- Часы работы сравниваются с местным временем зала. Блокировка зала, как и при создании
+    -- окна обслуживания, не даёт сеансу и окну, добавляемым одновременно, разминуться.
+    SELECT opens_at, closes_at, NEW.start_time AT TIME ZONE time_zone, time_zone
+    INTO v_opens_at, v_closes_at, v_local_start, v_time_zone
+    FROM halls
+    WHERE id = NEW.hall_id
+    FOR UPDATE;
+
+    IF v_opens_at IS NOT NULL AND (
+        v_local_start::time < v_opens_at OR
+        v_local_start + v_duration > v_local_start::date + v_closes_at
     ) THEN
-        RAISE EXCEPTION 'Невозможно запланировать показ, поскольку в это время кинозал будет занят показом другого фильма или будет проводиться уборка';
+        RAISE EXCEPTION 'Невозможно запланировать показ вне часов работы кинозала (% - %)',
+            to_char(v_opens_at::interval, 'HH24:MI'), to_char(v_closes_at::interval, 'HH24:MI');
+    END IF;
+
+    NEW.occupied := movie_show_occupied(NEW.movie_id, NEW.hall_id, NEW.start_time);
+
+    -- Сеанс вместе с уборкой не должен пересекаться с обслуживанием зала
+    SELECT * INTO v_window
+    FROM hall_maintenance_windows
+    WHERE hall_id = NEW.hall_id AND period && NEW.occupied
+    ORDER BY lower(period)
+    LIMIT 1;
+
+    IF FOUND THEN
+        RAISE EXCEPTION 'Невозможно запланировать показ: кинозал закрыт на обслуживание с % по % (%)',
+            to_char(lower(v_window.period) AT TIME ZONE v_time_zone, 'YYYY-MM-DD HH24:MI'),
+            to_char(upper(v_window.period) AT TIME ZONE v_time_zone, 'YYYY-MM-DD HH24:MI'),
+            v_window.reason;
     END IF;
 
     RETURN NEW;
@@ -114,9 +504,66 @@ EXECUTE FUNCTION check_movie_show_conflict();
 CREATE TRIGGER check_movie_show_on_update
 BEFORE UPDATE ON movie_shows
 FOR EACH ROW
-WHEN (OLD.start_time IS DISTINCT FROM NEW.start_time)
+WHEN (
+    OLD.start_time IS DISTINCT FROM NEW.start_time OR
+    OLD.hall_id IS DISTINCT FROM NEW.hall_id OR
+    OLD.movie_id IS DISTINCT FROM NEW.movie_id
+)
 EXECUTE FUNCTION check_movie_show_conflict();
 
+-- Перенос, смена фильма или отмена сеанса — новая ревизия события в календарях
+CREATE OR REPLACE FUNCTION bump_movie_show_revision()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.revision := OLD.revision + 1;
+    NEW.updated_at := CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bump_movie_show_revision_on_update
+BEFORE UPDATE ON movie_shows
+FOR EACH ROW
+WHEN (
+    OLD.start_time IS DISTINCT FROM NEW.start_time OR
+    OLD.hall_id IS DISTINCT FROM NEW.hall_id OR
+    OLD.movie_id IS DISTINCT FROM NEW.movie_id OR
+    (OLD.status IS DISTINCT FROM NEW.status AND NEW.status = 'cancelled')
+)
+EXECUTE FUNCTION bump_movie_show_revision();
+
+-- Длительность фильма и время уборки зала входят в интервал занятости предстоящих сеансов
+CREATE OR REPLACE FUNCTION refresh_movie_shows_occupied()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE movie_shows
+    SET occupied = movie_show_occupied(movie_id, hall_id, start_time)
+    WHERE status NOT IN ('finished', 'cancelled')
+    AND CASE TG_TABLE_NAME
+        WHEN 'movies' THEN movie_id = NEW.id
+        WHEN 'halls' THEN hall_id = NEW.id
+        ELSE hall_id IN (SELECT id FROM halls WHERE screen_type_id = NEW.id)
+    END;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER refresh_movie_shows_occupied_on_movie_update
+AFTER UPDATE OF duration ON movies
+FOR EACH ROW
+EXECUTE FUNCTION refresh_movie_shows_occupied();
+
+CREATE TRIGGER refresh_movie_shows_occupied_on_hall_update
+AFTER UPDATE OF cleaning_minutes, screen_type_id ON halls
+FOR EACH ROW
+EXECUTE FUNCTION refresh_movie_shows_occupied();
+
+CREATE TRIGGER refresh_movie_shows_occupied_on_screen_type_update
+AFTER UPDATE OF default_cleaning_minutes ON screen_types
+FOR EACH ROW
+EXECUTE FUNCTION refresh_movie_shows_occupied();
+
 CREATE TABLE IF NOT EXISTS seat_types (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(100) NOT NULL UNIQUE,
@@ -128,19 +575,127 @@ CREATE TABLE IF NOT EXISTS seat_types (
 ALTER TABLE seat_types ADD COLUMN price_modifier DECIMAL(3,2) 
 DEFAULT 1.0 CHECK (price_modifier > 0);
 
+CREATE TYPE seat_service_state_enum AS ENUM (
+    'active',
+    'blocked',
+    'maintenance'
+);
+
+-- Зоны зала (VIP, первые ряды и т.п.): наценка зоны умножается на наценки экрана и типа места
+CREATE TABLE IF NOT EXISTS hall_zones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hall_id UUID NOT NULL REFERENCES halls(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price_modifier DECIMAL(3,2) NOT NULL DEFAULT 1.0 CHECK (price_modifier > 0),
+    CONSTRAINT zone_name_per_hall UNIQUE (hall_id, name),
+    CONSTRAINT valid_name CHECK (name ~ '\S')
+);
+
+-- Неисправное место не удаляется, а выводится из обслуживания на период [service_from, service_until).
+-- service_until = NULL: до отмены блокировки.
 CREATE TABLE IF NOT EXISTS seats (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     hall_id UUID REFERENCES halls(id),
     seat_type_id UUID REFERENCES seat_types(id),
     row_number INTEGER NOT NULL CHECK (row_number > 0),
     seat_number INTEGER NOT NULL CHECK (seat_number > 0),
-    CONSTRAINT unique_seat UNIQUE (hall_id, row_number, seat_number)
+    service_state seat_service_state_enum NOT NULL DEFAULT 'active',
+    service_reason VARCHAR(500),
+    service_from TIMESTAMPTZ,
+    service_until TIMESTAMPTZ,
+    wheelchair_space BOOLEAN NOT NULL DEFAULT FALSE, -- место для кресла-коляски
+    companion_seat_id UUID REFERENCES seats(id) ON DELETE SET NULL, -- место сопровождающего рядом с местом для коляски
+    zone_id UUID REFERENCES hall_zones(id) ON DELETE SET NULL,
+    CONSTRAINT unique_seat UNIQUE (hall_id, row_number, seat_number),
+    CONSTRAINT unique_companion_seat UNIQUE (companion_seat_id),
+    CONSTRAINT valid_companion_seat CHECK (
+        companion_seat_id IS NULL OR (wheelchair_space AND companion_seat_id <> id)
+    ),
+    CONSTRAINT valid_service_period CHECK (
+        (service_state = 'active' AND service_reason IS NULL AND service_from IS NULL AND service_until IS NULL) OR
+        (service_state <> 'active' AND service_reason ~ '\S' AND service_from IS NOT NULL AND
+            (service_until IS NULL OR service_until > service_from))
+    )
 );
 
+CREATE INDEX IF NOT EXISTS idx_seats_out_of_service ON seats(hall_id) WHERE service_state <> 'active';
+CREATE INDEX IF NOT EXISTS idx_seats_zone ON seats(zone_id) WHERE zone_id IS NOT NULL;
+
+-- Место может входить только в зону своего зала
+CREATE OR REPLACE FUNCTION check_seat_zone()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM hall_zones
+        WHERE id = NEW.zone_id AND hall_id IS DISTINCT FROM NEW.hall_id
+    ) THEN
+        RAISE EXCEPTION 'Недопустимая зона места: зона принадлежит другому залу';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER check_seat_zone_trigger
+BEFORE INSERT OR UPDATE OF hall_id, zone_id ON seats
+FOR EACH ROW
+EXECUTE FUNCTION check_seat_zone();
+
+-- Место сопровождающего находится в том же зале и само не является местом для коляски
+CREATE OR REPLACE FUNCTION check_companion_seat()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Несуществующее место отклоняет внешний ключ
+    IF EXISTS (
+        SELECT 1 FROM seats
+        WHERE id = NEW.companion_seat_id AND (hall_id IS DISTINCT FROM NEW.hall_id OR wheelchair_space)
+    ) THEN
+        RAISE EXCEPTION 'Недопустимое место сопровождающего: оно должно быть обычным местом того же зала';
+    END IF;
+
+    IF NEW.wheelchair_space AND EXISTS (SELECT 1 FROM seats WHERE companion_seat_id = NEW.id) THEN
+        RAISE EXCEPTION 'Недопустимое место сопровождающего: место для коляски не может сопровождать другое';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER check_companion_seat_trigger
+BEFORE INSERT OR UPDATE OF hall_id, wheelchair_space, companion_seat_id ON seats
+FOR EACH ROW
+EXECUTE FUNCTION check_companion_seat();
+
+-- Места для маломобильных зрителей (для коляски и места сопровождающих) придерживаются
+-- от общей продажи до accessible_release_minutes минут до начала сеанса
+CREATE OR REPLACE FUNCTION accessible_seat_held(p_seat_id UUID, p_movie_show_id UUID, p_now TIMESTAMPTZ)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM seats s
+        JOIN halls h ON h.id = s.hall_id
+        JOIN movie_shows ms ON ms.id = p_movie_show_id
+        WHERE s.id = p_seat_id
+        AND (s.wheelchair_space OR EXISTS (SELECT 1 FROM seats w WHERE w.companion_seat_id = s.id))
+        AND p_now < ms.start_time - make_interval(mins => COALESCE(h.accessible_release_minutes, 120))
+    );
+$$ LANGUAGE sql STABLE;
+
+-- Место выведено из обслуживания на какое-то время в пределах p_period
+CREATE OR REPLACE FUNCTION seat_out_of_service(
+    p_state seat_service_state_enum,
+    p_from TIMESTAMPTZ,
+    p_until TIMESTAMPTZ,
+    p_period TSTZRANGE)
+RETURNS BOOLEAN AS $$
+    SELECT p_state <> 'active' AND COALESCE(tstzrange(p_from, p_until) && p_period, FALSE);
+$$ LANGUAGE sql IMMUTABLE;
+
 CREATE TYPE ticket_status_enum AS ENUM (
     'Purchased',
     'Reserved',
-    'Available'
+    'Available',
+    'Refunded'
 );
 
 CREATE TABLE IF NOT EXISTS tickets (
@@ -150,13 +705,49 @@ CREATE TABLE IF NOT EXISTS tickets (
     user_id UUID REFERENCES users(id),
     ticket_status ticket_status_enum NOT NULL,
     price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    held_until TIMESTAMPTZ, -- временная бронь: после этого момента билет снова свободен
     CONSTRAINT unique_ticket UNIQUE (movie_show_id, seat_id),
     CONSTRAINT user_id_status_check CHECK (
         (user_id IS NULL AND ticket_status = 'Available') OR
         (user_id IS NOT NULL)
-    )
+    ),
+    CONSTRAINT valid_hold CHECK (held_until IS NULL OR ticket_status = 'Reserved')
 );
 
+CREATE INDEX IF NOT EXISTS idx_tickets_held_until ON tickets(held_until) WHERE held_until IS NOT NULL;
+
+-- Покупка или возврат временно забронированного билета снимает ограничение по времени
+CREATE OR REPLACE FUNCTION clear_ticket_hold()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.held_until := NULL;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER clear_ticket_hold_when_status_changed
+BEFORE UPDATE OF ticket_status ON tickets
+FOR EACH ROW
+WHEN (NEW.ticket_status <> 'Reserved' AND NEW.held_until IS NOT NULL)
+EXECUTE FUNCTION clear_ticket_hold();
+
+-- Снимает истёкшие временные брони. Вызывается периодически приложением, как и refresh_movie_show_statuses.
+-- Освободившиеся билеты удаляет триггер release_ticket_when_available.
+CREATE OR REPLACE FUNCTION release_expired_seat_holds(p_now TIMESTAMPTZ)
+RETURNS INT AS $$
+DECLARE
+    v_released INT;
+BEGIN
+    UPDATE tickets
+    SET ticket_status = 'Available', user_id = NULL, held_until = NULL
+    WHERE ticket_status = 'Reserved'
+    AND held_until <= p_now;
+    GET DIAGNOSTICS v_released = ROW_COUNT;
+
+    RETURN v_released;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
 CREATE OR REPLACE FUNCTION update_box_office_revenue()
 RETURNS TRIGGER AS $$
 BEGIN
@@ -183,6 +774,323 @@ FOR EACH ROW
 WHEN (OLD.ticket_status IS DISTINCT FROM NEW.ticket_status)
 EXECUTE FUNCTION update_box_office_revenue();
 
+-- Свободные билеты не хранятся: строки в tickets есть только у проданных, забронированных и возвращённых мест.
+-- Цена свободного места вычисляется из базовой цены сеанса и модификаторов экрана и типа места.
+CREATE TABLE IF NOT EXISTS movie_show_prices (
+    movie_show_id UUID PRIMARY KEY REFERENCES movie_shows(id) ON DELETE CASCADE,
+    base_price DECIMAL(10,2) NOT NULL CHECK (base_price > 0)
+);
+
+-- Режим дистанцирования сеанса: вокруг забронированных и купленных мест блокируются side_seats мест ряда
+-- с каждой стороны, а при block_adjacent_rows — и места с тем же номером в соседних рядах.
+-- Места одного пользователя друг друга не блокируют. max_occupancy_percent ограничивает долю мест зала,
+-- которую можно забронировать или купить; NULL — без ограничения.
+CREATE TABLE IF NOT EXISTS movie_show_distancing (
+    movie_show_id UUID PRIMARY KEY REFERENCES movie_shows(id) ON DELETE CASCADE,
+    side_seats INT NOT NULL CHECK (side_seats BETWEEN 0 AND 10),
+    block_adjacent_rows BOOLEAN NOT NULL DEFAULT FALSE,
+    max_occupancy_percent INT CHECK (max_occupancy_percent BETWEEN 1 AND 100)
+);
+
+-- Место находится в зоне дистанции чужих билетов. p_user_id = NULL: блокируют билеты любых пользователей.
+CREATE OR REPLACE FUNCTION seat_distancing_blocked(p_movie_show_id UUID, p_seat_id UUID, p_user_id UUID)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM movie_show_distancing d
+        JOIN seats s ON s.id = p_seat_id
+        JOIN tickets t ON t.movie_show_id = d.movie_show_id AND t.ticket_status IN ('Reserved', 'Purchased')
+        JOIN seats o ON o.id = t.seat_id
+        WHERE d.movie_show_id = p_movie_show_id
+        AND o.id <> s.id
+        AND (p_user_id IS NULL OR t.user_id IS DISTINCT FROM p_user_id)
+        AND (
+            (o.row_number = s.row_number AND abs(o.seat_number - s.seat_number) <= d.side_seats) OR
+            (d.block_adjacent_rows AND abs(o.row_number - s.row_number) = 1 AND o.seat_number = s.seat_number)
+        )
+    );
+$$ LANGUAGE sql STABLE;
+
+-- Наибольшее число забронированных и купленных мест сеанса; NULL — без ограничения
+CREATE OR REPLACE FUNCTION movie_show_max_occupancy(p_movie_show_id UUID)
+RETURNS INT AS $$
+    SELECT floor(COUNT(s.id) * d.max_occupancy_percent / 100.0)::INT
+    FROM movie_show_distancing d
+    JOIN movie_shows ms ON ms.id = d.movie_show_id
+    LEFT JOIN seats s ON s.hall_id = ms.hall_id
+    WHERE d.movie_show_id = p_movie_show_id AND d.max_occupancy_percent IS NOT NULL
+    GROUP BY d.max_occupancy_percent;
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION movie_show_at_capacity(p_movie_show_id UUID)
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE((
+        SELECT COUNT(*) FROM tickets
+        WHERE movie_show_id = p_movie_show_id AND ticket_status IN ('Reserved', 'Purchased')
+    ) >= movie_show_max_occupancy(p_movie_show_id), FALSE);
+$$ LANGUAGE sql STABLE;
+
+-- ID свободного билета детерминирован, чтобы его можно было передать в API до сохранения в tickets
+CREATE OR REPLACE FUNCTION virtual_ticket_id(p_movie_show_id UUID, p_seat_id UUID)
+RETURNS UUID AS $$
+    SELECT uuid_generate_v5(p_movie_show_id, p_seat_id::text);
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Все билеты сеанса: сохранённые и вычисляемые свободные места текущей схемы зала.
+-- Места, выведенные из обслуживания на время сеанса, свободными не считаются,
+-- как и места в зоне дистанции и все места сеанса с достигнутой предельной заполняемостью.
+CREATE OR REPLACE VIEW movie_show_tickets AS
+SELECT t.id, t.movie_show_id, t.seat_id, t.user_id, t.ticket_status, t.price, TRUE AS stored
+FROM tickets t
+UNION ALL
+SELECT virtual_ticket_id(ms.id, s.id), ms.id, s.id, NULL::UUID, 'Available'::ticket_status_enum,
+    ROUND(p.base_price * scr.price_modifier * st.price_modifier * COALESCE(z.price_modifier, 1), 2), FALSE
+FROM movie_shows ms
+-- Заполняемость и наличие дистанции вычисляются один раз на сеанс, а не на каждое место
+CROSS JOIN LATERAL (
+    SELECT movie_show_at_capacity(ms.id) AS at_capacity,
+        EXISTS (SELECT 1 FROM movie_show_distancing d WHERE d.movie_show_id = ms.id) AS distancing
+) cap
+JOIN movie_show_prices p ON p.movie_show_id = ms.id
+JOIN halls h ON h.id = ms.hall_id
+JOIN screen_types scr ON scr.id = h.screen_type_id
+JOIN seats s ON s.hall_id = ms.hall_id
+JOIN seat_types st ON st.id = s.seat_type_id
+LEFT JOIN hall_zones z ON z.id = s.zone_id
+WHERE NOT cap.at_capacity
+AND NOT seat_out_of_service(s.service_state, s.service_from, s.service_until, ms.occupied)
+AND NOT EXISTS (
+    SELECT 1 FROM tickets t
+    WHERE t.movie_show_id = ms.id AND t.seat_id = s.id
+)
+AND NOT (cap.distancing AND seat_distancing_blocked(ms.id, s.id, NULL));
+
+-- Сохраняет вычисляемый свободный билет в tickets, чтобы его можно было забронировать или купить.
+-- Для сохранённых и несуществующих билетов ничего не делает.
+-- SECURITY DEFINER: у пользователей нет права на вставку билетов.
+CREATE OR REPLACE FUNCTION materialize_ticket(p_ticket_id UUID)
+RETURNS VOID AS $$
+    INSERT INTO tickets (id, movie_show_id, seat_id, ticket_status, price)
+    SELECT id, movie_show_id, seat_id, ticket_status, price
+    FROM movie_show_tickets
+    WHERE id = p_ticket_id AND NOT stored
+    ON CONFLICT DO NOTHING;
+$$ LANGUAGE sql SECURITY DEFINER SET search_path = public, pg_temp;
+
+-- Освободившийся билет удаляется, если его можно вычислить заново (место из текущей схемы зала)
+CREATE OR REPLACE FUNCTION release_available_ticket()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM tickets t
+    USING movie_shows ms, movie_show_prices p, seats s
+    WHERE t.id = NEW.id
+    AND ms.id = NEW.movie_show_id
+    AND p.movie_show_id = ms.id
+    AND s.id = NEW.seat_id
+    AND s.hall_id = ms.hall_id
+    AND t.id = virtual_ticket_id(ms.id, s.id);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE TRIGGER release_ticket_when_available
+AFTER UPDATE OF ticket_status ON tickets
+FOR EACH ROW
+WHEN (NEW.ticket_status = 'Available')
+EXECUTE FUNCTION release_available_ticket();
+
+-- Сеанс без свободных мест переходит в sold_out, а при освобождении места — обратно в on_sale.
+-- SECURITY DEFINER: билеты бронируют пользователи, у которых нет прав на изменение киносеансов.
+CREATE OR REPLACE FUNCTION sync_movie_show_sold_out(p_movie_show_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_status show_status_enum;
+    v_has_available BOOLEAN;
+BEGIN
+    SELECT status INTO v_status FROM movie_shows WHERE id = p_movie_show_id;
+    IF v_status IS NULL OR v_status NOT IN ('on_sale', 'sold_out') THEN
+        RETURN;
+    END IF;
+
+    SELECT EXISTS (
+        SELECT 1 FROM movie_show_tickets
+        WHERE movie_show_id = p_movie_show_id AND ticket_status = 'Available'
+    ) INTO v_has_available;
+
+    IF v_status = 'on_sale' AND NOT v_has_available THEN
+        UPDATE movie_shows SET status = 'sold_out' WHERE id = p_movie_show_id;
+    ELSIF v_status = 'sold_out' AND v_has_available THEN
+        UPDATE movie_shows SET status = 'on_sale' WHERE id = p_movie_show_id;
+    END IF;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE OR REPLACE FUNCTION update_movie_show_sold_out()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM sync_movie_show_sold_out(OLD.movie_show_id);
+    ELSE
+        PERFORM sync_movie_show_sold_out(NEW.movie_show_id);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE TRIGGER update_movie_show_status_when_tickets_changed
+AFTER INSERT OR DELETE OR UPDATE OF ticket_status ON tickets
+FOR EACH ROW
+EXECUTE FUNCTION update_movie_show_sold_out();
+
+-- Изменение схемы зала меняет число свободных мест на его сеансах
+CREATE OR REPLACE FUNCTION update_hall_shows_sold_out()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_hall_id UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        v_hall_id := OLD.hall_id;
+    ELSE
+        v_hall_id := NEW.hall_id;
+    END IF;
+
+    PERFORM sync_movie_show_sold_out(id)
+    FROM movie_shows
+    WHERE hall_id = v_hall_id AND status IN ('on_sale', 'sold_out');
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE TRIGGER update_hall_shows_status_when_seats_changed
+AFTER INSERT OR DELETE OR UPDATE OF service_state, service_from, service_until ON seats
+FOR EACH ROW
+EXECUTE FUNCTION update_hall_shows_sold_out();
+
+-- Настройки дистанции меняют число свободных мест сеанса
+CREATE TRIGGER update_movie_show_status_when_distancing_changed
+AFTER INSERT OR DELETE OR UPDATE ON movie_show_distancing
+FOR EACH ROW
+EXECUTE FUNCTION update_movie_show_sold_out();
+
+-- Бронирование и покупка при включённой дистанции. Блокировка настроек сеанса
+-- упорядочивает одновременные бронирования, чтобы они не обошли дистанцию и предел заполняемости.
+-- SECURITY DEFINER: у пользователей нет права на блокировку настроек.
+CREATE OR REPLACE FUNCTION check_distancing_booking()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM 1 FROM movie_show_distancing WHERE movie_show_id = NEW.movie_show_id FOR UPDATE;
+    IF NOT FOUND THEN
+        RETURN NEW;
+    END IF;
+
+    IF seat_distancing_blocked(NEW.movie_show_id, NEW.seat_id, NEW.user_id) THEN
+        RAISE EXCEPTION 'Место недоступно из-за соблюдения дистанции между зрителями';
+    END IF;
+
+    IF movie_show_at_capacity(NEW.movie_show_id) THEN
+        RAISE EXCEPTION 'Достигнута предельная заполняемость сеанса';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE TRIGGER check_distancing_booking_on_insert
+BEFORE INSERT ON tickets
+FOR EACH ROW
+WHEN (NEW.ticket_status IN ('Reserved', 'Purchased'))
+EXECUTE FUNCTION check_distancing_booking();
+
+CREATE TRIGGER check_distancing_booking_on_update
+BEFORE UPDATE OF ticket_status ON tickets
+FOR EACH ROW
+WHEN (NEW.ticket_status IN ('Reserved', 'Purchased') AND OLD.ticket_status NOT IN ('Reserved', 'Purchased'))
+EXECUTE FUNCTION check_distancing_booking();
+
+-- Сохранённые свободные билеты на место, выведенное из обслуживания, больше нельзя продать
+CREATE OR REPLACE FUNCTION release_out_of_service_tickets()
+RETURNS TRIGGER AS $$
+BEGIN
+    DELETE FROM tickets t
+    USING movie_shows ms
+    WHERE t.seat_id = NEW.id
+    AND t.ticket_status = 'Available'
+    AND ms.id = t.movie_show_id
+    AND seat_out_of_service(NEW.service_state, NEW.service_from, NEW.service_until, ms.occupied);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = public, pg_temp;
+
+CREATE TRIGGER release_tickets_when_seat_out_of_service
+AFTER UPDATE OF service_state, service_from, service_until ON seats
+FOR EACH ROW
+WHEN (NEW.service_state <> 'active')
+EXECUTE FUNCTION release_out_of_service_tickets();
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    movie_show_id UUID REFERENCES movie_shows(id),
+    message VARCHAR(1000) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_message CHECK (message ~ '\S')
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+
+-- Секретный токен для ссылки на личный календарь; гостю таблица недоступна
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token UUID NOT NULL UNIQUE DEFAULT uuid_generate_v4(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE OR REPLACE FUNCTION calendar_token_valid(p_token UUID)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (SELECT 1 FROM calendar_tokens WHERE token = p_token);
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public, pg_temp;
+
+-- Сеансы, на которые у владельца токена есть билеты, с перечнем его мест.
+-- Билеты, возвращённые из-за отмены сеанса, остаются, чтобы календарь получил отмену.
+-- Брони при отмене снимаются вместе с владельцем, поэтому такие сеансы находятся
+-- по уведомлению об отмене; места у них не указываются.
+-- Билеты, которые вернул сам пользователь, освобождаются и в выборку не попадают:
+-- событие пропадает из календаря без отмены, лента показывает только действующие брони.
+CREATE OR REPLACE FUNCTION user_calendar_shows(p_token UUID, p_since TIMESTAMPTZ)
+RETURNS TABLE (movie_show_id UUID, seats TEXT) AS $$
+    SELECT t.movie_show_id,
+        string_agg(format('ряд %s, место %s', s.row_number, s.seat_number), '; '
+            ORDER BY s.row_number, s.seat_number)
+    FROM calendar_tokens ct
+    JOIN tickets t ON t.user_id = ct.user_id
+    JOIN movie_shows ms ON ms.id = t.movie_show_id
+    JOIN seats s ON s.id = t.seat_id
+    WHERE ct.token = p_token
+    AND ms.start_time >= p_since
+    AND (
+        t.ticket_status IN ('Purchased', 'Reserved') OR
+        (t.ticket_status = 'Refunded' AND ms.status = 'cancelled')
+    )
+    GROUP BY t.movie_show_id
+    UNION
+    SELECT ms.id, NULL
+    FROM calendar_tokens ct
+    JOIN notifications n ON n.user_id = ct.user_id
+    JOIN movie_shows ms ON ms.id = n.movie_show_id
+    WHERE ct.token = p_token
+    AND ms.start_time >= p_since
+    AND ms.status = 'cancelled'
+    AND n.created_at >= ms.cancelled_at
+    AND NOT EXISTS (
+        SELECT 1 FROM tickets t
+        WHERE t.movie_show_id = ms.id AND t.user_id = ct.user_id
+    );
+$$ LANGUAGE sql STABLE SECURITY DEFINER SET search_path = public, pg_temp;
+
 CREATE TABLE IF NOT EXISTS reviews (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     user_id UUID REFERENCES users(id),
@@ -193,44 +1101,35 @@ CREATE TABLE IF NOT EXISTS reviews (
     CONSTRAINT valid_review_comment CHECK (review_comment IS NULL OR review_comment ~ '\S')
 );
 
+-- Средние оценки списка фильмов считаются одним запросом по movie_id = ANY(...)
+CREATE INDEX IF NOT EXISTS idx_reviews_movie ON reviews (movie_id);
+
+-- Билеты на сеанс не создаются заранее: свободные места вычисляются представлением movie_show_tickets
 CREATE OR REPLACE FUNCTION create_movie_show_with_tickets(
     p_movie_id UUID,
     p_hall_id UUID,
-    p_start_time TIMESTAMP,
-    p_language language_enum,
-    p_base_price DECIMAL(10,2))
+    p_start_time TIMESTAMPTZ,
+    p_language VARCHAR(50),
+    p_base_price DECIMAL(10,2),
+    p_status show_status_enum DEFAULT 'on_sale',
+    p_subtitle_language VARCHAR(50) DEFAULT NULL,
+    p_format_ids UUID[] DEFAULT '{}')
 RETURNS UUID AS $$
 DECLARE
     v_show_id UUID;
-    v_screen_modifier DECIMAL(5,2);
-    v_seat RECORD;
-    v_price DECIMAL(10,2);
 BEGIN
-    INSERT INTO movie_shows (id, movie_id, hall_id, start_time, language)
-    VALUES (uuid_generate_v4(), p_movie_id, p_hall_id, p_start_time, p_language)
+    INSERT INTO movie_shows (id, movie_id, hall_id, start_time, language, subtitle_language, status)
+    VALUES (uuid_generate_v4(), p_movie_id, p_hall_id, p_start_time, p_language, p_subtitle_language, p_status)
     RETURNING id INTO v_show_id;
 
-    SELECT st.price_modifier INTO v_screen_modifier
-    FROM screen_types st
-    JOIN halls h ON st.id = h.screen_type_id
-    WHERE h.id = p_hall_id;
+    INSERT INTO movie_show_prices (movie_show_id, base_price)
+    VALUES (v_show_id, p_base_price);
 
-    FOR v_seat IN (
-        SELECT s.id, st.price_modifier as seat_modifier
-        FROM seats s
-        JOIN seat_types st ON s.seat_type_id = st.id
-        WHERE s.hall_id = p_hall_id
-    ) LOOP
-        v_price := ROUND(p_base_price * v_screen_modifier * v_seat.seat_modifier, 2);
-        
-        INSERT INTO tickets (id, movie_show_id, seat_id, ticket_status, price)
-        VALUES (uuid_generate_v4(), v_show_id, v_seat.id, 'Available', v_price);
-    END LOOP;
+    INSERT INTO movie_show_formats (movie_show_id, format_id)
+    SELECT v_show_id, unnest(p_format_ids)
+    ON CONFLICT DO NOTHING;
 
     RETURN v_show_id;
-EXCEPTION
-    WHEN OTHERS THEN
-        RAISE EXCEPTION 'Ошибка при создании сеанса: %', SQLERRM;
 END;
 $$ LANGUAGE plpgsql;
 
@@ -1,15 +1,26 @@
 GRANT SELECT ON
     movies, 
     movie_shows, 
+    movie_show_prices,
+    movie_show_distancing,
+    movie_show_tickets,
+    movie_show_formats,
+    languages,
+    show_formats,
+    cinemas,
     halls, 
     tickets,
     genres, 
     screen_types, 
     seats,
+    hall_zones,
     seat_types, 
     reviews,
     users,
-    movies_genres
+    movies_genres,
+    people,
+    movie_credits,
+    movie_media
 TO cinema_test_guest;
 GRANT INSERT ON users TO cinema_test_guest;
 
@@ -19,6 +30,8 @@ GRANT cinema_test_guest TO cinema_test_user;
 GRANT UPDATE ON users TO cinema_test_user;
 GRANT UPDATE ON tickets TO cinema_test_user;
 GRANT INSERT, UPDATE, DELETE ON reviews TO cinema_test_user;
+GRANT SELECT ON notifications TO cinema_test_user;
+GRANT SELECT, INSERT, UPDATE ON calendar_tokens TO cinema_test_user;
 
 GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO cinema_test_admin;
 GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO cinema_test_admin;
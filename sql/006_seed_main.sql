@@ -19,10 +19,10 @@ INSERT INTO genres (name, description) VALUES
 ('Научный', 'Фильмы, которые исследуют научные концепции и идеи.');
 
 -- Вставка типов экранов
-INSERT INTO screen_types (name, description, price_modifier) VALUES
-('2D', 'Стандартный экран для показа фильмов в 2D.', 1.0),
-('3D', 'Экран для показа фильмов в 3D с использованием специальных очков.', 1.5),
-('IMAX', 'Большой экран с высоким разрешением для погружающего опыта.', 2.0);
+INSERT INTO screen_types (name, description, price_modifier, default_cleaning_minutes) VALUES
+('2D', 'Стандартный экран для показа фильмов в 2D.', 1.0, 10),
+('3D', 'Экран для показа фильмов в 3D с использованием специальных очков.', 1.5, 15),
+('IMAX', 'Большой экран с высоким разрешением для погружающего опыта.', 2.0, 20);
 
 -- Вставка типов мест
 INSERT INTO seat_types (name, description, price_modifier) VALUES
@@ -30,13 +30,18 @@ INSERT INTO seat_types (name, description, price_modifier) VALUES
 ('Премиум', 'Места с увеличенным пространством для ног и лучшим комфортом.', 1.5),
 ('VIP', 'Места в отдельном зале с повышенным уровнем сервиса и удобством.', 2.0),
 ('Детское', 'Места, предназначенные для детей, с безопасными и удобными сиденьями.', 0.8),
-('Люкс', 'Места с максимальным комфортом, включая возможность заказа еды и напитков.', 2.5);
+('Люкс', 'Места с максимальным комфортом, включая возможность заказа еды и напитков.', 2.5),
+('Сиденье для инвалидов', 'Место для кресла-коляски рядом с местом сопровождающего.', 1);
+
+-- Вставка кинотеатров
+INSERT INTO cinemas (name, address, time_zone, phone, email) VALUES
+('Кинотеатр Центральный', 'Москва, ул. Тверская, 1', 'Europe/Moscow', '+7 495 123-45-67', 'central@cinema.ru');
 
 -- Вставка залов
-INSERT INTO halls (screen_type_id, name, description) VALUES
-((SELECT id FROM screen_types WHERE name = '2D'), 'Зал 1', 'Небольшой зал с VIP местами.'),
-((SELECT id FROM screen_types WHERE name = '3D'), 'Зал 2', 'Зал для показа 3D фильмов.'),
-((SELECT id FROM screen_types WHERE name = 'IMAX'), 'Зал 3', 'Зал с IMAX экраном.');
+INSERT INTO halls (cinema_id, screen_type_id, name, description) VALUES
+((SELECT id FROM cinemas WHERE name = 'Кинотеатр Центральный'), (SELECT id FROM screen_types WHERE name = '2D'), 'Зал 1', 'Небольшой зал с VIP местами.'),
+((SELECT id FROM cinemas WHERE name = 'Кинотеатр Центральный'), (SELECT id FROM screen_types WHERE name = '3D'), 'Зал 2', 'Зал для показа 3D фильмов.'),
+((SELECT id FROM cinemas WHERE name = 'Кинотеатр Центральный'), (SELECT id FROM screen_types WHERE name = 'IMAX'), 'Зал 3', 'Зал с IMAX экраном.');
 
 -- Вставка мест
 -- Вставка мест в зал 1
@@ -67,6 +72,17 @@ BEGIN
             (hall_id, (SELECT id FROM seat_types WHERE name = 'Стандарт'), row_num, seat_num);
         END LOOP;
     END LOOP;
+
+    -- Крайние места последнего ряда - для колясок, соседние с ними - для сопровождающих
+    UPDATE seats s
+    SET seat_type_id = (SELECT id FROM seat_types WHERE name = 'Сиденье для инвалидов'),
+        wheelchair_space = TRUE,
+        companion_seat_id = (
+            SELECT c.id FROM seats c
+            WHERE c.hall_id = s.hall_id AND c.row_number = s.row_number
+            AND c.seat_number = CASE s.seat_number WHEN 1 THEN 2 ELSE 9 END
+        )
+    WHERE s.hall_id = (SELECT id FROM halls WHERE name = 'Зал 2') AND s.row_number = 10 AND s.seat_number IN (1, 10);
 END $$;
 
 -- Вставка мест в зал 3
@@ -142,30 +158,28 @@ INSERT INTO movies_genres (movie_id, genre_id) VALUES
 ((SELECT id FROM movies WHERE title = 'Форрест Гамп'), (SELECT id FROM genres WHERE name = 'Драма')),
 ((SELECT id FROM movies WHERE title = 'Форрест Гамп'), (SELECT id FROM genres WHERE name = 'Комедия'));
 
--- Вставка данных о билетах
-DO $$
-DECLARE
-    show_id UUID;
-    seat_id UUID;
-    price_modifier_seat DECIMAL;
-    price_modifier_screen DECIMAL;
-    ticket_price DECIMAL;
-BEGIN
-    FOR show_id IN SELECT id FROM movie_shows LOOP
-        FOR seat_id IN SELECT id FROM seats LOOP
-            SELECT price_modifier INTO price_modifier_seat
-            FROM seat_types
-            WHERE id = (SELECT seat_type_id FROM seats WHERE id = seat_id);
-            
-            SELECT price_modifier INTO price_modifier_screen
-            FROM screen_types
-            WHERE id = (SELECT screen_type_id FROM halls WHERE id = (SELECT hall_id FROM movie_shows WHERE id = show_id));
-            
-            ticket_price := 300 * price_modifier_seat * price_modifier_screen;
-            
-            INSERT INTO tickets (movie_show_id, seat_id, user_id, ticket_status, price)
-            VALUES (show_id, seat_id, NULL, 'Available', ticket_price);
-        END LOOP;
-    END LOOP;
-END $$;
+INSERT INTO people (name, birth_date) VALUES
+('Кристофер Нолан', '1970-07-30'),
+('Кристиан Бейл', '1974-01-30'),
+('Хит Леджер', '1979-04-04'),
+('Леонардо ДиКаприо', '1974-11-11'),
+('Джеймс Кэмерон', '1954-08-16'),
+('Роберт Земекис', '1951-05-14'),
+('Том Хэнкс', '1956-07-09');
+
+INSERT INTO movie_credits (movie_id, person_id, role, character_name, billing_order) VALUES
+((SELECT id FROM movies WHERE title = 'Темный рыцарь'), (SELECT id FROM people WHERE name = 'Кристофер Нолан'), 'director', NULL, 0),
+((SELECT id FROM movies WHERE title = 'Темный рыцарь'), (SELECT id FROM people WHERE name = 'Кристофер Нолан'), 'writer', NULL, 0),
+((SELECT id FROM movies WHERE title = 'Темный рыцарь'), (SELECT id FROM people WHERE name = 'Кристиан Бейл'), 'actor', 'Брюс Уэйн / Бэтмен', 1),
+((SELECT id FROM movies WHERE title = 'Темный рыцарь'), (SELECT id FROM people WHERE name = 'Хит Леджер'), 'actor', 'Джокер', 2),
+((SELECT id FROM movies WHERE title = 'Начало'), (SELECT id FROM people WHERE name = 'Кристофер Нолан'), 'director', NULL, 0),
+((SELECT id FROM movies WHERE title = 'Начало'), (SELECT id FROM people WHERE name = 'Кристофер Нолан'), 'writer', NULL, 0),
+((SELECT id FROM movies WHERE title = 'Начало'), (SELECT id FROM people WHERE name = 'Леонардо ДиКаприо'), 'actor', 'Кобб', 1),
+((SELECT id FROM movies WHERE title = 'Титаник'), (SELECT id FROM people WHERE name = 'Джеймс Кэмерон'), 'director', NULL, 0),
+((SELECT id FROM movies WHERE title = 'Титаник'), (SELECT id FROM people WHERE name = 'Леонардо ДиКаприо'), 'actor', 'Джек Доусон', 1),
+((SELECT id FROM movies WHERE title = 'Форрест Гамп'), (SELECT id FROM people WHERE name = 'Роберт Земекис'), 'director', NULL, 0),
+((SELECT id FROM movies WHERE title = 'Форрест Гамп'), (SELECT id FROM people WHERE name = 'Том Хэнкс'), 'actor', 'Форрест Гамп', 1);
 
+-- Базовые цены сеансов: свободные билеты не хранятся, а вычисляются представлением movie_show_tickets
+INSERT INTO movie_show_prices (movie_show_id, base_price)
+SELECT id, 300 FROM movie_shows;
@@ -2,13 +2,56 @@
 DROP TRIGGER IF EXISTS update_movie_revenue_when_ticket_status_changed ON tickets;
 DROP TRIGGER IF EXISTS check_movie_show_on_insert ON movie_shows;
 DROP TRIGGER IF EXISTS check_movie_show_on_update ON movie_shows;
+DROP TRIGGER IF EXISTS bump_movie_show_revision_on_update ON movie_shows;
+DROP TRIGGER IF EXISTS refresh_movie_shows_occupied_on_movie_update ON movies;
+DROP TRIGGER IF EXISTS refresh_movie_shows_occupied_on_hall_update ON halls;
+DROP TRIGGER IF EXISTS set_hall_time_zone_trigger ON halls;
+DROP TRIGGER IF EXISTS propagate_cinema_time_zone_trigger ON cinemas;
+DROP TRIGGER IF EXISTS refresh_movie_shows_occupied_on_screen_type_update ON screen_types;
+DROP TRIGGER IF EXISTS check_movie_show_status_on_update ON movie_shows;
+DROP TRIGGER IF EXISTS update_movie_show_status_when_tickets_changed ON tickets;
+DROP TRIGGER IF EXISTS update_hall_shows_status_when_seats_changed ON seats;
+DROP TRIGGER IF EXISTS release_tickets_when_seat_out_of_service ON seats;
+DROP TRIGGER IF EXISTS release_ticket_when_available ON tickets;
+DROP TRIGGER IF EXISTS clear_ticket_hold_when_status_changed ON tickets;
+DROP TRIGGER IF EXISTS check_companion_seat_trigger ON seats;
+DROP TRIGGER IF EXISTS check_seat_zone_trigger ON seats;
+DROP TRIGGER IF EXISTS update_movie_show_status_when_distancing_changed ON movie_show_distancing;
+DROP TRIGGER IF EXISTS check_distancing_booking_on_insert ON tickets;
+DROP TRIGGER IF EXISTS check_distancing_booking_on_update ON tickets;
 
 DROP INDEX IF EXISTS idx_users_email;
 
 -- Удаляем функции
 DROP FUNCTION IF EXISTS update_box_office_revenue();
+DROP FUNCTION IF EXISTS search_movies(TEXT, BOOLEAN);
+DROP FUNCTION IF EXISTS search_genres(TEXT, BOOLEAN);
 DROP FUNCTION IF EXISTS check_movie_show_conflict();
+DROP FUNCTION IF EXISTS refresh_movie_shows_occupied();
+DROP FUNCTION IF EXISTS bump_movie_show_revision();
+DROP FUNCTION IF EXISTS calendar_token_valid(UUID);
+DROP FUNCTION IF EXISTS user_calendar_shows(UUID, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS movie_show_occupied(UUID, UUID, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS hall_cleaning_interval(UUID);
 DROP FUNCTION IF EXISTS create_movie_show_with_tickets;
+DROP FUNCTION IF EXISTS check_movie_show_status_transition();
+DROP FUNCTION IF EXISTS show_status_transition_allowed(show_status_enum, show_status_enum);
+DROP FUNCTION IF EXISTS refresh_movie_show_statuses(TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS update_movie_show_sold_out();
+DROP FUNCTION IF EXISTS update_hall_shows_sold_out();
+DROP FUNCTION IF EXISTS sync_movie_show_sold_out(UUID);
+DROP FUNCTION IF EXISTS materialize_ticket(UUID);
+DROP FUNCTION IF EXISTS release_available_ticket();
+DROP FUNCTION IF EXISTS release_out_of_service_tickets();
+DROP FUNCTION IF EXISTS clear_ticket_hold();
+DROP FUNCTION IF EXISTS release_expired_seat_holds(TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS check_companion_seat();
+DROP FUNCTION IF EXISTS check_seat_zone();
+DROP FUNCTION IF EXISTS check_distancing_booking();
+DROP FUNCTION IF EXISTS accessible_seat_held(UUID, UUID, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS set_hall_time_zone();
+DROP FUNCTION IF EXISTS propagate_cinema_time_zone();
+DROP FUNCTION IF EXISTS admin_can_manage_cinema(UUID, UUID);
 
 DROP PROCEDURE update_movie(
     UUID,
@@ -20,22 +63,50 @@ DROP PROCEDURE update_movie(
     UUID[]
 );
 
+DROP VIEW IF EXISTS movie_show_tickets;
+DROP FUNCTION IF EXISTS seat_distancing_blocked(UUID, UUID, UUID);
+DROP FUNCTION IF EXISTS movie_show_at_capacity(UUID);
+DROP FUNCTION IF EXISTS movie_show_max_occupancy(UUID);
+DROP FUNCTION IF EXISTS virtual_ticket_id(UUID, UUID);
+DROP FUNCTION IF EXISTS seat_out_of_service(seat_service_state_enum, TIMESTAMPTZ, TIMESTAMPTZ, TSTZRANGE);
+
 -- Удаляем таблицы
+DROP TABLE IF EXISTS movie_show_prices CASCADE;
+DROP TABLE IF EXISTS movie_show_distancing CASCADE;
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS calendar_tokens CASCADE;
 DROP TABLE IF EXISTS tickets CASCADE;
 DROP TABLE IF EXISTS reviews CASCADE;
+DROP TABLE IF EXISTS movie_show_formats CASCADE;
+DROP TABLE IF EXISTS hall_maintenance_windows CASCADE;
 DROP TABLE IF EXISTS movie_shows CASCADE;
+DROP TABLE IF EXISTS show_formats CASCADE;
+DROP TABLE IF EXISTS languages CASCADE;
 DROP TABLE IF EXISTS seats CASCADE;
+DROP TABLE IF EXISTS hall_zones CASCADE;
 DROP TABLE IF EXISTS movies_genres CASCADE;
+DROP TABLE IF EXISTS movie_media CASCADE;
+DROP TABLE IF EXISTS movie_credits CASCADE;
+DROP TABLE IF EXISTS people CASCADE;
 DROP TABLE IF EXISTS halls CASCADE;
+DROP TABLE IF EXISTS cinema_admins CASCADE;
+DROP TABLE IF EXISTS cinemas CASCADE;
 DROP TABLE IF EXISTS screen_types CASCADE;
 DROP TABLE IF EXISTS users CASCADE;
 DROP TABLE IF EXISTS genres CASCADE;
 DROP TABLE IF EXISTS movies CASCADE;
 DROP TABLE IF EXISTS seat_types CASCADE;
 
+DROP FUNCTION IF EXISTS is_valid_time_zone(TEXT);
+
 -- Удаляем типы
 DROP TYPE IF EXISTS ticket_status_enum;
-DROP TYPE IF EXISTS language_enum;
+DROP TYPE IF EXISTS show_status_enum;
+DROP TYPE IF EXISTS seat_service_state_enum;
+DROP TYPE IF EXISTS credit_role_enum;
+DROP TYPE IF EXISTS media_kind_enum;
 
 -- Удаляем расширение
-DROP EXTENSION IF EXISTS "uuid-ossp";
\ No newline at end of file
+DROP EXTENSION IF EXISTS "uuid-ossp";
+DROP EXTENSION IF EXISTS btree_gist;
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -7,6 +7,8 @@ REVOKE CREATE, USAGE ON SCHEMA public FROM cinema_admin;
 REVOKE UPDATE ON users FROM cinema_user;
 REVOKE UPDATE ON tickets FROM cinema_user;
 REVOKE INSERT, UPDATE, DELETE ON reviews FROM cinema_user;
+REVOKE SELECT ON notifications FROM cinema_user;
+REVOKE SELECT, INSERT, UPDATE ON calendar_tokens FROM cinema_user;
 
 -- Revoke cinema_guest role from cinema_user
 REVOKE cinema_guest FROM cinema_user;
@@ -15,13 +17,24 @@ REVOKE cinema_guest FROM cinema_user;
 REVOKE SELECT ON 
     movies, 
     movie_shows, 
+    movie_show_prices,
+    movie_show_distancing,
+    movie_show_tickets,
+    movie_show_formats,
+    languages,
+    show_formats,
+    cinemas,
     halls, 
     tickets,
     genres, 
     screen_types, 
     seats,
+    hall_zones,
     seat_types, 
     reviews,
-    users
+    users,
+    people,
+    movie_credits,
+    movie_media
 FROM cinema_guest;
 REVOKE INSERT ON users FROM cinema_guest;
\ No newline at end of file
@@ -11,7 +11,8 @@ DECLARE
 BEGIN
     SELECT id INTO show_id FROM movie_shows LIMIT 1;
     SELECT id INTO new_user_id FROM users LIMIT 1;
-    SELECT id, price INTO ticket_id, ticket_price FROM tickets WHERE movie_show_id = show_id LIMIT 1;
+    SELECT id, price INTO ticket_id, ticket_price FROM movie_show_tickets WHERE movie_show_id = show_id LIMIT 1;
+    PERFORM materialize_ticket(ticket_id);
     
     -- Запоминаем начальные сборы
     SELECT box_office_revenue INTO initial_revenue FROM movies WHERE id = (SELECT movie_id FROM movie_shows WHERE id = show_id);
@@ -28,6 +29,7 @@ BEGIN
     UPDATE tickets SET ticket_status = 'Available'::ticket_status_enum, user_id = NULL WHERE id = ticket_id;
     SELECT box_office_revenue INTO new_revenue FROM movies WHERE id = (SELECT movie_id FROM movie_shows WHERE id = show_id);
     RAISE NOTICE 'Тест 2: Purchased → Available, Revenue изменился с % на % (ожидалось -%)', initial_revenue, new_revenue, ticket_price;
+    PERFORM materialize_ticket(ticket_id); -- освобождённый билет удаляется из tickets
 
     -- Запоминаем новый доход
     initial_revenue := new_revenue;
@@ -60,6 +62,7 @@ BEGIN
     UPDATE tickets SET ticket_status = 'Available'::ticket_status_enum, user_id = NULL WHERE id = ticket_id;
     SELECT box_office_revenue INTO new_revenue FROM movies WHERE id = (SELECT movie_id FROM movie_shows WHERE id = show_id);
     RAISE NOTICE 'Тест 6: Reserved → Available, Revenue изменился с % на % (ожидалось 0)', initial_revenue, new_revenue;
+    PERFORM materialize_ticket(ticket_id);
 
 	-- Запоминаем новый доход
     initial_revenue := new_revenue;
@@ -85,7 +88,8 @@ DECLARE
 BEGIN
     SELECT id INTO show_id FROM movie_shows LIMIT 1;
     SELECT id INTO new_user_id FROM users LIMIT 1;
-    SELECT id, price INTO ticket_id, ticket_price FROM tickets WHERE movie_show_id = show_id LIMIT 1;
+    SELECT id, price INTO ticket_id, ticket_price FROM movie_show_tickets WHERE movie_show_id = show_id LIMIT 1;
+    PERFORM materialize_ticket(ticket_id);
     
     -- Запоминаем начальные сборы
     SELECT box_office_revenue INTO initial_revenue FROM movies WHERE id = (SELECT movie_id FROM movie_shows WHERE id = show_id);
@@ -114,6 +118,9 @@ END $$;
 
 SET ROLE cinema_admin;
 
+INSERT INTO cinemas (name, address) VALUES ('Test Cinema', 'Test Address')
+ON CONFLICT (name) DO NOTHING;
+
 DO $$
 DECLARE
     v_hall_id UUID;
@@ -122,8 +129,8 @@ DECLARE
     v_new_show_id UUID;
     v_cleanup_time INTERVAL := INTERVAL '10 minutes';
     v_show_duration INTERVAL := INTERVAL '120 minutes';
-    v_test_start_time TIMESTAMP;
-    v_test_end_time TIMESTAMP;
+    v_test_start_time TIMESTAMPTZ;
+    v_test_end_time TIMESTAMPTZ;
     v_screen_type_id UUID;
     v_seat_type_id UUID;
     v_base_user_id UUID;
@@ -133,8 +140,8 @@ BEGIN
     VALUES ('Standard', 'Standard screen', 1.0)
     RETURNING id INTO v_screen_type_id;
     
-    INSERT INTO halls (screen_type_id, name, description)
-    VALUES (v_screen_type_id, 'Test Hall', 'Test Hall Description')
+    INSERT INTO halls (cinema_id, screen_type_id, name, description)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), v_screen_type_id, 'Test Hall', 'Test Hall Description')
     RETURNING id INTO v_hall_id;
     
     INSERT INTO seat_types (name, description, price_modifier)
@@ -159,8 +166,8 @@ BEGIN
     SELECT create_movie_show_with_tickets(
         v_movie_id, 
         v_hall_id, 
-         '2023-01-01:12:12:12'::timestamp, 
-        'English'::language_enum, 
+         '2023-01-01:12:12:12'::timestamptz, 
+        'English', 
         300.00
     ) INTO v_existing_show_id;
     
@@ -174,7 +181,7 @@ BEGIN
                           (SELECT duration FROM movies WHERE id = v_movie_id) + v_cleanup_time;
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum)
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English')
         RETURNING id INTO v_new_show_id;
         
         RAISE NOTICE 'Тест 1: Начало сразу после уборки - УСПЕХ (сеанс %)', v_new_show_id;
@@ -189,7 +196,7 @@ BEGIN
                           (SELECT duration FROM movies WHERE id = v_movie_id) + v_cleanup_time;
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum)
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English')
         RETURNING id INTO v_new_show_id;
         
         RAISE NOTICE 'Тест 2: Конец уборки = начало другого - УСПЕХ (сеанс %)', v_new_show_id;
@@ -205,10 +212,10 @@ BEGIN
         v_test_start_time := (SELECT start_time FROM movie_shows WHERE id = v_existing_show_id) + INTERVAL '30 minutes';
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum);
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English');
         
         RAISE NOTICE 'Тест 3: Полное вложение интервала - НЕПРОЙДЕН (ожидалась ошибка)';
-    EXCEPTION WHEN OTHERS THEN
+    EXCEPTION WHEN exclusion_violation THEN
         RAISE NOTICE 'Тест 3: Полное вложение интервала - ОШИБКА (ожидаемо): %', SQLERRM;
     END;
     
@@ -217,10 +224,10 @@ BEGIN
         v_test_start_time := (SELECT start_time FROM movie_shows WHERE id = v_existing_show_id) - INTERVAL '30 minutes';
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum);
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English');
         
         RAISE NOTICE 'Тест 4: Частичное перекрытие (левый край) - НЕПРОЙДЕН (ожидалась ошибка)';
-    EXCEPTION WHEN OTHERS THEN
+    EXCEPTION WHEN exclusion_violation THEN
         RAISE NOTICE 'Тест 4: Частичное перекрытие (левый край) - ОШИБКА (ожидаемо): %', SQLERRM;
     END;
     
@@ -230,10 +237,10 @@ BEGIN
                          (SELECT duration FROM movies WHERE id = v_movie_id) - INTERVAL '30 minutes';
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum);
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English');
         
         RAISE NOTICE 'Тест 5: Частичное перекрытие (правый край) - НЕПРОЙДЕН (ожидалась ошибка)';
-    EXCEPTION WHEN OTHERS THEN
+    EXCEPTION WHEN exclusion_violation THEN
         RAISE NOTICE 'Тест 5: Частичное перекрытие (правый край) - ОШИБКА (ожидаемо): %', SQLERRM;
     END;
     
@@ -242,10 +249,10 @@ BEGIN
         v_test_start_time := (SELECT start_time FROM movie_shows WHERE id = v_existing_show_id);
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum);
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English');
         
         RAISE NOTICE 'Тест 6: Полное совпадение интервалов - НЕПРОЙДЕН (ожидалась ошибка)';
-    EXCEPTION WHEN OTHERS THEN
+    EXCEPTION WHEN exclusion_violation THEN
         RAISE NOTICE 'Тест 6: Полное совпадение интервалов - ОШИБКА (ожидаемо): %', SQLERRM;
     END;
     
@@ -254,10 +261,10 @@ BEGIN
         v_test_start_time := (SELECT start_time FROM movie_shows WHERE id = v_existing_show_id) - INTERVAL '30 minutes';
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum);
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English');
         
         RAISE NOTICE 'Тест 7: Полное перекрытие интервалов - НЕПРОЙДЕН (ожидалась ошибка)';
-    EXCEPTION WHEN OTHERS THEN
+    EXCEPTION WHEN exclusion_violation THEN
         RAISE NOTICE 'Тест 7: Полное перекрытие интервалов - ОШИБКА (ожидаемо): %', SQLERRM;
     END;
     
@@ -269,7 +276,7 @@ BEGIN
                           (SELECT duration FROM movies WHERE id = v_movie_id) + v_cleanup_time + INTERVAL '1 hour';
         
         INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
-        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English'::language_enum)
+        VALUES (v_movie_id, v_hall_id, v_test_start_time, 'English')
         RETURNING id INTO v_new_show_id;
         
         RAISE NOTICE 'Тест 8: Раздельные интервалы - УСПЕХ (сеанс %)', v_new_show_id;
@@ -280,7 +287,7 @@ BEGIN
     
     -- 9. Изменение невременных атрибутов (успех)
     BEGIN
-        UPDATE movie_shows SET language = 'Русский'::language_enum WHERE id = v_existing_show_id;
+        UPDATE movie_shows SET language = 'Русский' WHERE id = v_existing_show_id;
         RAISE NOTICE 'Тест 9: Изменение невременных атрибутов - УСПЕХ';
     EXCEPTION WHEN OTHERS THEN
         RAISE NOTICE 'Тест 9: Изменение невременных атрибутов - ОШИБКА: %', SQLERRM;
@@ -306,7 +313,7 @@ DECLARE
     v_test_show_id UUID;
     v_cleanup_time INTERVAL := INTERVAL '10 minutes';
     v_show_duration INTERVAL := INTERVAL '120 minutes';
-    v_test_start_time TIMESTAMP;
+    v_test_start_time TIMESTAMPTZ;
     v_screen_type_id UUID;
     v_seat_type_id UUID;
     v_base_user_id UUID;
@@ -316,8 +323,8 @@ BEGIN
     VALUES ('Standard', 'Standard screen', 1.0)
     RETURNING id INTO v_screen_type_id;
     
-    INSERT INTO halls (screen_type_id, name, description)
-    VALUES (v_screen_type_id, 'Test Hall', 'Test Hall Description')
+    INSERT INTO halls (cinema_id, screen_type_id, name, description)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), v_screen_type_id, 'Test Hall', 'Test Hall Description')
     RETURNING id INTO v_hall_id;
     
     INSERT INTO seat_types (name, description, price_modifier)
@@ -342,8 +349,8 @@ BEGIN
     SELECT create_movie_show_with_tickets(
         v_movie_id, 
         v_hall_id, 
-        '2023-01-01:12:12:12'::timestamp, 
-        'English'::language_enum, 
+        '2023-01-01:12:12:12'::timestamptz, 
+        'English', 
         300.00
     ) INTO v_base_show_id;
     
@@ -351,8 +358,8 @@ BEGIN
     SELECT create_movie_show_with_tickets(
         v_movie_id, 
         v_hall_id, 
-        '2023-01-01:12:12:12'::timestamp + INTERVAL '5 hours', 
-        'English'::language_enum, 
+        '2023-01-01:12:12:12'::timestamptz + INTERVAL '5 hours', 
+        'English', 
         300.00
     ) INTO v_test_show_id;
     
@@ -475,7 +482,7 @@ BEGIN
     -- 9. Обновление: изменение невременных атрибутов (успех)
     BEGIN
         UPDATE movie_shows 
-        SET language = 'Русский'::language_enum 
+        SET language = 'Русский' 
         WHERE id = v_test_show_id;
         
         RAISE NOTICE 'Тест 9: Обновление - изменение невременных атрибутов - УСПЕХ';
@@ -519,8 +526,8 @@ BEGIN
     VALUES ('Standard', 'Standard screen', 1.2)
     RETURNING id INTO v_screen_modifier;
     
-    INSERT INTO halls (screen_type_id, name, description)
-    VALUES (v_screen_modifier, 'Test Hall', 'Test Hall Description')
+    INSERT INTO halls (cinema_id, screen_type_id, name, description)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), v_screen_modifier, 'Test Hall', 'Test Hall Description')
     RETURNING id INTO v_hall_id;
     
     INSERT INTO seat_types (name, description, price_modifier)
@@ -543,13 +550,13 @@ BEGIN
         v_movie_id, 
         v_hall_id, 
         NOW() + INTERVAL '1 hour', 
-        'Russian'::language_enum, 
+        'Русский', 
         300.00
     ) INTO v_show_id;
     
     -- Проверяем результаты
-    SELECT COUNT(*) INTO v_tickets_count FROM tickets WHERE movie_show_id = v_show_id;
-    SELECT price INTO v_actual_price FROM tickets WHERE movie_show_id = v_show_id LIMIT 1;
+    SELECT COUNT(*) INTO v_tickets_count FROM movie_show_tickets WHERE movie_show_id = v_show_id;
+    SELECT price INTO v_actual_price FROM movie_show_tickets WHERE movie_show_id = v_show_id LIMIT 1;
     v_expected_price := ROUND(300.00 * 1.2 * 1.0, 2); -- base_price * screen_mod * seat_mod
     
     RAISE NOTICE 'ID сеанса: %', v_show_id;
@@ -577,12 +584,12 @@ BEGIN
         v_movie_id, 
         v_hall_id, 
         NOW() + INTERVAL '2 hours', 
-        'English'::language_enum, 
+        'English', 
         350.00
     ) INTO v_show_id;
     
     -- Проверяем результаты
-    SELECT COUNT(*) INTO v_tickets_count FROM tickets WHERE movie_show_id = v_show_id;
+    SELECT COUNT(*) INTO v_tickets_count FROM movie_show_tickets WHERE movie_show_id = v_show_id;
     RAISE NOTICE 'Создано билетов: % (ожидалось 25)', v_tickets_count;
     
     -- Очистка
@@ -608,13 +615,13 @@ BEGIN
         v_movie_id, 
         v_hall_id, 
         NOW() + INTERVAL '3 hours', 
-        'Russian'::language_enum, 
+        'Русский', 
         400.00
     ) INTO v_show_id;
     
     -- Проверяем разные цены
     SELECT price INTO v_actual_price 
-    FROM tickets 
+    FROM movie_show_tickets 
     WHERE movie_show_id = v_show_id 
     AND seat_id IN (SELECT id FROM seats WHERE seat_type_id = (SELECT id FROM seat_types WHERE name = 'Standard'))
     LIMIT 1;
@@ -623,7 +630,7 @@ BEGIN
     RAISE NOTICE 'Цена стандартного места: % (ожидалось %)', v_actual_price, v_expected_price;
     
     SELECT price INTO v_actual_price 
-    FROM tickets 
+    FROM movie_show_tickets 
     WHERE movie_show_id = v_show_id 
     AND seat_id IN (SELECT id FROM seats WHERE seat_type_id = (SELECT id FROM seat_types WHERE name = 'VIP'))
     LIMIT 1;
@@ -646,7 +653,7 @@ BEGIN
             gen_random_uuid(), -- случайный UUID
             v_hall_id, 
             NOW() + INTERVAL '4 hours', 
-            'English'::language_enum, 
+            'English', 
             300.00
         ) INTO v_show_id;
         
@@ -661,7 +668,7 @@ BEGIN
             v_movie_id, 
             gen_random_uuid(), -- случайный UUID
             NOW() + INTERVAL '4 hours', 
-            'English'::language_enum, 
+            'English', 
             300.00
         ) INTO v_show_id;
         
@@ -676,7 +683,7 @@ BEGIN
             v_movie_id, 
             v_hall_id, 
             NOW() + INTERVAL '4 hours', 
-            'English'::language_enum, 
+            'English', 
             -10.00
         ) INTO v_show_id;
         
@@ -691,7 +698,7 @@ BEGIN
             v_movie_id, 
             v_hall_id, 
             NOW() + INTERVAL '4 hours', 
-            'English'::language_enum, 
+            'English', 
             0.00
         ) INTO v_show_id;
         
@@ -707,20 +714,20 @@ BEGIN
     -- 5.1 Зал без мест
     BEGIN
         -- Создаем пустой зал
-        INSERT INTO halls (screen_type_id, name, description)
-        VALUES (v_screen_modifier, 'Empty Hall', 'Empty Hall Description')
+        INSERT INTO halls (cinema_id, screen_type_id, name, description)
+        VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), v_screen_modifier, 'Empty Hall', 'Empty Hall Description')
         RETURNING id INTO v_hall_id;
         
         SELECT create_movie_show_with_tickets(
             v_movie_id, 
             v_hall_id, 
             NOW() + INTERVAL '5 hours', 
-            'English'::language_enum, 
+            'English', 
             300.00
         ) INTO v_show_id;
         
         -- Проверяем
-        SELECT COUNT(*) INTO v_tickets_count FROM tickets WHERE movie_show_id = v_show_id;
+        SELECT COUNT(*) INTO v_tickets_count FROM movie_show_tickets WHERE movie_show_id = v_show_id;
         RAISE NOTICE 'Тест 5.1: Зал без мест - сеанс создан с ID: %', v_show_id;
         RAISE NOTICE 'Создано билетов: % (ожидалось 0)', v_tickets_count;
         
@@ -738,4 +745,569 @@ BEGIN
     DELETE FROM movies WHERE id = v_movie_id;
 END $$;
 
-RESET ROLE;
\ No newline at end of file
+RESET ROLE;
+
+SET ROLE cinema_admin;
+
+-- Языки сеансов хранятся в справочнике
+DO $$
+DECLARE
+    v_movie_id UUID;
+    v_hall_id UUID;
+    v_show_id UUID;
+    v_language VARCHAR(50);
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Language Test Movie', '02:00:00', 'Test Description', 12, '2023-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO halls (cinema_id, name, description)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), 'Language Test Hall', 'Test Hall Description')
+    RETURNING id INTO v_hall_id;
+
+    BEGIN
+        INSERT INTO movie_shows (movie_id, hall_id, start_time, language)
+        VALUES (v_movie_id, v_hall_id, NOW() + INTERVAL '1 day', 'Klingon');
+        RAISE NOTICE 'Тест 6.1: Неизвестный язык - ОШИБКА: сеанс создан';
+    EXCEPTION WHEN foreign_key_violation THEN
+        RAISE NOTICE 'Тест 6.1: Неизвестный язык - OK';
+    END;
+
+    INSERT INTO languages (name) VALUES ('日本語');
+    SELECT create_movie_show_with_tickets(
+        v_movie_id,
+        v_hall_id,
+        NOW() + INTERVAL '1 day',
+        '日本語',
+        300.00,
+        'on_sale',
+        'Русский',
+        ARRAY(SELECT id FROM show_formats WHERE name IN ('2D', 'Оригинал'))
+    ) INTO v_show_id;
+
+    UPDATE languages SET name = 'Японский' WHERE name = '日本語';
+    SELECT language INTO v_language FROM movie_shows WHERE id = v_show_id;
+    RAISE NOTICE 'Тест 6.2: Переименование языка - % (ожидалось Японский)', v_language;
+
+    BEGIN
+        DELETE FROM languages WHERE name = 'Японский';
+        RAISE NOTICE 'Тест 6.3: Удаление используемого языка - ОШИБКА: язык удалён';
+    EXCEPTION WHEN foreign_key_violation THEN
+        RAISE NOTICE 'Тест 6.3: Удаление используемого языка - OK';
+    END;
+
+    DELETE FROM movie_shows WHERE id = v_show_id;
+    DELETE FROM languages WHERE name = 'Японский';
+    DELETE FROM halls WHERE id = v_hall_id;
+    DELETE FROM movies WHERE id = v_movie_id;
+END $$;
+
+-- Кинотеатры: имена залов уникальны в пределах кинотеатра, пояс зала следует за кинотеатром
+DO $$
+DECLARE
+    v_other_cinema_id UUID;
+    v_hall_id UUID;
+    v_admin_id UUID;
+    v_time_zone VARCHAR(64);
+BEGIN
+    INSERT INTO cinemas (name, address, time_zone)
+    VALUES ('Other Test Cinema', 'Other Test Address', 'Asia/Vladivostok')
+    RETURNING id INTO v_other_cinema_id;
+
+    INSERT INTO halls (cinema_id, name) VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), 'Cinema Test Hall');
+    INSERT INTO halls (cinema_id, name, time_zone) VALUES (v_other_cinema_id, 'Cinema Test Hall', 'UTC')
+    RETURNING id INTO v_hall_id;
+    RAISE NOTICE 'Тест 7.1: Одинаковые имена залов в разных кинотеатрах - OK';
+
+    BEGIN
+        INSERT INTO halls (cinema_id, name) VALUES (v_other_cinema_id, 'Cinema Test Hall');
+        RAISE NOTICE 'Тест 7.2: Повтор имени зала в кинотеатре - ОШИБКА: зал создан';
+    EXCEPTION WHEN unique_violation THEN
+        RAISE NOTICE 'Тест 7.2: Повтор имени зала в кинотеатре - OK';
+    END;
+
+    SELECT time_zone INTO v_time_zone FROM halls WHERE id = v_hall_id;
+    RAISE NOTICE 'Тест 7.3: Пояс нового зала - % (ожидалось Asia/Vladivostok)', v_time_zone;
+
+    UPDATE cinemas SET time_zone = 'Europe/Kaliningrad' WHERE id = v_other_cinema_id;
+    SELECT time_zone INTO v_time_zone FROM halls WHERE id = v_hall_id;
+    RAISE NOTICE 'Тест 7.4: Смена пояса кинотеатра - % (ожидалось Europe/Kaliningrad)', v_time_zone;
+
+    SELECT id INTO v_admin_id FROM users WHERE is_admin LIMIT 1;
+    INSERT INTO cinema_admins (user_id, cinema_id) VALUES (v_admin_id, v_other_cinema_id);
+    RAISE NOTICE 'Тест 7.5: Доступ к своему кинотеатру - % (ожидалось true), к чужому - % (ожидалось false)',
+        admin_can_manage_cinema(v_admin_id, v_other_cinema_id),
+        admin_can_manage_cinema(v_admin_id, (SELECT id FROM cinemas WHERE name = 'Test Cinema'));
+
+    DELETE FROM cinema_admins WHERE cinema_id = v_other_cinema_id;
+    DELETE FROM halls WHERE name = 'Cinema Test Hall';
+    DELETE FROM cinemas WHERE id = v_other_cinema_id;
+END $$;
+
+-- Места вне обслуживания: не продаются на сеансы, пересекающиеся с периодом блокировки
+DO $$
+DECLARE
+    v_movie_id UUID;
+    v_hall_id UUID;
+    v_show_id UUID;
+    v_seat_ids UUID[];
+    v_available INT;
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Seat Service Test Movie', '02:00:00', 'Test Description', 12, '2023-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Seat Service Test Hall')
+    RETURNING id INTO v_hall_id;
+
+    INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+    SELECT v_hall_id, (SELECT id FROM seat_types LIMIT 1), 1, n
+    FROM generate_series(1, 3) AS n;
+    SELECT array_agg(id ORDER BY seat_number) INTO v_seat_ids FROM seats WHERE hall_id = v_hall_id;
+
+    SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, NOW() + INTERVAL '1 day', 'Русский', 300.00)
+    INTO v_show_id;
+
+    UPDATE seats SET service_state = 'maintenance', service_reason = 'Сломано кресло',
+        service_from = NOW(), service_until = NOW() + INTERVAL '2 days'
+    WHERE id = v_seat_ids[1];
+    UPDATE seats SET service_state = 'blocked', service_reason = 'Резерв для съёмочной группы',
+        service_from = NOW() + INTERVAL '3 days'
+    WHERE id = v_seat_ids[2];
+    SELECT COUNT(*) INTO v_available FROM movie_show_tickets WHERE movie_show_id = v_show_id AND ticket_status = 'Available';
+    RAISE NOTICE 'Тест 8.1: Свободных мест при блокировке в период сеанса и после него - % (ожидалось 2)', v_available;
+
+    PERFORM materialize_ticket(virtual_ticket_id(v_show_id, v_seat_ids[3]));
+    UPDATE seats SET service_state = 'blocked', service_reason = 'Протечка', service_from = NOW()
+    WHERE id = v_seat_ids[3];
+    SELECT COUNT(*) INTO v_available FROM tickets WHERE movie_show_id = v_show_id;
+    RAISE NOTICE 'Тест 8.2: Сохранённых свободных билетов на заблокированное место - % (ожидалось 0)', v_available;
+
+    SELECT COUNT(*) INTO v_available FROM movie_show_tickets WHERE movie_show_id = v_show_id AND ticket_status = 'Available';
+    RAISE NOTICE 'Тест 8.3: Свободных мест - %, статус сеанса - % (ожидалось 1, on_sale)',
+        v_available, (SELECT status FROM movie_shows WHERE id = v_show_id);
+
+    UPDATE seats SET service_state = 'active', service_reason = NULL, service_from = NULL, service_until = NULL
+    WHERE hall_id = v_hall_id;
+    SELECT COUNT(*) INTO v_available FROM movie_show_tickets WHERE movie_show_id = v_show_id AND ticket_status = 'Available';
+    RAISE NOTICE 'Тест 8.4: Свободных мест после возврата в обслуживание - % (ожидалось 3)', v_available;
+
+    BEGIN
+        UPDATE seats SET service_state = 'blocked' WHERE id = v_seat_ids[1];
+        RAISE NOTICE 'Тест 8.5: Блокировка без причины - ОШИБКА: место заблокировано';
+    EXCEPTION WHEN check_violation THEN
+        RAISE NOTICE 'Тест 8.5: Блокировка без причины - OK';
+    END;
+
+    DELETE FROM movie_shows WHERE id = v_show_id;
+    DELETE FROM seats WHERE hall_id = v_hall_id;
+    DELETE FROM halls WHERE id = v_hall_id;
+    DELETE FROM movies WHERE id = v_movie_id;
+END $$;
+
+-- Временные брони: истёкшие снимаются, покупка снимает ограничение по времени
+DO $$
+DECLARE
+    v_movie_id UUID;
+    v_hall_id UUID;
+    v_show_id UUID;
+    v_user_id UUID;
+    v_ticket_ids UUID[];
+    v_released INT;
+    v_held_until TIMESTAMPTZ;
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Seat Hold Test Movie', '02:00:00', 'Test Description', 12, '2023-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Seat Hold Test Hall')
+    RETURNING id INTO v_hall_id;
+
+    INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+    SELECT v_hall_id, (SELECT id FROM seat_types LIMIT 1), 1, n
+    FROM generate_series(1, 2) AS n;
+
+    SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, NOW() + INTERVAL '1 day', 'Русский', 300.00)
+    INTO v_show_id;
+    SELECT id INTO v_user_id FROM users LIMIT 1;
+
+    SELECT array_agg(virtual_ticket_id(v_show_id, s.id) ORDER BY s.seat_number)
+    INTO v_ticket_ids
+    FROM seats s WHERE s.hall_id = v_hall_id;
+    PERFORM materialize_ticket(id) FROM unnest(v_ticket_ids) AS id;
+    UPDATE tickets SET ticket_status = 'Reserved', user_id = v_user_id, held_until = NOW() + INTERVAL '10 minutes'
+    WHERE id = ANY(v_ticket_ids);
+
+    SELECT release_expired_seat_holds(NOW()) INTO v_released;
+    RAISE NOTICE 'Тест 9.1: Снято броней до истечения срока - % (ожидалось 0)', v_released;
+
+    UPDATE tickets SET ticket_status = 'Purchased' WHERE id = v_ticket_ids[1];
+    SELECT held_until INTO v_held_until FROM tickets WHERE id = v_ticket_ids[1];
+    RAISE NOTICE 'Тест 9.2: Срок брони после покупки - % (ожидалось NULL)', COALESCE(v_held_until::TEXT, 'NULL');
+
+    SELECT release_expired_seat_holds(NOW() + INTERVAL '11 minutes') INTO v_released;
+    RAISE NOTICE 'Тест 9.3: Снято истёкших броней - %, сохранённых билетов - % (ожидалось 1, 1)',
+        v_released, (SELECT COUNT(*) FROM tickets WHERE movie_show_id = v_show_id);
+
+    BEGIN
+        UPDATE tickets SET held_until = NOW() WHERE id = v_ticket_ids[1];
+        RAISE NOTICE 'Тест 9.4: Срок брони у купленного билета - ОШИБКА: срок установлен';
+    EXCEPTION WHEN check_violation THEN
+        RAISE NOTICE 'Тест 9.4: Срок брони у купленного билета - OK';
+    END;
+
+    DELETE FROM tickets WHERE movie_show_id = v_show_id;
+    DELETE FROM movie_shows WHERE id = v_show_id;
+    DELETE FROM seats WHERE hall_id = v_hall_id;
+    DELETE FROM halls WHERE id = v_hall_id;
+    DELETE FROM movies WHERE id = v_movie_id;
+END $$;
+
+-- Места для маломобильных зрителей: место сопровождающего в том же зале, придержка до открытия общей продажи
+DO $$
+DECLARE
+    v_movie_id UUID;
+    v_hall_id UUID;
+    v_other_hall_id UUID;
+    v_show_id UUID;
+    v_seat_ids UUID[];
+    v_other_seat_id UUID;
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Accessibility Test Movie', '02:00:00', 'Test Description', 12, '2023-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Accessibility Test Hall')
+    RETURNING id INTO v_hall_id;
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Accessibility Other Hall')
+    RETURNING id INTO v_other_hall_id;
+
+    INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+    SELECT v_hall_id, (SELECT id FROM seat_types LIMIT 1), 1, n
+    FROM generate_series(1, 3) AS n;
+    SELECT array_agg(id ORDER BY seat_number) INTO v_seat_ids FROM seats WHERE hall_id = v_hall_id;
+    INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+    VALUES (v_other_hall_id, (SELECT id FROM seat_types LIMIT 1), 1, 1)
+    RETURNING id INTO v_other_seat_id;
+
+    BEGIN
+        UPDATE seats SET wheelchair_space = TRUE, companion_seat_id = v_other_seat_id WHERE id = v_seat_ids[1];
+        RAISE NOTICE 'Тест 10.1: Место сопровождающего в другом зале - ОШИБКА: место связано';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 10.1: Место сопровождающего в другом зале - OK';
+    END;
+
+    UPDATE seats SET wheelchair_space = TRUE, companion_seat_id = v_seat_ids[2] WHERE id = v_seat_ids[1];
+
+    BEGIN
+        UPDATE seats SET wheelchair_space = TRUE WHERE id = v_seat_ids[2];
+        RAISE NOTICE 'Тест 10.2: Место сопровождающего под коляску - ОШИБКА: место изменено';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 10.2: Место сопровождающего под коляску - OK';
+    END;
+
+    SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, NOW() + INTERVAL '1 day', 'Русский', 300.00)
+    INTO v_show_id;
+    RAISE NOTICE 'Тест 10.3: Придержано место для коляски - %, сопровождающего - %, обычное - % (ожидалось true, true, false)',
+        accessible_seat_held(v_seat_ids[1], v_show_id, NOW()),
+        accessible_seat_held(v_seat_ids[2], v_show_id, NOW()),
+        accessible_seat_held(v_seat_ids[3], v_show_id, NOW());
+
+    UPDATE halls SET accessible_release_minutes = 24 * 60 + 60 WHERE id = v_hall_id;
+    RAISE NOTICE 'Тест 10.4: Придержано после открытия общей продажи - % (ожидалось false)',
+        accessible_seat_held(v_seat_ids[1], v_show_id, NOW());
+
+    DELETE FROM seats WHERE id = v_seat_ids[2];
+    RAISE NOTICE 'Тест 10.5: Место сопровождающего после удаления - % (ожидалось NULL)',
+        COALESCE((SELECT companion_seat_id::TEXT FROM seats WHERE id = v_seat_ids[1]), 'NULL');
+
+    DELETE FROM movie_shows WHERE id = v_show_id;
+    DELETE FROM seats WHERE hall_id IN (v_hall_id, v_other_hall_id);
+    DELETE FROM halls WHERE id IN (v_hall_id, v_other_hall_id);
+    DELETE FROM movies WHERE id = v_movie_id;
+END $$;
+
+-- Зоны зала: место только в зоне своего зала, наценка зоны входит в цену свободного билета
+DO $$
+DECLARE
+    v_movie_id UUID;
+    v_hall_id UUID;
+    v_other_hall_id UUID;
+    v_show_id UUID;
+    v_zone_id UUID;
+    v_other_zone_id UUID;
+    v_seat_ids UUID[];
+    v_price DECIMAL(10,2);
+    v_zone_price DECIMAL(10,2);
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Zone Test Movie', '02:00:00', 'Test Description', 12, '2023-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Zone Test Hall')
+    RETURNING id INTO v_hall_id;
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Zone Other Hall')
+    RETURNING id INTO v_other_hall_id;
+
+    INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+    SELECT v_hall_id, (SELECT id FROM seat_types LIMIT 1), 1, n
+    FROM generate_series(1, 2) AS n;
+    SELECT array_agg(id ORDER BY seat_number) INTO v_seat_ids FROM seats WHERE hall_id = v_hall_id;
+
+    INSERT INTO hall_zones (hall_id, name, price_modifier)
+    VALUES (v_hall_id, 'VIP', 1.50)
+    RETURNING id INTO v_zone_id;
+    INSERT INTO hall_zones (hall_id, name)
+    VALUES (v_other_hall_id, 'VIP')
+    RETURNING id INTO v_other_zone_id;
+
+    BEGIN
+        UPDATE seats SET zone_id = v_other_zone_id WHERE id = v_seat_ids[1];
+        RAISE NOTICE 'Тест 11.1: Зона другого зала - ОШИБКА: место добавлено в зону';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 11.1: Зона другого зала - OK';
+    END;
+
+    UPDATE seats SET zone_id = v_zone_id WHERE id = v_seat_ids[1];
+    SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, NOW() + INTERVAL '1 day', 'Русский', 300.00)
+    INTO v_show_id;
+    SELECT price INTO v_zone_price FROM movie_show_tickets WHERE movie_show_id = v_show_id AND seat_id = v_seat_ids[1];
+    SELECT price INTO v_price FROM movie_show_tickets WHERE movie_show_id = v_show_id AND seat_id = v_seat_ids[2];
+    RAISE NOTICE 'Тест 11.2: Отношение цены места в зоне к обычному - % (ожидалось 1.50)',
+        ROUND(v_zone_price / v_price, 2);
+
+    DELETE FROM hall_zones WHERE id = v_zone_id;
+    RAISE NOTICE 'Тест 11.3: Зона места после удаления зоны - % (ожидалось NULL)',
+        COALESCE((SELECT zone_id::TEXT FROM seats WHERE id = v_seat_ids[1]), 'NULL');
+
+    DELETE FROM movie_shows WHERE id = v_show_id;
+    DELETE FROM seats WHERE hall_id = v_hall_id;
+    DELETE FROM halls WHERE id IN (v_hall_id, v_other_hall_id);
+    DELETE FROM movies WHERE id = v_movie_id;
+END $$;
+
+-- Режим дистанцирования: блокировка мест вокруг чужой брони и предельная заполняемость сеанса
+DO $$
+DECLARE
+    v_movie_id UUID;
+    v_hall_id UUID;
+    v_show_id UUID;
+    v_user_ids UUID[];
+    v_available INT;
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Distancing Test Movie', '02:00:00', 'Test Description', 12, '2023-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Distancing Test Hall')
+    RETURNING id INTO v_hall_id;
+
+    -- Два ряда по 8 мест
+    INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number)
+    SELECT v_hall_id, (SELECT id FROM seat_types LIMIT 1), r, n
+    FROM generate_series(1, 2) AS r, generate_series(1, 8) AS n;
+
+    INSERT INTO users (name, email, password_hash, birth_date)
+    SELECT 'Distancing User ' || n, 'distancing' || n || '@test.com', 'hash', '1990-01-01'
+    FROM generate_series(1, 2) AS n;
+    SELECT array_agg(id ORDER BY email) INTO v_user_ids FROM users WHERE email LIKE 'distancing%@test.com';
+
+    SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, NOW() + INTERVAL '1 day', 'Русский', 300.00)
+    INTO v_show_id;
+    INSERT INTO movie_show_distancing (movie_show_id, side_seats, block_adjacent_rows, max_occupancy_percent)
+    VALUES (v_show_id, 1, TRUE, 25);
+
+    -- Группа бронирует места 4-5 первого ряда одной командой
+    PERFORM materialize_ticket(virtual_ticket_id(v_show_id, s.id))
+    FROM seats s WHERE s.hall_id = v_hall_id AND s.row_number = 1 AND s.seat_number IN (4, 5);
+    UPDATE tickets SET ticket_status = 'Reserved', user_id = v_user_ids[1]
+    WHERE movie_show_id = v_show_id;
+
+    -- Блокируются места 3 и 6 первого ряда и места 4-5 второго
+    SELECT COUNT(*) INTO v_available FROM movie_show_tickets WHERE movie_show_id = v_show_id AND ticket_status = 'Available';
+    RAISE NOTICE 'Тест 12.1: Свободных мест после брони группы - % (ожидалось 10)', v_available;
+
+    BEGIN
+        INSERT INTO tickets (movie_show_id, seat_id, user_id, ticket_status, price)
+        SELECT v_show_id, id, v_user_ids[2], 'Reserved', 300
+        FROM seats WHERE hall_id = v_hall_id AND row_number = 1 AND seat_number = 6;
+        RAISE NOTICE 'Тест 12.2: Бронь рядом с чужой группой - ОШИБКА: место забронировано';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 12.2: Бронь рядом с чужой группой - OK';
+    END;
+
+    -- Предел 25% от 16 мест - 4 места
+    INSERT INTO tickets (movie_show_id, seat_id, user_id, ticket_status, price)
+    SELECT v_show_id, id, v_user_ids[2], 'Reserved', 300
+    FROM seats WHERE hall_id = v_hall_id AND row_number = 1 AND seat_number IN (1, 8);
+    RAISE NOTICE 'Тест 12.3: Свободных мест при достигнутой заполняемости - %, статус сеанса - % (ожидалось 0, sold_out)',
+        (SELECT COUNT(*) FROM movie_show_tickets WHERE movie_show_id = v_show_id AND ticket_status = 'Available'),
+        (SELECT status FROM movie_shows WHERE id = v_show_id);
+
+    BEGIN
+        INSERT INTO tickets (movie_show_id, seat_id, user_id, ticket_status, price)
+        SELECT v_show_id, id, v_user_ids[2], 'Reserved', 300
+        FROM seats WHERE hall_id = v_hall_id AND row_number = 2 AND seat_number = 1;
+        RAISE NOTICE 'Тест 12.4: Бронь сверх предельной заполняемости - ОШИБКА: место забронировано';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 12.4: Бронь сверх предельной заполняемости - OK';
+    END;
+
+    DELETE FROM movie_show_distancing WHERE movie_show_id = v_show_id;
+    RAISE NOTICE 'Тест 12.5: Свободных мест без дистанции - %, статус сеанса - % (ожидалось 12, on_sale)',
+        (SELECT COUNT(*) FROM movie_show_tickets WHERE movie_show_id = v_show_id AND ticket_status = 'Available'),
+        (SELECT status FROM movie_shows WHERE id = v_show_id);
+
+    DELETE FROM tickets WHERE movie_show_id = v_show_id;
+    DELETE FROM movie_shows WHERE id = v_show_id;
+    DELETE FROM seats WHERE hall_id = v_hall_id;
+    DELETE FROM halls WHERE id = v_hall_id;
+    DELETE FROM users WHERE id = ANY(v_user_ids);
+    DELETE FROM movies WHERE id = v_movie_id;
+END $$;
+
+-- Окна обслуживания зала: сеанс не может пересекаться с обслуживанием
+DO $$
+DECLARE
+    v_movie_id UUID;
+    v_hall_id UUID;
+    v_show_id UUID;
+    v_start TIMESTAMPTZ := date_trunc('day', NOW()) + INTERVAL '2 days 12 hours';
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Maintenance Test Movie', '02:00:00', 'Test Description', 12, '2023-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO halls (cinema_id, screen_type_id, name)
+    VALUES ((SELECT id FROM cinemas WHERE name = 'Test Cinema'), (SELECT id FROM screen_types LIMIT 1), 'Maintenance Test Hall')
+    RETURNING id INTO v_hall_id;
+
+    INSERT INTO hall_maintenance_windows (hall_id, period, reason)
+    VALUES (v_hall_id, tstzrange(v_start, v_start + INTERVAL '3 hours'), 'Ремонт проектора');
+
+    BEGIN
+        SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, v_start + INTERVAL '1 hour', 'Русский', 300.00)
+        INTO v_show_id;
+        RAISE NOTICE 'Тест 13.1: Сеанс во время обслуживания - ОШИБКА: сеанс создан';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 13.1: Сеанс во время обслуживания - OK';
+    END;
+
+    -- Сеанс с уборкой заканчивается позже начала обслуживания
+    BEGIN
+        SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, v_start - INTERVAL '2 hours', 'Русский', 300.00)
+        INTO v_show_id;
+        RAISE NOTICE 'Тест 13.2: Уборка после сеанса во время обслуживания - ОШИБКА: сеанс создан';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 13.2: Уборка после сеанса во время обслуживания - OK';
+    END;
+
+    SELECT create_movie_show_with_tickets(v_movie_id, v_hall_id, v_start + INTERVAL '3 hours', 'Русский', 300.00)
+    INTO v_show_id;
+    RAISE NOTICE 'Тест 13.3: Сеанс после обслуживания - % (ожидалось t)', v_show_id IS NOT NULL;
+
+    BEGIN
+        UPDATE movie_shows SET start_time = v_start WHERE id = v_show_id;
+        RAISE NOTICE 'Тест 13.4: Перенос сеанса на время обслуживания - ОШИБКА: сеанс перенесён';
+    EXCEPTION WHEN raise_exception THEN
+        RAISE NOTICE 'Тест 13.4: Перенос сеанса на время обслуживания - OK';
+    END;
+
+    BEGIN
+        INSERT INTO hall_maintenance_windows (hall_id, period, reason)
+        VALUES (v_hall_id, tstzrange(v_start + INTERVAL '2 hours', v_start + INTERVAL '4 hours'), 'Реконструкция');
+        RAISE NOTICE 'Тест 13.5: Пересекающиеся окна обслуживания - ОШИБКА: окно создано';
+    EXCEPTION WHEN exclusion_violation THEN
+        RAISE NOTICE 'Тест 13.5: Пересекающиеся окна обслуживания - OK';
+    END;
+
+    DELETE FROM tickets WHERE movie_show_id = v_show_id;
+    DELETE FROM movie_shows WHERE hall_id = v_hall_id;
+    DELETE FROM halls WHERE id = v_hall_id;
+    DELETE FROM movies WHERE id = v_movie_id;
+END $$;
+
+-- Медиа фильма: у фильма один постер, файлы и ссылки не смешиваются
+DO $$
+DECLARE
+    v_movie_id UUID;
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Media Test Movie', '01:30:00', 'Фильм для проверки медиа', 12, '2020-01-01')
+    RETURNING id INTO v_movie_id;
+
+    INSERT INTO movie_media (movie_id, kind, storage_key, thumbnail_key, content_type, size_bytes, width, height)
+    VALUES (v_movie_id, 'poster', 'movies/test/poster.jpg', 'movies/test/poster_thumb.jpg', 'image/jpeg', 1024, 1000, 1500);
+    RAISE NOTICE 'Тест 14.1: Постер фильма - % (ожидалось 1)',
+        (SELECT COUNT(*) FROM movie_media WHERE movie_id = v_movie_id);
+
+    BEGIN
+        INSERT INTO movie_media (movie_id, kind, storage_key, thumbnail_key, content_type, size_bytes, width, height)
+        VALUES (v_movie_id, 'poster', 'movies/test/poster2.jpg', 'movies/test/poster2_thumb.jpg', 'image/jpeg', 1024, 1000, 1500);
+        RAISE NOTICE 'Тест 14.2: Второй постер - ОШИБКА: постер добавлен';
+    EXCEPTION WHEN unique_violation THEN
+        RAISE NOTICE 'Тест 14.2: Второй постер - OK';
+    END;
+
+    BEGIN
+        INSERT INTO movie_media (movie_id, kind, external_url) VALUES (v_movie_id, 'still', 'https://example.com/still.jpg');
+        RAISE NOTICE 'Тест 14.3: Кадр по внешней ссылке - ОШИБКА: кадр добавлен';
+    EXCEPTION WHEN check_violation THEN
+        RAISE NOTICE 'Тест 14.3: Кадр по внешней ссылке - OK';
+    END;
+
+    BEGIN
+        INSERT INTO movie_media (movie_id, kind, external_url) VALUES (v_movie_id, 'trailer', 'javascript:alert(1)');
+        RAISE NOTICE 'Тест 14.4: Трейлер не по http - ОШИБКА: трейлер добавлен';
+    EXCEPTION WHEN check_violation THEN
+        RAISE NOTICE 'Тест 14.4: Трейлер не по http - OK';
+    END;
+
+    INSERT INTO movie_media (movie_id, kind, external_url) VALUES (v_movie_id, 'trailer', 'https://example.com/trailer');
+    DELETE FROM movies WHERE id = v_movie_id;
+    RAISE NOTICE 'Тест 14.5: Медиа после удаления фильма - % (ожидалось 0)',
+        (SELECT COUNT(*) FROM movie_media WHERE movie_id = v_movie_id);
+END $$;
+
+-- Полнотекстовый поиск фильмов: морфология, ранжирование и опечатки
+DO $$
+DECLARE
+    v_title_id UUID;
+    v_description_id UUID;
+BEGIN
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Звёздный путь', '02:00:00', 'Экипаж корабля исследует космос.', 12, '2020-01-01')
+    RETURNING id INTO v_title_id;
+    INSERT INTO movies (title, duration, description, age_limit, release_date)
+    VALUES ('Search Odyssey', '02:00:00', 'Фильм о звёздном пути человечества.', 12, '2020-01-01')
+    RETURNING id INTO v_description_id;
+
+    RAISE NOTICE 'Тест 15.1: Поиск по другой форме слова - % (ожидалось t)',
+        EXISTS (SELECT 1 FROM search_movies('звёздные пути', FALSE) WHERE movie_id = v_description_id);
+
+    RAISE NOTICE 'Тест 15.2: Совпадение в названии выше совпадения в описании - % (ожидалось t)',
+        (SELECT movie_id FROM search_movies('звёздный путь', FALSE) LIMIT 1) = v_title_id;
+
+    RAISE NOTICE 'Тест 15.3: Английская морфология - % (ожидалось t)',
+        EXISTS (SELECT 1 FROM search_movies('odysseys', FALSE) WHERE movie_id = v_description_id);
+
+    RAISE NOTICE 'Тест 15.4: Опечатка без нечёткого поиска - %, с нечётким - % (ожидалось f, t)',
+        EXISTS (SELECT 1 FROM search_movies('Звёздный пуьт', FALSE) WHERE movie_id = v_title_id),
+        EXISTS (SELECT 1 FROM search_movies('Звёздный пуьт', TRUE) WHERE movie_id = v_title_id AND fuzzy);
+
+    RAISE NOTICE 'Тест 15.5: Выделение в названии - % (ожидалось <mark>Звёздный</mark> <mark>путь</mark>)',
+        (SELECT title_highlight FROM search_movies('звёздный путь', FALSE) WHERE movie_id = v_title_id);
+
+    DELETE FROM movies WHERE id IN (v_title_id, v_description_id);
+END $$;
+
+DELETE FROM cinemas WHERE name = 'Test Cinema';
+
+RESET ROLE;
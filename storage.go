@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Storage хранит файлы медиа фильмов по ключам вида movies/<id фильма>/<файл>
+type Storage interface {
+	// Put сохраняет объект, перезаписывая существующий
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Open возвращает содержимое объекта и его тип; ErrMediaNotFound, если объекта нет
+	Open(ctx context.Context, key string) (io.ReadCloser, string, error)
+	// Delete удаляет объект; удаление отсутствующего объекта не считается ошибкой
+	Delete(ctx context.Context, key string) error
+	// URL возвращает адрес, по которому клиент может получить объект
+	URL(key string) string
+}
+
+var ErrMediaNotFound = errors.New("медиафайл не найден")
+
+// MediaStorage — хранилище медиа, с которым работают обработчики
+var MediaStorage Storage
+
+var mediaKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(/[A-Za-z0-9_-]+)*\.[a-z0-9]+$`)
+
+// validMediaKey не пускает в хранилище ключи с .., абсолютными путями и прочими сюрпризами
+func validMediaKey(key string) bool {
+	return len(key) <= 300 && mediaKeyPattern.MatchString(key)
+}
+
+func mediaURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
+
+// NewStorageFromEnv создаёт хранилище по переменным окружения:
+// MEDIA_STORAGE=local (по умолчанию) хранит файлы в каталоге MEDIA_DIR,
+// MEDIA_STORAGE=s3 — в бакете S3_BUCKET S3-совместимого сервиса S3_ENDPOINT.
+// MEDIA_PUBLIC_URL задаёт префикс адресов файлов; по умолчанию они раздаются самим сервером через /media.
+func NewStorageFromEnv() (Storage, error) {
+	publicURL := os.Getenv("MEDIA_PUBLIC_URL")
+	if publicURL == "" {
+		publicURL = "/media"
+	}
+
+	switch kind := os.Getenv("MEDIA_STORAGE"); kind {
+	case "", "local":
+		dir := os.Getenv("MEDIA_DIR")
+		if dir == "" {
+			dir = "media"
+		}
+		return NewLocalStorage(dir, publicURL)
+	case "s3":
+		s := &S3Storage{
+			Endpoint:  strings.TrimRight(os.Getenv("S3_ENDPOINT"), "/"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: publicURL,
+		}
+		if s.Region == "" {
+			s.Region = "us-east-1"
+		}
+		if s.Endpoint == "" || s.Bucket == "" || s.AccessKey == "" || s.SecretKey == "" {
+			return nil, errors.New("для хранилища s3 нужны S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY и S3_SECRET_KEY")
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип хранилища медиа %q, используйте local или s3", kind)
+	}
+}
+
+// LocalStorage хранит объекты файлами в каталоге на диске
+type LocalStorage struct {
+	Dir       string
+	PublicURL string
+}
+
+func NewLocalStorage(dir, publicURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог медиа: %v", err)
+	}
+	return &LocalStorage{Dir: dir, PublicURL: publicURL}, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if !validMediaKey(key) {
+		return "", fmt.Errorf("недопустимый ключ медиафайла %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalStorage) Put(_ context.Context, key, _ string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы читатели не увидели недописанный файл
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (s *LocalStorage) Open(_ context.Context, key string) (io.ReadCloser, string, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, "", ErrMediaNotFound
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", ErrMediaNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return f, mime.TypeByExtension(path.Ext(key)), nil
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return mediaURL(s.PublicURL, key)
+}
+
+// S3Storage хранит объекты в S3-совместимом сервисе (AWS S3, MinIO и т.п.).
+// Запросы адресуются в стиле path (Endpoint/Bucket/key) и подписываются AWS Signature V4.
+type S3Storage struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	PublicURL string
+	Client    *http.Client
+}
+
+func (s *S3Storage) do(ctx context.Context, method, key, contentType string, body []byte) (*http.Response, error) {
+	if !validMediaKey(key) {
+		return nil, fmt.Errorf("недопустимый ключ медиафайла %q", key)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.Endpoint+"/"+s.Bucket+"/"+key, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	payloadHash := sha256.Sum256(body)
+	signS3RequestV4(req, hex.EncodeToString(payloadHash[:]), s.AccessKey, s.SecretKey, s.Region, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func s3StatusError(op, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3: %s %s: %s: %s", op, key, resp.Status, strings.TrimSpace(string(body)))
+}
+
+func (s *S3Storage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, contentType, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3StatusError("put", key, resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	if !validMediaKey(key) {
+		return nil, "", ErrMediaNotFound
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, "", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.Header.Get("Content-Type"), nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, "", ErrMediaNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, "", s3StatusError("get", key, resp)
+	}
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// S3 отвечает 204 и на удаление отсутствующего объекта, некоторые совместимые сервисы — 404
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3StatusError("delete", key, resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	return mediaURL(s.PublicURL, key)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// signS3RequestV4 добавляет к запросу заголовки x-amz-date, x-amz-content-sha256 и Authorization.
+// Подписываются host и все заголовки, уже установленные в req.Header.
+func signS3RequestV4(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	canonicalQuery := strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method, canonicalURI, canonicalQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -35,6 +37,13 @@ func validateTicketData(w http.ResponseWriter, t TicketData) bool {
 	return true
 }
 
+// materializeTicket сохраняет вычисляемый свободный билет в tickets, чтобы его можно было изменить.
+// Для сохранённых и несуществующих билетов ничего не делает.
+func materializeTicket(ctx context.Context, db *pgxpool.Pool, id uuid.UUID) error {
+	_, err := db.Exec(ctx, "SELECT materialize_ticket($1)", id)
+	return err
+}
+
 func validateTicketStatus(status TicketStatusEnumType) error {
 	if !status.IsValid() {
 		return errors.New("недопустимый статус билета")
@@ -42,16 +51,40 @@ func validateTicketStatus(status TicketStatusEnumType) error {
 	return nil
 }
 
+var ticketListSpec = listSpec{
+	ID: "t.id",
+	Sorts: map[string]listSortField{
+		"id":    {Expr: "t.id", Kind: listUUID},
+		"price": {Expr: "t.price", Kind: listNumeric},
+	},
+	DefaultSort: "id",
+	Filters: []listFilter{
+		{Param: "status", Kind: listText, Cond: "t.ticket_status::text = $%[1]d"},
+	},
+}
+
+func fetchTicketPage(ctx context.Context, db *pgxpool.Pool, q *listQuery, from string) (Page[Ticket], error) {
+	return fetchPage(ctx, db, q, "t.id, t.movie_show_id, t.seat_id, t.ticket_status, t.price, t.user_id", from,
+		func(row pgx.Row) (Ticket, error) {
+			var t Ticket
+			err := row.Scan(&t.ID, &t.MovieShowID, &t.SeatID, &t.Status, &t.Price, &t.UserID)
+			return t, err
+		})
+}
+
 // @Summary Получить все билеты для сеанса фильма по ID (admin)
-// @Description Возвращает список всех билетов по ID сеанаса, содержащихся в базе данных.
+// @Description Возвращает страницу билетов сеанса, включая вычисляемые свободные.
 // @Tags Билеты
 // @Produce json
 // @Security BearerAuth
 // @Param movie_show_id path string true "ID показа фильма"
-// @Success 200 {array} Ticket
-// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Param status query string false "Статус билета"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: id, price; с префиксом - по убыванию"
+// @Success 200 {object} Page[Ticket] "Страница билетов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID или неверные параметры списка"
 // @Failure 403 {object} ErrorResponse "Доступ запрещён"
-// @Failure 404 {object} ErrorResponse "Билеты не найдены"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /tickets/movie-show/{movie_show_id} [get]
 func GetTicketsByMovieShowID(db *pgxpool.Pool) http.HandlerFunc {
@@ -67,41 +100,32 @@ func GetTicketsByMovieShowID(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(), `
-			SELECT t.id, t.movie_show_id, t.seat_id, t.ticket_status, t.price, t.user_id
-			FROM tickets t
-			WHERE t.movie_show_id = $1`, movieShowID)
-		if HandleDatabaseError(w, err, "билетами") {
+		q, ok := parseListQuery(w, r, ticketListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var tickets []Ticket
-		for rows.Next() {
-			var t Ticket
-			if err := rows.Scan(&t.ID, &t.MovieShowID, &t.SeatID, &t.Status, &t.Price, &t.UserID); HandleDatabaseError(w, err, "билетом") {
-				return
-			}
-			tickets = append(tickets, t)
-		}
+		q.where("t.movie_show_id = $%[1]d", movieShowID)
 
-		if len(tickets) == 0 {
-			http.Error(w, "Билеты не найдены", http.StatusNotFound)
+		page, err := fetchTicketPage(context.Background(), db, q, "movie_show_tickets t")
+		if HandleDatabaseError(w, err, "билетами") {
+			return
 		}
 
-		json.NewEncoder(w).Encode(tickets)
+		writePage(w, page)
 	}
 }
 
 // @Summary Получить свободные билеты для сеанса фильма по ID (guest | user | admin)
-// @Description Возвращает список свободные билетов по ID сеанса, содержащихся в базе данных.
+// @Description Возвращает страницу свободных билетов по ID сеанса. Свободные билеты вычисляются по текущей схеме зала
+// @Description и базовой цене сеанса, их ID постоянны и могут использоваться для бронирования.
 // @Tags Билеты
 // @Produce json
 // @Param movie_show_id path string true "ID показа фильма"
-// @Success 200 {array} Ticket
-// @Failure 400 {object} ErrorResponse "Неверный формат ID"
-// @Failure 403 {object} ErrorResponse "Доступ запрещён"
-// @Failure 404 {object} ErrorResponse "Билеты не найдены"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: id, price; с префиксом - по убыванию"
+// @Success 200 {object} Page[Ticket] "Страница свободных билетов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID или неверные параметры списка"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /tickets/available-movie-show/{movie_show_id} [get]
 func GetAvailableTicketsByMovieShowID(db *pgxpool.Pool) http.HandlerFunc {
@@ -111,29 +135,19 @@ func GetAvailableTicketsByMovieShowID(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(), `
-			SELECT t.id, t.movie_show_id, t.seat_id, t.price
-			FROM tickets t
-			WHERE t.movie_show_id = $1 AND t.ticket_status = 'Available'`, movieShowID)
-		if HandleDatabaseError(w, err, "билетами") {
+		q, ok := parseListQuery(w, r, ticketListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var tickets []Ticket
-		for rows.Next() {
-			var t Ticket
-			if err := rows.Scan(&t.ID, &t.MovieShowID, &t.SeatID, &t.Price); HandleDatabaseError(w, err, "билетом") {
-				return
-			}
-			tickets = append(tickets, t)
-		}
+		q.where("t.movie_show_id = $%[1]d AND t.ticket_status = 'Available' AND ms.status = 'on_sale'", movieShowID)
 
-		if len(tickets) == 0 {
-			http.Error(w, "Билеты не найдены", http.StatusNotFound)
+		page, err := fetchTicketPage(context.Background(), db, q,
+			"movie_show_tickets t JOIN movie_shows ms ON ms.id = t.movie_show_id")
+		if HandleDatabaseError(w, err, "билетами") {
+			return
 		}
 
-		json.NewEncoder(w).Encode(tickets)
+		writePage(w, page)
 	}
 }
 
@@ -187,6 +201,10 @@ func CreateTicket(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfShow, t.MovieShowID) {
+			return
+		}
+
 		id := uuid.New()
 		_, err := db.Exec(context.Background(), "INSERT INTO tickets (id, movie_show_id, seat_id, ticket_status, price, user_id) VALUES ($1, $2, $3, $4, $5, $6)",
 			id, t.MovieShowID, t.SeatID, t.Status, t.Price, t.UserID)
@@ -235,6 +253,14 @@ func UpdateTicket(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfTicket, id) || !requireCinemaAccess(w, r, db, cinemaOfShow, t.MovieShowID) {
+			return
+		}
+
+		if err := materializeTicket(r.Context(), db, id); IsError(w, err) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(), "UPDATE tickets SET movie_show_id=$1, seat_id=$2, ticket_status=$3, price=$4, user_id=$5 WHERE id=$6",
 			t.MovieShowID, t.SeatID, t.Status, t.Price, t.UserID, id)
 		if IsError(w, err) {
@@ -249,7 +275,11 @@ func UpdateTicket(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Изменить статус бронирования билета билет (user* | admin)
-// @Description Бронирует или возвращает билет по ID.
+// @Description Бронирует или возвращает билет по ID. Бронировать можно только билеты на сеансы в статусе on_sale,
+// @Description вернуть бронь — пока сеанс в статусе on_sale или sold_out. Места для колясок и места сопровождающих
+// @Description до открытия общей продажи бронируют только пользователи с needs_accessibility.
+// @Description В режиме дистанцирования места рядом с чужими бронями и места сверх предельной заполняемости недоступны.
+// @Description Бронирование места, временно удерживаемого тем же пользователем после подбора, делает бронь постоянной.
 // @Tags Билеты
 // @Accept json
 // @Produce json
@@ -258,8 +288,9 @@ func UpdateTicket(db *pgxpool.Pool) http.HandlerFunc {
 // @Param ticket body TicketStatusData true "Данные для бронирования билета"
 // @Success 200 "Билет успешно забронирован"
 // @Failure 400 {object} ErrorResponse "Неверный формат JSON"
-// @Failure 404 {object} ErrorResponse "Билет не найден"
+// @Failure 404 {object} ErrorResponse "Билет не найден или место недоступно для бронирования"
 // @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 409 {object} ErrorResponse "Киносеанс отменён, продажа билетов закрыта, билет уже забронирован (или не забронирован пользователем при возврате), место придержано для маломобильных зрителей или нарушена дистанция"
 // @Failure 500 {object} ErrorResponse "Ошибка"
 // @Router /tickets/reserve/{id} [put]
 func ReserveOrReturnReservedTicket(db *pgxpool.Pool) http.HandlerFunc {
@@ -280,13 +311,38 @@ func ReserveOrReturnReservedTicket(db *pgxpool.Pool) http.HandlerFunc {
 		}
 
 		var prev_ticket_status string
-		err := db.QueryRow(context.Background(), "SELECT ticket_status FROM tickets WHERE id = $1", id).
-			Scan(&prev_ticket_status)
+		var show_status ShowStatusEnumType
+		var held_for_accessibility bool
+		err := db.QueryRow(context.Background(), `
+			SELECT t.ticket_status, ms.status,
+				accessible_seat_held(t.seat_id, t.movie_show_id, NOW())
+				AND NOT COALESCE((SELECT accessibility_needs FROM users WHERE id = $2), FALSE)
+			FROM movie_show_tickets t
+			JOIN movie_shows ms ON ms.id = t.movie_show_id
+			WHERE t.id = $1`, id, t.UserID).
+			Scan(&prev_ticket_status, &show_status, &held_for_accessibility)
+		if isNoRows(err) {
+			// Свободного билета нет и на местах в зоне дистанции или на сеансе с достигнутой заполняемостью
+			http.Error(w, "Билет не найден или место недоступно для бронирования", http.StatusNotFound)
+			return
+		}
 		if err != nil {
 			http.Error(w, "Ошибка сервера", http.StatusInternalServerError)
 			return
 		}
 
+		if show_status == ShowCancelled || prev_ticket_status == string(Refunded) {
+			http.Error(w, "Киносеанс отменён", http.StatusConflict)
+			return
+		}
+
+		// Бронировать можно только при открытой продаже, вернуть бронь — пока сеанс не начался
+		if (t.Reserve && show_status != ShowOnSale) ||
+			(!t.Reserve && show_status != ShowOnSale && show_status != ShowSoldOut) {
+			http.Error(w, fmt.Sprintf("Продажа билетов на киносеанс закрыта (статус %s)", show_status), http.StatusConflict)
+			return
+		}
+
 		role := r.Header.Get("Role")
 		token_user_id := r.Header.Get("UserID")
 		if (role != os.Getenv("CLAIM_ROLE_ADMIN")) && (token_user_id != t.UserID || prev_ticket_status == string(Purchased)) {
@@ -294,19 +350,39 @@ func ReserveOrReturnReservedTicket(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		// До открытия общей продажи места для маломобильных зрителей бронируют только те, кому они нужны
+		if t.Reserve && held_for_accessibility && role != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Место придержано для маломобильных зрителей до открытия общей продажи", http.StatusConflict)
+			return
+		}
+
+		if err := materializeTicket(r.Context(), db, id); IsError(w, err) {
+			return
+		}
+
+		// Статус проверяется в самом UPDATE: между чтением выше и записью билет мог забронировать
+		// или вернуть другой запрос
 		var res pgconn.CommandTag
 		if t.Reserve {
-			res, err = db.Exec(context.Background(), "UPDATE tickets SET ticket_status=$1, user_id=$2 WHERE id=$3",
+			res, err = db.Exec(context.Background(),
+				`UPDATE tickets SET ticket_status=$1, user_id=$2, held_until=NULL
+				WHERE id=$3 AND (ticket_status = 'Available' OR (ticket_status = 'Reserved' AND user_id = $2 AND held_until IS NOT NULL))`,
 				Reserved, t.UserID, id)
 		} else {
-			res, err = db.Exec(context.Background(), "UPDATE tickets SET ticket_status=$1, user_id=$2 WHERE id=$3",
-				Available, nil, id)
+			res, err = db.Exec(context.Background(),
+				"UPDATE tickets SET ticket_status=$1, user_id=NULL WHERE id=$3 AND user_id = $2 AND ticket_status = 'Reserved'",
+				Available, t.UserID, id)
 		}
 		if IsError(w, err) {
 			return
 		}
 
-		if !CheckRowsAffected(w, res.RowsAffected()) {
+		if res.RowsAffected() == 0 {
+			if t.Reserve {
+				http.Error(w, "Билет уже забронирован или куплен", http.StatusConflict)
+			} else {
+				http.Error(w, "Билет не забронирован этим пользователем", http.StatusConflict)
+			}
 			return
 		}
 		json.NewEncoder(w)
@@ -331,6 +407,10 @@ func DeleteTicket(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		if !requireCinemaAccess(w, r, db, cinemaOfTicket, id) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(), "DELETE FROM tickets WHERE id = $1", id)
 		if IsError(w, err) {
 			return
@@ -344,12 +424,17 @@ func DeleteTicket(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Получить билеты пользователя (user* | admin)
+// @Description Возвращает страницу билетов, забронированных или купленных пользователем.
 // @Tags Билеты
 // @Produce json
 // @Security BearerAuth
 // @Param user_id path string true "ID пользователя"
-// @Success 200 {array} Ticket
-// @Failure 400 {object} ErrorResponse "Неверный формат ID"
+// @Param status query string false "Статус билета"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: id, price; с префиксом - по убыванию"
+// @Success 200 {object} Page[Ticket] "Страница билетов"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID или неверные параметры списка"
 // @Failure 403 {object} ErrorResponse "Доступ запрещён"
 // @Failure 500 {object} ErrorResponse "Ошибка"
 // @Router /tickets/user/{user_id} [get]
@@ -367,30 +452,17 @@ func GetTicketsByUserID(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(), `
-			SELECT id, movie_show_id, seat_id, user_id, ticket_status, price
-			FROM tickets
-			WHERE user_id = $1`, userID)
-		if IsError(w, err) {
+		q, ok := parseListQuery(w, r, ticketListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var tickets []Ticket
-		for rows.Next() {
-			var t Ticket
-			if err := rows.Scan(&t.ID, &t.MovieShowID, &t.SeatID, &t.UserID, &t.Status, &t.Price); err != nil {
-				println(err.Error())
-				http.Error(w, "Ошибка при сканировании", http.StatusInternalServerError)
-				return
-			}
-			tickets = append(tickets, t)
-		}
+		q.where("t.user_id = $%[1]d", userID)
 
-		if len(tickets) == 0 {
-			http.Error(w, "Билеты не найдены", http.StatusNotFound)
+		page, err := fetchTicketPage(context.Background(), db, q, "tickets t")
+		if HandleDatabaseError(w, err, "билетами") {
+			return
 		}
 
-		json.NewEncoder(w).Encode(tickets)
+		writePage(w, page)
 	}
 }
@@ -167,7 +167,7 @@ func TestGetTicketsByMovieShowID(t *testing.T) {
 	}{
 		{"Empty as Guest", false, "", http.StatusForbidden},
 		{"Empty as User", false, os.Getenv("CLAIM_ROLE_USER"), http.StatusForbidden},
-		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusNotFound},
+		{"Empty as Admin", false, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
 		{"NonEmpty as Guest", true, "", http.StatusForbidden},
 		{"NonEmpty as User", true, os.Getenv("CLAIM_ROLE_USER"), http.StatusForbidden},
 		{"NonEmpty as Admin", true, os.Getenv("CLAIM_ROLE_ADMIN"), http.StatusOK},
@@ -190,10 +190,13 @@ func TestGetTicketsByMovieShowID(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var tickets []Ticket
-				parseResponseBody(t, resp, &tickets)
+				var page Page[Ticket]
+				parseResponseBody(t, resp, &page)
 
-				if len(tickets) == 0 {
+				if !tt.seedData && len(page.Items) != 0 {
+					t.Errorf("Expected no tickets, got %d", len(page.Items))
+				}
+				if tt.seedData && len(page.Items) == 0 {
 					t.Error("Expected at least one ticket, got none")
 				}
 			}
@@ -468,6 +471,7 @@ func TestGetTicketsByUserID(t *testing.T) {
 		userID         string
 		role           string
 		expectedStatus int
+		expectEmpty    bool
 	}{
 		{
 			"Valid user ID with tickets",
@@ -479,6 +483,7 @@ func TestGetTicketsByUserID(t *testing.T) {
 			UsersData[len(UsersData)-1].ID,
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusOK,
+			false,
 		},
 		{
 			"Invalid user ID format",
@@ -486,6 +491,7 @@ func TestGetTicketsByUserID(t *testing.T) {
 			"invalid-uuid",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusBadRequest,
+			false,
 		},
 		{
 			"Non-existent user ID",
@@ -497,6 +503,7 @@ func TestGetTicketsByUserID(t *testing.T) {
 			"",
 			os.Getenv("CLAIM_ROLE_USER"),
 			http.StatusForbidden,
+			false,
 		},
 		{
 			"User without tickets",
@@ -507,7 +514,8 @@ func TestGetTicketsByUserID(t *testing.T) {
 			},
 			"",
 			os.Getenv("CLAIM_ROLE_ADMIN"),
-			http.StatusNotFound,
+			http.StatusOK,
+			true,
 		},
 	}
 
@@ -527,10 +535,14 @@ func TestGetTicketsByUserID(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var tickets []Ticket
-				parseResponseBody(t, resp, &tickets)
+				var page Page[Ticket]
+				parseResponseBody(t, resp, &page)
+				tickets := page.Items
 
-				if len(tickets) == 0 {
+				if tt.expectEmpty && len(tickets) != 0 {
+					t.Errorf("Expected empty tickets list, got %v", tickets)
+				}
+				if !tt.expectEmpty && len(tickets) == 0 {
 					t.Error("Expected non-empty tickets list")
 				}
 
@@ -543,3 +555,272 @@ func TestGetTicketsByUserID(t *testing.T) {
 		})
 	}
 }
+
+func TestReserveTicketForCancelledShow(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	_, err := TestAdminDB.Exec(context.Background(),
+		"UPDATE movie_shows SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP, cancel_reason = 'Тест' WHERE id = $1", MovieShowsData[2].ID)
+	if err != nil {
+		t.Fatalf("Failed to cancel movie show: %v", err)
+	}
+
+	body := TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: true}
+	req := createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+TicketsData[2].ID, generateToken(t, os.Getenv("CLAIM_ROLE_USER")), body)
+	resp := executeRequest(t, req, http.StatusConflict)
+	defer resp.Body.Close()
+
+	availableReq := createRequest(t, "GET", ts.URL+"/tickets/available-movie-show/"+MovieShowsData[2].ID, generateToken(t, ""), nil)
+	availableResp := executeRequest(t, availableReq, http.StatusOK)
+	defer availableResp.Body.Close()
+
+	var page Page[Ticket]
+	parseResponseBody(t, availableResp, &page)
+	if len(page.Items) != 0 {
+		t.Errorf("Expected no available tickets for a cancelled show, got %d", len(page.Items))
+	}
+}
+
+func TestReserveTicketShowStatus(t *testing.T) {
+	setStatus := func(t *testing.T, id string, status ShowStatusEnumType) {
+		t.Helper()
+		_, err := TestAdminDB.Exec(context.Background(), "UPDATE movie_shows SET status = $1 WHERE id = $2", status, id)
+		if err != nil {
+			t.Fatalf("Failed to set movie show status: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		ticketID       string
+		body           TicketStatusData
+		setup          func(t *testing.T)
+		expectedStatus int
+	}{
+		{"Reserve when sales are closed", TicketsData[2].ID,
+			TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: true},
+			func(t *testing.T) { setStatus(t, MovieShowsData[2].ID, ShowScheduled) },
+			http.StatusConflict},
+		{"Return reservation when sales are closed", TicketsData[3].ID,
+			TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: false},
+			func(t *testing.T) { setStatus(t, MovieShowsData[2].ID, ShowScheduled) },
+			http.StatusConflict},
+		{"Reserve when show is in progress", TicketsData[2].ID,
+			TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: true},
+			func(t *testing.T) { setStatus(t, MovieShowsData[2].ID, ShowInProgress) },
+			http.StatusConflict},
+		{"Return reservation when sold out", TicketsData[3].ID,
+			TicketStatusData{UserID: UsersData[len(UsersData)-1].ID, Reserve: false},
+			func(t *testing.T) {
+				_, err := TestAdminDB.Exec(context.Background(),
+					"UPDATE tickets SET ticket_status = 'Purchased', user_id = $1 WHERE id = $2",
+					UsersData[0].ID, TicketsData[2].ID)
+				if err != nil {
+					t.Fatalf("Failed to purchase ticket: %v", err)
+				}
+			},
+			http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			tt.setup(t)
+
+			req := createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+tt.ticketID, generateToken(t, os.Getenv("CLAIM_ROLE_USER")), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+		})
+	}
+}
+
+func TestReserveTicketStatusGuards(t *testing.T) {
+	owner := UsersData[len(UsersData)-1].ID
+	tests := []struct {
+		name     string
+		role     string
+		ticketID string
+		body     TicketStatusData
+	}{
+		{"Reserve an already reserved ticket", os.Getenv("CLAIM_ROLE_USER"), TicketsData[3].ID, TicketStatusData{UserID: owner, Reserve: true}},
+		{"Return an available ticket", os.Getenv("CLAIM_ROLE_USER"), TicketsData[2].ID, TicketStatusData{UserID: owner, Reserve: false}},
+		{"Return someone else's reservation", os.Getenv("CLAIM_ROLE_ADMIN"), TicketsData[3].ID, TicketStatusData{UserID: UsersData[0].ID, Reserve: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+tt.ticketID, generateToken(t, tt.role), tt.body)
+			executeRequest(t, req, http.StatusConflict).Body.Close()
+
+			var status TicketStatusEnumType
+			var userID *string
+			err := TestAdminDB.QueryRow(context.Background(),
+				"SELECT ticket_status, user_id FROM movie_show_tickets WHERE id = $1", TicketsData[3].ID).Scan(&status, &userID)
+			if err != nil {
+				t.Fatalf("Failed to get ticket: %v", err)
+			}
+			if status != Reserved || userID == nil || *userID != owner {
+				t.Errorf("Expected reservation of %s to stay intact, got %s (%v)", owner, status, userID)
+			}
+		})
+	}
+}
+
+func TestLazyTicketInventory(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	ctx := context.Background()
+	showID := MovieShowsData[3].ID
+	user := UsersData[len(UsersData)-1].ID
+
+	_, err := TestAdminDB.Exec(ctx, "INSERT INTO movie_show_prices (movie_show_id, base_price) VALUES ($1, 300)", showID)
+	if err != nil {
+		t.Fatalf("Failed to set base price: %v", err)
+	}
+
+	storedTickets := func(t *testing.T) int {
+		t.Helper()
+		var count int
+		err := TestAdminDB.QueryRow(ctx, "SELECT COUNT(*) FROM tickets WHERE movie_show_id = $1", showID).Scan(&count)
+		if err != nil {
+			t.Fatalf("Failed to count tickets: %v", err)
+		}
+		return count
+	}
+
+	showStatus := func(t *testing.T) ShowStatusEnumType {
+		t.Helper()
+		var status ShowStatusEnumType
+		err := TestAdminDB.QueryRow(ctx, "SELECT status FROM movie_shows WHERE id = $1", showID).Scan(&status)
+		if err != nil {
+			t.Fatalf("Failed to get movie show status: %v", err)
+		}
+		return status
+	}
+
+	availableTickets := func(t *testing.T) []Ticket {
+		t.Helper()
+		req := createRequest(t, "GET", ts.URL+"/tickets/available-movie-show/"+showID, generateToken(t, ""), nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+
+		var page Page[Ticket]
+		parseResponseBody(t, resp, &page)
+		return page.Items
+	}
+
+	tickets := availableTickets(t)
+	if len(tickets) != 1 || tickets[0].SeatID != SeatsData[2].ID || tickets[0].Price <= 0 {
+		t.Fatalf("Expected one priced ticket on seat %s, got %+v", SeatsData[2].ID, tickets)
+	}
+	if n := storedTickets(t); n != 0 {
+		t.Errorf("Expected no stored tickets, got %d", n)
+	}
+	ticketID := tickets[0].ID
+
+	if again := availableTickets(t); again[0].ID != ticketID {
+		t.Errorf("Expected stable ticket id %s, got %s", ticketID, again[0].ID)
+	}
+
+	reserveReq := createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+ticketID, generateToken(t, os.Getenv("CLAIM_ROLE_USER")),
+		TicketStatusData{UserID: user, Reserve: true})
+	reserveResp := executeRequest(t, reserveReq, http.StatusOK)
+	reserveResp.Body.Close()
+
+	if n := storedTickets(t); n != 1 {
+		t.Errorf("Expected reserved ticket to be stored, got %d rows", n)
+	}
+	if status := showStatus(t); status != ShowSoldOut {
+		t.Errorf("Expected show to be sold out, got %s", status)
+	}
+
+	returnReq := createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+ticketID, generateToken(t, os.Getenv("CLAIM_ROLE_USER")),
+		TicketStatusData{UserID: user, Reserve: false})
+	returnResp := executeRequest(t, returnReq, http.StatusOK)
+	returnResp.Body.Close()
+
+	if n := storedTickets(t); n != 0 {
+		t.Errorf("Expected returned ticket to be released, got %d rows", n)
+	}
+	if status := showStatus(t); status != ShowOnSale {
+		t.Errorf("Expected show to be on sale again, got %s", status)
+	}
+	if again := availableTickets(t); len(again) != 1 || again[0].ID != ticketID {
+		t.Errorf("Expected ticket %s to be available again, got %+v", ticketID, again)
+	}
+
+	_, err = TestAdminDB.Exec(ctx,
+		"INSERT INTO seats (hall_id, seat_type_id, row_number, seat_number) VALUES ($1, $2, 4, 1)",
+		SeatsData[2].HallID, SeatsData[2].SeatTypeID)
+	if err != nil {
+		t.Fatalf("Failed to add seat: %v", err)
+	}
+
+	if tickets := availableTickets(t); len(tickets) != 2 {
+		t.Errorf("Expected new seat to be on sale, got %d tickets", len(tickets))
+	}
+}
+
+func TestReserveAccessibleSeat(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	ctx := context.Background()
+	showID := MovieShowsData[3].ID
+	user := UsersData[len(UsersData)-1].ID
+	userToken := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+
+	if _, err := TestAdminDB.Exec(ctx, "INSERT INTO movie_show_prices (movie_show_id, base_price) VALUES ($1, 300)", showID); err != nil {
+		t.Fatalf("Failed to set base price: %v", err)
+	}
+	req := createRequest(t, "PUT", ts.URL+"/seats/"+SeatsData[2].ID+"/accessibility",
+		generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), SeatAccessibility{WheelchairSpace: true})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	var ticketID string
+	if err := TestAdminDB.QueryRow(ctx, "SELECT id FROM movie_show_tickets WHERE movie_show_id = $1 AND seat_id = $2",
+		showID, SeatsData[2].ID).Scan(&ticketID); err != nil {
+		t.Fatalf("Failed to get ticket: %v", err)
+	}
+	reserve := func(status int) {
+		t.Helper()
+		req := createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+ticketID, userToken, TicketStatusData{UserID: user, Reserve: true})
+		executeRequest(t, req, status).Body.Close()
+	}
+
+	// Сеанс через 48 часов: место ещё придержано для маломобильных зрителей
+	reserve(http.StatusConflict)
+	req = createRequest(t, "GET", ts.URL+"/movie-shows/"+showID+"/best-seats?count=1", userToken, nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+
+	req = createRequest(t, "PUT", ts.URL+"/users/"+UsersData[0].ID+"/accessibility", userToken, UserAccessibility{NeedsAccessibility: true})
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/users/"+user+"/accessibility", userToken, UserAccessibility{NeedsAccessibility: true})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	req = createRequest(t, "GET", ts.URL+"/movie-shows/"+showID+"/best-seats?count=1", userToken, nil)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	reserve(http.StatusOK)
+
+	// После открытия общей продажи место доступно всем
+	req = createRequest(t, "PUT", ts.URL+"/tickets/reserve/"+ticketID, userToken, TicketStatusData{UserID: user, Reserve: false})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/users/"+user+"/accessibility", userToken, UserAccessibility{NeedsAccessibility: false})
+	executeRequest(t, req, http.StatusOK).Body.Close()
+	if _, err := TestAdminDB.Exec(ctx, "UPDATE halls SET accessible_release_minutes = 3 * 24 * 60 WHERE id = $1", SeatsData[2].HallID); err != nil {
+		t.Fatalf("Failed to update hall: %v", err)
+	}
+	reserve(http.StatusOK)
+}
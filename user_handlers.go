@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -92,14 +93,33 @@ func validateUserPassword(password string) error {
 	return nil
 }
 
+var userListSpec = listSpec{
+	ID: "id",
+	Sorts: map[string]listSortField{
+		"name":       {Expr: "name", Kind: listText},
+		"email":      {Expr: "email", Kind: listText},
+		"birth_date": {Expr: "birth_date", Kind: listDate},
+	},
+	DefaultSort: "name",
+	Filters: []listFilter{
+		{Param: "is_admin", Kind: listBool, Cond: "COALESCE(is_admin, FALSE) = $%[1]d"},
+		{Param: "accessibility_needs", Kind: listBool, Cond: "accessibility_needs = $%[1]d"},
+	},
+}
+
 // @Summary Получить всех пользователей (admin)
 // @Description Возвращает список всех пользователей.
 // @Tags Пользователи
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {array} User "Список пользователей"
+// @Param is_admin query bool false "Только администраторы (true) или только зрители (false)"
+// @Param accessibility_needs query bool false "Нужны ли места для маломобильных зрителей"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name, email, birth_date; с префиксом - по убыванию"
+// @Success 200 {object} Page[User] "Страница пользователей"
+// @Failure 400 {object} ErrorResponse "Неверные параметры списка"
 // @Failure 403 {object} ErrorResponse "Доступ запрещён"
-// @Failure 404 {object} ErrorResponse "Пользователи не найдены"
 // @Failure 500 {object} ErrorResponse "Ошибка сервера"
 // @Router /users [get]
 func GetUsers(db *pgxpool.Pool) http.HandlerFunc {
@@ -112,30 +132,27 @@ func GetUsers(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
-		rows, err := db.Query(context.Background(),
-			"SELECT id, name, email, birth_date, password_hash, is_admin FROM users")
-		if HandleDatabaseError(w, err, "пользователями") {
+		q, ok := parseListQuery(w, r, userListSpec)
+		if !ok {
 			return
 		}
-		defer rows.Close()
-
-		var users []User
-		var birthDate time.Time
-		for rows.Next() {
-			var u User
-			if err := rows.Scan(&u.ID, &u.Name, &u.Email, &birthDate, &u.PasswordHash, &u.IsAdmin); HandleDatabaseError(w, err, "пользователем") {
-				return
-			}
-			u.BirthDate = birthDate.Format("2006-01-02")
-			users = append(users, u)
-		}
 
-		if len(users) == 0 {
-			http.Error(w, "Пользователи не найдены", http.StatusNotFound)
+		page, err := fetchPage(context.Background(), db, q,
+			"id, name, email, birth_date, password_hash, is_admin, accessibility_needs", "users",
+			func(row pgx.Row) (User, error) {
+				var u User
+				var birthDate time.Time
+				if err := row.Scan(&u.ID, &u.Name, &u.Email, &birthDate, &u.PasswordHash, &u.IsAdmin, &u.NeedsAccessibility); err != nil {
+					return u, err
+				}
+				u.BirthDate = birthDate.Format("2006-01-02")
+				return u, nil
+			})
+		if HandleDatabaseError(w, err, "пользователями") {
 			return
 		}
 
-		json.NewEncoder(w).Encode(users)
+		writePage(w, page)
 	}
 }
 
@@ -167,8 +184,8 @@ func GetUserByID(db *pgxpool.Pool) http.HandlerFunc {
 		var u User
 		var birthDate time.Time
 		err := db.QueryRow(context.Background(),
-			"SELECT id, name, email, birth_date, password_hash FROM users WHERE id = $1", id).
-			Scan(&u.ID, &u.Name, &u.Email, &birthDate, &u.PasswordHash)
+			"SELECT id, name, email, birth_date, password_hash, accessibility_needs FROM users WHERE id = $1", id).
+			Scan(&u.ID, &u.Name, &u.Email, &birthDate, &u.PasswordHash, &u.NeedsAccessibility)
 		u.BirthDate = birthDate.Format("2006-01-02")
 
 		if IsError(w, err) {
@@ -179,6 +196,60 @@ func GetUserByID(db *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
+var notificationListSpec = listSpec{
+	ID:          "id",
+	Sorts:       map[string]listSortField{"created_at": {Expr: "created_at", Kind: listTime}},
+	DefaultSort: "-created_at",
+}
+
+// @Summary Получить уведомления пользователя (user* | admin)
+// @Description Возвращает страницу уведомлений пользователя (например, об отмене сеансов), начиная с новых.
+// @Tags Пользователи
+// @Produce json
+// @Security BearerAuth
+// @Param user_id path string true "ID пользователя"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: created_at или -created_at (по убыванию, по умолчанию)"
+// @Success 200 {object} Page[Notification] "Страница уведомлений"
+// @Failure 400 {object} ErrorResponse "Неверный формат ID или неверные параметры списка"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /users/{user_id}/notifications [get]
+func GetNotificationsByUserID(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := ParseUUIDFromPath(w, r.PathValue("user_id"))
+		if !ok {
+			return
+		}
+
+		role := r.Header.Get("Role")
+		user_id := r.Header.Get("UserID")
+		if (role != os.Getenv("CLAIM_ROLE_ADMIN")) && (userID.String() != user_id) {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		q, ok := parseListQuery(w, r, notificationListSpec)
+		if !ok {
+			return
+		}
+		q.where("user_id = $%[1]d", userID)
+
+		page, err := fetchPage(r.Context(), db, q, "id, user_id, movie_show_id, message, created_at", "notifications",
+			func(row pgx.Row) (Notification, error) {
+				var n Notification
+				err := row.Scan(&n.ID, &n.UserID, &n.MovieShowID, &n.Message, &n.CreatedAt)
+				return n, err
+			})
+		if HandleDatabaseError(w, err, "уведомлением") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
 // @Summary Обновить пользователя (user* | admin)
 // @Description Обновляет данные пользователя.
 // @Tags Пользователи
@@ -232,6 +303,53 @@ func UpdateUser(db *pgxpool.Pool) http.HandlerFunc {
 	}
 }
 
+// @Summary Изменить потребность пользователя в доступной среде (user* | admin)
+// @Description Отмечает, что пользователю нужны места для маломобильных зрителей. Такие пользователи могут бронировать
+// @Description места для колясок и места сопровождающих до того, как они поступят в общую продажу.
+// @Tags Пользователи
+// @Accept json
+// @Security BearerAuth
+// @Param id path string true "ID пользователя"
+// @Param accessibility body UserAccessibility true "Потребность в доступной среде"
+// @Success 200 "Данные пользователя успешно обновлены"
+// @Failure 400 {object} ErrorResponse "В запросе предоставлены неверные данные"
+// @Failure 403 {object} ErrorResponse "Доступ запрещён"
+// @Failure 404 {object} ErrorResponse "Пользователь не найден"
+// @Failure 500 {object} ErrorResponse "Ошибка сервера"
+// @Router /users/{id}/accessibility [put]
+func UpdateUserAccessibility(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		role := r.Header.Get("Role")
+		user_id := r.Header.Get("UserID")
+		if (role != os.Getenv("CLAIM_ROLE_ADMIN")) && (id.String() != user_id) {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		var u UserAccessibility
+		if !DecodeJSONBody(w, r, &u) {
+			return
+		}
+
+		res, err := db.Exec(context.Background(),
+			"UPDATE users SET accessibility_needs=$1 WHERE id=$2", u.NeedsAccessibility, id)
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
 // @Summary Получить nickname по ID пользователя (guest | user | admin)
 // @Description Возвращает nickname по ID пользователя.
 // @Tags Пользователи
@@ -265,7 +383,7 @@ func GetUserNickname(db *pgxpool.Pool) http.HandlerFunc {
 }
 
 // @Summary Изменить статус администратора для пользователя (admin)
-// @Description Изменяет статус администратора для пользователя по ID.
+// @Description Изменяет статус администратора для пользователя по ID. Доступно только администратору всей сети.
 // @Tags Пользователи
 // @Accept json
 // @Produce json
@@ -297,6 +415,11 @@ func UpdateAdminStatusUser(db *pgxpool.Pool) http.HandlerFunc {
 			return
 		}
 
+		// Администратор кинотеатра не может раздавать права на всю сеть
+		if !requireNetworkAdmin(w, r, db) {
+			return
+		}
+
 		res, err := db.Exec(context.Background(),
 			"UPDATE users SET is_admin=$1 WHERE id=$2",
 			u.IsAdmin, id)
@@ -48,10 +48,14 @@ func TestGetUsers(t *testing.T) {
 			defer resp.Body.Close()
 
 			if tt.expectedStatus == http.StatusOK {
-				var users []User
-				parseResponseBody(t, resp, &users)
+				var page Page[User]
+				parseResponseBody(t, resp, &page)
+				users := page.Items
 
-				if len(users) == 0 {
+				if !tt.seedData && (users == nil || len(users) != 0) {
+					t.Errorf("Expected empty users list, got %v", users)
+				}
+				if tt.seedData && len(users) == 0 {
 					t.Error("Expected non-empty users list")
 				}
 			}
@@ -1687,3 +1691,60 @@ func Benchmark_s_i(b *testing.B) {
 		})
 	}
 }
+
+func TestGetNotificationsByUserID(t *testing.T) {
+	cancelShow := func(t *testing.T, ts *httptest.Server) {
+		req := createRequest(t, "POST", ts.URL+"/movie-shows/"+MovieShowsData[2].ID+"/cancel",
+			generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN")), MovieShowCancel{Reason: "Отключение электричества"})
+		resp := executeRequest(t, req, http.StatusOK)
+		resp.Body.Close()
+	}
+
+	tests := []struct {
+		name           string
+		role           string
+		userID         string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{"Own notifications", os.Getenv("CLAIM_ROLE_USER"), UsersData[len(UsersData)-1].ID, http.StatusOK, 1},
+		{"Admin reads user notifications", os.Getenv("CLAIM_ROLE_ADMIN"), UsersData[len(UsersData)-1].ID, http.StatusOK, 1},
+		{"Other user notifications", os.Getenv("CLAIM_ROLE_USER"), UsersData[0].ID, http.StatusForbidden, 0},
+		{"Guest", "", UsersData[len(UsersData)-1].ID, http.StatusForbidden, 0},
+		{"Invalid user ID", os.Getenv("CLAIM_ROLE_ADMIN"), "invalid-uuid", http.StatusBadRequest, 0},
+		{"User without notifications", os.Getenv("CLAIM_ROLE_ADMIN"), UsersData[3].ID, http.StatusOK, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+			cancelShow(t, ts)
+
+			req := createRequest(t, "GET", ts.URL+"/users/"+tt.userID+"/notifications", generateToken(t, tt.role), nil)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var page Page[Notification]
+			parseResponseBody(t, resp, &page)
+			if len(page.Items) != tt.expectedCount {
+				t.Fatalf("Expected %d notifications, got %d", tt.expectedCount, len(page.Items))
+			}
+			if tt.expectedCount == 0 {
+				return
+			}
+			n := page.Items[0]
+			if n.MovieShowID == nil || *n.MovieShowID != MovieShowsData[2].ID {
+				t.Errorf("Expected notification about show %s, got %v", MovieShowsData[2].ID, n.MovieShowID)
+			}
+			if !strings.Contains(n.Message, "Отключение электричества") {
+				t.Errorf("Expected cancellation reason in message, got %q", n.Message)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,6 +22,58 @@ func PrepareStringPointer(input *string) *string {
 	return nil
 }
 
+// ParseClock разбирает время суток в формате HH:MM или HH:MM:SS
+// и возвращает смещение от начала суток. Допускается значение 24:00.
+func ParseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" || s == "24:00:00" {
+		return 24 * time.Hour, nil
+	}
+
+	layout := "15:04"
+	if strings.Count(s, ":") == 2 {
+		layout = "15:04:05"
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// timeZones кэширует загруженные часовые пояса: time.LoadLocation каждый раз читает базу tzdata
+var timeZones sync.Map
+
+// LoadTimeZone возвращает часовой пояс по имени IANA (например, Europe/Moscow).
+// Пояс Local не допускается, чтобы время сеансов не зависело от настроек сервера.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if loc, ok := timeZones.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("неизвестный часовой пояс %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный часовой пояс %q", name)
+	}
+
+	timeZones.Store(name, loc)
+	return loc, nil
+}
+
+// InTimeZone переводит момент времени в указанный часовой пояс.
+// Для неизвестного пояса время возвращается в UTC.
+func InTimeZone(t time.Time, name string) time.Time {
+	loc, err := LoadTimeZone(name)
+	if err != nil {
+		return t.UTC()
+	}
+	return t.In(loc)
+}
+
 func MustParseTime(ts string) time.Time {
 	t, err := time.Parse(time.DateOnly, ts)
 	if err != nil {
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Наценка хранится как DECIMAL(3,2)
+const maxZonePriceModifier = 9.99
+
+const hallZoneColumns = `z.id, z.hall_id, z.name, z.price_modifier,
+	ARRAY(SELECT s.id::text FROM seats s WHERE s.zone_id = z.id ORDER BY s.row_number, s.seat_number)`
+
+func validateHallZoneData(w http.ResponseWriter, z *HallZoneData) bool {
+	z.Name = PrepareString(z.Name)
+
+	if z.Name == "" {
+		http.Error(w, "Название зоны не может быть пустым или состоять только из пробелов", http.StatusBadRequest)
+		return false
+	}
+	if utf8.RuneCountInString(z.Name) > 100 {
+		http.Error(w, "Название зоны не может превышать 100 символов", http.StatusBadRequest)
+		return false
+	}
+
+	if z.PriceModifier <= 0 || z.PriceModifier > maxZonePriceModifier {
+		http.Error(w, "Наценка зоны должна быть больше 0 и не больше 9.99", http.StatusBadRequest)
+		return false
+	}
+
+	seen := make(map[string]bool, len(z.SeatIDs))
+	for _, id := range z.SeatIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			http.Error(w, "Неверный формат ID места", http.StatusBadRequest)
+			return false
+		}
+		if seen[id] {
+			http.Error(w, "Место указано в зоне несколько раз", http.StatusBadRequest)
+			return false
+		}
+		seen[id] = true
+	}
+
+	return true
+}
+
+// assignZoneSeats делает seatIDs точным составом зоны. Места из других зон переходят в эту,
+// места другого зала или несуществующие отклоняются с 400.
+func assignZoneSeats(ctx context.Context, w http.ResponseWriter, tx pgx.Tx, zoneID, hallID string, seatIDs []string) bool {
+	if seatIDs == nil {
+		seatIDs = []string{}
+	}
+
+	_, err := tx.Exec(ctx,
+		"UPDATE seats SET zone_id = NULL WHERE zone_id = $1 AND id <> ALL($2::uuid[])", zoneID, seatIDs)
+	if IsError(w, err) {
+		return false
+	}
+
+	res, err := tx.Exec(ctx,
+		"UPDATE seats SET zone_id = $1 WHERE id = ANY($2::uuid[]) AND hall_id = $3", zoneID, seatIDs, hallID)
+	if IsError(w, err) {
+		return false
+	}
+	if res.RowsAffected() != int64(len(seatIDs)) {
+		http.Error(w, "Не все места зоны найдены в её зале", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+var hallZoneListSpec = nameListSpec("z.id", "z.name")
+
+// @Summary Получить зоны зала (guest | user | admin)
+// @Description Возвращает страницу зон зала, упорядоченных по названию, с местами каждой зоны.
+// @Tags Места
+// @Produce json
+// @Param id path string true "ID зала"
+// @Param limit query int false "Размер страницы, от 1 до 200 (по умолчанию 50)"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor"
+// @Param sort query string false "Сортировка: name или -name (по убыванию)"
+// @Success 200 {object} Page[HallZone] "Страница зон"
+// @Failure 400 {string} string "Неверный формат ID зала или неверные параметры списка"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /halls/{id}/zones [get]
+func GetHallZones(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		q, ok := parseListQuery(w, r, hallZoneListSpec)
+		if !ok {
+			return
+		}
+		q.where("z.hall_id = $%[1]d", hallID)
+
+		page, err := fetchPage(r.Context(), db, q, hallZoneColumns, "hall_zones z",
+			func(row pgx.Row) (HallZone, error) {
+				var z HallZone
+				err := row.Scan(&z.ID, &z.HallID, &z.Name, &z.PriceModifier, &z.SeatIDs)
+				return z, err
+			})
+		if HandleDatabaseError(w, err, "зонами зала") {
+			return
+		}
+
+		writePage(w, page)
+	}
+}
+
+// @Summary Получить зону зала по ID (guest | user | admin)
+// @Description Возвращает зону зала с её местами.
+// @Tags Места
+// @Produce json
+// @Param id path string true "ID зоны"
+// @Success 200 {object} HallZone "Зона зала"
+// @Failure 400 {string} string "Неверный формат ID"
+// @Failure 404 {string} string "Зона не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /zones/{id} [get]
+func GetHallZoneByID(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var z HallZone
+		err := db.QueryRow(r.Context(), `
+			SELECT `+hallZoneColumns+`
+			FROM hall_zones z
+			WHERE z.id = $1`, id).
+			Scan(&z.ID, &z.HallID, &z.Name, &z.PriceModifier, &z.SeatIDs)
+		if IsError(w, err) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(z)
+	}
+}
+
+// @Summary Создать зону зала (admin)
+// @Description Создаёт именованную зону из мест зала. Наценка зоны умножается на наценки типа экрана и типа места
+// @Description при расчёте цены свободных билетов; уже сохранённые билеты цену не меняют.
+// @Tags Места
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID зала"
+// @Param zone body HallZoneData true "Данные зоны"
+// @Success 201 {object} CreateResponse "ID созданной зоны"
+// @Failure 400 {string} string "В запросе предоставлены неверные данные или места другого зала"
+// @Failure 403 {string} string "Доступ запрещён"
+// @Failure 404 {string} string "Кинозал не найден"
+// @Failure 409 {string} string "Зона с таким названием уже есть в зале"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /halls/{id}/zones [post]
+func CreateHallZone(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		hallID, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var z HallZoneData
+		if !DecodeJSONBody(w, r, &z) {
+			return
+		}
+		if !validateHallZoneData(w, &z) {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfHall, hallID) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		var lockedID string
+		err = tx.QueryRow(ctx, "SELECT id FROM halls WHERE id = $1 FOR UPDATE", hallID).Scan(&lockedID)
+		if IsError(w, err) {
+			return
+		}
+
+		id := uuid.New()
+		_, err = tx.Exec(ctx,
+			"INSERT INTO hall_zones (id, hall_id, name, price_modifier) VALUES ($1, $2, $3, $4)",
+			id, hallID, z.Name, z.PriceModifier)
+		if IsError(w, err) {
+			return
+		}
+
+		if !assignZoneSeats(ctx, w, tx, id.String(), hallID.String(), z.SeatIDs) {
+			return
+		}
+
+		if IsError(w, tx.Commit(ctx)) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(id.String())
+	}
+}
+
+// @Summary Обновить зону зала (admin)
+// @Description Меняет название и наценку зоны и заменяет её состав местами из запроса.
+// @Description Места, не попавшие в запрос, выходят из зоны.
+// @Tags Места
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "ID зоны"
+// @Param zone body HallZoneData true "Новые данные зоны"
+// @Success 200 "Данные о зоне успешно обновлены"
+// @Failure 400 {string} string "В запросе предоставлены неверные данные или места другого зала"
+// @Failure 403 {string} string "Доступ запрещён"
+// @Failure 404 {string} string "Зона не найдена"
+// @Failure 409 {string} string "Зона с таким названием уже есть в зале"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /zones/{id} [put]
+func UpdateHallZone(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		var z HallZoneData
+		if !DecodeJSONBody(w, r, &z) {
+			return
+		}
+		if !validateHallZoneData(w, &z) {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfZone, id) {
+			return
+		}
+
+		ctx := r.Context()
+		tx, err := db.Begin(ctx)
+		if IsError(w, err) {
+			return
+		}
+		defer func() {
+			if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+				log.Printf("failed to rollback transaction: %v", err)
+			}
+		}()
+
+		var hallID string
+		err = tx.QueryRow(ctx,
+			"UPDATE hall_zones SET name = $1, price_modifier = $2 WHERE id = $3 RETURNING hall_id",
+			z.Name, z.PriceModifier, id).Scan(&hallID)
+		if IsError(w, err) {
+			return
+		}
+
+		if !assignZoneSeats(ctx, w, tx, id.String(), hallID, z.SeatIDs) {
+			return
+		}
+
+		if IsError(w, tx.Commit(ctx)) {
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// @Summary Удалить зону зала (admin)
+// @Description Удаляет зону; её места остаются в зале без зоны.
+// @Tags Места
+// @Param id path string true "ID зоны"
+// @Security BearerAuth
+// @Success 204 "Зона успешно удалена"
+// @Failure 400 {string} string "Неверный формат ID"
+// @Failure 403 {string} string "Доступ запрещён"
+// @Failure 404 {string} string "Зона не найдена"
+// @Failure 500 {string} string "Ошибка сервера"
+// @Router /zones/{id} [delete]
+func DeleteHallZone(db *pgxpool.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Role") != os.Getenv("CLAIM_ROLE_ADMIN") {
+			http.Error(w, "Доступ запрещён", http.StatusForbidden)
+			return
+		}
+
+		id, ok := ParseUUIDFromPath(w, r.PathValue("id"))
+		if !ok {
+			return
+		}
+
+		if !requireCinemaAccess(w, r, db, cinemaOfZone, id) {
+			return
+		}
+
+		res, err := db.Exec(r.Context(), "DELETE FROM hall_zones WHERE id = $1", id)
+		if IsError(w, err) {
+			return
+		}
+
+		if !CheckRowsAffected(w, res.RowsAffected()) {
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateHallZone(t *testing.T) {
+	admin := os.Getenv("CLAIM_ROLE_ADMIN")
+
+	tests := []struct {
+		name           string
+		role           string
+		hallID         string
+		body           HallZoneData
+		expectedStatus int
+	}{
+		{"Valid zone", admin, HallsData[0].ID, HallZoneData{Name: "VIP", PriceModifier: 1.5, SeatIDs: []string{SeatsData[1].ID, SeatsData[3].ID}}, http.StatusCreated},
+		{"Zone without seats", admin, HallsData[0].ID, HallZoneData{Name: "Балкон", PriceModifier: 0.8}, http.StatusCreated},
+		{"Forbidden User", os.Getenv("CLAIM_ROLE_USER"), HallsData[0].ID, HallZoneData{Name: "VIP", PriceModifier: 1.5}, http.StatusForbidden},
+		{"Empty name", admin, HallsData[0].ID, HallZoneData{Name: "   ", PriceModifier: 1.5}, http.StatusBadRequest},
+		{"Zero modifier", admin, HallsData[0].ID, HallZoneData{Name: "VIP"}, http.StatusBadRequest},
+		{"Modifier too large", admin, HallsData[0].ID, HallZoneData{Name: "VIP", PriceModifier: 10}, http.StatusBadRequest},
+		{"Invalid seat ID", admin, HallsData[0].ID, HallZoneData{Name: "VIP", PriceModifier: 1.5, SeatIDs: []string{"invalid-uuid"}}, http.StatusBadRequest},
+		{"Duplicate seat", admin, HallsData[0].ID, HallZoneData{Name: "VIP", PriceModifier: 1.5, SeatIDs: []string{SeatsData[1].ID, SeatsData[1].ID}}, http.StatusBadRequest},
+		{"Seat of another hall", admin, HallsData[0].ID, HallZoneData{Name: "VIP", PriceModifier: 1.5, SeatIDs: []string{SeatsData[2].ID}}, http.StatusBadRequest},
+		{"Unknown seat", admin, HallsData[0].ID, HallZoneData{Name: "VIP", PriceModifier: 1.5, SeatIDs: []string{uuid.New().String()}}, http.StatusBadRequest},
+		{"Non-existent hall", admin, uuid.New().String(), HallZoneData{Name: "VIP", PriceModifier: 1.5}, http.StatusNotFound},
+		{"Invalid hall ID", admin, "invalid-uuid", HallZoneData{Name: "VIP", PriceModifier: 1.5}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := setupTestServer()
+			SeedAll(TestAdminDB)
+			defer ts.Close()
+
+			req := createRequest(t, "POST", ts.URL+"/halls/"+tt.hallID+"/zones", generateToken(t, tt.role), tt.body)
+			resp := executeRequest(t, req, tt.expectedStatus)
+			defer resp.Body.Close()
+
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+			var id string
+			parseResponseBody(t, resp, &id)
+
+			req = createRequest(t, "GET", ts.URL+"/zones/"+id, "", nil)
+			resp = executeRequest(t, req, http.StatusOK)
+			defer resp.Body.Close()
+			var z HallZone
+			parseResponseBody(t, resp, &z)
+			if z.HallID != tt.hallID || z.Name != tt.body.Name || z.PriceModifier != tt.body.PriceModifier ||
+				len(z.SeatIDs) != len(tt.body.SeatIDs) {
+				t.Errorf("Expected %+v, got %+v", tt.body, z)
+			}
+		})
+	}
+}
+
+func TestHallZoneLifecycle(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	user := generateToken(t, os.Getenv("CLAIM_ROLE_USER"))
+	zonesURL := ts.URL + "/halls/" + HallsData[0].ID + "/zones"
+
+	req := createRequest(t, "GET", zonesURL, "", nil)
+	resp := executeRequest(t, req, http.StatusOK)
+	var zones Page[HallZone]
+	parseResponseBody(t, resp, &zones)
+	resp.Body.Close()
+	if len(zones.Items) != 0 {
+		t.Fatalf("Expected no zones, got %+v", zones.Items)
+	}
+
+	createZone := func(body HallZoneData) string {
+		t.Helper()
+		req := createRequest(t, "POST", zonesURL, admin, body)
+		resp := executeRequest(t, req, http.StatusCreated)
+		defer resp.Body.Close()
+		var id string
+		parseResponseBody(t, resp, &id)
+		return id
+	}
+	seatZone := func(seatID string) *string {
+		t.Helper()
+		req := createRequest(t, "GET", ts.URL+"/seats/"+seatID, "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+		var s Seat
+		parseResponseBody(t, resp, &s)
+		return s.ZoneID
+	}
+
+	vip := createZone(HallZoneData{Name: "VIP", PriceModifier: 1.5, SeatIDs: []string{SeatsData[1].ID}})
+	req = createRequest(t, "POST", zonesURL, admin, HallZoneData{Name: "VIP", PriceModifier: 2})
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+
+	// Место переходит из прежней зоны в новую
+	front := createZone(HallZoneData{Name: "Первый ряд", PriceModifier: 0.9, SeatIDs: []string{SeatsData[0].ID, SeatsData[1].ID}})
+	if z := seatZone(SeatsData[1].ID); z == nil || *z != front {
+		t.Fatalf("Expected seat to move to zone %s, got %v", front, z)
+	}
+
+	req = createRequest(t, "GET", zonesURL, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	zones = Page[HallZone]{}
+	parseResponseBody(t, resp, &zones)
+	resp.Body.Close()
+	if len(zones.Items) != 2 {
+		t.Fatalf("Expected 2 zones, got %+v", zones.Items)
+	}
+	for _, z := range zones.Items {
+		if z.ID == vip && len(z.SeatIDs) != 0 {
+			t.Errorf("Expected VIP zone to be empty, got %+v", z)
+		}
+	}
+
+	update := HallZoneData{Name: "VIP+", PriceModifier: 2, SeatIDs: []string{SeatsData[3].ID, SeatsData[1].ID}}
+	req = createRequest(t, "PUT", ts.URL+"/zones/"+vip, user, update)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/zones/"+vip, admin, HallZoneData{Name: "VIP", PriceModifier: 1, SeatIDs: []string{SeatsData[2].ID}})
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/zones/"+front, admin, HallZoneData{Name: "VIP", PriceModifier: 1})
+	executeRequest(t, req, http.StatusConflict).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/zones/"+uuid.New().String(), admin, update)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "PUT", ts.URL+"/zones/"+vip, admin, update)
+	executeRequest(t, req, http.StatusOK).Body.Close()
+
+	req = createRequest(t, "GET", ts.URL+"/zones/"+vip, "", nil)
+	resp = executeRequest(t, req, http.StatusOK)
+	var z HallZone
+	parseResponseBody(t, resp, &z)
+	resp.Body.Close()
+	// Места зоны упорядочены по ряду и номеру
+	if z.Name != "VIP+" || z.PriceModifier != 2 || len(z.SeatIDs) != 2 ||
+		z.SeatIDs[0] != SeatsData[1].ID || z.SeatIDs[1] != SeatsData[3].ID {
+		t.Errorf("Expected updated zone, got %+v", z)
+	}
+
+	req = createRequest(t, "DELETE", ts.URL+"/zones/"+vip, user, nil)
+	executeRequest(t, req, http.StatusForbidden).Body.Close()
+	req = createRequest(t, "DELETE", ts.URL+"/zones/"+vip, admin, nil)
+	executeRequest(t, req, http.StatusNoContent).Body.Close()
+	req = createRequest(t, "DELETE", ts.URL+"/zones/"+vip, admin, nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/zones/"+vip, "", nil)
+	executeRequest(t, req, http.StatusNotFound).Body.Close()
+	req = createRequest(t, "GET", ts.URL+"/zones/invalid-uuid", "", nil)
+	executeRequest(t, req, http.StatusBadRequest).Body.Close()
+
+	if z := seatZone(SeatsData[3].ID); z != nil {
+		t.Errorf("Expected seat to leave deleted zone, got %v", *z)
+	}
+}
+
+func TestHallZonePricing(t *testing.T) {
+	ts := setupTestServer()
+	SeedAll(TestAdminDB)
+	defer ts.Close()
+
+	if _, err := TestAdminDB.Exec(context.Background(),
+		"INSERT INTO movie_show_prices (movie_show_id, base_price) VALUES ($1, 300)", MovieShowsData[0].ID); err != nil {
+		t.Fatalf("Failed to set show price: %v", err)
+	}
+
+	seatPrice := func(seatID string) float64 {
+		t.Helper()
+		req := createRequest(t, "GET", ts.URL+"/tickets/available-movie-show/"+MovieShowsData[0].ID, "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		defer resp.Body.Close()
+		var page Page[Ticket]
+		parseResponseBody(t, resp, &page)
+		for _, ticket := range page.Items {
+			if ticket.SeatID == seatID {
+				return ticket.Price
+			}
+		}
+		t.Fatalf("No available ticket for seat %s in %+v", seatID, page.Items)
+		return 0
+	}
+
+	before := seatPrice(SeatsData[1].ID)
+	admin := generateToken(t, os.Getenv("CLAIM_ROLE_ADMIN"))
+	req := createRequest(t, "POST", ts.URL+"/halls/"+HallsData[0].ID+"/zones", admin,
+		HallZoneData{Name: "VIP", PriceModifier: 1.5, SeatIDs: []string{SeatsData[1].ID}})
+	executeRequest(t, req, http.StatusCreated).Body.Close()
+
+	if after := seatPrice(SeatsData[1].ID); math.Abs(after-before*1.5) > 0.01 {
+		t.Errorf("Expected zone price %.2f, got %.2f", before*1.5, after)
+	}
+
+	for _, url := range []string{
+		"/halls/" + HallsData[0].ID + "/seat-map.svg",
+		"/tickets/seat-map/" + MovieShowsData[0].ID,
+	} {
+		req = createRequest(t, "GET", ts.URL+url, "", nil)
+		resp := executeRequest(t, req, http.StatusOK)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		for _, want := range []string{"Зона VIP ×1.5", ", зона VIP", `stroke="` + seatZonePalette[0] + `"`} {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("Expected %s to contain %q", url, want)
+			}
+		}
+	}
+}